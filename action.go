@@ -1,6 +1,18 @@
 package juice
 
 // Action defines a sql action.
+//
+// The action of a statement is derived from its element name, for example <select> is a Select.
+// The action attribute overrides it for the statements whose element doesn't tell what they do,
+// for example a select which writes data with a CTE:
+//
+//	<select id="ArchiveUsers" action="delete">
+//	    WITH archived AS (DELETE FROM users WHERE ... RETURNING *) SELECT * FROM archived
+//	</select>
+//
+// The overridden action is reported by Statement.Action, so the statement is treated as a write:
+// its result is never cached and the cache is flushed after it runs, unless flushCache is "false".
+// The element name still decides which child nodes are allowed, like values for insert.
 type Action string
 
 const (
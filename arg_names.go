@@ -0,0 +1,116 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/go-juicedev/juice/driver"
+)
+
+// NamedArg is an arg of a built statement with the name of its parameter, see BuildNamedArgs.
+type NamedArg struct {
+	// Name is the name of the parameter, like user.id, empty if unknown.
+	Name  string
+	Value any
+}
+
+// String returns the arg in the form of name=value, for the logs.
+func (a NamedArg) String() string {
+	if a.Name == "" {
+		return fmt.Sprintf("?=%v", a.Value)
+	}
+	return fmt.Sprintf("%s=%v", a.Name, a.Value)
+}
+
+// argNamesKey is the context key of the *argNamesTranslator recording the names of the built args.
+type argNamesKey struct{}
+
+// argNamesTranslator is a Translator which records the names of the parameters in the order
+// of their placeholders, which is the order of the args.
+type argNamesTranslator struct {
+	driver.Translator
+	names []string
+}
+
+// Translate implements driver.Translator.
+func (t *argNamesTranslator) Translate(matched string) string {
+	t.names = append(t.names, matched)
+	return t.Translator.Translate(matched)
+}
+
+// Unwrap returns the wrapped translator, so that the capabilities of the driver are reachable.
+func (t *argNamesTranslator) Unwrap() driver.Translator {
+	return t.Translator
+}
+
+// argNamesTranslatorFromContext wraps the translator to record the names of the args
+// if the context is made by BuildNamedArgs, otherwise it returns the translator as it is.
+func argNamesTranslatorFromContext(ctx context.Context, translator driver.Translator) driver.Translator {
+	if recorder, ok := ctx.Value(argNamesKey{}).(*argNamesTranslator); ok {
+		recorder.Translator = translator
+		return recorder
+	}
+	return translator
+}
+
+// BuildNamedArgs builds the statement of the executor the same way as it is executed, and returns
+// the query with the args named by their parameters, so that the logs and the tools can show them
+// in a readable form, like id=42 for #{id}, instead of by position:
+//
+//	query, args, err := juice.BuildNamedArgs(ctx, engine.Object(QueryUser), param)
+//	log.Println(query, args) // SELECT * FROM user WHERE id = ? [id=42]
+//
+// The args of a foreach node are named by its item, and the args added by the interceptors and
+// the rewriters are unnamed. Nothing is executed, and the names are recorded only by this build.
+func BuildNamedArgs(ctx context.Context, executor SQLRowsExecutor, param Param) (string, []NamedArg, error) {
+	if exe, ok := isInvalidExecutor(executor); ok {
+		return "", nil, exe.err
+	}
+	recorder := &argNamesTranslator{}
+	ctx = context.WithValue(ctx, argNamesKey{}, recorder)
+	var (
+		query string
+		args  []any
+		err   error
+	)
+	if builder, ok := executor.(interface {
+		build(ctx context.Context, param Param) (string, []any, error)
+	}); ok {
+		query, args, err = builder.build(ctx, param)
+	} else {
+		query, args, err = executor.Statement().Build(argNamesTranslatorFromContext(ctx, executor.Driver().Translator()), param)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	named := make([]NamedArg, len(args))
+	for i, arg := range args {
+		// the args of the drivers which bind the args by name are named already.
+		if namedArg, ok := arg.(sql.NamedArg); ok {
+			named[i] = NamedArg{Name: namedArg.Name, Value: namedArg.Value}
+			continue
+		}
+		named[i].Value = arg
+		if i < len(recorder.names) {
+			named[i].Name = recorder.names[i]
+		}
+	}
+	return query, named, nil
+}
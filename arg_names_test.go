@@ -0,0 +1,61 @@
+package juice
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	juicedriver "github.com/go-juicedev/juice/driver"
+)
+
+func TestBuildNamedArgs(t *testing.T) {
+	db, state := newFakeDB(t, fakeResultSet{})
+	stmt := parseTestStatement(t, Select, `<select id="QueryUsers">
+		SELECT * FROM user WHERE status = #{status} AND id IN
+		<foreach collection="ids" item="id" open="(" separator=", " close=")">#{id}</foreach>
+		<limit value="limit"/>
+	</select>`)
+	executor := newFakeExecutor(db, stmt)
+
+	query, args, err := BuildNamedArgs(context.Background(), executor, H{"status": 1, "ids": []int{7, 8}, "limit": 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM user WHERE status = ? AND id IN (?, ?) LIMIT ? OFFSET ?" {
+		t.Errorf("unexpected query: %s", query)
+	}
+	want := []NamedArg{{"status", 1}, {"id", 7}, {"id", 8}, {"limit", int64(10)}, {"offset", int64(0)}}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("unexpected args: %#v", args)
+	}
+	if args[0].String() != "status=1" {
+		t.Errorf("unexpected string: %s", args[0])
+	}
+	if len(state.executions) != 0 {
+		t.Errorf("unexpected executions: %v", state.executions)
+	}
+
+	// the executions don't record the names.
+	rows, err := executor.QueryContext(context.Background(), H{"status": 1, "ids": []int{7}, "limit": 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = rows.Close()
+}
+
+func TestBuildNamedArgs_NamedArgsDriver(t *testing.T) {
+	db, _ := newFakeDB(t, fakeResultSet{})
+	drv := juicedriver.SQLServerDriver{NamedArgs: true}
+	executor := &sqlRowsExecutor{
+		statement:        newFakeStatement("SELECT * FROM user WHERE owner = #{id} OR creator = #{id}"),
+		statementHandler: NewDefaultStatementHandler(drv, db),
+		driver:           drv,
+	}
+	_, args, err := BuildNamedArgs(context.Background(), executor, H{"id": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(args, []NamedArg{{"id", 1}}) {
+		t.Errorf("unexpected args: %#v", args)
+	}
+}
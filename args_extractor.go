@@ -0,0 +1,94 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/go-juicedev/juice/internal/reflectlite"
+)
+
+// ArgsExtractor is the write side counterpart of RowScanner. A type implements it to
+// control how it is expanded into the bind args of a #{} placeholder, instead of being
+// bound as a single value.
+//
+// The placeholder is expanded into one placeholder for each extracted arg, separated
+// by commas. For example, with the Money type below:
+//
+//	type Money struct {
+//	    Amount   decimal.Decimal
+//	    Currency string
+//	}
+//
+//	func (m Money) ExtractArgs() ([]any, error) {
+//	    return []any{m.Amount, m.Currency}, nil
+//	}
+//
+//	<insert id="CreateOrder">
+//	    INSERT INTO orders (id, amount, currency) VALUES (#{id}, #{price})
+//	</insert>
+//
+// the statement is built as:
+//
+//	INSERT INTO orders (id, amount, currency) VALUES (?, ?, ?)
+//
+// The extractor takes precedence over the type handlers and driver.Valuer, but not over
+// the JSON columns. A nil pointer is bound as NULL without calling the extractor.
+type ArgsExtractor interface {
+	// ExtractArgs returns the bind args of the value, at least one.
+	ExtractArgs() ([]any, error)
+}
+
+// argsExtractorOf returns the ArgsExtractor implemented by the value or its pointer.
+func argsExtractorOf(value reflect.Value) (ArgsExtractor, bool) {
+	if !value.IsValid() {
+		return nil, false
+	}
+	if reflectlite.NilAble(value) && value.IsNil() {
+		return nil, false
+	}
+	if value.Kind() == reflect.Interface {
+		return argsExtractorOf(value.Elem())
+	}
+	if value.CanInterface() {
+		if extractor, ok := value.Interface().(ArgsExtractor); ok {
+			return extractor, true
+		}
+	}
+	if value.CanAddr() && value.Addr().CanInterface() {
+		extractor, ok := value.Addr().Interface().(ArgsExtractor)
+		return extractor, ok
+	}
+	return nil, false
+}
+
+// extractArgs returns the args extracted by the ArgsExtractor of the named parameter.
+func extractArgs(p Parameter, name string, value reflect.Value) ([]any, bool, error) {
+	extractor, ok := argsExtractorOf(value)
+	if !ok || isJSONParameter(p, name) {
+		return nil, false, nil
+	}
+	args, err := extractor.ExtractArgs()
+	if err != nil {
+		return nil, true, fmt.Errorf("parameter %s: %w", name, err)
+	}
+	if len(args) == 0 {
+		return nil, true, fmt.Errorf("parameter %s: no args extracted", name)
+	}
+	return args, true, nil
+}
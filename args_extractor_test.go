@@ -0,0 +1,73 @@
+package juice
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-juicedev/juice/driver"
+)
+
+type extractorMoney struct {
+	Amount   int64
+	Currency string
+}
+
+func (m extractorMoney) ExtractArgs() ([]any, error) {
+	if m.Currency == "" {
+		return nil, errors.New("currency is required")
+	}
+	return []any{m.Amount, m.Currency}, nil
+}
+
+type extractorPoint struct {
+	X, Y float64
+}
+
+func (p *extractorPoint) ExtractArgs() ([]any, error) {
+	return []any{p.X, p.Y}, nil
+}
+
+func TestArgsExtractor(t *testing.T) {
+	node := NewTextNode("INSERT INTO orders (id, amount, currency) VALUES (#{id}, #{price})")
+	param := H{"id": 1, "price": extractorMoney{Amount: 100, Currency: "EUR"}}
+	query, args, err := node.Accept(driver.PostgresDriver{}.Translator(), param.AsParam())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "INSERT INTO orders (id, amount, currency) VALUES ($1, $2, $3)" {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if !reflect.DeepEqual(args, []any{1, int64(100), "EUR"}) {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	param = H{"id": 1, "price": extractorMoney{Amount: 100}}
+	if _, _, err = node.Accept(driver.PostgresDriver{}.Translator(), param.AsParam()); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestArgsExtractor_PointerReceiver(t *testing.T) {
+	type Shape struct {
+		Center extractorPoint
+	}
+	node := NewTextNode("SELECT * FROM shape WHERE center = point(#{Center})")
+	query, args, err := node.Accept(driver.MySQLDriver{}.Translator(), newGenericParam(&Shape{Center: extractorPoint{X: 1, Y: 2}}, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM shape WHERE center = point(?, ?)" || !reflect.DeepEqual(args, []any{1.0, 2.0}) {
+		t.Errorf("unexpected result: %s %v", query, args)
+	}
+
+	// a nil pointer is bound as NULL.
+	var point *extractorPoint
+	query, args, err = NewTextNode("#{point}").Accept(driver.MySQLDriver{}.Translator(), H{"point": point}.AsParam())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "?" || len(args) != 1 || args[0] != point {
+		t.Errorf("unexpected result: %s %v", query, args)
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type auditActorKey struct{}
+
+// ContextWithAuditActor returns a new context with the given actor.
+// The actor identifies who runs the statements, such as a user id or a service name,
+// and is recorded by the AuditMiddleware.
+func ContextWithAuditActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// AuditActorFromContext returns the actor from the context.
+// It returns an empty string if no actor is set.
+func AuditActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(auditActorKey{}).(string)
+	return actor
+}
+
+// AuditRecord is a record of an executed statement.
+type AuditRecord struct {
+	// Actor is who ran the statement, see ContextWithAuditActor.
+	Actor string
+
+	// Statement is the fully qualified id of the statement, see Statement.Name.
+	Statement string
+
+	// Action is the action of the statement.
+	Action Action
+
+	// Time is when the statement started to execute.
+	Time time.Time
+
+	// Args are the args of the statement.
+	// Only set when AuditMiddleware.IncludeArgs is true.
+	// The args which implement slog.LogValuer are recorded by their LogValue.
+	Args []any
+
+	// RowsAffected is the number of rows affected by an exec statement.
+	// It is zero for the queries, or when the driver doesn't report it.
+	RowsAffected int64
+
+	// Err is the error returned by the execution, if any.
+	Err error
+}
+
+// AuditSink receives the audit records.
+// Implementations must be safe for concurrent use.
+type AuditSink interface {
+	Record(ctx context.Context, record AuditRecord)
+}
+
+// AuditSinkFunc is an adapter to allow the use of ordinary functions as AuditSink.
+type AuditSinkFunc func(ctx context.Context, record AuditRecord)
+
+// Record implements AuditSink.
+func (f AuditSinkFunc) Record(ctx context.Context, record AuditRecord) {
+	f(ctx, record)
+}
+
+// NoOpAuditSink is an AuditSink that does nothing.
+type NoOpAuditSink struct{}
+
+// Record implements AuditSink.
+func (NoOpAuditSink) Record(context.Context, AuditRecord) {}
+
+// ensure AuditMiddleware implements Middleware.
+var _ Middleware = (*AuditMiddleware)(nil) // compile time check
+
+// AuditMiddleware is a middleware that records who ran which statement for security audit trails.
+// It reads the actor from the context, see ContextWithAuditActor.
+// The args of the statement are not recorded unless IncludeArgs is true.
+type AuditMiddleware struct {
+	// Sink receives the audit records.
+	// If nil, NoOpAuditSink is used.
+	Sink AuditSink
+
+	// IncludeArgs reports whether the args of the statement should be recorded.
+	IncludeArgs bool
+}
+
+// QueryContext implements Middleware.
+// QueryContext will record the executed query statement.
+func (m *AuditMiddleware) QueryContext(stmt Statement, next QueryHandler) QueryHandler {
+	return func(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+		start := time.Now()
+		rows, err := next(ctx, query, args...)
+		m.record(ctx, stmt, start, args, 0, err)
+		return rows, err
+	}
+}
+
+// ExecContext implements Middleware.
+// ExecContext will record the executed exec statement.
+func (m *AuditMiddleware) ExecContext(stmt Statement, next ExecHandler) ExecHandler {
+	return func(ctx context.Context, query string, args ...any) (sql.Result, error) {
+		start := time.Now()
+		result, err := next(ctx, query, args...)
+		var affected int64
+		if err == nil && result != nil {
+			// the result is a CachedResult, reading it here doesn't consume it.
+			affected, _ = result.RowsAffected()
+		}
+		m.record(ctx, stmt, start, args, affected, err)
+		return result, err
+	}
+}
+
+// record sends the audit record to the sink.
+func (m *AuditMiddleware) record(ctx context.Context, stmt Statement, start time.Time, args []any, affected int64, err error) {
+	sink := m.Sink
+	if sink == nil {
+		sink = NoOpAuditSink{}
+	}
+	record := AuditRecord{
+		Actor:        AuditActorFromContext(ctx),
+		Statement:    stmt.Name(),
+		Action:       stmt.Action(),
+		Time:         start,
+		RowsAffected: affected,
+		Err:          err,
+	}
+	if m.IncludeArgs {
+		record.Args = logArgs(args)
+	}
+	sink.Record(ctx, record)
+}
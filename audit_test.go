@@ -0,0 +1,84 @@
+package juice
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"reflect"
+	"testing"
+)
+
+func TestAuditMiddleware(t *testing.T) {
+	stmt := &xmlSQLStatement{
+		mapper: &Mapper{namespace: "main.UserMapper", mappers: &Mappers{}},
+		action: Update,
+		id:     "UpdateUser",
+	}
+	var records []AuditRecord
+	sink := AuditSinkFunc(func(_ context.Context, record AuditRecord) {
+		records = append(records, record)
+	})
+	next := func(ctx context.Context, query string, args ...any) (sql.Result, error) {
+		return nil, nil
+	}
+	ctx := ContextWithAuditActor(context.Background(), "alice")
+
+	middleware := &AuditMiddleware{Sink: sink}
+	if _, err := middleware.ExecContext(stmt, next)(ctx, "UPDATE user SET name = ?", "bob"); err != nil {
+		t.Fatal(err)
+	}
+	middleware.IncludeArgs = true
+	if _, err := middleware.ExecContext(stmt, next)(ctx, "UPDATE user SET name = ?", "bob"); err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	record := records[0]
+	if record.Actor != "alice" {
+		t.Errorf("unexpected actor: %s", record.Actor)
+	}
+	if record.Statement != "main.UserMapper.UpdateUser" {
+		t.Errorf("unexpected statement: %s", record.Statement)
+	}
+	if record.Action != Update {
+		t.Errorf("unexpected action: %s", record.Action)
+	}
+	if record.Time.IsZero() {
+		t.Error("time should be set")
+	}
+	if record.Args != nil {
+		t.Error("args should not be recorded by default")
+	}
+	if args := records[1].Args; len(args) != 1 || args[0] != "bob" {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
+
+// auditPassword redacts itself in the logs.
+type auditPassword string
+
+func (auditPassword) LogValue() slog.Value { return slog.StringValue("******") }
+
+func TestAuditMiddleware_LogValuer(t *testing.T) {
+	var records []AuditRecord
+	middleware := &AuditMiddleware{
+		Sink:        AuditSinkFunc(func(_ context.Context, record AuditRecord) { records = append(records, record) }),
+		IncludeArgs: true,
+	}
+	var executed []any
+	handler := middleware.ExecContext(newFakeStatement("UPDATE user SET password = #{password}"), func(_ context.Context, _ string, args ...any) (sql.Result, error) {
+		executed = args
+		return nil, nil
+	})
+	if _, err := handler(context.Background(), "UPDATE user SET password = ? WHERE id = ?", auditPassword("secret"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || !reflect.DeepEqual(records[0].Args, []any{"******", 1}) {
+		t.Errorf("unexpected records: %+v", records)
+	}
+	// the executed args are not changed.
+	if !reflect.DeepEqual(executed, []any{auditPassword("secret"), 1}) {
+		t.Errorf("unexpected executed args: %v", executed)
+	}
+}
@@ -18,6 +18,7 @@ package juice
 
 import (
 	"database/sql"
+	"fmt"
 	"reflect"
 	"time"
 )
@@ -38,7 +39,8 @@ var (
 //   - rows: The source sql.Rows to map from. Must not be nil.
 //   - v: The destination value to map to. Must be a pointer and not nil.
 //   - resultMap: The mapping strategy to use. If nil, a default mapper will be selected
-//     based on the destination type (SingleRowResultMap for struct, MultiRowsResultMap for slice).
+//     based on the destination type (SingleRowResultMap for struct, MultiRowsResultMap for slice,
+//     MapResultMap for map[string]any and []map[string]any).
 //
 // The function follows this process:
 // 1. Validates input parameters
@@ -69,7 +71,11 @@ func bindWithResultMap(rows *sql.Rows, v any, resultMap ResultMap) error {
 
 	// Select default mapper if none provided
 	if resultMap == nil {
-		if kd := reflect.Indirect(rv).Kind(); kd == reflect.Slice {
+		if target := reflect.Indirect(rv).Type(); isStringAnyMap(target) ||
+			(target.Kind() == reflect.Slice && isStringAnyMap(target.Elem())) {
+			// map[string]any and []map[string]any are mapped by column name.
+			resultMap = MapResultMap{}
+		} else if kd := reflect.Indirect(rv).Kind(); kd == reflect.Slice {
 			resultMap = MultiRowsResultMap{}
 		} else {
 			resultMap = SingleRowResultMap{}
@@ -84,10 +90,18 @@ func bindWithResultMap(rows *sql.Rows, v any, resultMap ResultMap) error {
 // dest can be a pointer to a struct, a pointer to a slice of struct, or a pointer to a slice of any type.
 // rows won't be closed when the function returns.
 func BindWithResultMap[T any](rows *sql.Rows, resultMap ResultMap) (result T, err error) {
+	// the scalars of a single column are scanned without reflection.
+	if resultMap == nil && rows != nil {
+		var ok bool
+		if result, ok, err = bindScalar[T](rows); ok || err != nil {
+			return result, err
+		}
+	}
 	// ptr is the pointer of the result, it is the destination of the binding.
 	var ptr any = &result
 
-	if _type := reflect.TypeOf(result); _type.Kind() == reflect.Ptr {
+	// the type of an interface result, like any, is nil.
+	if _type := reflect.TypeOf(result); _type != nil && _type.Kind() == reflect.Ptr {
 		// if the result is a pointer, create a new instance of the element.
 		// you'd better not use a nil pointer as the result.
 		result = reflect.New(_type.Elem()).Interface().(T)
@@ -163,6 +177,58 @@ func List[T any](rows *sql.Rows) (result []T, err error) {
 	return
 }
 
+// One binds the only row of the rows to the given entity type with SingleRowResultMap,
+// and closes the rows.
+// It returns sql.ErrNoRows if there are no rows, and ErrTooManyRows if there is more than one row.
+//
+// Example_one shows how to use the One function:
+//
+//	rows, err := db.Query("SELECT id, name FROM users WHERE id = ?", id)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	user, err := One[User](rows)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func One[T any](rows *sql.Rows) (result T, err error) {
+	if rows == nil {
+		return result, ErrNilRows
+	}
+	defer func() { _ = rows.Close() }()
+	return BindWithResultMap[T](rows, SingleRowResultMap{})
+}
+
+// Scalar binds the single value of the rows to the given type, and closes the rows.
+// It is useful for the queries like SELECT COUNT(*) or SELECT MAX(id).
+// It returns ErrTooManyColumns if there is more than one column, sql.ErrNoRows if there
+// are no rows, and ErrTooManyRows if there is more than one row.
+//
+// Example_scalar shows how to use the Scalar function:
+//
+//	rows, err := db.Query("SELECT COUNT(*) FROM users")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	count, err := Scalar[int64](rows)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func Scalar[T any](rows *sql.Rows) (result T, err error) {
+	if rows == nil {
+		return result, ErrNilRows
+	}
+	defer func() { _ = rows.Close() }()
+	columns, err := rows.Columns()
+	if err != nil {
+		return result, fmt.Errorf("failed to get columns: %w", err)
+	}
+	if len(columns) > 1 {
+		return result, fmt.Errorf("%w: expected 1 column, got %d", ErrTooManyColumns, len(columns))
+	}
+	return BindWithResultMap[T](rows, SingleRowResultMap{})
+}
+
 // List2 converts database query results into a slice of pointers.
 // Unlike List function, List2 returns a slice of pointers []*T instead of a slice of values []T.
 // This is particularly useful when you need to modify slice elements or handle large structs.
@@ -0,0 +1,81 @@
+package juice
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+type binderUser struct {
+	ID   int64  `column:"id"`
+	Name string `column:"name"`
+}
+
+func TestOne(t *testing.T) {
+	columns := []string{"id", "name"}
+	rows := queryFakeRows(t, fakeResultSet{columns: columns, rows: [][]driver.Value{{int64(1), "a"}}})
+	user, err := One[binderUser](rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user != (binderUser{ID: 1, Name: "a"}) {
+		t.Errorf("unexpected user: %v", user)
+	}
+	if rows.Next() {
+		t.Error("expected the rows to be closed")
+	}
+
+	pointer, err := One[*binderUser](queryFakeRows(t, fakeResultSet{columns: columns, rows: [][]driver.Value{{int64(2), "b"}}}))
+	if err != nil || pointer == nil || pointer.ID != 2 {
+		t.Errorf("unexpected user: %v, %v", pointer, err)
+	}
+
+	if _, err = One[binderUser](queryFakeRows(t, fakeResultSet{columns: columns})); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows, got %v", err)
+	}
+	multi := fakeResultSet{columns: columns, rows: [][]driver.Value{{int64(1), "a"}, {int64(2), "b"}}}
+	if _, err = One[binderUser](queryFakeRows(t, multi)); !errors.Is(err, ErrTooManyRows) {
+		t.Errorf("expected ErrTooManyRows, got %v", err)
+	}
+	if _, err = One[binderUser](nil); !errors.Is(err, ErrNilRows) {
+		t.Errorf("expected ErrNilRows, got %v", err)
+	}
+}
+
+func TestScalar(t *testing.T) {
+	count, err := Scalar[int64](queryFakeRows(t, fakeResultSet{columns: []string{"count"}, rows: [][]driver.Value{{int64(42)}}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 42 {
+		t.Errorf("unexpected count: %d", count)
+	}
+
+	name, err := Scalar[sql.NullString](queryFakeRows(t, fakeResultSet{columns: []string{"name"}, rows: [][]driver.Value{{nil}}}))
+	if err != nil || name.Valid {
+		t.Errorf("unexpected name: %v, %v", name, err)
+	}
+
+	if _, err = Scalar[int64](queryFakeRows(t, fakeResultSet{columns: []string{"count"}})); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows, got %v", err)
+	}
+	multi := fakeResultSet{columns: []string{"count"}, rows: [][]driver.Value{{int64(1)}, {int64(2)}}}
+	if _, err = Scalar[int64](queryFakeRows(t, multi)); !errors.Is(err, ErrTooManyRows) {
+		t.Errorf("expected ErrTooManyRows, got %v", err)
+	}
+	wide := fakeResultSet{columns: []string{"id", "name"}, rows: [][]driver.Value{{int64(1), "a"}}}
+	if _, err = Scalar[int64](queryFakeRows(t, wide)); !errors.Is(err, ErrTooManyColumns) {
+		t.Errorf("expected ErrTooManyColumns, got %v", err)
+	}
+}
+
+func TestList_Empty(t *testing.T) {
+	users, err := List[binderUser](queryFakeRows(t, fakeResultSet{columns: []string{"id", "name"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if users == nil || len(users) != 0 {
+		t.Errorf("expected an empty slice, got %v", users)
+	}
+}
@@ -3,12 +3,16 @@ package cache
 import (
 	"context"
 	"errors"
+	"strings"
 )
 
 // ErrCacheNotFound is the error that cache not found.
 var ErrCacheNotFound = errors.New("juice: cache not found")
 
 // ScopeCache is an interface for transactional cache.
+//
+// The keys are prefixed with the mapper namespace of the statement and a colon,
+// see NamespaceKey, so that the results of a namespace can be flushed together.
 type ScopeCache interface {
 	// Set sets the value for the key.
 	Set(ctx context.Context, key string, value any) error
@@ -20,4 +24,32 @@ type ScopeCache interface {
 	// Flush flushes all the cache.
 	// It will be called after Commit() or Rollback().
 	Flush(ctx context.Context) error
+
+	// FlushNamespace flushes the values of the namespace, whose keys start with NamespaceKey(namespace, "").
+	// It will be called after a statement of the namespace whose flushCache is true is executed.
+	FlushNamespace(ctx context.Context, namespace string) error
+}
+
+// namespaceSeparator separates the namespace from the rest of the key.
+// The namespaces never contain it, since they are dot-separated identifiers.
+const namespaceSeparator = ":"
+
+// NamespaceKey returns the key prefixed with the namespace, which is the key scheme of the cached results:
+//
+//	<namespace>:<key>
+//
+// For example "main.UserMapper:3f2a...". A ScopeCache flushes a namespace by deleting the keys
+// with the prefix "<namespace>:", or by any other way as long as they are not found afterward.
+func NamespaceKey(namespace, key string) string {
+	return namespace + namespaceSeparator + key
+}
+
+// SplitNamespaceKey splits the key built by NamespaceKey into the namespace and the rest of the key.
+// The namespace is empty if the key is not prefixed with a namespace.
+func SplitNamespaceKey(key string) (namespace, rest string) {
+	namespace, rest, found := strings.Cut(key, namespaceSeparator)
+	if !found {
+		return "", key
+	}
+	return namespace, rest
 }
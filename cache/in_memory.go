@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/gob"
+	"strings"
 	"sync"
 )
 
@@ -67,6 +68,19 @@ func (m *inMemoryScopeCache) Flush(_ context.Context) error {
 	return nil
 }
 
+// FlushNamespace clears the data whose keys are prefixed with the namespace.
+func (m *inMemoryScopeCache) FlushNamespace(_ context.Context, namespace string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prefix := NamespaceKey(namespace, "")
+	for key := range m.data {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.data, key)
+		}
+	}
+	return nil
+}
+
 // InMemoryScopeCache returns a new instance of inMemoryScopeCache.
 func InMemoryScopeCache() ScopeCache {
 	return new(inMemoryScopeCache)
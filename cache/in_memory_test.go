@@ -153,3 +153,36 @@ func BenchmarkInMemoryScopeCache_Flush(b *testing.B) {
 		_ = c.Flush(ctx)
 	}
 }
+
+func TestInMemoryScopeCache_FlushNamespace(t *testing.T) {
+	c := InMemoryScopeCache()
+	ctx := context.Background()
+	users := NamespaceKey("main.UserMapper", "key")
+	orders := NamespaceKey("main.OrderMapper", "key")
+	for _, key := range []string{users, orders} {
+		if err := c.Set(ctx, key, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.FlushNamespace(ctx, "main.UserMapper"); err != nil {
+		t.Fatal(err)
+	}
+	var value int
+	if err := c.Get(ctx, users, &value); !errors.Is(err, ErrCacheNotFound) {
+		t.Errorf("expected ErrCacheNotFound, got %v", err)
+	}
+	if err := c.Get(ctx, orders, &value); err != nil || value != 1 {
+		t.Errorf("expected 1, got %d, %v", value, err)
+	}
+}
+
+func TestSplitNamespaceKey(t *testing.T) {
+	namespace, rest := SplitNamespaceKey(NamespaceKey("main.UserMapper", "abc"))
+	if namespace != "main.UserMapper" || rest != "abc" {
+		t.Errorf("unexpected namespace %q and rest %q", namespace, rest)
+	}
+	namespace, rest = SplitNamespaceKey("abc")
+	if namespace != "" || rest != "abc" {
+		t.Errorf("unexpected namespace %q and rest %q", namespace, rest)
+	}
+}
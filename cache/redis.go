@@ -0,0 +1,200 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"time"
+)
+
+// RedisClient is the subset of the Redis commands used by the redis ScopeCache.
+// Implement it with the client of your choice, for example with go-redis:
+//
+//	type goRedisClient struct{ *redis.Client }
+//
+//	func (c goRedisClient) Get(ctx context.Context, key string) ([]byte, error) {
+//	    data, err := c.Client.Get(ctx, key).Bytes()
+//	    if errors.Is(err, redis.Nil) {
+//	        return nil, cache.ErrCacheNotFound
+//	    }
+//	    return data, err
+//	}
+//
+//	func (c goRedisClient) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+//	    return c.Client.Set(ctx, key, value, ttl).Err()
+//	}
+//
+//	func (c goRedisClient) Incr(ctx context.Context, key string) (int64, error) {
+//	    return c.Client.Incr(ctx, key).Result()
+//	}
+type RedisClient interface {
+	// Get returns the value of the key.
+	// If the key does not exist, it should return ErrCacheNotFound.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Set sets the value of the key, which expires after the ttl.
+	// A zero ttl means the key never expires.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Incr increments the integer value of the key by one and returns the new value.
+	Incr(ctx context.Context, key string) (int64, error)
+}
+
+// Codec serializes the cached values.
+type Codec interface {
+	Marshal(value any) ([]byte, error)
+	Unmarshal(data []byte, ptr any) error
+}
+
+// GobCodec is a Codec which uses encoding/gob, the same encoding as InMemoryScopeCache.
+type GobCodec struct{}
+
+// Marshal implements Codec.
+func (GobCodec) Marshal(value any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal implements Codec.
+func (GobCodec) Unmarshal(data []byte, ptr any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(ptr)
+}
+
+// redisOption is the option of the redis ScopeCache.
+type redisOption struct {
+	codec  Codec
+	ttl    time.Duration
+	prefix string
+}
+
+// RedisOptionFunc is a function to set the option of the redis ScopeCache.
+type RedisOptionFunc func(*redisOption)
+
+// RedisWithCodec sets the codec of the cached values, GobCodec by default.
+func RedisWithCodec(codec Codec) RedisOptionFunc {
+	return func(option *redisOption) {
+		option.codec = codec
+	}
+}
+
+// RedisWithTTL sets the time to live of the cached values, which never expire by default.
+// Setting a ttl is recommended, since the flushed values are left to expire.
+func RedisWithTTL(ttl time.Duration) RedisOptionFunc {
+	return func(option *redisOption) {
+		option.ttl = ttl
+	}
+}
+
+// RedisWithKeyPrefix sets the prefix of the keys, "juice" by default.
+func RedisWithKeyPrefix(prefix string) RedisOptionFunc {
+	return func(option *redisOption) {
+		option.prefix = prefix
+	}
+}
+
+// redisScopeCache is a ScopeCache backed by Redis, which is shared by the instances of an application.
+//
+// The keys are versioned by generations, a global one and one for each mapper namespace,
+// so that flushing is a single INCR command instead of deleting the keys one by one:
+//
+//	<prefix>:<global generation>:<namespace>:<namespace generation>:<rest of the key>
+//
+// The namespace is the one of the key built by NamespaceKey.
+type redisScopeCache struct {
+	client RedisClient
+	redisOption
+}
+
+// RedisScopeCache returns a ScopeCache backed by Redis, which is used as a second-level
+// cache shared by the instances of an application.
+//
+// The key is the output of juice.CacheKeyFunc, prefixed by the mapper namespace of the statement.
+// FlushNamespace, which is called after a write statement is executed, invalidates the values
+// of that namespace only, matching the flush-on-update semantics of MyBatis.
+// Flush invalidates all the values.
+func RedisScopeCache(client RedisClient, opts ...RedisOptionFunc) ScopeCache {
+	if client == nil {
+		panic("cache: redis client is nil")
+	}
+	option := redisOption{codec: GobCodec{}, prefix: "juice"}
+	for _, opt := range opts {
+		opt(&option)
+	}
+	return &redisScopeCache{client: client, redisOption: option}
+}
+
+// generationKey returns the key of the generation of the namespace, or the global generation if it is empty.
+func (r *redisScopeCache) generationKey(namespace string) string {
+	if namespace == "" {
+		return r.prefix + ":generation"
+	}
+	return r.prefix + ":generation:" + namespace
+}
+
+// generation returns the current generation of the key, 0 if it is not set.
+func (r *redisScopeCache) generation(ctx context.Context, key string) (string, error) {
+	data, err := r.client.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, ErrCacheNotFound) {
+			return "0", nil
+		}
+		return "", err
+	}
+	return string(data), nil
+}
+
+// key returns the versioned key of the cache key.
+func (r *redisScopeCache) key(ctx context.Context, key string) (string, error) {
+	global, err := r.generation(ctx, r.generationKey(""))
+	if err != nil {
+		return "", err
+	}
+	namespace, rest := SplitNamespaceKey(key)
+	local, err := r.generation(ctx, r.generationKey(namespace))
+	if err != nil {
+		return "", err
+	}
+	return r.prefix + ":" + global + ":" + namespace + ":" + local + ":" + rest, nil
+}
+
+// Set encodes the value with the codec and stores it under the versioned key.
+func (r *redisScopeCache) Set(ctx context.Context, key string, value any) error {
+	data, err := r.codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+	versioned, err := r.key(ctx, key)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, versioned, data, r.ttl)
+}
+
+// Get retrieves the value of the versioned key and decodes it with the codec.
+func (r *redisScopeCache) Get(ctx context.Context, key string, ptr any) error {
+	versioned, err := r.key(ctx, key)
+	if err != nil {
+		return err
+	}
+	data, err := r.client.Get(ctx, versioned)
+	if err != nil {
+		return err
+	}
+	return r.codec.Unmarshal(data, ptr)
+}
+
+// Flush invalidates all the values.
+func (r *redisScopeCache) Flush(ctx context.Context) error {
+	_, err := r.client.Incr(ctx, r.generationKey(""))
+	return err
+}
+
+// FlushNamespace invalidates the values of the namespace.
+func (r *redisScopeCache) FlushNamespace(ctx context.Context, namespace string) error {
+	_, err := r.client.Incr(ctx, r.generationKey(namespace))
+	return err
+}
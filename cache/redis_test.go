@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedisClient is an in-memory RedisClient.
+type fakeRedisClient struct {
+	mu   sync.Mutex
+	data map[string][]byte
+	ttls map[string]time.Duration
+}
+
+func newFakeRedisClient() *fakeRedisClient {
+	return &fakeRedisClient{data: make(map[string][]byte), ttls: make(map[string]time.Duration)}
+}
+
+func (f *fakeRedisClient) Get(_ context.Context, key string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.data[key]
+	if !ok {
+		return nil, ErrCacheNotFound
+	}
+	return data, nil
+}
+
+func (f *fakeRedisClient) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.data[key] = value
+	f.ttls[key] = ttl
+	return nil
+}
+
+func (f *fakeRedisClient) Incr(_ context.Context, key string) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	value, _ := strconv.ParseInt(string(f.data[key]), 10, 64)
+	value++
+	f.data[key] = []byte(strconv.FormatInt(value, 10))
+	return value, nil
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(value any) ([]byte, error) { return json.Marshal(value) }
+
+func (jsonCodec) Unmarshal(data []byte, ptr any) error { return json.Unmarshal(data, ptr) }
+
+func TestRedisScopeCache_SetAndGet(t *testing.T) {
+	client := newFakeRedisClient()
+	c := RedisScopeCache(client, RedisWithTTL(time.Minute))
+	ctx := context.Background()
+	key := NamespaceKey("main.UserMapper", "key")
+
+	if err := c.Set(ctx, key, []string{"a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	var result []string
+	if err := c.Get(ctx, key, &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 || result[0] != "a" || result[1] != "b" {
+		t.Errorf("unexpected result %v", result)
+	}
+	if ttl := client.ttls["juice:0:main.UserMapper:0:key"]; ttl != time.Minute {
+		t.Errorf("expected ttl of one minute, got %v", ttl)
+	}
+	if err := c.Get(ctx, NamespaceKey("main.UserMapper", "missing"), &result); !errors.Is(err, ErrCacheNotFound) {
+		t.Errorf("expected ErrCacheNotFound, got %v", err)
+	}
+}
+
+func TestRedisScopeCache_Flush(t *testing.T) {
+	c := RedisScopeCache(newFakeRedisClient(), RedisWithCodec(jsonCodec{}), RedisWithKeyPrefix("app"))
+	ctx := context.Background()
+	users := NamespaceKey("main.UserMapper", "key")
+	orders := NamespaceKey("main.OrderMapper", "key")
+	for _, key := range []string{users, orders} {
+		if err := c.Set(ctx, key, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// flushing a namespace only invalidates the values of the namespace.
+	if err := c.FlushNamespace(ctx, "main.UserMapper"); err != nil {
+		t.Fatal(err)
+	}
+	var value int
+	if err := c.Get(ctx, users, &value); !errors.Is(err, ErrCacheNotFound) {
+		t.Errorf("expected ErrCacheNotFound, got %v", err)
+	}
+	if err := c.Get(ctx, orders, &value); err != nil || value != 1 {
+		t.Errorf("expected 1, got %d, %v", value, err)
+	}
+
+	// flushing all invalidates all the values.
+	if err := c.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(ctx, orders, &value); !errors.Is(err, ErrCacheNotFound) {
+		t.Errorf("expected ErrCacheNotFound, got %v", err)
+	}
+}
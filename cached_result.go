@@ -0,0 +1,95 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"database/sql"
+	"sync"
+)
+
+// ensure CachedResult implements sql.Result.
+var _ sql.Result = (*CachedResult)(nil) // compile time check
+
+// CachedResult is a sql.Result which calls LastInsertId and RowsAffected of the wrapped
+// result at most once, and returns the cached values and errors afterward.
+//
+// Some drivers don't allow to read the result twice, so the results of the executions are
+// wrapped before they are returned to the middlewares, and an exec middleware can read the
+// metadata without breaking the caller:
+//
+//	func (m *RowsAffectedMiddleware) ExecContext(stmt juice.Statement, next juice.ExecHandler) juice.ExecHandler {
+//	    return func(ctx context.Context, query string, args ...any) (sql.Result, error) {
+//	        result, err := next(ctx, query, args...)
+//	        if err == nil {
+//	            affected, _ := result.RowsAffected()
+//	            log.Printf("%s affected %d rows", stmt.Name(), affected)
+//	        }
+//	        return result, err
+//	    }
+//	}
+//
+// It is safe for concurrent use.
+type CachedResult struct {
+	result sql.Result
+
+	lastInsertIDOnce sync.Once
+	lastInsertID     int64
+	lastInsertIDErr  error
+
+	rowsAffectedOnce sync.Once
+	rowsAffected     int64
+	rowsAffectedErr  error
+}
+
+// NewCachedResult returns a CachedResult which wraps the result.
+// It returns the result itself if it is already a CachedResult, and nil if the result is nil.
+func NewCachedResult(result sql.Result) sql.Result {
+	switch result := result.(type) {
+	case nil:
+		return nil
+	case *CachedResult:
+		return result
+	default:
+		return &CachedResult{result: result}
+	}
+}
+
+// LastInsertId implements sql.Result.
+func (r *CachedResult) LastInsertId() (int64, error) {
+	r.lastInsertIDOnce.Do(func() {
+		r.lastInsertID, r.lastInsertIDErr = r.result.LastInsertId()
+	})
+	return r.lastInsertID, r.lastInsertIDErr
+}
+
+// RowsAffected implements sql.Result.
+func (r *CachedResult) RowsAffected() (int64, error) {
+	r.rowsAffectedOnce.Do(func() {
+		r.rowsAffected, r.rowsAffectedErr = r.result.RowsAffected()
+	})
+	return r.rowsAffected, r.rowsAffectedErr
+}
+
+// Unwrap returns the wrapped result.
+func (r *CachedResult) Unwrap() sql.Result {
+	return r.result
+}
+
+// cachedExecResult wraps the result of an execution with NewCachedResult.
+func cachedExecResult(result sql.Result, err error) (sql.Result, error) {
+	return NewCachedResult(result), err
+}
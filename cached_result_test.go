@@ -0,0 +1,90 @@
+package juice
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	juicedriver "github.com/go-juicedev/juice/driver"
+)
+
+// onceResult is a sql.Result which can be read only once, like the results of some drivers.
+type onceResult struct {
+	reads int
+}
+
+func (r *onceResult) LastInsertId() (int64, error) {
+	if r.reads++; r.reads > 1 {
+		return 0, errors.New("result already read")
+	}
+	return 7, nil
+}
+
+func (r *onceResult) RowsAffected() (int64, error) {
+	if r.reads++; r.reads > 1 {
+		return 0, errors.New("result already read")
+	}
+	return 3, nil
+}
+
+func TestCachedResult(t *testing.T) {
+	inner := &onceResult{}
+	result := NewCachedResult(inner)
+	for range 2 {
+		affected, err := result.RowsAffected()
+		if err != nil || affected != 3 {
+			t.Fatalf("unexpected rows affected: %d, %v", affected, err)
+		}
+	}
+	if _, err := result.LastInsertId(); err == nil {
+		t.Error("expected the error of the wrapped result")
+	}
+	if _, err := result.LastInsertId(); err == nil {
+		t.Error("expected the cached error")
+	}
+	if inner.reads != 2 {
+		t.Errorf("expected the wrapped result read twice, got %d", inner.reads)
+	}
+	if NewCachedResult(result) != result {
+		t.Error("expected the CachedResult not to be wrapped again")
+	}
+	if NewCachedResult(nil) != nil {
+		t.Error("expected nil for nil result")
+	}
+	if result.(*CachedResult).Unwrap() != inner {
+		t.Error("unexpected unwrapped result")
+	}
+}
+
+func TestStatementHandler_ExecContext_CachedResult(t *testing.T) {
+	db, state := newFakeDB(t, fakeResultSet{})
+	drv := juicedriver.MySQLDriver{}
+	var records []AuditRecord
+	audit := &AuditMiddleware{Sink: AuditSinkFunc(func(_ context.Context, record AuditRecord) {
+		records = append(records, record)
+	})}
+	handlers := map[string]StatementHandler{
+		"rows":     &SQLRowsStatementHandler{driver: drv, middlewares: MiddlewareGroup{audit}, session: db},
+		"prepared": &PreparedStatementHandler{driver: drv, middlewares: MiddlewareGroup{audit}, session: db},
+	}
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			records = nil
+			state.execResult = &onceResult{}
+			result, err := handler.ExecContext(context.Background(), newFakeStatement("UPDATE user SET status = 1"), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := result.(*CachedResult); !ok {
+				t.Fatalf("expected CachedResult, got %T", result)
+			}
+			affected, err := result.RowsAffected()
+			if err != nil || affected != 3 {
+				t.Errorf("unexpected rows affected: %d, %v", affected, err)
+			}
+			if len(records) != 1 || records[0].RowsAffected != 3 {
+				t.Errorf("unexpected audit records: %v", records)
+			}
+		})
+	}
+}
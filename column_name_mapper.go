@@ -0,0 +1,115 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import "reflect"
+
+// ColumnNameMapper maps the struct fields without a column tag to their column names,
+// so that the rows are scanned into them without the tags.
+type ColumnNameMapper interface {
+	// ColumnName returns the column name of the field with the given name.
+	ColumnName(fieldName string) string
+}
+
+// ColumnNameMapperFunc is an adapter to allow the use of ordinary functions as ColumnNameMapper.
+type ColumnNameMapperFunc func(fieldName string) string
+
+// ColumnName implements ColumnNameMapper.
+func (f ColumnNameMapperFunc) ColumnName(fieldName string) string {
+	return f(fieldName)
+}
+
+// SnakeCaseColumnNameMapper maps the field names to snake case, UserName to user_name
+// and UserID to user_id.
+var SnakeCaseColumnNameMapper ColumnNameMapper = ColumnNameMapperFunc(snakeCase)
+
+// columnNameMapping holds the mapper set by Engine.SetColumnNameMapper. A new one is created by
+// every call, so that it keys the cached struct columns of the mapper, see structColumnsKey.
+type columnNameMapping struct {
+	mapper ColumnNameMapper
+}
+
+// SetColumnNameMapper sets the mapper of the exported struct fields without a column tag
+// of the results of the engine, which are not mapped from any column by default.
+//
+//	engine.SetColumnNameMapper(juice.SnakeCaseColumnNameMapper)
+//
+//	type User struct {
+//	    ID       int64  `column:"id"`
+//	    UserName string // mapped from the user_name column
+//	    Password string `column:"-"`
+//	}
+//
+// The column tags stay authoritative, the fields tagged with "-" are still skipped, and the
+// embedded structs without a tag are still walked into. It applies to the results mapped by
+// the default result maps of the engine, the ResultMap of a statement is used as it is.
+// A nil mapper, which is the default, only maps the tagged fields.
+func (e *Engine) SetColumnNameMapper(mapper ColumnNameMapper) {
+	if e.columnNames != nil {
+		// the struct columns resolved by the replaced mapper are never used again.
+		releaseStructColumns(e.columnNames)
+	}
+	e.columnNames = nil
+	if mapper != nil {
+		e.columnNames = &columnNameMapping{mapper: mapper}
+	}
+}
+
+// releaseStructColumns removes the cached struct columns resolved by the mapping.
+func releaseStructColumns(mapping *columnNameMapping) {
+	structColumnsCache.Range(func(key, _ any) bool {
+		if key.(structColumnsKey).mapping == mapping {
+			structColumnsCache.Delete(key)
+		}
+		return true
+	})
+}
+
+// columnNameResultMap returns the result map of T which maps the untagged fields by the mapping.
+// The result maps other than SingleRowResultMap and MultiRowsResultMap are returned as they are,
+// and so is nil for the results which are not structs, so that the scalars are still bound
+// without reflection.
+func columnNameResultMap[T any](resultMap ResultMap, mapping *columnNameMapping) ResultMap {
+	if mapping == nil {
+		return resultMap
+	}
+	switch resultMap := resultMap.(type) {
+	case SingleRowResultMap:
+		resultMap.mapping = mapping
+		return resultMap
+	case MultiRowsResultMap:
+		resultMap.mapping = mapping
+		return resultMap
+	case nil:
+	default:
+		return resultMap
+	}
+	tp := reflect.TypeFor[T]()
+	if tp.Kind() == reflect.Ptr {
+		tp = tp.Elem()
+	}
+	switch {
+	case tp == timeType || reflect.PointerTo(tp).Implements(scannerType):
+		return nil
+	case tp.Kind() == reflect.Struct:
+		return SingleRowResultMap{mapping: mapping}
+	case tp.Kind() == reflect.Slice && !isStringAnyMap(tp.Elem()):
+		return MultiRowsResultMap{mapping: mapping}
+	default:
+		return nil
+	}
+}
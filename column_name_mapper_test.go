@@ -0,0 +1,81 @@
+package juice
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestEngine_SetColumnNameMapper(t *testing.T) {
+	type User struct {
+		ID       int64 `column:"id"`
+		UserName string
+		Nickname string `column:"nick"`
+		Password string `column:"-"`
+	}
+	db, _ := newFakeDB(t, fakeResultSet{
+		columns: []string{"id", "user_name", "nick", "nickname", "password"},
+		rows:    [][]driver.Value{{int64(1), "eatmoreapple", "eat", "more", "secret"}},
+	})
+	exe := newFakeExecutor(db, newFakeStatement("SELECT * FROM user")).(*sqlRowsExecutor)
+	ctx := context.Background()
+
+	// the untagged fields are skipped without a mapper.
+	users, err := (&GenericExecutor[[]User]{SQLRowsExecutor: exe}).QueryContext(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if users[0].UserName != "" {
+		t.Errorf("unexpected user name: %s", users[0].UserName)
+	}
+
+	engine := &Engine{}
+	engine.SetColumnNameMapper(SnakeCaseColumnNameMapper)
+	exe.columnNames = engine.columnNames
+
+	want := User{ID: 1, UserName: "eatmoreapple", Nickname: "eat"}
+	users, err = (&GenericExecutor[[]User]{SQLRowsExecutor: exe}).QueryContext(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if users[0] != want {
+		t.Errorf("unexpected user: %+v", users[0])
+	}
+	user, err := (&GenericExecutor[*User]{SQLRowsExecutor: exe}).QueryContext(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *user != want {
+		t.Errorf("unexpected user: %+v", *user)
+	}
+
+	// the other engines are not affected.
+	if users, err = List[User](queryFakeRows(t, fakeResultSet{
+		columns: []string{"user_name"},
+		rows:    [][]driver.Value{{"eatmoreapple"}},
+	})); err != nil || users[0].UserName != "" {
+		t.Errorf("unexpected result: %+v %v", users, err)
+	}
+
+	// the struct columns of the replaced mapper are released.
+	mapping := engine.columnNames
+	engine.SetColumnNameMapper(nil)
+	structColumnsCache.Range(func(key, _ any) bool {
+		if key.(structColumnsKey).mapping == mapping {
+			t.Errorf("unexpected cached columns: %v", key)
+		}
+		return true
+	})
+}
+
+func TestSnakeCaseColumnNameMapper(t *testing.T) {
+	for name, want := range map[string]string{
+		"UserName": "user_name",
+		"UserID":   "user_id",
+		"ID":       "id",
+	} {
+		if got := SnakeCaseColumnNameMapper.ColumnName(name); got != want {
+			t.Errorf("%s: expected %s, got %s", name, want, got)
+		}
+	}
+}
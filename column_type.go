@@ -0,0 +1,130 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+)
+
+var (
+	// anyType is the reflect.Type of any.
+	anyType = reflect.TypeOf((*any)(nil)).Elem()
+
+	// rawBytesType is the reflect.Type of sql.RawBytes.
+	rawBytesType = reflect.TypeOf(sql.RawBytes{})
+
+	// stringType is the reflect.Type of string.
+	stringType = reflect.TypeOf("")
+
+	// valuerType is the reflect.Type of driver.Valuer.
+	valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
+
+// ColumnTypeDestination builds typed scan destinations from the column types of a result set.
+// It is useful for dynamic scanning, where the shape of the result is unknown at compile time.
+//
+// Each column is scanned into a value of its *sql.ColumnType.ScanType, for example
+// *int64 for integer columns and *string for text columns, instead of the driver
+// default which is often []byte. The following rules apply:
+//   - sql.RawBytes is scanned into a string, since it can not outlive the row.
+//   - NULL is always allowed and is reported as nil by Values.
+//   - sql.Null* types, or any driver.Valuer, are reported by their driver value.
+//
+// Fallback: when the driver doesn't report the column types (it does not implement
+// driver.RowsColumnTypeScanType), the scan type is any and the column is scanned
+// into the driver-native value.
+type ColumnTypeDestination struct {
+	scanTypes []reflect.Type
+}
+
+// NewColumnTypeDestination creates a ColumnTypeDestination from the given column types.
+func NewColumnTypeDestination(columnTypes []*sql.ColumnType) *ColumnTypeDestination {
+	scanTypes := make([]reflect.Type, len(columnTypes))
+	for i, columnType := range columnTypes {
+		scanTypes[i] = columnScanType(columnType)
+	}
+	return &ColumnTypeDestination{scanTypes: scanTypes}
+}
+
+// ColumnTypeDestinationFromRows creates a ColumnTypeDestination from the column types of the rows.
+func ColumnTypeDestinationFromRows(rows *sql.Rows) (*ColumnTypeDestination, error) {
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	return NewColumnTypeDestination(columnTypes), nil
+}
+
+// columnScanType returns the type which the column should be scanned into.
+func columnScanType(columnType *sql.ColumnType) reflect.Type {
+	scanType := columnType.ScanType()
+	switch {
+	case scanType == nil || scanType.Kind() == reflect.Interface:
+		return anyType
+	case scanType == rawBytesType:
+		return stringType
+	default:
+		return scanType
+	}
+}
+
+// Destination returns new scan destinations for one row.
+// The destinations are pointers to pointers, so that NULL values can be scanned.
+func (c *ColumnTypeDestination) Destination() []any {
+	dest := make([]any, len(c.scanTypes))
+	for i, scanType := range c.scanTypes {
+		if scanType == anyType {
+			dest[i] = new(any)
+			continue
+		}
+		dest[i] = reflect.New(reflect.PointerTo(scanType)).Interface()
+	}
+	return dest
+}
+
+// Values returns the scanned values of the destinations returned by Destination.
+// NULL values are returned as nil.
+func (c *ColumnTypeDestination) Values(dest []any) ([]any, error) {
+	values := make([]any, len(dest))
+	for i, d := range dest {
+		value, err := destinationValue(d)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// destinationValue returns the value of a single destination.
+func destinationValue(dest any) (any, error) {
+	if value, ok := dest.(*any); ok {
+		return *value, nil
+	}
+	// dest is a pointer to a pointer
+	rv := reflect.ValueOf(dest).Elem()
+	if rv.IsNil() {
+		return nil, nil
+	}
+	rv = rv.Elem()
+	if rv.Type().Implements(valuerType) {
+		return rv.Interface().(driver.Valuer).Value()
+	}
+	return rv.Interface(), nil
+}
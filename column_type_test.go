@@ -0,0 +1,50 @@
+package juice
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+func TestColumnTypeDestination(t *testing.T) {
+	rows := queryFakeRows(t, fakeResultSet{
+		columns: []string{"id", "name", "age", "extra"},
+		scanTypes: []reflect.Type{
+			reflect.TypeOf(int64(0)),
+			reflect.TypeOf(sql.RawBytes{}),
+			reflect.TypeOf(sql.NullInt64{}),
+			nil,
+		},
+		rows: [][]driver.Value{
+			{int64(1), []byte("eatmoreapple"), int64(18), []byte("a")},
+			{int64(2), nil, nil, nil},
+		},
+	})
+	destination, err := ColumnTypeDestinationFromRows(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results [][]any
+	for rows.Next() {
+		dest := destination.Destination()
+		if err = rows.Scan(dest...); err != nil {
+			t.Fatal(err)
+		}
+		values, err := destination.Values(dest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, values)
+	}
+	if err = rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]any{
+		{int64(1), "eatmoreapple", int64(18), []byte("a")},
+		{int64(2), nil, nil, nil},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("unexpected values: %#v", results)
+	}
+}
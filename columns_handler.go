@@ -0,0 +1,225 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ErrColumnsHandlerNotRegistered is an error that is returned when the handler of a result node is not registered.
+var ErrColumnsHandlerNotRegistered = errors.New("juice: columns handler not registered")
+
+// ColumnsHandler combines the values of several columns of a row into the value of one field.
+type ColumnsHandler interface {
+	// Combine returns the value of the field from the column values keyed by the column names.
+	// The values are the driver values of the columns, and nil for NULL.
+	// The returned value must be assignable or convertible to the field, nil leaves the field zero.
+	Combine(values map[string]any) (any, error)
+}
+
+// ColumnsHandlerFunc is an adapter to allow the use of ordinary functions as ColumnsHandler.
+type ColumnsHandlerFunc func(values map[string]any) (any, error)
+
+// Combine implements ColumnsHandler.
+func (f ColumnsHandlerFunc) Combine(values map[string]any) (any, error) {
+	return f(values)
+}
+
+// columnsHandlerLibraries is a map of the columns handlers by name.
+var columnsHandlerLibraries = map[string]ColumnsHandler{}
+
+// RegisterColumnsHandler registers a columns handler which can be referenced by the
+// handler attribute of the result elements of the select statements:
+//
+//	juice.RegisterColumnsHandler("fullName", juice.ColumnsHandlerFunc(func(values map[string]any) (any, error) {
+//	    return fmt.Sprintf("%s %s", values["first_name"], values["last_name"]), nil
+//	}))
+//
+//	<select id="ListUsers" resultType="User">
+//	    <result property="FullName" columns="first_name,last_name" handler="fullName"/>
+//	    select id, first_name, last_name from user
+//	</select>
+//
+// It is not goroutine safe, so it should be called before the statements are executed.
+func RegisterColumnsHandler(name string, handler ColumnsHandler) {
+	if len(name) == 0 {
+		panic("name is empty")
+	}
+	if handler == nil {
+		panic("juice: columns handler is nil")
+	}
+	columnsHandlerLibraries[name] = handler
+}
+
+// GetColumnsHandler returns the columns handler registered with the name.
+func GetColumnsHandler(name string) (ColumnsHandler, bool) {
+	handler, exists := columnsHandlerLibraries[name]
+	return handler, exists
+}
+
+// ColumnsResult maps several columns of a row to one field of the result type by a ColumnsHandler.
+type ColumnsResult struct {
+	// Property is the name of the struct field, the fields of the embedded structs are promoted.
+	Property string
+
+	// Columns is the names of the columns passed to the handler.
+	Columns []string
+
+	// Handler combines the column values into the value of the field.
+	Handler ColumnsHandler
+}
+
+// columnsResult is a result element of a select statement, the handler is resolved by its name when mapping.
+type columnsResult struct {
+	property string
+	columns  []string
+	handler  string
+}
+
+// resolve returns the ColumnsResult with the registered handler.
+func (r columnsResult) resolve() (ColumnsResult, error) {
+	handler, exists := GetColumnsHandler(r.handler)
+	if !exists {
+		return ColumnsResult{}, fmt.Errorf("%w: %s", ErrColumnsHandlerNotRegistered, r.handler)
+	}
+	return ColumnsResult{Property: r.property, Columns: r.columns, Handler: handler}, nil
+}
+
+// splitResultColumns splits the comma separated columns of the result element.
+func splitResultColumns(columns string) []string {
+	var names []string
+	for _, name := range strings.Split(columns, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// columnsResultRow maps the rows to the result type with the ColumnsResults.
+type columnsResultRow struct {
+	tp          reflect.Type
+	columns     []string
+	results     []ColumnsResult
+	fields      [][]int
+	positions   [][]int
+	combined    []int
+	destination rowDestination
+}
+
+// newColumnsResultRow resolves the fields of the results and the positions of their columns.
+func newColumnsResultRow(tp reflect.Type, columns []string, results []ColumnsResult) (*columnsResultRow, error) {
+	if tp.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected struct, but got %s", tp.Kind())
+	}
+	row := &columnsResultRow{tp: tp, columns: columns, results: results}
+	for _, result := range results {
+		field, ok := tp.FieldByName(result.Property)
+		if !ok {
+			return nil, fmt.Errorf("property %s not found in %s", result.Property, tp)
+		}
+		positions := make([]int, len(result.Columns))
+		for i, column := range result.Columns {
+			if positions[i] = slices.Index(columns, column); positions[i] < 0 {
+				return nil, fmt.Errorf("column %s of property %s not found in result set", column, result.Property)
+			}
+			if !slices.Contains(row.combined, positions[i]) {
+				row.combined = append(row.combined, positions[i])
+			}
+		}
+		row.fields = append(row.fields, field.Index)
+		row.positions = append(row.positions, positions)
+	}
+	return row, nil
+}
+
+// remainingColumns returns the columns which are not combined by the results.
+func (r *columnsResultRow) remainingColumns() []string {
+	remaining := make([]string, 0, len(r.columns))
+	for i, column := range r.columns {
+		if !slices.Contains(r.combined, i) {
+			remaining = append(remaining, column)
+		}
+	}
+	return remaining
+}
+
+// mapRow scans the current row into a new value of the result type.
+func (r *columnsResultRow) mapRow(rows *sql.Rows) (reflect.Value, error) {
+	value := reflect.New(r.tp)
+	dest, err := r.destination.Destination(value.Elem(), r.columns)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	// the combined columns are scanned as the driver values, they are handed to the handlers.
+	values := make([]any, len(r.columns))
+	for _, i := range r.combined {
+		dest[i] = &values[i]
+	}
+	if err = rows.Scan(dest...); err != nil {
+		return reflect.Value{}, err
+	}
+	for i, result := range r.results {
+		named := make(map[string]any, len(result.Columns))
+		for j, column := range result.Columns {
+			named[column] = values[r.positions[i][j]]
+		}
+		combined, err := result.Handler.Combine(named)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("property %s: %w", result.Property, err)
+		}
+		if err = setCombinedValue(value.Elem().FieldByIndex(r.fields[i]), combined); err != nil {
+			return reflect.Value{}, fmt.Errorf("property %s: %w", result.Property, err)
+		}
+	}
+	return value, nil
+}
+
+// setCombinedValue sets the value returned by a ColumnsHandler to the field.
+func setCombinedValue(field reflect.Value, value any) error {
+	if value == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(value)
+	switch {
+	case rv.Type().AssignableTo(field.Type()):
+		field.Set(rv)
+	case rv.Type().ConvertibleTo(field.Type()):
+		field.Set(rv.Convert(field.Type()))
+	default:
+		return fmt.Errorf("cannot assign %s to %s", rv.Type(), field.Type())
+	}
+	return nil
+}
+
+// mapRows maps all the rows to the values of the result type.
+func (r *columnsResultRow) mapRows(rows *sql.Rows) ([]reflect.Value, error) {
+	var values []reflect.Value
+	for rows.Next() {
+		value, err := r.mapRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
@@ -0,0 +1,89 @@
+package juice
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type columnsHandlerUser struct {
+	ID       int64  `column:"id"`
+	FullName string `column:"-"`
+}
+
+func TestStatement_ColumnsResult(t *testing.T) {
+	RegisterResultType("test.columnsHandlerUser", columnsHandlerUser{})
+	RegisterColumnsHandler("test.fullName", ColumnsHandlerFunc(func(values map[string]any) (any, error) {
+		if values["last_name"] == nil {
+			return values["first_name"], nil
+		}
+		return fmt.Sprintf("%s %s", values["first_name"], values["last_name"]), nil
+	}))
+	statement := parseTestStatement(t, Select, `<select id="ListUsers" resultType="test.columnsHandlerUser">
+		<result property="FullName" columns="first_name, last_name" handler="test.fullName"/>
+		SELECT id, first_name, last_name FROM user
+	</select>`)
+	if len(statement.results) != 1 || len(statement.results[0].columns) != 2 {
+		t.Fatalf("unexpected results: %v", statement.results)
+	}
+	db, _ := newFakeDB(t, fakeResultSet{
+		columns: []string{"id", "first_name", "last_name"},
+		rows:    [][]driver.Value{{int64(1), "Ada", "Lovelace"}, {int64(2), "Plato", nil}},
+	})
+
+	users, err := (&GenericExecutor[[]*columnsHandlerUser]{SQLRowsExecutor: newFakeExecutor(db, statement)}).QueryContext(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || *users[0] != (columnsHandlerUser{ID: 1, FullName: "Ada Lovelace"}) || *users[1] != (columnsHandlerUser{ID: 2, FullName: "Plato"}) {
+		t.Errorf("unexpected users: %v, %v", users[0], users[1])
+	}
+
+	result, err := (&GenericExecutor[any]{SQLRowsExecutor: newFakeExecutor(db, statement)}).QueryContext(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list, ok := result.([]columnsHandlerUser); !ok || len(list) != 2 {
+		t.Errorf("unexpected result: %#v", result)
+	}
+
+	_, err = (&GenericExecutor[columnsHandlerUser]{SQLRowsExecutor: newFakeExecutor(db, statement)}).QueryContext(context.Background(), nil)
+	if !errors.Is(err, ErrTooManyRows) {
+		t.Errorf("expected ErrTooManyRows, got %v", err)
+	}
+
+	missing, _ := newFakeDB(t, fakeResultSet{columns: []string{"id", "first_name"}})
+	_, err = (&GenericExecutor[any]{SQLRowsExecutor: newFakeExecutor(missing, statement)}).QueryContext(context.Background(), nil)
+	if err == nil {
+		t.Error("expected error for missing column")
+	}
+}
+
+func TestParseResultNode(t *testing.T) {
+	statement := parseTestStatement(t, Select, `<select id="ListUsers" resultType="test.columnsHandlerUser">
+		<result property="FullName" columns="first_name" handler="test.unknown"/>
+		SELECT id, first_name FROM user
+	</select>`)
+	if _, err := statement.ResultMap(); !errors.Is(err, ErrColumnsHandlerNotRegistered) {
+		t.Errorf("expected ErrColumnsHandlerNotRegistered, got %v", err)
+	}
+
+	for _, content := range []string{
+		`<select id="ListUsers"><result property="FullName" columns="first_name" handler="h"/>SELECT 1</select>`,
+		`<select id="ListUsers" resultType="User"><result property="FullName" handler="h"/>SELECT 1</select>`,
+	} {
+		decoder := xml.NewDecoder(strings.NewReader(content))
+		token, err := decoder.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stmt := &xmlSQLStatement{mapper: &Mapper{namespace: "main.UserMapper", mappers: &Mappers{}}, action: Select}
+		if err = (&XMLMappersElementParser{}).parseStatement(stmt, decoder, token.(xml.StartElement)); err == nil {
+			t.Errorf("expected parse error for %s", content)
+		}
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package juice
 
 import (
+	"io"
 	"io/fs"
 	"path"
 	"path/filepath"
@@ -87,11 +88,17 @@ func newXMLConfigurationParser(fs fs.FS, filename string, ignoreEnv bool) (IConf
 		return nil, err
 	}
 	defer func() { _ = file.Close() }()
+	return parseXMLConfiguration(fs, file, ignoreEnv)
+}
+
+// parseXMLConfiguration parses the Configuration from the reader,
+// the resources referenced by the configuration are opened from the fs.
+func parseXMLConfiguration(fs fs.FS, reader io.Reader, ignoreEnv bool) (IConfiguration, error) {
 	parser := &XMLParser{FS: fs, ignoreEnv: ignoreEnv}
 	parser.AddXMLElementParser(
 		&XMLEnvironmentsElementParser{},
 		&XMLMappersElementParser{},
 		&XMLSettingsElementParser{},
 	)
-	return parser.Parse(file)
+	return parser.Parse(reader)
 }
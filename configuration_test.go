@@ -2,7 +2,11 @@ package juice
 
 import (
 	"embed"
+	"encoding/xml"
+	"maps"
+	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 //go:embed testdata/configuration
@@ -21,3 +25,51 @@ func TestNewXMLConfiguration(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestXMLSettingsElementParser_ParseSettings(t *testing.T) {
+	decoder := xml.NewDecoder(strings.NewReader(`<settings>
+		<setting name="debug" value="false"/>
+		<setting name="maxLimit" value="100"/>
+	</settings>`))
+	token, err := decoder.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parser XMLSettingsElementParser
+	settings, err := parser.parseSettings(decoder, token.(xml.StartElement))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(settings) != 2 || settings.Get("debug") != "false" || settings.Get("maxLimit") != "100" {
+		t.Errorf("unexpected settings: %v", settings)
+	}
+}
+
+func TestParseEnvironment_Properties(t *testing.T) {
+	t.Setenv("JUICE_TEST_TLS", "custom")
+	fsys := fstest.MapFS{
+		"juice.xml": {Data: []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<configuration>
+    <environments default="prod">
+        <environment id="prod" provider="env">
+            <dataSource>root:@tcp(localhost:3306)/db</dataSource>
+            <driver>mysql</driver>
+            <property name="tls" value="${JUICE_TEST_TLS}"/>
+            <property name="parseTime" value="true"/>
+        </environment>
+    </environments>
+</configuration>`)},
+	}
+	configuration, err := NewXMLConfigurationWithFS(fsys, "juice.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := configuration.Environments().Use("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"tls": "custom", "parseTime": "true"}
+	if !maps.Equal(env.Properties, expected) {
+		t.Errorf("unexpected properties: %v", env.Properties)
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"errors"
+
+	"github.com/go-juicedev/juice/driver"
+)
+
+var (
+	// ErrDuplicateKey indicates that a unique constraint of the database is violated.
+	ErrDuplicateKey = errors.New("duplicate key")
+
+	// ErrForeignKeyViolation indicates that a foreign key constraint of the database is violated.
+	ErrForeignKeyViolation = errors.New("foreign key violation")
+
+	// ErrNotNullViolation indicates that a not-null constraint of the database is violated.
+	ErrNotNullViolation = errors.New("not null violation")
+
+	// ErrCheckViolation indicates that a check constraint of the database is violated.
+	ErrCheckViolation = errors.New("check violation")
+)
+
+// DuplicateKeyError wraps the driver error of a unique constraint violation.
+// It matches ErrDuplicateKey with errors.Is, and the original error is still reachable by errors.As.
+type DuplicateKeyError struct {
+	Err error
+
+	// Constraint is the name of the violated constraint or index, if the driver reports it.
+	Constraint string
+}
+
+func (e *DuplicateKeyError) Error() string { return ErrDuplicateKey.Error() + ": " + e.Err.Error() }
+
+// Unwrap returns the original driver error.
+func (e *DuplicateKeyError) Unwrap() error { return e.Err }
+
+// Is reports whether the target is ErrDuplicateKey.
+func (e *DuplicateKeyError) Is(target error) bool { return target == ErrDuplicateKey }
+
+// ForeignKeyError wraps the driver error of a foreign key constraint violation.
+// It matches ErrForeignKeyViolation with errors.Is.
+type ForeignKeyError struct {
+	Err error
+
+	// Constraint is the name of the violated constraint, if the driver reports it.
+	Constraint string
+}
+
+func (e *ForeignKeyError) Error() string {
+	return ErrForeignKeyViolation.Error() + ": " + e.Err.Error()
+}
+
+// Unwrap returns the original driver error.
+func (e *ForeignKeyError) Unwrap() error { return e.Err }
+
+// Is reports whether the target is ErrForeignKeyViolation.
+func (e *ForeignKeyError) Is(target error) bool { return target == ErrForeignKeyViolation }
+
+// NotNullError wraps the driver error of a not-null constraint violation.
+// It matches ErrNotNullViolation with errors.Is.
+type NotNullError struct {
+	Err error
+
+	// Column is the name of the column, if the driver reports it.
+	Column string
+}
+
+func (e *NotNullError) Error() string { return ErrNotNullViolation.Error() + ": " + e.Err.Error() }
+
+// Unwrap returns the original driver error.
+func (e *NotNullError) Unwrap() error { return e.Err }
+
+// Is reports whether the target is ErrNotNullViolation.
+func (e *NotNullError) Is(target error) bool { return target == ErrNotNullViolation }
+
+// CheckError wraps the driver error of a check constraint violation.
+// It matches ErrCheckViolation with errors.Is.
+type CheckError struct {
+	Err error
+
+	// Constraint is the name of the violated constraint, if the driver reports it.
+	Constraint string
+}
+
+func (e *CheckError) Error() string { return ErrCheckViolation.Error() + ": " + e.Err.Error() }
+
+// Unwrap returns the original driver error.
+func (e *CheckError) Unwrap() error { return e.Err }
+
+// Is reports whether the target is ErrCheckViolation.
+func (e *CheckError) Is(target error) bool { return target == ErrCheckViolation }
+
+// ClassifyError returns the typed error of the constraint violation, which is one of
+// *DuplicateKeyError, *ForeignKeyError, *NotNullError and *CheckError, or the error itself
+// if it is not a constraint violation. For example:
+//
+//	_, err := engine.Object(CreateUser).ExecContext(ctx, user)
+//	var notNull *juice.NotNullError
+//	switch err = juice.ClassifyError(err); {
+//	case errors.Is(err, juice.ErrDuplicateKey):
+//	    // the user already exists
+//	case errors.As(err, &notNull):
+//	    // the column notNull.Column is required
+//	}
+//
+// The error is classified by the registered drivers which implement driver.ErrorClassifier.
+// Use the ErrorTranslateMiddleware to classify the errors by the driver of the engine.
+func ClassifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, name := range driver.Drivers() {
+		drv, _ := driver.Get(name)
+		if classified := classifyError(drv, err); classified != err {
+			return classified
+		}
+	}
+	return err
+}
+
+// classifyError returns the typed error of the constraint violation recognized by the driver,
+// or the error itself.
+func classifyError(drv driver.Driver, err error) error {
+	// already classified
+	if isConstraintError(err) {
+		return err
+	}
+	classifier, ok := drv.(driver.ErrorClassifier)
+	if !ok {
+		return err
+	}
+	violation, ok := classifier.ClassifyError(err)
+	if !ok {
+		return err
+	}
+	switch violation.Kind {
+	case driver.UniqueConstraint:
+		return &DuplicateKeyError{Err: err, Constraint: violation.Constraint}
+	case driver.ForeignKeyConstraint:
+		return &ForeignKeyError{Err: err, Constraint: violation.Constraint}
+	case driver.NotNullConstraint:
+		return &NotNullError{Err: err, Column: violation.Column}
+	case driver.CheckConstraint:
+		return &CheckError{Err: err, Constraint: violation.Constraint}
+	default:
+		return err
+	}
+}
+
+// isConstraintError reports whether the error is one of the typed constraint errors.
+func isConstraintError(err error) bool {
+	return errors.Is(err, ErrDuplicateKey) ||
+		errors.Is(err, ErrForeignKeyViolation) ||
+		errors.Is(err, ErrNotNullViolation) ||
+		errors.Is(err, ErrCheckViolation)
+}
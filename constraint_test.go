@@ -0,0 +1,53 @@
+package juice
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// constraintError mimics *pq.Error.
+type constraintError struct {
+	Code       string
+	Constraint string
+	Column     string
+}
+
+func (e *constraintError) Error() string    { return "pq: violation " + e.Code }
+func (e *constraintError) SQLState() string { return e.Code }
+
+func TestClassifyError(t *testing.T) {
+	err := ClassifyError(fmt.Errorf("insert user: %w", &constraintError{Code: "23502", Column: "name"}))
+	var notNull *NotNullError
+	if !errors.Is(err, ErrNotNullViolation) || !errors.As(err, &notNull) || notNull.Column != "name" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	var original *constraintError
+	if !errors.As(err, &original) {
+		t.Errorf("expected the original error to be wrapped, got %v", err)
+	}
+
+	err = ClassifyError(&constraintError{Code: "23503", Constraint: "fk_order_user"})
+	var foreignKey *ForeignKeyError
+	if !errors.As(err, &foreignKey) || foreignKey.Constraint != "fk_order_user" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err = ClassifyError(&constraintError{Code: "23514"}); !errors.Is(err, ErrCheckViolation) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err = ClassifyError(&constraintError{Code: "23505"}); !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	// classified errors are returned as they are.
+	if classified := ClassifyError(err); classified != err {
+		t.Errorf("unexpected error: %v", classified)
+	}
+
+	other := errors.New("connection refused")
+	if err = ClassifyError(other); err != other {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if ClassifyError(nil) != nil {
+		t.Error("expected nil")
+	}
+}
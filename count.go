@@ -0,0 +1,289 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/go-juicedev/juice/driver"
+)
+
+// countSubqueryAlias is the alias of the subquery of the count query fallback.
+const countSubqueryAlias = "juice_count"
+
+// CountQuery derives the count query from the built SQL of a data query,
+// so that the count and the data share the same dynamic conditions without duplication.
+//
+// The SELECT list is replaced with COUNT(*), and the top level ORDER BY, LIMIT,
+// OFFSET and FETCH clauses are stripped together with their arguments:
+//
+//	SELECT id, name FROM user WHERE status = ? ORDER BY id LIMIT ?  (args: 1, 20)
+//	SELECT COUNT(*) FROM user WHERE status = ?                      (args: 1)
+//
+// When the count can not be derived by replacing the SELECT list, like DISTINCT,
+// GROUP BY, HAVING, UNION or placeholders in the SELECT list, the query is wrapped
+// as a subquery instead:
+//
+//	SELECT COUNT(*) FROM (SELECT DISTINCT name FROM user) AS juice_count
+//
+// The stripped clauses must be at the end of the query, which is always the case of valid SQL.
+func CountQuery(query string, args []any) (string, []any, error) {
+	return countQuery(query, args, false)
+}
+
+// countQuery is CountQuery which also recognizes the @name placeholders if named is true,
+// like the ones of the drivers which bind the args by name.
+func countQuery(query string, args []any, named bool) (string, []any, error) {
+	tokens := scanSQLKeywords(query)
+	if len(tokens) == 0 || tokens[0].word != "SELECT" {
+		return "", nil, errors.New("count: query must start with SELECT")
+	}
+
+	from, tail := -1, len(query)
+	subquery := false
+	for i, token := range tokens {
+		switch token.word {
+		case "FROM":
+			if from < 0 {
+				from = token.pos
+			}
+		case "DISTINCT":
+			// SELECT DISTINCT
+			if i == 1 {
+				subquery = true
+			}
+		case "GROUP", "HAVING", "UNION", "INTERSECT", "EXCEPT", "WINDOW":
+			subquery = true
+		case "ORDER", "LIMIT", "OFFSET", "FETCH":
+			if from >= 0 && token.pos < tail {
+				tail = token.pos
+			}
+		}
+	}
+	if from < 0 {
+		return "", nil, errors.New("count: query has no FROM clause")
+	}
+
+	// drop the arguments of the stripped clauses, which are the last ones.
+	stripped := strippedArgs(query[:tail], query[tail:], named)
+	if stripped > len(args) {
+		return "", nil, errors.New("count: arguments mismatch the placeholders")
+	}
+	args = args[:len(args)-stripped]
+	body := strings.TrimSpace(query[:tail])
+
+	// placeholders in the select list would be dropped.
+	if !subquery && countPlaceholders(query[:from], named) > 0 {
+		subquery = true
+	}
+	if subquery {
+		return "SELECT COUNT(*) FROM (" + body + ") AS " + countSubqueryAlias, args, nil
+	}
+	return "SELECT COUNT(*) " + strings.TrimSpace(query[from:tail]), args, nil
+}
+
+// sqlKeyword is a top level keyword of a SQL query.
+type sqlKeyword struct {
+	word string
+	pos  int
+}
+
+// scanSQLKeywords returns the top level words of the query in upper case,
+// skipping the quoted strings, identifiers and the parenthesized expressions.
+func scanSQLKeywords(query string) []sqlKeyword {
+	var tokens []sqlKeyword
+	depth := 0
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(query, i)
+			continue
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case isWordByte(c):
+			start := i
+			for i < len(query) && isWordByte(query[i]) {
+				i++
+			}
+			if depth == 0 {
+				tokens = append(tokens, sqlKeyword{word: strings.ToUpper(query[start:i]), pos: start})
+			}
+			continue
+		}
+		i++
+	}
+	return tokens
+}
+
+// countPlaceholders returns the number of the placeholders outside the quoted strings,
+// which are ?, $n and :n for the supported drivers, and @name if named is true.
+func countPlaceholders(query string, named bool) int {
+	var count int
+	scanPlaceholders(query, named, func(string) { count++ })
+	return count
+}
+
+// strippedArgs returns the number of the args of the placeholders in the stripped tail of a query.
+// A named placeholder which is also in the body is a reused one, whose arg is kept.
+func strippedArgs(body, tail string, named bool) int {
+	if !named {
+		return countPlaceholders(tail, false)
+	}
+	kept := make(map[string]struct{})
+	scanPlaceholders(body, true, func(name string) { kept[name] = struct{}{} })
+	var count int
+	scanPlaceholders(tail, true, func(name string) {
+		if _, exists := kept[name]; exists && name != "" {
+			return
+		}
+		kept[name] = struct{}{}
+		count++
+	})
+	return count
+}
+
+// scanPlaceholders calls fn with the placeholders outside the quoted strings in order,
+// the name is empty for the positional ones. The @name placeholders are recognized only
+// if named is true, since @name is a user variable of MySQL. The @@name system variables
+// are never placeholders.
+func scanPlaceholders(query string, named bool, fn func(name string)) {
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(query, i)
+			continue
+		case c == '?':
+			fn("")
+		case (c == '$' || c == ':') && i+1 < len(query) && unicode.IsDigit(rune(query[i+1])):
+			fn("")
+			for i+1 < len(query) && unicode.IsDigit(rune(query[i+1])) {
+				i++
+			}
+		case c == '@' && i+1 < len(query) && query[i+1] == '@':
+			for i += 2; i < len(query) && isWordByte(query[i]); i++ {
+			}
+			continue
+		case named && c == '@' && i+1 < len(query) && isWordByte(query[i+1]) && !unicode.IsDigit(rune(query[i+1])):
+			start := i + 1
+			for i = start; i < len(query) && isWordByte(query[i]); i++ {
+			}
+			fn(query[start:i])
+			continue
+		}
+		i++
+	}
+}
+
+// skipQuoted returns the position after the quoted part starting at i.
+func skipQuoted(query string, i int) int {
+	quote := query[i]
+	for i++; i < len(query); i++ {
+		if query[i] == quote {
+			// doubled quote is an escaped quote
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return i
+}
+
+// isWordByte reports whether the byte is part of a word.
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// countStatement is a Statement which builds the count query of the data statement.
+type countStatement struct {
+	Statement
+}
+
+// Action implements Statement.
+func (c countStatement) Action() Action { return Select }
+
+// ResultMap implements Statement.
+// The result of the count query is always scanned by the default result map.
+func (c countStatement) ResultMap() (ResultMap, error) { return nil, ErrResultMapNotSet }
+
+// Build implements Statement.
+func (c countStatement) Build(translator driver.Translator, param Param) (string, []any, error) {
+	query, args, err := c.Statement.Build(translator, param)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.derive(translator, query, args)
+}
+
+// source implements derivedStatement.
+func (c countStatement) source() Statement { return c.Statement }
+
+// derive implements derivedStatement.
+// The @name placeholders are counted if the translator binds the args by name.
+func (c countStatement) derive(translator driver.Translator, query string, args []any) (string, []any, error) {
+	_, named := driver.TranslatorAs[driver.NamedArgsBinder](translator)
+	return countQuery(query, args, named)
+}
+
+// derivedStatement is a Statement whose query is derived from the query of its source statement,
+// like the count query. The statement interceptors intercept the query of the source statement,
+// so that the predicates they append, like the one of the SoftDeleteInterceptor, filter the
+// rows of the source query instead of the derived one.
+type derivedStatement interface {
+	Statement
+	// source returns the statement which the query is derived from.
+	source() Statement
+	// derive derives the query from the built query of the source statement,
+	// which is built by the translator.
+	derive(translator driver.Translator, query string, args []any) (string, []any, error)
+}
+
+// ensure countStatement implements derivedStatement.
+var _ derivedStatement = countStatement{} // compile time check
+
+// CountContext executes the count query derived from the statement of the executor with CountQuery,
+// and returns the count. It is used with the data query of a paginated list, for example:
+//
+//	users, err := juice.NewGenericManager[[]User](engine).Object(QueryUsers).QueryContext(ctx, param)
+//	total, err := juice.CountContext(ctx, engine.Object(QueryUsers), param)
+//
+// The count query is executed through the same middlewares as the data query.
+func CountContext(ctx context.Context, executor SQLRowsExecutor, param Param) (int64, error) {
+	if exe, ok := isInvalidExecutor(executor); ok {
+		return 0, exe.err
+	}
+	exe, ok := executor.(*sqlRowsExecutor)
+	if !ok {
+		return 0, errors.New("count: unsupported executor")
+	}
+	countExecutor := &GenericExecutor[int64]{
+		SQLRowsExecutor: &sqlRowsExecutor{
+			statement:        countStatement{Statement: exe.statement},
+			statementHandler: exe.statementHandler,
+			driver:           exe.driver,
+		},
+	}
+	return countExecutor.QueryContext(ctx, param)
+}
@@ -0,0 +1,142 @@
+package juice
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	juicedriver "github.com/go-juicedev/juice/driver"
+)
+
+func TestCountQuery(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		args         []any
+		expected     string
+		expectedArgs []any
+	}{
+		{
+			name:         "strip select list, order by and limit",
+			query:        "SELECT id, name FROM user WHERE status = ? ORDER BY id DESC LIMIT ? OFFSET ?",
+			args:         []any{1, 20, 40},
+			expected:     "SELECT COUNT(*) FROM user WHERE status = ?",
+			expectedArgs: []any{1},
+		},
+		{
+			name:         "postgres placeholders",
+			query:        "select id from user where status = $1 limit $2",
+			args:         []any{1, 20},
+			expected:     "SELECT COUNT(*) from user where status = $1",
+			expectedArgs: []any{1},
+		},
+		{
+			name:         "keywords in subquery and strings are ignored",
+			query:        "SELECT id FROM user WHERE name = 'ORDER BY ?' AND id IN (SELECT user_id FROM orders ORDER BY id LIMIT 10)",
+			args:         nil,
+			expected:     "SELECT COUNT(*) FROM user WHERE name = 'ORDER BY ?' AND id IN (SELECT user_id FROM orders ORDER BY id LIMIT 10)",
+			expectedArgs: nil,
+		},
+		{
+			name:         "distinct falls back to subquery",
+			query:        "SELECT DISTINCT name FROM user WHERE status = ? LIMIT ?",
+			args:         []any{1, 20},
+			expected:     "SELECT COUNT(*) FROM (SELECT DISTINCT name FROM user WHERE status = ?) AS juice_count",
+			expectedArgs: []any{1},
+		},
+		{
+			name:         "group by falls back to subquery",
+			query:        "SELECT status, COUNT(*) FROM user GROUP BY status ORDER BY status",
+			expected:     "SELECT COUNT(*) FROM (SELECT status, COUNT(*) FROM user GROUP BY status) AS juice_count",
+			expectedArgs: []any{},
+		},
+		{
+			name:         "placeholders in select list fall back to subquery",
+			query:        "SELECT id, ? AS tag FROM user LIMIT ?",
+			args:         []any{"a", 20},
+			expected:     "SELECT COUNT(*) FROM (SELECT id, ? AS tag FROM user) AS juice_count",
+			expectedArgs: []any{"a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args, err := CountQuery(tt.query, tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if query != tt.expected {
+				t.Errorf("unexpected query: %s", query)
+			}
+			if len(args) != len(tt.expectedArgs) || (len(args) > 0 && !reflect.DeepEqual(args, tt.expectedArgs)) {
+				t.Errorf("unexpected args: %v", args)
+			}
+		})
+	}
+
+	if _, _, err := CountQuery("UPDATE user SET status = 1", nil); err == nil {
+		t.Error("expected error for non select query")
+	}
+}
+
+func TestCountContext_SoftDelete(t *testing.T) {
+	stmt := parseTestStatement(t, Select, `<select id="QueryUsers" softDelete="true">
+		SELECT DISTINCT name FROM user WHERE status = #{status} ORDER BY name
+	</select>`)
+	builder := statementBuilder{interceptors: StatementInterceptorGroup{SoftDeleteInterceptor{}}}
+	translator := juicedriver.MySQLDriver{}.Translator()
+
+	// the predicate is appended to the data query before it is wrapped.
+	query, args, err := builder.build(context.Background(), translator, countStatement{Statement: stmt}, H{"status": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT COUNT(*) FROM (SELECT DISTINCT name FROM user WHERE (status = ?) AND deleted_at IS NULL) AS juice_count" || !reflect.DeepEqual(args, []any{1}) {
+		t.Errorf("unexpected result: %s %v", query, args)
+	}
+}
+
+func TestCountContext_NamedArgs(t *testing.T) {
+	stmt := parseTestStatement(t, Select, `<select id="QueryUsers">
+		SELECT id, name FROM user WHERE status = #{status}
+		ORDER BY name OFFSET #{offset} ROWS FETCH NEXT #{limit} ROWS ONLY
+	</select>`)
+	translator := juicedriver.SQLServerDriver{NamedArgs: true}.Translator()
+
+	// the args of the stripped named placeholders are dropped with them.
+	query, args, err := statementBuilder{}.build(context.Background(), translator, countStatement{Statement: stmt}, H{"status": 1, "offset": 20, "limit": 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT COUNT(*) FROM user WHERE status = @status" || !reflect.DeepEqual(args, []any{sql.Named("status", 1)}) {
+		t.Errorf("unexpected result: %s %v", query, args)
+	}
+
+	// a reused placeholder in the stripped clauses has no arg of its own.
+	query, args, err = countQuery("SELECT * FROM user WHERE status = @status ORDER BY CASE WHEN status = @status THEN 0 END, @@ROWCOUNT OFFSET @offset ROWS", []any{1, 20}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT COUNT(*) FROM user WHERE status = @status" || !reflect.DeepEqual(args, []any{1}) {
+		t.Errorf("unexpected result: %s %v", query, args)
+	}
+}
+
+func TestCountContext(t *testing.T) {
+	db, state := newFakeDB(t, fakeResultSet{
+		columns: []string{"COUNT(*)"},
+		rows:    [][]driver.Value{{int64(42)}},
+	})
+	executor := newFakeExecutor(db, newFakeStatement("SELECT id, name FROM user WHERE status = #{status} LIMIT #{limit}"))
+	total, err := CountContext(context.Background(), executor, H{"status": 1, "limit": 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 42 {
+		t.Errorf("unexpected total: %d", total)
+	}
+	if len(state.executions) != 1 || state.executions[0].query != "SELECT COUNT(*) FROM user WHERE status = ?" {
+		t.Errorf("unexpected executions: %v", state.executions)
+	}
+}
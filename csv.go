@@ -0,0 +1,123 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// CSVOptions is the options of StreamCSV.
+type CSVOptions struct {
+	// Comma is the field delimiter, ',' if zero.
+	Comma rune
+
+	// Null is the field written for the NULL values, empty by default.
+	Null string
+
+	// TimeFormat is the layout of the time.Time values, time.RFC3339Nano if empty.
+	TimeFormat string
+
+	// NoHeader skips the header row of the column names.
+	NoHeader bool
+}
+
+// StreamCSV executes the query of the executor and writes the rows to w as CSV, for the data
+// export endpoints, without loading the rows into memory:
+//
+//	w.Header().Set("Content-Type", "text/csv")
+//	err := juice.StreamCSV(ctx, engine.Object(ExportUsers), param, w, juice.CSVOptions{Null: "NULL"})
+//
+// The header row is the column names of the rows. The values are written by their driver-native
+// form: the bytes as strings, the times by TimeFormat and the NULL values as Null.
+// The rows are always closed. Since the rows are streamed, w may have received a part of the
+// rows when an error is returned, like a write error or the cancellation of ctx.
+func StreamCSV(ctx context.Context, executor SQLRowsExecutor, param Param, w io.Writer, opts CSVOptions) error {
+	ctx, release := withRowsReleaser(ctx)
+	defer release()
+	rows, err := executor.QueryContext(ctx, param)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	if opts.Comma != 0 {
+		writer.Comma = opts.Comma
+	}
+	if !opts.NoHeader {
+		if err = writer.Write(columns); err != nil {
+			return err
+		}
+	}
+	values := make([]any, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	record := make([]string, len(columns))
+	for rows.Next() {
+		if err = rows.Scan(dest...); err != nil {
+			return err
+		}
+		for i, value := range values {
+			record[i] = opts.format(value)
+		}
+		if err = writer.Write(record); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// format returns the CSV field of the driver-native value.
+func (o CSVOptions) format(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return o.Null
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		layout := o.TimeFormat
+		if layout == "" {
+			layout = time.RFC3339Nano
+		}
+		return v.Format(layout)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,66 @@
+package juice
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamCSV(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	db, _ := newFakeDB(t, fakeResultSet{
+		columns: []string{"id", "name", "score", "active", "created_at", "deleted_at"},
+		rows: [][]driver.Value{
+			{int64(1), "eat, more", 1.5, true, created, nil},
+			{int64(2), []byte(`"apple"`), float64(2), false, created, created},
+		},
+	})
+	executor := newFakeExecutor(db, newFakeStatement("SELECT * FROM user"))
+
+	var builder strings.Builder
+	err := StreamCSV(context.Background(), executor, nil, &builder, CSVOptions{Null: "NULL", TimeFormat: time.DateOnly})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "id,name,score,active,created_at,deleted_at\n" +
+		"1,\"eat, more\",1.5,true,2024-01-02,NULL\n" +
+		"2,\"\"\"apple\"\"\",2,false,2024-01-02,2024-01-02\n"
+	if builder.String() != want {
+		t.Errorf("unexpected csv:\n%s", builder.String())
+	}
+
+	builder.Reset()
+	err = StreamCSV(context.Background(), executor, nil, &builder, CSVOptions{Comma: ';', NoHeader: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(builder.String(), "1;eat, more;1.5;true;2024-01-02T03:04:05Z;\n") {
+		t.Errorf("unexpected csv:\n%s", builder.String())
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("write failed") }
+
+func TestStreamCSV_Error(t *testing.T) {
+	db, state := newFakeDB(t, fakeResultSet{
+		columns: []string{"id"},
+		rows:    [][]driver.Value{{int64(1)}},
+	})
+	executor := newFakeExecutor(db, newFakeStatement("SELECT id FROM user"))
+	if err := StreamCSV(context.Background(), executor, nil, failingWriter{}, CSVOptions{}); err == nil {
+		t.Error("expected write error")
+	}
+	if inUse := db.Stats().InUse; inUse != 0 {
+		t.Errorf("expected the rows closed, %d connections in use", inUse)
+	}
+
+	state.queryErr = errors.New("query failed")
+	if err := StreamCSV(context.Background(), executor, nil, failingWriter{}, CSVOptions{}); !errors.Is(err, state.queryErr) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-juicedev/juice/driver"
+	"github.com/go-juicedev/juice/session"
+)
+
+// dataSourceAttribute is the attribute of the statements which executes them with the database
+// of the configured environment with the given id, instead of the default environment:
+//
+//	<environments default="prod">
+//	    <environment id="prod">...</environment>
+//	    <environment id="analytics">...</environment>
+//	</environments>
+//
+//	<select id="QueryDailyReport" dataSource="analytics">...</select>
+//
+// The statement is built with the driver of the environment, and its database is connected by
+// the first use and closed with the engine. Set on the mapper, it applies to all its statements.
+const dataSourceAttribute = "dataSource"
+
+// ErrDataSourceInTransaction is returned when a statement with another data source is executed
+// in a transaction, which is bound to the database of the default environment.
+var ErrDataSourceInTransaction = errors.New("juice: statement with another data source can not be executed in the transaction")
+
+// dataSource returns the session and driver of the environment selected by the dataSource
+// attribute of the statement, or the ones of the engine if it is not set.
+func (e *Engine) dataSource(stat Statement) (session.Session, driver.Driver, error) {
+	id := stat.Attribute(dataSourceAttribute)
+	envs := e.configuration.Environments()
+	if id == "" || id == envs.Attribute("default") {
+		return e.DB(), e.driver, nil
+	}
+	env, err := envs.Use(id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: data source %s: %w", stat.Name(), id, err)
+	}
+	drv, err := driver.Get(env.Driver)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: data source %s: %w", stat.Name(), id, err)
+	}
+	db, err := e.environmentDBs.get(env)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: data source %s: %w", stat.Name(), id, err)
+	}
+	return db, drv, nil
+}
+
+// checkTxDataSource returns ErrDataSourceInTransaction if the statement selects
+// an environment other than the default one.
+func checkTxDataSource(configuration IConfiguration, stat Statement) error {
+	id, ok := otherDataSource(configuration, stat)
+	if !ok {
+		return nil
+	}
+	return fmt.Errorf("%w: %s uses %s", ErrDataSourceInTransaction, stat.Name(), id)
+}
+
+// otherDataSource returns the environment selected by the dataSource attribute of the statement,
+// and reports whether it is not the default environment of the configuration.
+func otherDataSource(configuration IConfiguration, stat Statement) (string, bool) {
+	id := stat.Attribute(dataSourceAttribute)
+	if id == "" {
+		return "", false
+	}
+	if configuration != nil && id == configuration.Environments().Attribute("default") {
+		return id, false
+	}
+	return id, true
+}
@@ -0,0 +1,82 @@
+package juice
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-juicedev/juice/driver"
+)
+
+func TestEngine_DataSource(t *testing.T) {
+	// the analytics database speaks the postgres dialect.
+	driver.Register("juice_fake", driver.PostgresDriver{})
+	analytics := &fakeDB{}
+	fakeDBs.Store("data_source_test", analytics)
+	t.Cleanup(func() { fakeDBs.Delete("data_source_test") })
+
+	fsys := fstest.MapFS{
+		"config/juice.xml": {Data: []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<configuration>
+    <environments default="primary">
+        <environment id="primary">
+            <dataSource>fake</dataSource>
+            <driver>fake</driver>
+        </environment>
+        <environment id="analytics">
+            <dataSource>data_source_test</dataSource>
+            <driver>juice_fake</driver>
+        </environment>
+    </environments>
+    <mappers pattern="mappers/*.xml"/>
+</configuration>`)},
+		"config/mappers/mapper.xml": {Data: []byte(`<?xml version="1.0" encoding="utf-8" ?>
+<mapper namespace="main.Repository">
+    <select id="QueryUser">SELECT id FROM user WHERE id = #{id}</select>
+    <select id="QueryPrimaryUser" dataSource="primary">SELECT id FROM user WHERE id = #{id}</select>
+    <select id="QueryReport" dataSource="analytics">SELECT total FROM report WHERE day = #{day}</select>
+    <select id="QueryUnknown" dataSource="unknown">SELECT 1</select>
+</mapper>`)},
+	}
+	cfg, err := NewXMLConfigurationWithFS(fsys, "config/juice.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, primary := newFakeDB(t, fakeResultSet{})
+	engine := &Engine{configuration: cfg, driver: driver.MySQLDriver{}, db: db, rw: &NoOpRWMutex{}}
+	defer func() { _ = engine.Close() }()
+
+	ctx := context.Background()
+	query := func(manager Manager, id string) error {
+		t.Helper()
+		rows, err := manager.Object(id).QueryContext(ctx, H{"id": 1, "day": "2024-01-01"})
+		if err != nil {
+			return err
+		}
+		return rows.Close()
+	}
+	for _, id := range []string{"main.Repository.QueryUser", "main.Repository.QueryPrimaryUser", "main.Repository.QueryReport"} {
+		if err = query(engine, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(primary.executions) != 2 {
+		t.Errorf("unexpected primary executions: %v", primary.executions)
+	}
+	if len(analytics.executions) != 1 || analytics.executions[0].query != "SELECT total FROM report WHERE day = $1" {
+		t.Errorf("unexpected analytics executions: %v", analytics.executions)
+	}
+
+	if err = query(engine, "main.Repository.QueryUnknown"); err == nil {
+		t.Error("expected error for unknown data source")
+	}
+
+	// the transaction is bound to the default environment.
+	err = Transaction(ContextWithManager(ctx, engine), func(ctx context.Context) error {
+		return query(ManagerFromContext(ctx), "main.Repository.QueryReport")
+	})
+	if !errors.Is(err, ErrDataSourceInTransaction) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
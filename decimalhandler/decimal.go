@@ -0,0 +1,112 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package decimalhandler provides juice.TypeHandler implementations for exact decimal types,
+// so that the monetary columns are scanned and bound without the rounding of float64.
+//
+// Register the handlers before the engine is used:
+//
+//	decimalhandler.Register()
+//
+// Then the fields of type decimal.Decimal or *big.Rat are scanned from the decimal
+// and numeric columns, and bound as decimal strings.
+package decimalhandler
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/go-juicedev/juice"
+)
+
+// ensure the handlers implement juice.TypeHandler.
+var (
+	_ juice.TypeHandler[decimal.Decimal] = Decimal{} // compile time check
+	_ juice.TypeHandler[*big.Rat]        = Rat{}     // compile time check
+)
+
+// Register registers the handlers of decimal.Decimal and *big.Rat.
+func Register() {
+	juice.RegisterTypeHandler[decimal.Decimal](Decimal{})
+	juice.RegisterTypeHandler[*big.Rat](Rat{})
+}
+
+// Decimal is the TypeHandler of github.com/shopspring/decimal.Decimal.
+// NULL is scanned as zero, use decimal.NullDecimal for the nullable columns.
+type Decimal struct{}
+
+// Scan implements juice.TypeHandler.
+func (Decimal) Scan(src any) (decimal.Decimal, error) {
+	var value decimal.Decimal
+	if src == nil {
+		return value, nil
+	}
+	err := value.Scan(src)
+	return value, err
+}
+
+// Value implements juice.TypeHandler.
+func (Decimal) Value(value decimal.Decimal) (driver.Value, error) {
+	return value.String(), nil
+}
+
+// Rat is the TypeHandler of *big.Rat.
+// NULL is scanned as nil, and nil is bound as NULL.
+type Rat struct{}
+
+// Scan implements juice.TypeHandler.
+func (Rat) Scan(src any) (*big.Rat, error) {
+	switch value := src.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return parseRat(string(value))
+	case string:
+		return parseRat(value)
+	case int64:
+		return new(big.Rat).SetInt64(value), nil
+	case float64:
+		// the driver has already lost the exactness, keep the value it reported.
+		return new(big.Rat).SetFloat64(value), nil
+	default:
+		return nil, fmt.Errorf("decimalhandler: can not scan %T into *big.Rat", src)
+	}
+}
+
+// Value implements juice.TypeHandler.
+// It returns an error if the value can not be represented as a finite decimal, like 1/3.
+func (Rat) Value(value *big.Rat) (driver.Value, error) {
+	if value == nil {
+		return nil, nil
+	}
+	prec, exact := value.FloatPrec()
+	if !exact {
+		return nil, fmt.Errorf("decimalhandler: %s is not a finite decimal", value.RatString())
+	}
+	return value.FloatString(prec), nil
+}
+
+// parseRat parses the decimal string into *big.Rat.
+func parseRat(text string) (*big.Rat, error) {
+	value, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return nil, fmt.Errorf("decimalhandler: invalid decimal %q", text)
+	}
+	return value, nil
+}
@@ -0,0 +1,61 @@
+package decimalhandler
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestRat(t *testing.T) {
+	var handler Rat
+	value, err := handler.Scan([]byte("12.345"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value.Cmp(big.NewRat(12345, 1000)) != 0 {
+		t.Errorf("unexpected value %s", value.RatString())
+	}
+	bound, err := handler.Value(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bound != "12.345" {
+		t.Errorf("expected 12.345, got %v", bound)
+	}
+
+	if value, err = handler.Scan(nil); err != nil || value != nil {
+		t.Errorf("expected nil for NULL, got %v, %v", value, err)
+	}
+	if bound, err = handler.Value(nil); err != nil || bound != nil {
+		t.Errorf("expected NULL for nil, got %v, %v", bound, err)
+	}
+	if _, err = handler.Value(big.NewRat(1, 3)); err == nil {
+		t.Error("expected error for 1/3")
+	}
+	if _, err = handler.Scan("abc"); err == nil {
+		t.Error("expected error for invalid decimal")
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	var handler Decimal
+	value, err := handler.Scan("0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := value.Add(value).Add(value)
+	if !sum.Equal(decimal.RequireFromString("0.3")) {
+		t.Errorf("expected 0.3, got %s", sum)
+	}
+	bound, err := handler.Value(sum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bound != "0.3" {
+		t.Errorf("expected 0.3, got %v", bound)
+	}
+	if value, err = handler.Scan(nil); err != nil || !value.IsZero() {
+		t.Errorf("expected zero for NULL, got %v, %v", value, err)
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/go-juicedev/juice/decimalhandler
+
+go 1.23
+
+require (
+	github.com/go-juicedev/juice v0.0.0
+	github.com/shopspring/decimal v1.4.0
+)
+
+replace github.com/go-juicedev/juice => ../
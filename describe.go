@@ -0,0 +1,96 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-juicedev/juice/driver"
+)
+
+// DescribeResult returns the metadata of the columns of the result of the statement,
+// like their names and database types, without scanning the rows.
+// It is useful for the generic tooling, like building dynamic views or validating
+// the result mappings against the actual columns. For example:
+//
+//	columns, err := engine.DescribeResult(ctx, "main.UserMapper.QueryUsers", param)
+//	for _, column := range columns {
+//	    fmt.Println(column.Name(), column.DatabaseTypeName())
+//	}
+//
+// The statement is executed with a LIMIT 0 clause of the driver, so that no rows are returned.
+// If the driver does not support pagination, the statement already contains a pagination clause,
+// or the database rejects the limited query as a syntax error or an unsupported feature, the
+// statement is executed as it is and its rows are closed right after the columns are read.
+// The other errors, like the cancellation of the context, are returned as they are.
+// Only the select statements can be described, since the statement is executed.
+func (e *Engine) DescribeResult(ctx context.Context, v any, param Param) ([]*sql.ColumnType, error) {
+	exe, err := e.executor(v)
+	if err != nil {
+		return nil, err
+	}
+	return describeResult(ctx, exe, param)
+}
+
+// describeResult returns the column types of the result of the statement executed by the executor.
+func describeResult(ctx context.Context, executor SQLRowsExecutor, param Param) ([]*sql.ColumnType, error) {
+	if action := executor.Statement().Action(); !action.ForRead() {
+		return nil, fmt.Errorf("describe result: can not describe %s statement %s", action, executor.Statement().Name())
+	}
+	ctx, release := withRowsReleaser(ctx)
+	defer release()
+	if _, ok := executor.Driver().(driver.Paginator); ok {
+		rows, err := paginate(executor, 0, 0).QueryContext(ctx, param)
+		if err == nil {
+			return columnTypes(rows)
+		}
+		if !errors.Is(err, ErrStatementAlreadyPaginated) && !isSyntaxError(err) {
+			return nil, err
+		}
+	}
+	rows, err := executor.QueryContext(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+	return columnTypes(rows)
+}
+
+// isSyntaxError reports whether the error is a syntax error or an unsupported feature
+// reported by the database, which is recognized by the SQLSTATE classes 42 and 0A of the
+// drivers which report it, like lib/pq and pgx, or by the message of the others.
+func isSyntaxError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		state := stateErr.SQLState()
+		return strings.HasPrefix(state, "42") || strings.HasPrefix(state, "0A")
+	}
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "syntax") || strings.Contains(message, "not supported") || strings.Contains(message, "unsupported")
+}
+
+// columnTypes returns the column types of the rows and closes them.
+func columnTypes(rows *sql.Rows) ([]*sql.ColumnType, error) {
+	defer func() { _ = rows.Close() }()
+	return rows.ColumnTypes()
+}
@@ -0,0 +1,90 @@
+package juice
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDescribeResult(t *testing.T) {
+	db, state := newFakeDB(t, fakeResultSet{
+		columns:   []string{"id", "name"},
+		scanTypes: []reflect.Type{reflect.TypeOf(int64(0)), reflect.TypeOf("")},
+	})
+	executor := newFakeExecutor(db, newFakeStatement("SELECT id, name FROM user"))
+	columns, err := describeResult(context.Background(), executor, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(columns) != 2 || columns[0].Name() != "id" || columns[1].Name() != "name" {
+		t.Fatalf("unexpected columns: %v", columns)
+	}
+	if columns[0].ScanType() != reflect.TypeOf(int64(0)) {
+		t.Errorf("unexpected scan type %v", columns[0].ScanType())
+	}
+	if len(state.executions) != 1 || state.executions[0].query != "SELECT id, name FROM user LIMIT ? OFFSET ?" {
+		t.Errorf("expected the limited query, got %v", state.executions)
+	}
+}
+
+func TestDescribeResult_Fallback(t *testing.T) {
+	db, state := newFakeDB(t, fakeResultSet{columns: []string{"id"}})
+	executor := newFakeExecutor(db, newFakeStatement("SELECT id FROM user LIMIT 10"))
+	columns, err := describeResult(context.Background(), executor, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(columns) != 1 || columns[0].Name() != "id" {
+		t.Fatalf("unexpected columns: %v", columns)
+	}
+	// the statement already contains a LIMIT clause, so it is executed as it is.
+	if len(state.executions) != 1 || state.executions[0].query != "SELECT id FROM user LIMIT 10" {
+		t.Errorf("expected the original query, got %v", state.executions)
+	}
+}
+
+func TestDescribeResult_Errors(t *testing.T) {
+	db, state := newFakeDB(t, fakeResultSet{columns: []string{"id"}})
+	executor := newFakeExecutor(db, newFakeStatement("SELECT id FROM user"))
+
+	// the database rejects the limited query, the statement is executed as it is.
+	state.queryErrs = []error{errors.New("near \"LIMIT\": syntax error"), nil}
+	columns, err := describeResult(context.Background(), executor, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(columns) != 1 || len(state.executions) != 2 || state.executions[1].query != "SELECT id FROM user" {
+		t.Errorf("expected the original query, got %v", state.executions)
+	}
+
+	// the other errors are returned without the fallback.
+	state.executions = nil
+	state.queryErrs = []error{errors.New("connection reset"), nil}
+	if _, err = describeResult(context.Background(), executor, nil); err == nil || err.Error() != "connection reset" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(state.executions) != 1 {
+		t.Errorf("expected no fallback, got %v", state.executions)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = describeResult(ctx, executor, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestDescribeResult_Write(t *testing.T) {
+	db, state := newFakeDB(t, fakeResultSet{})
+	statement := newFakeStatement("DELETE FROM user")
+	statement.action = Delete
+	_, err := describeResult(context.Background(), newFakeExecutor(db, statement), nil)
+	if err == nil || !strings.Contains(err.Error(), "can not describe") {
+		t.Errorf("expected error for delete statement, got %v", err)
+	}
+	if len(state.executions) != 0 {
+		t.Error("expected the statement not to be executed")
+	}
+}
@@ -18,6 +18,7 @@ package driver
 
 import (
 	"database/sql"
+	"fmt"
 	"time"
 )
 
@@ -27,6 +28,7 @@ type connectOption struct {
 	MaxOpenConnNum      int
 	MaxConnLifetime     time.Duration
 	MaxIdleConnLifetime time.Duration
+	Properties          map[string]string
 }
 
 // ConnectOptionFunc is a function to set the connection option.
@@ -60,12 +62,27 @@ func ConnectWithMaxIdleConnLifetime(d time.Duration) ConnectOptionFunc {
 	}
 }
 
+// ConnectWithProperties sets the driver specific properties of the connection.
+// They are applied to the data source by the registered driver of the same name,
+// which must implement DataSourceBuilder.
+func ConnectWithProperties(properties map[string]string) ConnectOptionFunc {
+	return func(option *connectOption) {
+		option.Properties = properties
+	}
+}
+
 // Connect connects to the database.
 func Connect(driver string, datasource string, opts ...ConnectOptionFunc) (*sql.DB, error) {
 	var option connectOption
 	for _, opt := range opts {
 		opt(&option)
 	}
+	if len(option.Properties) > 0 {
+		var err error
+		if datasource, err = dataSourceWithProperties(driver, datasource, option.Properties); err != nil {
+			return nil, err
+		}
+	}
 	db, err := sql.Open(driver, datasource)
 	if err != nil {
 		return nil, err
@@ -84,3 +101,16 @@ func Connect(driver string, datasource string, opts ...ConnectOptionFunc) (*sql.
 	}
 	return db, nil
 }
+
+// dataSourceWithProperties applies the properties to the data source with the registered driver.
+func dataSourceWithProperties(name, dataSource string, properties map[string]string) (string, error) {
+	drv, err := Get(name)
+	if err != nil {
+		return "", err
+	}
+	builder, ok := drv.(DataSourceBuilder)
+	if !ok {
+		return "", fmt.Errorf("driver %s does not support properties", name)
+	}
+	return builder.DataSource(dataSource, properties)
+}
@@ -0,0 +1,368 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// ConstraintKind is the kind of the violated constraint.
+type ConstraintKind int
+
+const (
+	// UniqueConstraint is a unique or primary key constraint.
+	UniqueConstraint ConstraintKind = iota + 1
+
+	// ForeignKeyConstraint is a foreign key constraint.
+	ForeignKeyConstraint
+
+	// NotNullConstraint is a not-null constraint.
+	NotNullConstraint
+
+	// CheckConstraint is a check constraint.
+	CheckConstraint
+)
+
+// String returns the name of the constraint kind.
+func (k ConstraintKind) String() string {
+	switch k {
+	case UniqueConstraint:
+		return "unique"
+	case ForeignKeyConstraint:
+		return "foreign key"
+	case NotNullConstraint:
+		return "not null"
+	case CheckConstraint:
+		return "check"
+	default:
+		return "unknown"
+	}
+}
+
+// ConstraintViolation describes a constraint violation reported by the database.
+// Constraint and Column are empty when the driver doesn't report them.
+type ConstraintViolation struct {
+	Kind       ConstraintKind
+	Constraint string
+	Column     string
+}
+
+// ErrorClassifier is implemented by the drivers which recognize the constraint violations of their database.
+// The errors are inspected without importing the database/sql driver packages, so they are
+// recognized by their methods, their exported fields, or their messages.
+type ErrorClassifier interface {
+	// ClassifyError returns the constraint violation of the error, or any error it wraps.
+	// It returns false if the error is not a constraint violation.
+	ClassifyError(err error) (ConstraintViolation, bool)
+}
+
+// DuplicateKeyDetector is implemented by the drivers which recognize the duplicate key errors of their database.
+type DuplicateKeyDetector interface {
+	// IsDuplicateKey reports whether the error, or any error it wraps, is a unique constraint violation.
+	IsDuplicateKey(err error) bool
+}
+
+// ensure the built-in drivers implement ErrorClassifier and DuplicateKeyDetector.
+var (
+	_ ErrorClassifier      = (*MySQLDriver)(nil)     // compile time check
+	_ ErrorClassifier      = (*SQLiteDriver)(nil)    // compile time check
+	_ ErrorClassifier      = (*PostgresDriver)(nil)  // compile time check
+	_ ErrorClassifier      = (*OracleDriver)(nil)    // compile time check
+	_ ErrorClassifier      = (*SQLServerDriver)(nil) // compile time check
+	_ DuplicateKeyDetector = (*MySQLDriver)(nil)     // compile time check
+	_ DuplicateKeyDetector = (*SQLiteDriver)(nil)    // compile time check
+	_ DuplicateKeyDetector = (*PostgresDriver)(nil)  // compile time check
+	_ DuplicateKeyDetector = (*OracleDriver)(nil)    // compile time check
+	_ DuplicateKeyDetector = (*SQLServerDriver)(nil) // compile time check
+)
+
+// isDuplicateKey reports whether the error is classified as a unique constraint violation.
+func isDuplicateKey(classifier ErrorClassifier, err error) bool {
+	violation, ok := classifier.ClassifyError(err)
+	return ok && violation.Kind == UniqueConstraint
+}
+
+// sqlState returns the SQLSTATE of the error, which is reported by the SQLState method
+// of the PostgreSQL drivers, like lib/pq and pgx.
+func sqlState(err error) (string, bool) {
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		return stateErr.SQLState(), true
+	}
+	return "", false
+}
+
+// errorCode returns the code of the error, which is reported by the Code method,
+// like the errors of modernc.org/sqlite.
+func errorCode(err error) (int64, bool) {
+	var codeErr interface{ Code() int }
+	if errors.As(err, &codeErr) {
+		return int64(codeErr.Code()), true
+	}
+	return 0, false
+}
+
+// errorStructField returns the field of the error struct with the given name,
+// searching the error and the errors it wraps.
+func errorStructField(err error, name string) (reflect.Value, bool) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		value := reflect.Indirect(reflect.ValueOf(err))
+		if value.Kind() != reflect.Struct {
+			continue
+		}
+		if field := value.FieldByName(name); field.IsValid() {
+			return field, true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// errorField returns the integer field of the error struct with the given name,
+// like the Number field of *mysql.MySQLError.
+func errorField(err error, name string) (int64, bool) {
+	field, ok := errorStructField(err, name)
+	if !ok {
+		return 0, false
+	}
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return field.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(field.Uint()), true
+	default:
+		return 0, false
+	}
+}
+
+// errorStringField returns the first non-empty string field of the error struct with the given names,
+// like the Constraint field of *pq.Error and the ConstraintName field of *pgconn.PgError.
+func errorStringField(err error, names ...string) string {
+	for _, name := range names {
+		if field, ok := errorStructField(err, name); ok && field.Kind() == reflect.String && field.String() != "" {
+			return field.String()
+		}
+	}
+	return ""
+}
+
+// submatch returns the first submatch of the regexp in the message.
+func submatch(regex *regexp.Regexp, message string) string {
+	if matched := regex.FindStringSubmatch(message); len(matched) > 1 {
+		return matched[1]
+	}
+	return ""
+}
+
+// lastName returns the last part of the dotted and quoted name, like "name" of "user"."name".
+func lastName(name string) string {
+	if index := strings.LastIndexByte(name, '.'); index >= 0 {
+		name = name[index+1:]
+	}
+	return strings.Trim(name, "\"`'[]")
+}
+
+var (
+	// mysqlKeyRegexp matches the key of "Duplicate entry 'a' for key 'user.uk_email'".
+	mysqlKeyRegexp = regexp.MustCompile(`for key '([^']+)'`)
+
+	// mysqlConstraintRegexp matches the constraint of "CONSTRAINT `fk_user` FOREIGN KEY" and "Check constraint 'ck_age'".
+	mysqlConstraintRegexp = regexp.MustCompile("(?i)constraint [`']([^`']+)[`']")
+
+	// mysqlColumnRegexp matches the column of "Column 'name' cannot be null" and "Field 'name' doesn't have a default value".
+	mysqlColumnRegexp = regexp.MustCompile(`(?:Column|Field) '([^']+)'`)
+)
+
+// ClassifyError implements ErrorClassifier.
+// It recognizes the errors of go-sql-driver/mysql by their numbers:
+// 1062 (duplicate entry), 1451 and 1452 (foreign key), 1048 and 1364 (not null), and 3819 (check).
+func (d MySQLDriver) ClassifyError(err error) (ConstraintViolation, bool) {
+	number, ok := errorField(err, "Number")
+	if !ok {
+		return ConstraintViolation{}, false
+	}
+	message := err.Error()
+	switch number {
+	case 1062:
+		return ConstraintViolation{Kind: UniqueConstraint, Constraint: lastName(submatch(mysqlKeyRegexp, message))}, true
+	case 1451, 1452:
+		return ConstraintViolation{Kind: ForeignKeyConstraint, Constraint: submatch(mysqlConstraintRegexp, message)}, true
+	case 1048, 1364:
+		return ConstraintViolation{Kind: NotNullConstraint, Column: submatch(mysqlColumnRegexp, message)}, true
+	case 3819:
+		return ConstraintViolation{Kind: CheckConstraint, Constraint: submatch(mysqlConstraintRegexp, message)}, true
+	default:
+		return ConstraintViolation{}, false
+	}
+}
+
+// IsDuplicateKey implements DuplicateKeyDetector.
+func (d MySQLDriver) IsDuplicateKey(err error) bool {
+	return isDuplicateKey(d, err)
+}
+
+// sqliteSubjectRegexp matches the subject of "UNIQUE constraint failed: user.email".
+var sqliteSubjectRegexp = regexp.MustCompile(`constraint failed: ([^,\s]+)`)
+
+// ClassifyError implements ErrorClassifier.
+// It recognizes the extended result codes of mattn/go-sqlite3 and modernc.org/sqlite:
+// SQLITE_CONSTRAINT_UNIQUE (2067), SQLITE_CONSTRAINT_PRIMARYKEY (1555), SQLITE_CONSTRAINT_FOREIGNKEY (787),
+// SQLITE_CONSTRAINT_NOTNULL (1299) and SQLITE_CONSTRAINT_CHECK (275).
+func (d SQLiteDriver) ClassifyError(err error) (ConstraintViolation, bool) {
+	code, ok := errorField(err, "ExtendedCode")
+	if !ok {
+		code, ok = errorCode(err)
+	}
+	if !ok {
+		return ConstraintViolation{}, false
+	}
+	subject := submatch(sqliteSubjectRegexp, err.Error())
+	switch code {
+	case 1555, 2067:
+		return ConstraintViolation{Kind: UniqueConstraint, Column: lastName(subject)}, true
+	case 787:
+		return ConstraintViolation{Kind: ForeignKeyConstraint}, true
+	case 1299:
+		return ConstraintViolation{Kind: NotNullConstraint, Column: lastName(subject)}, true
+	case 275:
+		return ConstraintViolation{Kind: CheckConstraint, Constraint: subject}, true
+	default:
+		return ConstraintViolation{}, false
+	}
+}
+
+// IsDuplicateKey implements DuplicateKeyDetector.
+func (d SQLiteDriver) IsDuplicateKey(err error) bool {
+	return isDuplicateKey(d, err)
+}
+
+// ClassifyError implements ErrorClassifier.
+// It recognizes the SQLSTATE 23505 (unique_violation), 23503 (foreign_key_violation),
+// 23502 (not_null_violation) and 23514 (check_violation), with the constraint and the column
+// reported by lib/pq and pgx.
+func (d PostgresDriver) ClassifyError(err error) (ConstraintViolation, bool) {
+	state, ok := sqlState(err)
+	if !ok {
+		return ConstraintViolation{}, false
+	}
+	violation := ConstraintViolation{
+		Constraint: errorStringField(err, "Constraint", "ConstraintName"),
+		Column:     errorStringField(err, "Column", "ColumnName"),
+	}
+	switch state {
+	case "23505":
+		violation.Kind = UniqueConstraint
+	case "23503":
+		violation.Kind = ForeignKeyConstraint
+	case "23502":
+		violation.Kind = NotNullConstraint
+	case "23514":
+		violation.Kind = CheckConstraint
+	default:
+		return ConstraintViolation{}, false
+	}
+	return violation, true
+}
+
+// IsDuplicateKey implements DuplicateKeyDetector.
+func (d PostgresDriver) IsDuplicateKey(err error) bool {
+	return isDuplicateKey(d, err)
+}
+
+var (
+	// oracleCodeRegexp matches the error code of "ORA-00001: unique constraint (APP.UK_EMAIL) violated".
+	oracleCodeRegexp = regexp.MustCompile(`ORA-(\d{5})`)
+
+	// oracleConstraintRegexp matches the constraint of "unique constraint (APP.UK_EMAIL) violated".
+	oracleConstraintRegexp = regexp.MustCompile(`constraint \(([^)]+)\)`)
+
+	// oracleColumnRegexp matches the column of `cannot insert NULL into ("APP"."USER"."NAME")`.
+	oracleColumnRegexp = regexp.MustCompile(`into \(([^)]+)\)`)
+)
+
+// ClassifyError implements ErrorClassifier.
+// It recognizes ORA-00001 (unique), ORA-02291 and ORA-02292 (foreign key),
+// ORA-01400 (not null) and ORA-02290 (check) by the error messages.
+func (o OracleDriver) ClassifyError(err error) (ConstraintViolation, bool) {
+	message := err.Error()
+	constraint := lastName(submatch(oracleConstraintRegexp, message))
+	switch submatch(oracleCodeRegexp, message) {
+	case "00001":
+		return ConstraintViolation{Kind: UniqueConstraint, Constraint: constraint}, true
+	case "02291", "02292":
+		return ConstraintViolation{Kind: ForeignKeyConstraint, Constraint: constraint}, true
+	case "01400":
+		return ConstraintViolation{Kind: NotNullConstraint, Column: lastName(submatch(oracleColumnRegexp, message))}, true
+	case "02290":
+		return ConstraintViolation{Kind: CheckConstraint, Constraint: constraint}, true
+	default:
+		return ConstraintViolation{}, false
+	}
+}
+
+// IsDuplicateKey implements DuplicateKeyDetector.
+func (o OracleDriver) IsDuplicateKey(err error) bool {
+	return isDuplicateKey(o, err)
+}
+
+var (
+	// sqlServerConstraintRegexp matches the kind and the name of `conflicted with the FOREIGN KEY constraint "FK_USER"`.
+	sqlServerConstraintRegexp = regexp.MustCompile(`(FOREIGN KEY|CHECK|REFERENCE) constraint "([^"]+)"`)
+
+	// sqlServerUniqueRegexp matches the name of `Violation of UNIQUE KEY constraint 'UK_EMAIL'` and
+	// `unique index 'IX_EMAIL'`.
+	sqlServerUniqueRegexp = regexp.MustCompile(`(?:constraint|index) '([^']+)'`)
+
+	// sqlServerColumnRegexp matches the column of "Cannot insert the value NULL into column 'name'".
+	sqlServerColumnRegexp = regexp.MustCompile(`column '([^']+)'`)
+)
+
+// ClassifyError implements ErrorClassifier.
+// It recognizes the errors of microsoft/go-mssqldb by their numbers: 2601 and 2627 (unique),
+// 547 (foreign key or check, told apart by the message) and 515 (not null).
+func (d SQLServerDriver) ClassifyError(err error) (ConstraintViolation, bool) {
+	var numberErr interface{ SQLErrorNumber() int32 }
+	if !errors.As(err, &numberErr) {
+		return ConstraintViolation{}, false
+	}
+	message := err.Error()
+	switch numberErr.SQLErrorNumber() {
+	case 2601, 2627:
+		return ConstraintViolation{Kind: UniqueConstraint, Constraint: submatch(sqlServerUniqueRegexp, message)}, true
+	case 547:
+		matched := sqlServerConstraintRegexp.FindStringSubmatch(message)
+		if len(matched) != 3 {
+			return ConstraintViolation{}, false
+		}
+		kind := ForeignKeyConstraint
+		if matched[1] == "CHECK" {
+			kind = CheckConstraint
+		}
+		return ConstraintViolation{Kind: kind, Constraint: matched[2]}, true
+	case 515:
+		return ConstraintViolation{Kind: NotNullConstraint, Column: submatch(sqlServerColumnRegexp, message)}, true
+	default:
+		return ConstraintViolation{}, false
+	}
+}
+
+// IsDuplicateKey implements DuplicateKeyDetector.
+func (d SQLServerDriver) IsDuplicateKey(err error) bool {
+	return isDuplicateKey(d, err)
+}
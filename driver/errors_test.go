@@ -0,0 +1,134 @@
+package driver
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// mysqlError mimics *mysql.MySQLError.
+type mysqlError struct {
+	Number  uint16
+	Message string
+}
+
+// pqError mimics *pq.Error.
+type pqError struct {
+	Code       string
+	Constraint string
+	Column     string
+}
+
+func (e *pqError) Error() string    { return "pq: " + e.Code }
+func (e *pqError) SQLState() string { return e.Code }
+
+// sqlite3Message mimics sqlite3.Error of mattn/go-sqlite3 with its message.
+type sqlite3Message struct {
+	ExtendedCode int
+	Message      string
+}
+
+func (e sqlite3Message) Error() string { return e.Message }
+
+// mssqlMessage mimics mssql.Error with its message.
+type mssqlMessage struct {
+	Number  int32
+	Message string
+}
+
+func (e mssqlMessage) Error() string         { return e.Message }
+func (e mssqlMessage) SQLErrorNumber() int32 { return e.Number }
+
+func (e *mysqlError) Error() string { return e.Message }
+
+// sqlite3Error mimics sqlite3.Error of mattn/go-sqlite3.
+type sqlite3Error struct {
+	Code         int
+	ExtendedCode int
+}
+
+func (e sqlite3Error) Error() string { return "constraint failed" }
+
+// stateError mimics *pq.Error and *pgconn.PgError.
+type stateError string
+
+func (e stateError) Error() string    { return "pq: " + string(e) }
+func (e stateError) SQLState() string { return string(e) }
+
+// codeError mimics the errors reporting their code by the Code method.
+type codeError int
+
+func (e codeError) Error() string { return fmt.Sprintf("code %d", int(e)) }
+func (e codeError) Code() int     { return int(e) }
+
+// mssqlError mimics mssql.Error.
+type mssqlError int32
+
+func (e mssqlError) Error() string         { return "mssql" }
+func (e mssqlError) SQLErrorNumber() int32 { return int32(e) }
+
+func TestDuplicateKeyDetector(t *testing.T) {
+	tests := []struct {
+		driver    Driver
+		err       error
+		duplicate bool
+	}{
+		{MySQLDriver{}, &mysqlError{Number: 1062}, true},
+		{MySQLDriver{}, fmt.Errorf("insert: %w", &mysqlError{Number: 1062}), true},
+		{MySQLDriver{}, &mysqlError{Number: 1452}, false},
+		{MySQLDriver{}, errors.New("Error 1062"), false},
+		{SQLiteDriver{}, sqlite3Error{Code: 19, ExtendedCode: 2067}, true},
+		{SQLiteDriver{}, sqlite3Error{Code: 19, ExtendedCode: 1555}, true},
+		{SQLiteDriver{}, sqlite3Error{Code: 19, ExtendedCode: 787}, false},
+		{SQLiteDriver{}, codeError(2067), true},
+		{PostgresDriver{}, stateError("23505"), true},
+		{PostgresDriver{}, stateError("23503"), false},
+		{OracleDriver{}, errors.New("ORA-00001: unique constraint (USER_PK) violated"), true},
+		{OracleDriver{}, errors.New("ORA-01400: cannot insert NULL"), false},
+		{SQLServerDriver{}, mssqlError(2627), true},
+		{SQLServerDriver{}, mssqlError(2601), true},
+		{SQLServerDriver{}, mssqlError(547), false},
+	}
+	for _, tt := range tests {
+		if got := tt.driver.(DuplicateKeyDetector).IsDuplicateKey(tt.err); got != tt.duplicate {
+			t.Errorf("%T %v: expected %v, got %v", tt.driver, tt.err, tt.duplicate, got)
+		}
+	}
+}
+
+func TestErrorClassifier(t *testing.T) {
+	tests := []struct {
+		driver    Driver
+		err       error
+		violation ConstraintViolation
+	}{
+		{MySQLDriver{}, &mysqlError{Number: 1062, Message: "Error 1062 (23000): Duplicate entry 'a' for key 'user.uk_email'"}, ConstraintViolation{Kind: UniqueConstraint, Constraint: "uk_email"}},
+		{MySQLDriver{}, &mysqlError{Number: 1452, Message: "Cannot add or update a child row: a foreign key constraint fails (`app`.`order`, CONSTRAINT `fk_order_user` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`))"}, ConstraintViolation{Kind: ForeignKeyConstraint, Constraint: "fk_order_user"}},
+		{MySQLDriver{}, &mysqlError{Number: 1048, Message: "Column 'name' cannot be null"}, ConstraintViolation{Kind: NotNullConstraint, Column: "name"}},
+		{MySQLDriver{}, &mysqlError{Number: 3819, Message: "Check constraint 'ck_age' is violated."}, ConstraintViolation{Kind: CheckConstraint, Constraint: "ck_age"}},
+		{PostgresDriver{}, &pqError{Code: "23503", Constraint: "fk_order_user"}, ConstraintViolation{Kind: ForeignKeyConstraint, Constraint: "fk_order_user"}},
+		{PostgresDriver{}, &pqError{Code: "23502", Column: "name"}, ConstraintViolation{Kind: NotNullConstraint, Column: "name"}},
+		{PostgresDriver{}, &pqError{Code: "23514", Constraint: "ck_age"}, ConstraintViolation{Kind: CheckConstraint, Constraint: "ck_age"}},
+		{SQLiteDriver{}, sqlite3Message{ExtendedCode: 2067, Message: "UNIQUE constraint failed: user.email"}, ConstraintViolation{Kind: UniqueConstraint, Column: "email"}},
+		{SQLiteDriver{}, sqlite3Message{ExtendedCode: 787, Message: "FOREIGN KEY constraint failed"}, ConstraintViolation{Kind: ForeignKeyConstraint}},
+		{SQLiteDriver{}, sqlite3Message{ExtendedCode: 1299, Message: "NOT NULL constraint failed: user.name"}, ConstraintViolation{Kind: NotNullConstraint, Column: "name"}},
+		{SQLiteDriver{}, sqlite3Message{ExtendedCode: 275, Message: "CHECK constraint failed: ck_age"}, ConstraintViolation{Kind: CheckConstraint, Constraint: "ck_age"}},
+		{OracleDriver{}, errors.New("ORA-00001: unique constraint (APP.UK_EMAIL) violated"), ConstraintViolation{Kind: UniqueConstraint, Constraint: "UK_EMAIL"}},
+		{OracleDriver{}, errors.New("ORA-02291: integrity constraint (APP.FK_ORDER_USER) violated - parent key not found"), ConstraintViolation{Kind: ForeignKeyConstraint, Constraint: "FK_ORDER_USER"}},
+		{OracleDriver{}, errors.New(`ORA-01400: cannot insert NULL into ("APP"."USER"."NAME")`), ConstraintViolation{Kind: NotNullConstraint, Column: "NAME"}},
+		{OracleDriver{}, errors.New("ORA-02290: check constraint (APP.CK_AGE) violated"), ConstraintViolation{Kind: CheckConstraint, Constraint: "CK_AGE"}},
+		{SQLServerDriver{}, mssqlMessage{Number: 2627, Message: "Violation of UNIQUE KEY constraint 'UK_EMAIL'. Cannot insert duplicate key in object 'dbo.user'."}, ConstraintViolation{Kind: UniqueConstraint, Constraint: "UK_EMAIL"}},
+		{SQLServerDriver{}, mssqlMessage{Number: 547, Message: `The INSERT statement conflicted with the FOREIGN KEY constraint "FK_ORDER_USER".`}, ConstraintViolation{Kind: ForeignKeyConstraint, Constraint: "FK_ORDER_USER"}},
+		{SQLServerDriver{}, mssqlMessage{Number: 547, Message: `The INSERT statement conflicted with the CHECK constraint "CK_AGE".`}, ConstraintViolation{Kind: CheckConstraint, Constraint: "CK_AGE"}},
+		{SQLServerDriver{}, mssqlMessage{Number: 515, Message: "Cannot insert the value NULL into column 'name', table 'app.dbo.user'; column does not allow nulls."}, ConstraintViolation{Kind: NotNullConstraint, Column: "name"}},
+	}
+	for _, tt := range tests {
+		violation, ok := tt.driver.(ErrorClassifier).ClassifyError(tt.err)
+		if !ok || violation != tt.violation {
+			t.Errorf("%T %v: unexpected violation: %+v %v", tt.driver, tt.err, violation, ok)
+		}
+	}
+	if _, ok := (MySQLDriver{}).ClassifyError(errors.New("connection refused")); ok {
+		t.Error("expected no violation")
+	}
+}
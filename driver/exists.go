@@ -0,0 +1,45 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+// ExistsQuerier is implemented by the drivers whose databases can not select
+// the EXISTS predicate as a column, like SQL Server and Oracle.
+type ExistsQuerier interface {
+	// ExistsQuery wraps the query into a query which returns one row with one
+	// column, which is true if the query returns any rows and false otherwise.
+	ExistsQuery(query string) string
+}
+
+// ExistsQuery wraps the query into the exists query of the driver.
+// It returns "SELECT EXISTS(query)" unless the driver implements ExistsQuerier.
+func ExistsQuery(driver Driver, query string) string {
+	if querier, ok := driver.(ExistsQuerier); ok {
+		return querier.ExistsQuery(query)
+	}
+	return "SELECT EXISTS(" + query + ")"
+}
+
+// caseWhenExists returns the query which selects the EXISTS predicate as 1 or 0.
+func caseWhenExists(query string) string {
+	return "SELECT CASE WHEN EXISTS(" + query + ") THEN 1 ELSE 0 END"
+}
+
+// ensure SQLServerDriver and OracleDriver implement ExistsQuerier.
+var (
+	_ ExistsQuerier = (*SQLServerDriver)(nil) // compile time check
+	_ ExistsQuerier = (*OracleDriver)(nil)    // compile time check
+)
@@ -0,0 +1,22 @@
+package driver
+
+import "testing"
+
+func TestExistsQuery(t *testing.T) {
+	const query = "SELECT * FROM user WHERE id = ?"
+	tests := []struct {
+		driver Driver
+		want   string
+	}{
+		{MySQLDriver{}, "SELECT EXISTS(" + query + ")"},
+		{SQLiteDriver{}, "SELECT EXISTS(" + query + ")"},
+		{PostgresDriver{}, "SELECT EXISTS(" + query + ")"},
+		{SQLServerDriver{}, "SELECT CASE WHEN EXISTS(" + query + ") THEN 1 ELSE 0 END"},
+		{OracleDriver{}, "SELECT CASE WHEN EXISTS(" + query + ") THEN 1 ELSE 0 END FROM DUAL"},
+	}
+	for _, tt := range tests {
+		if got := ExistsQuery(tt.driver, query); got != tt.want {
+			t.Errorf("%T: unexpected query: %s", tt.driver, got)
+		}
+	}
+}
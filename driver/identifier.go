@@ -0,0 +1,89 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import "strings"
+
+// IdentifierQuoter is implemented by the translators which know how their dialect
+// quotes identifiers, like table and column names.
+type IdentifierQuoter interface {
+	// QuoteIdentifier returns the quoted identifier.
+	// The closing quote in the name is escaped by doubling it.
+	QuoteIdentifier(name string) string
+}
+
+// QuoteIdentifier quotes the name with the given translator.
+// The translators which wrap another one can expose it by an Unwrap() Translator method.
+// It returns false if none of them implements IdentifierQuoter.
+func QuoteIdentifier(translator Translator, name string) (string, bool) {
+	quoter, ok := TranslatorAs[IdentifierQuoter](translator)
+	if !ok {
+		return "", false
+	}
+	return quoter.QuoteIdentifier(name), true
+}
+
+// TranslatorAs returns the first translator of the Unwrap chain which implements T.
+func TranslatorAs[T any](translator Translator) (T, bool) {
+	for translator != nil {
+		if target, ok := translator.(T); ok {
+			return target, true
+		}
+		wrapper, ok := translator.(interface{ Unwrap() Translator })
+		if !ok {
+			break
+		}
+		translator = wrapper.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
+// dialectTranslator is a Translator which also knows the dialect of the driver,
+// it quotes identifiers between open and close, and renders the locking and pagination clauses.
+type dialectTranslator struct {
+	TranslateFunc
+	open, close string
+	locks       map[LockMode]string
+	paginator   Paginator
+}
+
+// QuoteIdentifier implements IdentifierQuoter.
+func (d dialectTranslator) QuoteIdentifier(name string) string {
+	return d.open + strings.ReplaceAll(name, d.close, d.close+d.close) + d.close
+}
+
+// LockClause implements RowLocker.
+func (d dialectTranslator) LockClause(mode LockMode) (string, bool) {
+	clause, ok := d.locks[mode]
+	return clause, ok
+}
+
+// Paginate implements Paginator with the paginator of the driver.
+func (d dialectTranslator) Paginate(translator Translator, limit, offset int64) (string, []any) {
+	if d.paginator == nil {
+		return limitOffsetClause(translator, limit, offset)
+	}
+	return d.paginator.Paginate(translator, limit, offset)
+}
+
+// ensure dialectTranslator implements IdentifierQuoter, RowLocker and Paginator.
+var (
+	_ IdentifierQuoter = (*dialectTranslator)(nil) // compile time check
+	_ RowLocker        = (*dialectTranslator)(nil) // compile time check
+	_ Paginator        = (*dialectTranslator)(nil) // compile time check
+)
@@ -0,0 +1,27 @@
+package driver
+
+import "testing"
+
+func TestQuoteIdentifier(t *testing.T) {
+	tests := []struct {
+		driver   Driver
+		name     string
+		expected string
+	}{
+		{MySQLDriver{}, "events_2024_01", "`events_2024_01`"},
+		{MySQLDriver{}, "a`b", "`a``b`"},
+		{SQLiteDriver{}, "events", `"events"`},
+		{PostgresDriver{}, `a"b`, `"a""b"`},
+		{OracleDriver{}, "events", `"events"`},
+		{SQLServerDriver{}, "a]b", "[a]]b]"},
+	}
+	for _, tt := range tests {
+		quoted, ok := QuoteIdentifier(tt.driver.Translator(), tt.name)
+		if !ok || quoted != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.driver, tt.expected, quoted)
+		}
+	}
+	if _, ok := QuoteIdentifier(TranslateFunc(func(string) string { return "?" }), "events"); ok {
+		t.Error("expected TranslateFunc not to quote identifiers")
+	}
+}
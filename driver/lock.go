@@ -0,0 +1,83 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"errors"
+	"fmt"
+)
+
+// LockMode is the mode of the row locking clause of a select statement.
+type LockMode string
+
+const (
+	// LockUpdate locks the selected rows for update, FOR UPDATE.
+	LockUpdate LockMode = "update"
+
+	// LockShare locks the selected rows against the updates of the others, FOR SHARE.
+	LockShare LockMode = "share"
+
+	// LockSkipLocked locks the selected rows for update and skips the rows which are
+	// already locked, FOR UPDATE SKIP LOCKED. It is useful for job queues.
+	LockSkipLocked LockMode = "skipLocked"
+)
+
+// Valid reports whether the lock mode is one of the defined modes.
+func (m LockMode) Valid() bool {
+	return m == LockUpdate || m == LockShare || m == LockSkipLocked
+}
+
+// ErrLockModeUnsupported is an error that is returned when the driver does not support the lock mode.
+var ErrLockModeUnsupported = errors.New("lock mode unsupported")
+
+// RowLocker is implemented by the translators of the dialects which support
+// the row locking clauses appended to the select statements.
+type RowLocker interface {
+	// LockClause returns the locking clause of the mode.
+	// It returns false if the mode is not supported.
+	LockClause(mode LockMode) (string, bool)
+}
+
+var (
+	// forUpdateLocks are the locking clauses of MySQL 8 and PostgreSQL.
+	forUpdateLocks = map[LockMode]string{
+		LockUpdate:     "FOR UPDATE",
+		LockShare:      "FOR SHARE",
+		LockSkipLocked: "FOR UPDATE SKIP LOCKED",
+	}
+
+	// oracleLocks are the locking clauses of Oracle, which has no shared row lock.
+	oracleLocks = map[LockMode]string{
+		LockUpdate:     "FOR UPDATE",
+		LockSkipLocked: "FOR UPDATE SKIP LOCKED",
+	}
+)
+
+// LockClause returns the locking clause of the mode with the given translator.
+// The translators which wrap another one can expose it by an Unwrap() Translator method.
+//
+// SQLite has no row locks, and SQL Server locks rows by the table hints like
+// WITH (UPDLOCK) which follow the table name instead of ending the statement,
+// so they return ErrLockModeUnsupported.
+func LockClause(translator Translator, mode LockMode) (string, error) {
+	if locker, ok := TranslatorAs[RowLocker](translator); ok {
+		if clause, ok := locker.LockClause(mode); ok {
+			return clause, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrLockModeUnsupported, mode)
+}
@@ -0,0 +1,42 @@
+package driver
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLockClause(t *testing.T) {
+	tests := []struct {
+		driver Driver
+		mode   LockMode
+		clause string
+	}{
+		{MySQLDriver{}, LockUpdate, "FOR UPDATE"},
+		{MySQLDriver{}, LockShare, "FOR SHARE"},
+		{MySQLDriver{}, LockSkipLocked, "FOR UPDATE SKIP LOCKED"},
+		{PostgresDriver{}, LockUpdate, "FOR UPDATE"},
+		{PostgresDriver{}, LockShare, "FOR SHARE"},
+		{PostgresDriver{}, LockSkipLocked, "FOR UPDATE SKIP LOCKED"},
+		{OracleDriver{}, LockUpdate, "FOR UPDATE"},
+		{OracleDriver{}, LockSkipLocked, "FOR UPDATE SKIP LOCKED"},
+		// unsupported
+		{OracleDriver{}, LockShare, ""},
+		{SQLiteDriver{}, LockUpdate, ""},
+		{SQLServerDriver{}, LockUpdate, ""},
+	}
+	for _, tt := range tests {
+		clause, err := LockClause(tt.driver.Translator(), tt.mode)
+		if tt.clause == "" {
+			if !errors.Is(err, ErrLockModeUnsupported) {
+				t.Errorf("%s %s: expected ErrLockModeUnsupported, got %v", tt.driver, tt.mode, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if clause != tt.clause {
+			t.Errorf("%s %s: expected %s, got %s", tt.driver, tt.mode, tt.clause, clause)
+		}
+	}
+}
@@ -21,7 +21,18 @@ type MySQLDriver struct{}
 
 // Translator returns a translator of SQL.
 func (d MySQLDriver) Translator() Translator {
-	return TranslateFunc(func(matched string) string { return "?" })
+	return dialectTranslator{
+		TranslateFunc: func(matched string) string { return "?" },
+		open:          "`",
+		close:         "`",
+		locks:         forUpdateLocks,
+		paginator:     d,
+	}
+}
+
+// Paginate implements Paginator.
+func (d MySQLDriver) Paginate(translator Translator, limit, offset int64) (string, []any) {
+	return limitOffsetClause(translator, limit, offset)
 }
 
 func (d MySQLDriver) String() string {
@@ -0,0 +1,178 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// NamedArgsBinder is implemented by the translators which translate the parameters into
+// named placeholders, like @name, so that the args are passed as sql.NamedArg instead of
+// by position. The repeated parameters with an equal value share one named arg.
+type NamedArgsBinder interface {
+	// Bind returns the placeholder of the parameter with the given value.
+	// It reports reused if the parameter has been bound with an equal value,
+	// in which case no new arg is added for the placeholder.
+	Bind(name string, value any) (placeholder string, reused bool)
+
+	// NamedArgs names the args, which are in the order of the placeholders in the query.
+	// The placeholders stripped from the query, like the ones of the ORDER BY clause of
+	// a count query, have no args.
+	NamedArgs(query string, args []any) ([]any, error)
+}
+
+// namedBinding is a parameter bound by namedTranslator.Bind.
+type namedBinding struct {
+	placeholder string
+	value       any
+}
+
+// namedTranslator is a dialectTranslator which translates the parameters into named placeholders.
+// The names are derived from the parameters, user.name to user_name, and are made unique by a
+// numeric suffix, so that the parameters of a foreach node get one placeholder per item.
+type namedTranslator struct {
+	dialectTranslator
+	prefix string
+	// names is the names of the placeholders in order.
+	names []string
+	// used is the set of the names in use.
+	used map[string]struct{}
+	// bound is the last binding of the parameters by their names.
+	bound map[string]namedBinding
+}
+
+// newNamedTranslator returns a namedTranslator of the dialect whose placeholders start with prefix.
+func newNamedTranslator(dialect dialectTranslator, prefix string) *namedTranslator {
+	return &namedTranslator{
+		dialectTranslator: dialect,
+		prefix:            prefix,
+		used:              make(map[string]struct{}),
+		bound:             make(map[string]namedBinding),
+	}
+}
+
+// Translate implements Translator.
+// Every call returns a new placeholder, which is bound to the next arg.
+func (n *namedTranslator) Translate(matched string) string {
+	base := namedArgName(matched)
+	name := base
+	for i := 2; ; i++ {
+		if _, exists := n.used[name]; !exists {
+			break
+		}
+		name = base + "_" + strconv.Itoa(i)
+	}
+	n.used[name] = struct{}{}
+	n.names = append(n.names, name)
+	return n.prefix + name
+}
+
+// Bind implements NamedArgsBinder.
+func (n *namedTranslator) Bind(name string, value any) (string, bool) {
+	if binding, ok := n.bound[name]; ok && namedArgEqual(binding.value, value) {
+		return binding.placeholder, true
+	}
+	placeholder := n.Translate(name)
+	n.bound[name] = namedBinding{placeholder: placeholder, value: value}
+	return placeholder, false
+}
+
+// NamedArgs implements NamedArgsBinder.
+// It returns an error if the args don't match the placeholders, like the args added
+// to the query without a placeholder of the translator.
+func (n *namedTranslator) NamedArgs(query string, args []any) ([]any, error) {
+	names := n.names
+	if len(args) != len(names) {
+		names = n.placeholdersIn(query)
+	}
+	if len(args) != len(names) {
+		return nil, fmt.Errorf("driver: %d args for %d named placeholders", len(args), len(names))
+	}
+	named := make([]any, len(args))
+	for i, arg := range args {
+		named[i] = sql.Named(names[i], arg)
+	}
+	return named, nil
+}
+
+// placeholdersIn returns the names of the placeholders which remain in the query in order,
+// skipping the quoted strings.
+func (n *namedTranslator) placeholdersIn(query string) []string {
+	found := make(map[string]struct{})
+	for i := 0; i < len(query); i++ {
+		switch {
+		case query[i] == '\'':
+			for i++; i < len(query) && query[i] != '\''; i++ {
+			}
+		case strings.HasPrefix(query[i:], n.prefix):
+			start := i + len(n.prefix)
+			end := start
+			for end < len(query) && isNamedArgByte(query[end]) {
+				end++
+			}
+			found[query[start:end]] = struct{}{}
+			i = end - 1
+		}
+	}
+	names := make([]string, 0, len(n.names))
+	for _, name := range n.names {
+		if _, exists := found[name]; exists {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// namedArgName returns the name of the parameter which is valid in a placeholder,
+// the characters other than letters, digits and underscores are replaced by underscores.
+func namedArgName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x80 && isNamedArgByte(byte(r)) {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// isNamedArgByte reports whether the byte is valid in the name of a placeholder.
+func isNamedArgByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// namedArgEqual reports whether the values of a parameter are equal, so that they can share a named arg.
+// The values of the types which are not comparable are never equal.
+func namedArgEqual(a, b any) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	if ta != tb || !ta.Comparable() || ta.Kind() == reflect.Struct || ta.Kind() == reflect.Array {
+		return false
+	}
+	return a == b
+}
+
+// ensure namedTranslator implements NamedArgsBinder, IdentifierQuoter and RowLocker.
+var (
+	_ NamedArgsBinder  = (*namedTranslator)(nil) // compile time check
+	_ IdentifierQuoter = (*namedTranslator)(nil) // compile time check
+	_ RowLocker        = (*namedTranslator)(nil) // compile time check
+)
@@ -0,0 +1,55 @@
+package driver
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestSQLServerDriver_NamedArgs(t *testing.T) {
+	translator := SQLServerDriver{NamedArgs: true}.Translator()
+	binder, ok := TranslatorAs[NamedArgsBinder](translator)
+	if !ok {
+		t.Fatal("expected a NamedArgsBinder")
+	}
+	var placeholders []string
+	bind := func(name string, value any) {
+		placeholder, _ := binder.Bind(name, value)
+		placeholders = append(placeholders, placeholder)
+	}
+	bind("id", 1)
+	bind("user.name", "a")
+	bind("id", 1)
+	// the items of a foreach node share the parameter name with different values.
+	bind("item", 1)
+	bind("item", 2)
+	placeholders = append(placeholders, translator.Translate("limit"))
+	if want := []string{"@id", "@user_name", "@id", "@item", "@item_2", "@limit"}; !reflect.DeepEqual(placeholders, want) {
+		t.Errorf("unexpected placeholders: %v", placeholders)
+	}
+
+	args, err := binder.NamedArgs("", []any{1, "a", 1, 2, int64(10)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []any{sql.Named("id", 1), sql.Named("user_name", "a"), sql.Named("item", 1), sql.Named("item_2", 2), sql.Named("limit", int64(10))}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("unexpected args: %v", args)
+	}
+	if _, err = binder.NamedArgs("SELECT * FROM t WHERE id = @id", []any{1, 2}); err == nil {
+		t.Error("expected error for mismatched args")
+	}
+
+	// the args of the placeholders stripped from the query are dropped.
+	args, err = binder.NamedArgs("SELECT COUNT(*) FROM t WHERE id = @id AND name = @user_name AND item IN (@item, @item_2)", []any{1, "a", 1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(args, want[:4]) {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	if _, ok = TranslatorAs[NamedArgsBinder](SQLServerDriver{}.Translator()); ok {
+		t.Error("expected positional args by default")
+	}
+}
@@ -24,10 +24,28 @@ type OracleDriver struct{}
 // Translator is a function to translate a matched string.
 func (o OracleDriver) Translator() Translator {
 	var i int
-	return TranslateFunc(func(matched string) string {
-		i++
-		return ":" + strconv.Itoa(i)
-	})
+	return dialectTranslator{
+		TranslateFunc: func(matched string) string {
+			i++
+			return ":" + strconv.Itoa(i)
+		},
+		open:      `"`,
+		close:     `"`,
+		locks:     oracleLocks,
+		paginator: o,
+	}
+}
+
+// Paginate implements Paginator.
+// The OFFSET FETCH clause requires Oracle 12c or later.
+func (o OracleDriver) Paginate(translator Translator, limit, offset int64) (string, []any) {
+	return offsetFetchClause(translator, limit, offset)
+}
+
+// ExistsQuery implements ExistsQuerier.
+// Oracle requires the FROM clause, so the query selects from DUAL.
+func (o OracleDriver) ExistsQuery(query string) string {
+	return caseWhenExists(query) + " FROM DUAL"
 }
 
 func (o OracleDriver) String() string {
@@ -0,0 +1,59 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+// Paginator is implemented by the drivers which support pagination.
+// A new dialect only needs to implement Paginate to be paginated by juice.
+// The translators of the built-in drivers implement it as well, see PaginationClause.
+type Paginator interface {
+	// Paginate returns the clause which skips offset rows and returns at most limit rows,
+	// and the arguments of its placeholders in order.
+	// The placeholders are translated by the given translator, so that they are
+	// numbered after the placeholders of the statement.
+	Paginate(translator Translator, limit, offset int64) (clause string, args []any)
+}
+
+// PaginationClause returns the pagination clause of the dialect of the translator.
+// The translators which wrap another one can expose it by an Unwrap() Translator method.
+// It falls back to the "LIMIT ? OFFSET ?" clause if no translator implements Paginator.
+func PaginationClause(translator Translator, limit, offset int64) (string, []any) {
+	if paginator, ok := TranslatorAs[Paginator](translator); ok {
+		return paginator.Paginate(translator, limit, offset)
+	}
+	return limitOffsetClause(translator, limit, offset)
+}
+
+// limitOffsetClause returns the "LIMIT ? OFFSET ?" clause.
+func limitOffsetClause(translator Translator, limit, offset int64) (string, []any) {
+	clause := "LIMIT " + translator.Translate("limit") + " OFFSET " + translator.Translate("offset")
+	return clause, []any{limit, offset}
+}
+
+// offsetFetchClause returns the "OFFSET ? ROWS FETCH NEXT ? ROWS ONLY" clause of the SQL standard.
+func offsetFetchClause(translator Translator, limit, offset int64) (string, []any) {
+	clause := "OFFSET " + translator.Translate("offset") + " ROWS FETCH NEXT " + translator.Translate("limit") + " ROWS ONLY"
+	return clause, []any{offset, limit}
+}
+
+// ensure the built-in drivers implement Paginator.
+var (
+	_ Paginator = (*MySQLDriver)(nil)     // compile time check
+	_ Paginator = (*SQLiteDriver)(nil)    // compile time check
+	_ Paginator = (*PostgresDriver)(nil)  // compile time check
+	_ Paginator = (*OracleDriver)(nil)    // compile time check
+	_ Paginator = (*SQLServerDriver)(nil) // compile time check
+)
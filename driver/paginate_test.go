@@ -0,0 +1,54 @@
+package driver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPaginator(t *testing.T) {
+	tests := []struct {
+		driver Driver
+		clause string
+		args   []any
+	}{
+		{MySQLDriver{}, "LIMIT ? OFFSET ?", []any{int64(10), int64(20)}},
+		{SQLiteDriver{}, "LIMIT ? OFFSET ?", []any{int64(10), int64(20)}},
+		{PostgresDriver{}, "LIMIT $2 OFFSET $3", []any{int64(10), int64(20)}},
+		{OracleDriver{}, "OFFSET :2 ROWS FETCH NEXT :3 ROWS ONLY", []any{int64(20), int64(10)}},
+		{SQLServerDriver{}, "OFFSET @p2 ROWS FETCH NEXT @p3 ROWS ONLY", []any{int64(20), int64(10)}},
+	}
+	for _, tt := range tests {
+		translator := tt.driver.Translator()
+		// the placeholders are numbered after the ones of the statement.
+		translator.Translate("id")
+		clause, args := tt.driver.(Paginator).Paginate(translator, 10, 20)
+		if clause != tt.clause {
+			t.Errorf("%T: unexpected clause: %s", tt.driver, clause)
+		}
+		if !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%T: unexpected args: %v", tt.driver, args)
+		}
+
+		// the translator renders the same clause as its driver.
+		translator = tt.driver.Translator()
+		translator.Translate("id")
+		if clause, _ = PaginationClause(translator, 10, 20); clause != tt.clause {
+			t.Errorf("%T: unexpected translator clause: %s", tt.driver, clause)
+		}
+	}
+}
+
+func TestPaginationClause_Fallback(t *testing.T) {
+	translator := TranslateFunc(func(string) string { return "?" })
+	clause, args := PaginationClause(translator, 10, 20)
+	if clause != "LIMIT ? OFFSET ?" || !reflect.DeepEqual(args, []any{int64(10), int64(20)}) {
+		t.Errorf("unexpected clause: %s %v", clause, args)
+	}
+}
+
+func TestSQLServerDriver(t *testing.T) {
+	translator := SQLServerDriver{}.Translator()
+	if translator.Translate("foo") != "@p1" || translator.Translate("bar") != "@p2" {
+		t.Fatal("failed to translate")
+	}
+}
@@ -24,10 +24,26 @@ type PostgresDriver struct{}
 // Translator is a function to translate a matched string.
 func (d PostgresDriver) Translator() Translator {
 	var i int
-	return TranslateFunc(func(matched string) string {
-		i++
-		return "$" + strconv.Itoa(i)
-	})
+	return dialectTranslator{
+		TranslateFunc: func(matched string) string {
+			i++
+			return "$" + strconv.Itoa(i)
+		},
+		open:      `"`,
+		close:     `"`,
+		locks:     forUpdateLocks,
+		paginator: d,
+	}
+}
+
+// Paginate implements Paginator.
+func (d PostgresDriver) Paginate(translator Translator, limit, offset int64) (string, []any) {
+	return limitOffsetClause(translator, limit, offset)
+}
+
+// ReturningClause implements KeyReturner.
+func (d PostgresDriver) ReturningClause(column string) string {
+	return "RETURNING " + column
 }
 
 func (d PostgresDriver) String() string {
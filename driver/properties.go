@@ -0,0 +1,128 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// DataSourceBuilder is implemented by the drivers which accept structured connection
+// properties, like the TLS config name or the parse time option, in addition to the
+// data source name.
+type DataSourceBuilder interface {
+	// DataSource returns the data source name with the properties applied.
+	DataSource(dataSource string, properties map[string]string) (string, error)
+}
+
+// sortedKeys returns the keys of the properties in order, so that the data source is stable.
+func sortedKeys(properties map[string]string) []string {
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// withQueryProperties appends the properties as the query parameters of the data source,
+// which is the format of MySQL, SQLite, SQL Server, Oracle and the URL of PostgreSQL.
+func withQueryProperties(dataSource string, properties map[string]string) string {
+	if len(properties) == 0 {
+		return dataSource
+	}
+	var builder strings.Builder
+	builder.WriteString(dataSource)
+	separator := "?"
+	if strings.Contains(dataSource, "?") {
+		separator = "&"
+	}
+	for _, key := range sortedKeys(properties) {
+		builder.WriteString(separator)
+		builder.WriteString(url.QueryEscape(key))
+		builder.WriteString("=")
+		builder.WriteString(url.QueryEscape(properties[key]))
+		separator = "&"
+	}
+	return builder.String()
+}
+
+// withKeywordProperties appends the properties as the keyword/value pairs of the data source,
+// like host=localhost sslmode=verify-full, which is the other format of PostgreSQL.
+func withKeywordProperties(dataSource string, properties map[string]string) (string, error) {
+	var builder strings.Builder
+	builder.WriteString(strings.TrimSpace(dataSource))
+	for _, key := range sortedKeys(properties) {
+		if key == "" || strings.ContainsAny(key, " ='\\") {
+			return "", fmt.Errorf("invalid property name %q", key)
+		}
+		if builder.Len() > 0 {
+			builder.WriteString(" ")
+		}
+		value := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(properties[key])
+		builder.WriteString(key + "='" + value + "'")
+	}
+	return builder.String(), nil
+}
+
+// DataSource implements DataSourceBuilder.
+// The properties are appended as the parameters of the DSN, like tls=custom&parseTime=true.
+func (d MySQLDriver) DataSource(dataSource string, properties map[string]string) (string, error) {
+	return withQueryProperties(dataSource, properties), nil
+}
+
+// DataSource implements DataSourceBuilder.
+// The properties are appended as the query parameters of the file URI, like _busy_timeout=5000.
+func (d SQLiteDriver) DataSource(dataSource string, properties map[string]string) (string, error) {
+	return withQueryProperties(dataSource, properties), nil
+}
+
+// DataSource implements DataSourceBuilder.
+// The properties are appended as the query parameters of a URL data source,
+// or as the keyword/value pairs otherwise, like sslmode='verify-full'.
+func (d PostgresDriver) DataSource(dataSource string, properties map[string]string) (string, error) {
+	if strings.Contains(dataSource, "://") {
+		return withQueryProperties(dataSource, properties), nil
+	}
+	if len(properties) == 0 {
+		return dataSource, nil
+	}
+	return withKeywordProperties(dataSource, properties)
+}
+
+// DataSource implements DataSourceBuilder.
+// The properties are appended as the query parameters of the URL, like SSL=enable.
+func (o OracleDriver) DataSource(dataSource string, properties map[string]string) (string, error) {
+	return withQueryProperties(dataSource, properties), nil
+}
+
+// DataSource implements DataSourceBuilder.
+// The properties are appended as the query parameters of the URL, like encrypt=true.
+func (d SQLServerDriver) DataSource(dataSource string, properties map[string]string) (string, error) {
+	return withQueryProperties(dataSource, properties), nil
+}
+
+// ensure the built-in drivers implement DataSourceBuilder.
+var (
+	_ DataSourceBuilder = (*MySQLDriver)(nil)     // compile time check
+	_ DataSourceBuilder = (*SQLiteDriver)(nil)    // compile time check
+	_ DataSourceBuilder = (*PostgresDriver)(nil)  // compile time check
+	_ DataSourceBuilder = (*OracleDriver)(nil)    // compile time check
+	_ DataSourceBuilder = (*SQLServerDriver)(nil) // compile time check
+)
@@ -0,0 +1,37 @@
+package driver
+
+import "testing"
+
+func TestDataSourceBuilder(t *testing.T) {
+	properties := map[string]string{"tls": "custom", "parseTime": "true"}
+	tests := []struct {
+		driver     DataSourceBuilder
+		dataSource string
+		expected   string
+	}{
+		{MySQLDriver{}, "root:@tcp(localhost:3306)/db", "root:@tcp(localhost:3306)/db?parseTime=true&tls=custom"},
+		{MySQLDriver{}, "root:@tcp(localhost:3306)/db?charset=utf8mb4", "root:@tcp(localhost:3306)/db?charset=utf8mb4&parseTime=true&tls=custom"},
+		{PostgresDriver{}, "postgres://localhost/db", "postgres://localhost/db?parseTime=true&tls=custom"},
+		{PostgresDriver{}, "host=localhost dbname=db", "host=localhost dbname=db parseTime='true' tls='custom'"},
+	}
+	for _, tt := range tests {
+		dataSource, err := tt.driver.DataSource(tt.dataSource, properties)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dataSource != tt.expected {
+			t.Errorf("expected %s, got %s", tt.expected, dataSource)
+		}
+	}
+
+	dataSource, err := PostgresDriver{}.DataSource("host=localhost", map[string]string{"password": `it's\`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dataSource != `host=localhost password='it\'s\\'` {
+		t.Errorf("unexpected data source: %s", dataSource)
+	}
+	if _, err = (PostgresDriver{}).DataSource("host=localhost", map[string]string{"bad key": "x"}); err == nil {
+		t.Error("expected error for invalid property name")
+	}
+}
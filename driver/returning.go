@@ -0,0 +1,28 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+// KeyReturner is implemented by the drivers whose databases report the generated keys
+// of the inserted rows by a RETURNING clause, instead of sql.Result.LastInsertId.
+type KeyReturner interface {
+	// ReturningClause returns the clause appended to the insert statement,
+	// which returns the given column of every inserted row, in the insertion order.
+	ReturningClause(column string) string
+}
+
+// ensure PostgresDriver implements KeyReturner.
+var _ KeyReturner = (*PostgresDriver)(nil) // compile time check
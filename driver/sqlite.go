@@ -21,7 +21,17 @@ type SQLiteDriver struct{}
 
 // Translator returns a translator of SQL.
 func (d SQLiteDriver) Translator() Translator {
-	return TranslateFunc(func(matched string) string { return "?" })
+	return dialectTranslator{
+		TranslateFunc: func(matched string) string { return "?" },
+		open:          `"`,
+		close:         `"`,
+		paginator:     d,
+	}
+}
+
+// Paginate implements Paginator.
+func (d SQLiteDriver) Paginate(translator Translator, limit, offset int64) (string, []any) {
+	return limitOffsetClause(translator, limit, offset)
 }
 
 func (d SQLiteDriver) String() string {
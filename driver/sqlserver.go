@@ -0,0 +1,66 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import "strconv"
+
+// SQLServerDriver is a driver of SQL Server.
+type SQLServerDriver struct {
+	// NamedArgs makes the translator produce the placeholders named by the parameters,
+	// like @name, instead of @p1, and the args are passed as sql.NamedArg, see NamedArgsBinder.
+	// The repeated parameters share one named arg. Register the driver to enable it:
+	//
+	//	driver.Register("sqlserver", driver.SQLServerDriver{NamedArgs: true})
+	NamedArgs bool
+}
+
+// Translator is a function to translate a matched string.
+func (d SQLServerDriver) Translator() Translator {
+	dialect := dialectTranslator{
+		open:      "[",
+		close:     "]",
+		paginator: d,
+	}
+	if d.NamedArgs {
+		return newNamedTranslator(dialect, "@")
+	}
+	var i int
+	dialect.TranslateFunc = func(matched string) string {
+		i++
+		return "@p" + strconv.Itoa(i)
+	}
+	return dialect
+}
+
+// Paginate implements Paginator.
+// Note that SQL Server requires an ORDER BY clause before OFFSET.
+func (d SQLServerDriver) Paginate(translator Translator, limit, offset int64) (string, []any) {
+	return offsetFetchClause(translator, limit, offset)
+}
+
+// ExistsQuery implements ExistsQuerier.
+func (d SQLServerDriver) ExistsQuery(query string) string {
+	return caseWhenExists(query)
+}
+
+func (d SQLServerDriver) String() string {
+	return "sqlserver"
+}
+
+func init() {
+	Register("sqlserver", &SQLServerDriver{})
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/go-juicedev/juice/internal/reflectlite"
+)
+
+// ErrInvalidEnumValue indicates that a value which is not registered for its enum type is bound.
+var ErrInvalidEnumValue = errors.New("invalid enum value")
+
+// InvalidEnumValueError is returned when a parameter of a registered enum type
+// is bound with a value which is not registered.
+// It matches ErrInvalidEnumValue with errors.Is.
+type InvalidEnumValueError struct {
+	// Parameter is the name of the parameter.
+	Parameter string
+
+	// Type is the enum type.
+	Type reflect.Type
+
+	// Value is the unknown value.
+	Value string
+}
+
+func (e *InvalidEnumValueError) Error() string {
+	return fmt.Sprintf("%s: parameter %s has value %q which is not a registered %s", ErrInvalidEnumValue, e.Parameter, e.Value, e.Type)
+}
+
+// Is reports whether the target is ErrInvalidEnumValue.
+func (e *InvalidEnumValueError) Is(target error) bool { return target == ErrInvalidEnumValue }
+
+// enumRegistry holds the registered values of the enum types, keyed by the reflect.Type.
+var enumRegistry sync.Map // map[reflect.Type]map[string]struct{}
+
+// RegisterEnum registers the values of the enum type T and enables the validation of T.
+//
+//	type Role string
+//
+//	const (
+//	    RoleAdmin Role = "admin"
+//	    RoleUser  Role = "user"
+//	)
+//
+//	juice.RegisterEnum(RoleAdmin, RoleUser)
+//
+// Once T is registered, binding a parameter of type T, or *T, by a #{} placeholder fails with
+// an *InvalidEnumValueError if its value is not one of the registered values.
+// The types which are not registered are never validated.
+// Registering T again replaces its values.
+func RegisterEnum[T ~string](values ...T) {
+	allowed := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		allowed[string(value)] = struct{}{}
+	}
+	enumRegistry.Store(reflect.TypeFor[T](), allowed)
+}
+
+// UnregisterEnum removes the enum type T from the registry and disables its validation.
+func UnregisterEnum[T ~string]() {
+	enumRegistry.Delete(reflect.TypeFor[T]())
+}
+
+// validateEnum validates the value of the named parameter if its type is a registered enum type.
+// Nil pointers are not validated, since they are bound as NULL.
+func validateEnum(name string, value reflect.Value) error {
+	value = reflectlite.Unwrap(value)
+	if !value.IsValid() || value.Kind() != reflect.String {
+		return nil
+	}
+	allowed, ok := enumRegistry.Load(value.Type())
+	if !ok {
+		return nil
+	}
+	if _, ok = allowed.(map[string]struct{})[value.String()]; ok {
+		return nil
+	}
+	return &InvalidEnumValueError{Parameter: name, Type: value.Type(), Value: value.String()}
+}
@@ -0,0 +1,85 @@
+package juice
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-juicedev/juice/driver"
+	"github.com/go-juicedev/juice/eval"
+)
+
+type enumTestRole string
+
+const (
+	enumTestRoleAdmin enumTestRole = "admin"
+	enumTestRoleUser  enumTestRole = "user"
+)
+
+type enumTestStatus string
+
+func TestRegisterEnum(t *testing.T) {
+	RegisterEnum(enumTestRoleAdmin, enumTestRoleUser)
+	t.Cleanup(UnregisterEnum[enumTestRole])
+
+	node := NewTextNode("SELECT * FROM user WHERE role = #{role}")
+	translator := driver.MySQLDriver{}.Translator()
+
+	_, args, err := node.Accept(translator, eval.H{"role": enumTestRoleAdmin}.AsParam())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(args) != 1 || args[0] != enumTestRoleAdmin {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	_, _, err = node.Accept(translator, eval.H{"role": enumTestRole("amdin")}.AsParam())
+	if !errors.Is(err, ErrInvalidEnumValue) {
+		t.Fatalf("expected ErrInvalidEnumValue, got %v", err)
+	}
+	var enumErr *InvalidEnumValueError
+	if !errors.As(err, &enumErr) {
+		t.Fatal("expected InvalidEnumValueError")
+	}
+	if enumErr.Parameter != "role" || enumErr.Value != "amdin" {
+		t.Errorf("unexpected error: %v", enumErr)
+	}
+
+	// pointers are validated, and nil pointers are bound as NULL.
+	unknown := enumTestRole("guest")
+	if _, _, err = node.Accept(translator, eval.H{"role": &unknown}.AsParam()); !errors.Is(err, ErrInvalidEnumValue) {
+		t.Errorf("expected ErrInvalidEnumValue, got %v", err)
+	}
+	if _, _, err = node.Accept(translator, eval.H{"role": (*enumTestRole)(nil)}.AsParam()); err != nil {
+		t.Error(err)
+	}
+
+	// the types which are not registered are not validated.
+	if _, _, err = node.Accept(translator, eval.H{"role": enumTestStatus("anything")}.AsParam()); err != nil {
+		t.Error(err)
+	}
+	if _, _, err = node.Accept(translator, eval.H{"role": "anything"}.AsParam()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRegisterEnum_Foreach(t *testing.T) {
+	RegisterEnum(enumTestRoleAdmin, enumTestRoleUser)
+	t.Cleanup(UnregisterEnum[enumTestRole])
+
+	node := ForeachNode{
+		Collection: "roles",
+		Item:       "item",
+		Separator:  ", ",
+		Nodes:      []Node{NewTextNode("#{item}")},
+	}
+	translator := driver.MySQLDriver{}.Translator()
+
+	roles := []enumTestRole{enumTestRoleAdmin, enumTestRoleUser}
+	if _, _, err := node.Accept(translator, eval.H{"roles": roles}.AsParam()); err != nil {
+		t.Fatal(err)
+	}
+	roles = append(roles, "root")
+	if _, _, err := node.Accept(translator, eval.H{"roles": roles}.AsParam()); !errors.Is(err, ErrInvalidEnumValue) {
+		t.Errorf("expected ErrInvalidEnumValue, got %v", err)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"slices"
 	"time"
 
 	"github.com/go-juicedev/juice/driver"
@@ -46,6 +47,11 @@ type Environment struct {
 	// MaxIdleConnLifetime is a maximum lifetime of an idle connection.
 	MaxIdleConnLifetime int
 
+	// Properties are the driver specific options declared by the <property> elements,
+	// like the TLS config name. They are applied to the DataSource by the driver,
+	// see driver.DataSourceBuilder.
+	Properties map[string]string
+
 	// attrs is a map of attributes.
 	attrs map[string]string
 }
@@ -83,6 +89,7 @@ func ConnectFromEnv(env *Environment) (*sql.DB, error) {
 		driver.ConnectWithMaxIdleConnNum(env.MaxIdleConnNum),
 		driver.ConnectWithMaxConnLifetime(time.Duration(env.MaxConnLifetime)*time.Second),
 		driver.ConnectWithMaxIdleConnLifetime(time.Duration(env.MaxIdleConnLifetime)*time.Second),
+		driver.ConnectWithProperties(env.Properties),
 	)
 }
 
@@ -126,6 +133,16 @@ func (e *environments) Use(id string) (*Environment, error) {
 	return env, nil
 }
 
+// IDs returns the identifiers of the environments in sorted order.
+func (e *environments) IDs() []string {
+	ids := make([]string, 0, len(e.envs))
+	for id := range e.envs {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
 // EnvValueProvider defines a environment value provider.
 type EnvValueProvider interface {
 	Get(key string) (string, error)
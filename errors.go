@@ -41,6 +41,18 @@ var (
 	// ErrPointerRequired is an error that is returned when the destination is not a pointer.
 	ErrPointerRequired = errors.New("destination must be a pointer")
 
+	// ErrNoChooseBranchMatched is an error that is returned when a required choose node
+	// has no matched when node and no otherwise node.
+	ErrNoChooseBranchMatched = errors.New("choose: no when matched and no otherwise")
+
+	// ErrPartitionNotAllowed is an error that is returned when the table name of
+	// a partition node does not match its pattern.
+	ErrPartitionNotAllowed = errors.New("partition not allowed")
+
+	// ErrIdentifierQuotingUnsupported is an error that is returned when the driver
+	// can not quote identifiers.
+	ErrIdentifierQuotingUnsupported = errors.New("identifier quoting unsupported")
+
 	// errSliceOrArrayRequired is an error that is returned when the destination is not a slice or array.
 	errSliceOrArrayRequired = errors.New("type must be a slice or array")
 )
@@ -78,6 +90,17 @@ func (e *nodeAttributeConflictError) Error() string {
 	return fmt.Sprintf("node %s has conflicting attribute %s", e.nodeName, e.attrName)
 }
 
+// valuesColumnSourceError is an error that is returned when the source parameter of a values column can not be resolved.
+type valuesColumnSourceError struct {
+	column string
+	param  string
+}
+
+// Error returns the error message.
+func (e *valuesColumnSourceError) Error() string {
+	return fmt.Sprintf("values column %s: source parameter %s not found", e.column, e.param)
+}
+
 // unreachable is a function that is used to mark unreachable code.
 // nolint:deadcode,unused
 func unreachable() error {
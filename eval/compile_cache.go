@@ -0,0 +1,111 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eval
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultCompileCacheSize is the default max number of the expressions cached by the default compiler.
+const DefaultCompileCacheSize = 1024
+
+// expressionCache is a least recently used cache of the compiled expressions by their sources.
+// The expressions are compiled outside the lock, so a compiler which compiles another expression,
+// or a slow compilation, never blocks the cache.
+type expressionCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List // of *expressionCacheEntry, the most recently used first
+	items map[string]*list.Element
+}
+
+// expressionCacheEntry is an entry of the expressionCache.
+type expressionCacheEntry struct {
+	expr       string
+	expression Expression
+}
+
+// newExpressionCache returns an expressionCache which holds at most size expressions.
+func newExpressionCache(size int) *expressionCache {
+	return &expressionCache{size: size, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+// get returns the cached expression of the source.
+func (c *expressionCache) get(expr string) (Expression, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.items[expr]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*expressionCacheEntry).expression, true
+}
+
+// put caches the expression of the source, evicting the least recently used ones over the size.
+func (c *expressionCache) put(expr string, expression Expression) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size <= 0 {
+		return
+	}
+	if element, ok := c.items[expr]; ok {
+		c.order.MoveToFront(element)
+		return
+	}
+	c.items[expr] = c.order.PushFront(&expressionCacheEntry{expr: expr, expression: expression})
+	c.evict()
+}
+
+// resize changes the size of the cache, the size less than or equal to 0 disables it.
+func (c *expressionCache) resize(size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.size = size
+	c.evict()
+}
+
+// evict removes the least recently used expressions over the size, the lock must be held.
+func (c *expressionCache) evict() {
+	for c.order.Len() > max(c.size, 0) {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*expressionCacheEntry).expr)
+	}
+}
+
+// len returns the number of the cached expressions.
+func (c *expressionCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// defaultExpressionCache is the cache of the default compiler, see SetCompileCacheSize.
+var defaultExpressionCache = newExpressionCache(DefaultCompileCacheSize)
+
+// SetCompileCacheSize sets the max number of the compiled expressions cached by the default
+// compiler, which are shared by Compile and Eval, so that compiling an expression again, like
+// by the tools which evaluate the same expressions repeatedly, skips parsing it.
+// The cache is keyed by the source of the expressions, as the pretreatment of the default
+// compiler always rewrites a source the same way. The least recently used expressions are
+// evicted over the size. A size less than or equal to 0 disables the cache and drops the
+// cached expressions, for the memory sensitive programs.
+func SetCompileCacheSize(size int) {
+	defaultExpressionCache.resize(size)
+}
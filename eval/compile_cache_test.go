@@ -0,0 +1,81 @@
+package eval
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestCompileCache(t *testing.T) {
+	compiler := &goExprCompiler{pretreatment: exprPretreatmentChain, cache: newExpressionCache(2)}
+	first, err := compiler.Compile("a > 1 and b < 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := compiler.Compile("a > 1 and b < 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Error("expected the cached expression")
+	}
+	if _, err = compiler.Compile("a >"); err == nil {
+		t.Error("expected syntax error")
+	}
+	if compiler.cache.len() != 1 {
+		t.Errorf("expected the errors not cached, got %d", compiler.cache.len())
+	}
+
+	// the least recently used expression is evicted.
+	for _, expr := range []string{"b == 1", "a > 1 and b < 2", "c == 1"} {
+		if _, err = compiler.Compile(expr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, ok := compiler.cache.get("b == 1"); ok {
+		t.Error("expected b == 1 evicted")
+	}
+	if _, ok := compiler.cache.get("a > 1 and b < 2"); !ok {
+		t.Error("expected a > 1 and b < 2 cached")
+	}
+
+	// the cache is disabled by a size of 0.
+	compiler.cache.resize(0)
+	if compiler.cache.len() != 0 {
+		t.Errorf("expected empty cache, got %d", compiler.cache.len())
+	}
+	third, err := compiler.Compile("a > 1 and b < 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third == first || compiler.cache.len() != 0 {
+		t.Error("expected the expression compiled again")
+	}
+}
+
+func TestCompileCacheConcurrent(t *testing.T) {
+	compiler := &goExprCompiler{pretreatment: exprPretreatmentChain, cache: newExpressionCache(8)}
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				expression, err := compiler.Compile("a == " + strconv.Itoa((i+j)%16))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				value, err := expression.Execute(H{"a": (i + j) % 16}.AsParam())
+				if err != nil || !value.Bool() {
+					t.Errorf("unexpected result: %v %v", value, err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	if compiler.cache.len() > 8 {
+		t.Errorf("expected at most 8 expressions, got %d", compiler.cache.len())
+	}
+}
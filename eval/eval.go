@@ -22,7 +22,9 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"math"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -96,9 +98,201 @@ var (
 	exprPretreatmentChain ExprPretreatment = ExprPretreatmentChain{
 		andReplacePretreatment,
 		orReplacePretreatment,
+		comparisonKeywordPretreatment,
+		defaultCallPretreatment,
+		inOperatorPretreatment,
 	}
 )
 
+// defaultCallRegexp matches the calls of the default function which are not selectors.
+var defaultCallRegexp = regexp.MustCompile(`(^|[^\w.])default(\s*\()`)
+
+// defaultFuncName is the name which the default function is registered with,
+// since default is a keyword of go and can not be parsed as a function name.
+const defaultFuncName = "__default"
+
+// exprDefaultCallPretreatment is an expression pretreatment that renames the default function calls.
+type exprDefaultCallPretreatment struct{}
+
+// PretreatmentExpr implements the ExprPretreatment interface.
+func (exprDefaultCallPretreatment) PretreatmentExpr(expr string) (string, error) {
+	if !strings.Contains(expr, "default") {
+		return expr, nil
+	}
+	return replaceOutsideStringLiterals(expr, func(expr string) string {
+		return defaultCallRegexp.ReplaceAllString(expr, "${1}"+defaultFuncName+"${2}")
+	}), nil
+}
+
+// defaultCallPretreatment is an expression pretreatment that replaces "default(" with "__default(".
+var defaultCallPretreatment ExprPretreatment = exprDefaultCallPretreatment{}
+
+var (
+	// inListRegexp matches the in operator with a parenthesized list, like status in (1, 2, 3).
+	inListRegexp = regexp.MustCompile(`(^|[^\w.])([A-Za-z_][\w.]*)\s+in\s*\(([^()]*)\)`)
+
+	// inCollectionRegexp matches the in operator with a collection, like role in roles.
+	inCollectionRegexp = regexp.MustCompile(`(^|[^\w.])([A-Za-z_][\w.]*)\s+in\s+([A-Za-z_][\w.]*)`)
+)
+
+// exprInOperatorPretreatment is an expression pretreatment that rewrites the in operator
+// into the calls of the in function, which go can parse:
+//
+//	status in (1, 2, 3)  ->  in(status, 1, 2, 3)
+//	role in roles        ->  in(role, roles)
+//
+// The left operand must be an identifier or a selector, like user.role. The string literals
+// are kept as they are, like name == "a in b".
+type exprInOperatorPretreatment struct{}
+
+// PretreatmentExpr implements the ExprPretreatment interface.
+func (exprInOperatorPretreatment) PretreatmentExpr(expr string) (string, error) {
+	if !strings.Contains(expr, " in") {
+		return expr, nil
+	}
+	return replaceOutsideStringLiterals(expr, func(expr string) string {
+		expr = inListRegexp.ReplaceAllString(expr, "${1}in(${2}, ${3})")
+		return inCollectionRegexp.ReplaceAllString(expr, "${1}in(${2}, ${3})")
+	}), nil
+}
+
+// inOperatorPretreatment is an expression pretreatment that rewrites "x in (a, b)" into "in(x, a, b)".
+var inOperatorPretreatment ExprPretreatment = exprInOperatorPretreatment{}
+
+// comparisonKeywords maps the comparison keywords to the go operators.
+var comparisonKeywords = map[string]string{
+	"gt":  ">",
+	"gte": ">=",
+	"lt":  "<",
+	"lte": "<=",
+	"eq":  "==",
+	"neq": "!=",
+}
+
+// exprComparisonKeywordPretreatment is an expression pretreatment that rewrites the comparison
+// keywords into the go operators, so that the test attributes don't need the xml entities:
+//
+//	age gt 18    ->  age > 18
+//	age lte 60   ->  age <= 60
+//	name neq ""  ->  name != ""
+//
+// A keyword is rewritten only when it is a whole word outside the string literals, and it
+// follows an operand, which is an identifier, a literal or a closing bracket. So the keywords
+// used as identifiers, like eq != nil or user.gt, or as function names, like lt(a, b), are kept.
+type exprComparisonKeywordPretreatment struct{}
+
+// PretreatmentExpr implements the ExprPretreatment interface.
+func (exprComparisonKeywordPretreatment) PretreatmentExpr(expr string) (string, error) {
+	// gte, lte and neq contain gt, lt and eq.
+	if !strings.Contains(expr, "gt") && !strings.Contains(expr, "lt") && !strings.Contains(expr, "eq") {
+		return expr, nil
+	}
+	var builder strings.Builder
+	builder.Grow(len(expr))
+	// last is the last non-space byte written, which tells whether an operand precedes a word.
+	var last byte
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case isExprQuote(c):
+			end := exprStringLiteralEnd(expr, i)
+			builder.WriteString(expr[i:end])
+			last, i = c, end
+		case isExprWordByte(c):
+			end := i + 1
+			for end < len(expr) && isExprWordByte(expr[end]) {
+				end++
+			}
+			word := expr[i:end]
+			if operator, ok := comparisonKeywords[word]; ok && isExprOperandEnd(last) {
+				builder.WriteString(operator)
+				last = operator[len(operator)-1]
+			} else {
+				builder.WriteString(word)
+				last = word[len(word)-1]
+			}
+			i = end
+		default:
+			builder.WriteByte(c)
+			if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+				last = c
+			}
+			i++
+		}
+	}
+	return builder.String(), nil
+}
+
+// isExprQuote reports whether the byte starts a string, rune or raw string literal.
+func isExprQuote(c byte) bool {
+	return c == '"' || c == '\'' || c == '`'
+}
+
+// exprStringLiteralEnd returns the end of the string literal which starts at i.
+// The unterminated literal ends at the end of the expression.
+func exprStringLiteralEnd(expr string, i int) int {
+	quote := expr[i]
+	end := i + 1
+	for end < len(expr) && expr[end] != quote {
+		if expr[end] == '\\' && quote != '`' {
+			end++
+		}
+		end++
+	}
+	return min(end+1, len(expr))
+}
+
+// replaceOutsideStringLiterals applies the replace function to the expression with its string
+// literals emptied, then restores the literals in order. So the rewrites by the regular expressions
+// don't touch the contents of the literals, as long as they keep the literals in order.
+func replaceOutsideStringLiterals(expr string, replace func(expr string) string) string {
+	var literals []string
+	var builder strings.Builder
+	builder.Grow(len(expr))
+	for i := 0; i < len(expr); {
+		if !isExprQuote(expr[i]) {
+			builder.WriteByte(expr[i])
+			i++
+			continue
+		}
+		end := exprStringLiteralEnd(expr, i)
+		literals = append(literals, expr[i:end])
+		builder.WriteByte(expr[i])
+		builder.WriteByte(expr[i])
+		i = end
+	}
+	if len(literals) == 0 {
+		return replace(expr)
+	}
+	replaced := replace(builder.String())
+	builder.Reset()
+	builder.Grow(len(replaced) + len(expr))
+	for i := 0; i < len(replaced); {
+		if !isExprQuote(replaced[i]) || len(literals) == 0 {
+			builder.WriteByte(replaced[i])
+			i++
+			continue
+		}
+		builder.WriteString(literals[0])
+		literals = literals[1:]
+		i += 2
+	}
+	return builder.String()
+}
+
+// isExprWordByte reports whether the byte is a part of an identifier or a number.
+func isExprWordByte(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c >= 0x80
+}
+
+// isExprOperandEnd reports whether the byte ends an operand.
+func isExprOperandEnd(c byte) bool {
+	return isExprWordByte(c) || c == ')' || c == ']' || c == '"' || c == '\'' || c == '`'
+}
+
+// comparisonKeywordPretreatment is an expression pretreatment that replaces "gt", "lte", "neq", etc. with the go operators.
+var comparisonKeywordPretreatment ExprPretreatment = exprComparisonKeywordPretreatment{}
+
 // ExprCompiler is an evaluator of the expression.
 type ExprCompiler interface {
 	// Compile compiles the expression and returns the expression.
@@ -118,10 +312,29 @@ type Expression interface {
 // goExprCompiler is an evaluator of the expression who uses the go/ast package.
 type goExprCompiler struct {
 	pretreatment ExprPretreatment
+
+	// cache is the cache of the compiled expressions, nil if they are not cached.
+	cache *expressionCache
 }
 
 // Compile compiles the expression and returns the expression.
 func (e *goExprCompiler) Compile(expr string) (Expression, error) {
+	if e.cache == nil {
+		return e.compile(expr)
+	}
+	if expression, ok := e.cache.get(expr); ok {
+		return expression, nil
+	}
+	expression, err := e.compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	e.cache.put(expr, expression)
+	return expression, nil
+}
+
+// compile compiles the expression without the cache.
+func (e *goExprCompiler) compile(expr string) (Expression, error) {
 	// pretreatment the expression first.
 	expr_, err := e.pretreatment.PretreatmentExpr(expr)
 	if err != nil {
@@ -160,7 +373,7 @@ func (e *goExpression) Execute(params Parameter) (Value, error) {
 var (
 	// DefaultExprCompiler is the default evaluator.
 	// Reset it to change the default behavior.
-	DefaultExprCompiler ExprCompiler = &goExprCompiler{pretreatment: exprPretreatmentChain}
+	DefaultExprCompiler ExprCompiler = &goExprCompiler{pretreatment: exprPretreatmentChain, cache: defaultExpressionCache}
 
 	// ErrNilExprCompiler returns when DefaultExprCompiler is changed to nil.
 	ErrNilExprCompiler = errors.New("expression compiler is nil")
@@ -185,6 +398,10 @@ func Eval(expr string, params Parameter) (Value, error) {
 	return expression.Execute(params)
 }
 
+// ErrUnsupportedExpression is returned when an expression is not supported by the evaluator,
+// like the composite literals, the function literals or the unsupported operators.
+var ErrUnsupportedExpression = errors.New("unsupported expression")
+
 func eval(exp ast.Expr, params Parameter) (reflect.Value, error) {
 	switch exp := exp.(type) {
 	case *ast.BinaryExpr:
@@ -208,7 +425,7 @@ func eval(exp ast.Expr, params Parameter) (reflect.Value, error) {
 	case *ast.SliceExpr:
 		return evalSliceExpr(exp, params)
 	default:
-		return reflect.Value{}, fmt.Errorf("unsupported expression: %T", exp)
+		return reflect.Value{}, fmt.Errorf("%w: %T", ErrUnsupportedExpression, exp)
 	}
 }
 
@@ -255,29 +472,53 @@ func evalSliceExpr(exp *ast.SliceExpr, params Parameter) (reflect.Value, error)
 	return value.Slice3(low, high, sliceMax), nil
 }
 
-var errUnsupportedUnaryExpr = errors.New("unsupported unary expression")
+var errUnsupportedUnaryExpr = fmt.Errorf("%w: unary expression", ErrUnsupportedExpression)
 
 func evalUnaryExpr(exp *ast.UnaryExpr, params Parameter) (reflect.Value, error) {
 	value, err := eval(exp.X, params)
 	if err != nil {
 		return reflect.Value{}, err
 	}
+	if exp.Op == token.NOT {
+		return reflect.ValueOf(!reflectlite.Unwrap(value).Bool()), nil
+	}
+	if exp.Op == token.MUL {
+		return reflect.ValueOf(value.Pointer()), nil
+	}
+	value = reflectlite.Unwrap(value)
 	switch exp.Op {
 	case token.SUB:
-		return reflect.ValueOf(-value.Int()), nil
+		switch {
+		case value.CanInt():
+			return reflect.ValueOf(-value.Int()), nil
+		case value.CanUint():
+			// the negation of an unsigned integer is signed.
+			if value.Uint() > math.MaxInt64 {
+				return reflect.Value{}, fmt.Errorf("%w: -%d overflows int64", errUnsupportedUnaryExpr, value.Uint())
+			}
+			return reflect.ValueOf(-int64(value.Uint())), nil
+		case value.CanFloat():
+			return reflect.ValueOf(-value.Float()), nil
+		}
 	case token.ADD:
-		return reflect.ValueOf(+value.Int()), nil
-	case token.NOT:
-		return reflect.ValueOf(!value.Bool()), nil
+		switch {
+		case value.CanInt():
+			return reflect.ValueOf(value.Int()), nil
+		case value.CanUint():
+			return reflect.ValueOf(value.Uint()), nil
+		case value.CanFloat():
+			return reflect.ValueOf(value.Float()), nil
+		}
 	case token.XOR:
-		return reflect.ValueOf(^value.Int()), nil
-	case token.AND:
-		return reflect.ValueOf(^value.Int()), nil
-	case token.MUL:
-		return reflect.ValueOf(value.Pointer()), nil
-	default:
-		return reflect.Value{}, errUnsupportedUnaryExpr
+		// bitwise complement, only for integers, in the width of the operand.
+		switch {
+		case value.CanInt():
+			return reflect.ValueOf(^value.Int()).Convert(value.Type()), nil
+		case value.CanUint():
+			return reflect.ValueOf(^value.Uint()).Convert(value.Type()), nil
+		}
 	}
+	return reflect.Value{}, errUnsupportedUnaryExpr
 }
 
 var ErrIndexOutOfRange = errors.New("index out of range")
@@ -296,7 +537,11 @@ func evalIndexExpr(exp *ast.IndexExpr, params Parameter) (reflect.Value, error)
 	switch value.Kind() {
 	case reflect.Array, reflect.Slice, reflect.String:
 		i := index.Int()
-		if i >= int64(value.Len()) {
+		// the negative index counts from the end, like a[-1] for the last element.
+		if i < 0 {
+			i += int64(value.Len())
+		}
+		if i < 0 || i >= int64(value.Len()) {
 			return reflect.Value{}, ErrIndexOutOfRange
 		}
 		return value.Index(int(i)), nil
@@ -332,11 +577,14 @@ func evalCallExpr(exp *ast.CallExpr, params Parameter) (reflect.Value, error) {
 		return reflect.Value{}, errors.New("unsupported call expression")
 	}
 	fnType := fn.Type()
-	if numIn := fnType.NumIn(); numIn != len(exp.Args) {
+	numIn := fnType.NumIn()
+	if fnType.IsVariadic() {
+		if len(exp.Args) < numIn-1 {
+			return reflect.Value{}, fmt.Errorf("invalid number of arguments: expected at least %d, got %d", numIn-1, len(exp.Args))
+		}
+	} else if numIn != len(exp.Args) {
 		return reflect.Value{}, fmt.Errorf("invalid number of arguments: expected %d, got %d", numIn, len(exp.Args))
 	}
-	// TODO dot dot dot support
-	// ...type
 	if fnType.NumOut() != 2 {
 		return reflect.Value{}, fmt.Errorf("invalid number of return values: expected 2, got %d", fn.Type().NumOut())
 	}
@@ -349,7 +597,20 @@ func evalCallExpr(exp *ast.CallExpr, params Parameter) (reflect.Value, error) {
 		}
 		value = reflectlite.Unwrap(value)
 		// type conversion for function arguments
-		in := fnType.In(i)
+		var in reflect.Type
+		if fnType.IsVariadic() && i >= numIn-1 {
+			// the arguments of the variadic parameter are passed one by one.
+			in = fnType.In(numIn - 1).Elem()
+		} else {
+			in = fnType.In(i)
+		}
+		// nil or nil pointer, use the zero value of the nilable argument type
+		if !value.IsValid() {
+			if !reflectlite.NilAble(reflect.Zero(in)) {
+				return reflect.Value{}, fmt.Errorf("cannot use nil as %s", in)
+			}
+			value = reflect.Zero(in)
+		}
 		if in.Kind() != value.Kind() {
 			if !value.CanConvert(in) {
 				return reflect.Value{}, fmt.Errorf("cannot convert %s to %s", value.Type().Name(), in.Name())
@@ -468,7 +729,7 @@ func evalIdent(exp *ast.Ident, params Parameter) (reflect.Value, error) {
 	return value, nil
 }
 
-var errUnsupportedBasicLiteral = errors.New("unsupported basic literal")
+var errUnsupportedBasicLiteral = fmt.Errorf("%w: basic literal", ErrUnsupportedExpression)
 
 func evalBasicLit(exp *ast.BasicLit) (reflect.Value, error) {
 	switch exp.Kind {
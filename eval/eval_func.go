@@ -21,6 +21,9 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+
+	"github.com/go-juicedev/juice/eval/expr"
+	"github.com/go-juicedev/juice/internal/reflectlite"
 )
 
 // return the length of the string or array
@@ -112,6 +115,69 @@ func contains(s any, v any) (bool, error) {
 	return false, errors.New("contains: invalid argument type")
 }
 
+// stringOf returns the string of the value whose kind is string, including the named string types.
+func stringOf(v any) (string, bool) {
+	rv := reflectlite.Unwrap(reflect.ValueOf(v))
+	if rv.Kind() != reflect.String {
+		return "", false
+	}
+	return rv.String(), true
+}
+
+// startsWith returns true if the string begins with the prefix.
+func startsWith(s, prefix any) (bool, error) {
+	str, ok := stringOf(s)
+	if !ok {
+		return false, errors.New("startsWith: invalid argument type")
+	}
+	value, ok := stringOf(prefix)
+	if !ok {
+		return false, errors.New("startsWith: invalid prefix type")
+	}
+	return strings.HasPrefix(str, value), nil
+}
+
+// endsWith returns true if the string ends with the suffix.
+func endsWith(s, suffix any) (bool, error) {
+	str, ok := stringOf(s)
+	if !ok {
+		return false, errors.New("endsWith: invalid argument type")
+	}
+	value, ok := stringOf(suffix)
+	if !ok {
+		return false, errors.New("endsWith: invalid suffix type")
+	}
+	return strings.HasSuffix(str, value), nil
+}
+
+// indexOf returns the index of the first instance of the value in the string or array,
+// or -1 if the value is not present. The index of a string is in bytes.
+func indexOf(s any, v any) (int, error) {
+	if str, ok := stringOf(s); ok {
+		value, ok := stringOf(v)
+		if !ok {
+			value = fmt.Sprintf("%v", v)
+		}
+		return strings.Index(str, value), nil
+	}
+	rv := reflectlite.Unwrap(reflect.ValueOf(s))
+	switch rv.Kind() {
+	case reflect.Array, reflect.Slice:
+		value := reflectlite.Unwrap(reflect.ValueOf(v))
+		if !value.IsValid() {
+			return -1, nil
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if equal(value, rv.Index(i)) {
+				return i, nil
+			}
+		}
+		return -1, nil
+	default:
+	}
+	return -1, errors.New("indexOf: invalid argument type")
+}
+
 // slice returns a slice of the array or string.
 func slice(v any, start, count int) ([]any, error) {
 	rv := reflect.Indirect(reflect.ValueOf(v))
@@ -182,6 +248,86 @@ func splitAfter(text, sep string) ([]string, error) {
 	return strings.SplitAfter(text, sep), nil
 }
 
+// defaultValue returns the value if it is not nil and not empty, otherwise the fallback.
+// The value is empty when it is a string, slice, array or map of zero length.
+// Zero numbers and false are not empty, since they are usually meaningful values,
+// for example default(age, 18) returns 0 when the age is 0.
+func defaultValue(value, fallback any) (any, error) {
+	rv := reflectlite.Unwrap(reflect.ValueOf(value))
+	if !rv.IsValid() {
+		return fallback, nil
+	}
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		if rv.Len() == 0 {
+			return fallback, nil
+		}
+	default:
+	}
+	return value, nil
+}
+
+// in reports whether the value equals one of the candidates, it stops at the first match.
+// The candidates can be given as the arguments, in(status, 1, 2, 3), as a collection,
+// in(role, roles), whose keys are the candidates if it is a map, or as a comma separated
+// list, in(status, "1,2,3"), whose items are compared with the value formatted by fmt.Sprint.
+// A nil value is never in the candidates.
+func in(value any, candidates ...any) (bool, error) {
+	rv := reflectlite.Unwrap(reflect.ValueOf(value))
+	if !rv.IsValid() {
+		return false, nil
+	}
+	if len(candidates) == 1 {
+		collection := reflectlite.Unwrap(reflect.ValueOf(candidates[0]))
+		switch collection.Kind() {
+		case reflect.String:
+			formatted := fmt.Sprint(rv.Interface())
+			for _, item := range strings.Split(collection.String(), ",") {
+				if strings.TrimSpace(item) == formatted {
+					return true, nil
+				}
+			}
+			return false, nil
+		case reflect.Array, reflect.Slice:
+			for i := 0; i < collection.Len(); i++ {
+				if equal(rv, collection.Index(i)) {
+					return true, nil
+				}
+			}
+			return false, nil
+		case reflect.Map:
+			for _, key := range collection.MapKeys() {
+				if equal(rv, key) {
+					return true, nil
+				}
+			}
+			return false, nil
+		default:
+		}
+	}
+	for _, candidate := range candidates {
+		if equal(rv, reflect.ValueOf(candidate)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// equal reports whether the values are equal as the == operator does,
+// the values which can not be compared are not equal.
+func equal(x, y reflect.Value) bool {
+	y = reflectlite.Unwrap(y)
+	if !y.IsValid() {
+		return false
+	}
+	result, err := expr.GenericOperator{OperatorExpr: expr.Eq}.Operate(x, y)
+	if err != nil {
+		return false
+	}
+	result = reflectlite.Unwrap(result)
+	return result.Kind() == reflect.Bool && result.Bool()
+}
+
 // RegisterEvalFunc registers a function for eval.
 // The function must be a function with one return value.
 // And Allowed to overwrite the built-in function.
@@ -235,6 +381,9 @@ func init() {
 	MustRegisterEvalFunc("substr", strSub)
 	MustRegisterEvalFunc("join", strJoin)
 	MustRegisterEvalFunc("contains", contains)
+	MustRegisterEvalFunc("startsWith", startsWith)
+	MustRegisterEvalFunc("endsWith", endsWith)
+	MustRegisterEvalFunc("indexOf", indexOf)
 	MustRegisterEvalFunc("slice", slice)
 	MustRegisterEvalFunc("lower", lower)
 	MustRegisterEvalFunc("upper", upper)
@@ -246,4 +395,6 @@ func init() {
 	MustRegisterEvalFunc("split", split)
 	MustRegisterEvalFunc("splitN", splitN)
 	MustRegisterEvalFunc("splitAfter", splitAfter)
+	MustRegisterEvalFunc(defaultFuncName, defaultValue)
+	MustRegisterEvalFunc("in", in)
 }
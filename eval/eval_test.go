@@ -1,8 +1,11 @@
 package eval
 
 import (
+	"errors"
+	"fmt"
 	"go/parser"
 	"reflect"
+	"sync"
 	"testing"
 )
 
@@ -234,6 +237,101 @@ func TestContains(t *testing.T) {
 	}
 }
 
+func TestStartsWith(t *testing.T) {
+	type Name string
+	param := H{
+		"a": "eatmoreapple",
+		"b": Name("eatmoreapple"),
+		"c": 1,
+	}
+	result, err := testEval(`startsWith(a, "eat")`, param)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !result.Bool() {
+		t.Error("eval error")
+		return
+	}
+
+	result, err = testEval(`startsWith(b, "apple")`, param)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if result.Bool() {
+		t.Error("eval error")
+		return
+	}
+
+	if _, err = testEval(`startsWith(c, "1")`, param); err == nil {
+		t.Error("expected error")
+		return
+	}
+}
+
+func TestEndsWith(t *testing.T) {
+	param := H{
+		"a": "eatmoreapple",
+	}
+	result, err := testEval(`endsWith(a, "apple")`, param)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !result.Bool() {
+		t.Error("eval error")
+		return
+	}
+
+	result, err = testEval(`endsWith(a, "eat") || endsWith("", "a")`, param)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if result.Bool() {
+		t.Error("eval error")
+		return
+	}
+
+	if _, err = testEval(`endsWith(a, 1)`, param); err == nil {
+		t.Error("expected error")
+		return
+	}
+}
+
+func TestIndexOf(t *testing.T) {
+	param := H{
+		"a": []string{"eat", "more", "apple"},
+		"b": []int64{1, 2, 3},
+		"c": map[string]int{"a": 1},
+	}
+	result, err := testEval(`indexOf("eatmoreapple", "more")`, param)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if result.Int() != 3 {
+		t.Error("eval error")
+		return
+	}
+
+	result, err = testEval(`indexOf(a, "apple") == 2 && indexOf(b, 2) == 1 && indexOf(b, 4) == -1`, param)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !result.Bool() {
+		t.Error("eval error")
+		return
+	}
+
+	if _, err = testEval(`indexOf(c, "a")`, param); err == nil {
+		t.Error("expected error")
+		return
+	}
+}
+
 func TestSlice(t *testing.T) {
 	param := H{
 		"a": []string{"eat", "more", "apple"},
@@ -310,6 +408,49 @@ func TestUnaryExpr2(t *testing.T) {
 	}
 }
 
+func TestUnaryExprKinds(t *testing.T) {
+	param := H{
+		"price":  2.5,
+		"amount": uint(7),
+		"max":    uint64(1 << 63),
+		"flags":  int8(5),
+		"mask":   uint8(3),
+		"name":   "eatmoreapple",
+	}
+	tests := []struct {
+		expr     string
+		expected any
+	}{
+		{`-3.14`, -3.14},
+		{`-price`, -2.5},
+		{`+price`, 2.5},
+		{`-amount`, int64(-7)},
+		{`+amount`, uint64(7)},
+		{`^amount`, ^uint(7)},
+		{`^flags`, int8(^5)},
+		{`^mask`, uint8(252)},
+		{`-flags`, int64(-5)},
+		{`-price > -3.0`, true},
+		{`-amount < -6`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			result, err := testEval(tt.expr, param)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Interface() != tt.expected {
+				t.Errorf("expected %v (%T), got %v (%T)", tt.expected, tt.expected, result.Interface(), result.Interface())
+			}
+		})
+	}
+	for _, expr := range []string{`-name`, `^price`, `-max`} {
+		if _, err := testEval(expr, param); err == nil {
+			t.Errorf("expected error for %s", expr)
+		}
+	}
+}
+
 func TestIndexExprSlice(t *testing.T) {
 	param := H{
 		"a": []string{"eat", "more", "apple"},
@@ -334,6 +475,33 @@ func TestIndexExprSlice(t *testing.T) {
 	}
 }
 
+func TestIndexExprNegative(t *testing.T) {
+	param := H{
+		"a": []string{"eat", "more", "apple"},
+		"s": "juice",
+	}
+	for expr, expected := range map[string]string{
+		`a[-1]`:       "apple",
+		`a[-len(a)]`:  "eat",
+		`a[len(a)-1]`: "apple",
+		`s[-1]`:       "101",
+	} {
+		result, err := testEval(expr, param)
+		if err != nil {
+			t.Errorf("%s: %v", expr, err)
+			continue
+		}
+		if got := fmt.Sprint(result.Interface()); got != expected {
+			t.Errorf("%s: expected %s, got %s", expr, expected, got)
+		}
+	}
+	for _, expr := range []string{`a[-4]`, `a[-len(a)-1]`, `a[3]`, `s[-6]`} {
+		if _, err := testEval(expr, param); !errors.Is(err, ErrIndexOutOfRange) {
+			t.Errorf("%s: expected ErrIndexOutOfRange, got %v", expr, err)
+		}
+	}
+}
+
 func TestIndexExprMap(t *testing.T) {
 	param := H{
 		"a": map[string]string{
@@ -924,3 +1092,241 @@ func TestStaticExprOptimizer(t *testing.T) {
 		})
 	}
 }
+
+func TestParameter_StructField(t *testing.T) {
+	type Inner struct {
+		Name string `param:"name" column:"name,json"`
+	}
+	type Outer struct {
+		Inner Inner `param:"inner"`
+		ID    int
+	}
+	param := ParamGroup{NewGenericParam(H{"outer": &Outer{}}, ""), NewGenericParam(H{"id": 1}, "")}
+	field, ok := param.StructField("outer.inner.name")
+	if !ok || field.Tag.Get("column") != "name,json" {
+		t.Errorf("unexpected field: %v %v", field, ok)
+	}
+	if field, ok = param.StructField("outer.ID"); !ok || field.Name != "ID" {
+		t.Errorf("unexpected field: %v %v", field, ok)
+	}
+	// map values are not struct fields
+	if _, ok = param.StructField("id"); ok {
+		t.Error("expected no struct field")
+	}
+	if _, ok = param.StructField("outer.missing"); ok {
+		t.Error("expected no struct field")
+	}
+}
+
+func TestSyncMapParameter(t *testing.T) {
+	var param SyncMapParameter
+	param.Store("region", "eu")
+	param.Store("limits", H{"max": 10})
+	param.Store(1, "ignored")
+
+	if value, ok := param.Get("region"); !ok || value.Interface() != "eu" {
+		t.Errorf("unexpected region: %v %v", value, ok)
+	}
+	if value, ok := param.Get("limits.max"); !ok || value.Interface() != 10 {
+		t.Errorf("unexpected limits.max: %v %v", value, ok)
+	}
+	if _, ok := param.Get("missing"); ok {
+		t.Error("expected missing key")
+	}
+	if keys := param.Keys(); !reflect.DeepEqual(keys, []string{"limits", "region"}) {
+		t.Errorf("unexpected keys: %v", keys)
+	}
+	result, err := Eval(`region == "eu" && limits.max > 5`, &param)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Bool() {
+		t.Error("expected true")
+	}
+
+	// reads while other goroutines write, run with -race.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				param.Store("region", fmt.Sprintf("eu-%d", j))
+				param.Delete("temp")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if _, ok := param.Get("region"); !ok {
+					t.Error("expected region")
+				}
+				_ = param.Keys()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDefaultFunc(t *testing.T) {
+	var nilName *string
+	name := "eatmoreapple"
+	params := H{
+		"nilName":  nilName,
+		"name":     &name,
+		"empty":    "",
+		"zero":     0,
+		"float":    0.0,
+		"disabled": false,
+		"ids":      []int{},
+		"nothing":  nil,
+		"label":    "default(x)",
+	}.AsParam()
+	tests := []struct {
+		expr string
+		want any
+	}{
+		{`default(nilName, "anonymous")`, "anonymous"},
+		{`default(nothing, "anonymous")`, "anonymous"},
+		{`default(name, "anonymous")`, "eatmoreapple"},
+		{`default(empty, "anonymous")`, "anonymous"},
+		{`default(ids, 1)`, 1},
+		// zero numbers and false do not trigger the fallback.
+		{`default(zero, 18)`, 0},
+		{`default(float, 1.5)`, 0.0},
+		{`default(disabled, true)`, false},
+		{`default (empty, "anonymous") == "anonymous"`, true},
+		// the string literals are not rewritten.
+		{`default(empty, "default(x)")`, "default(x)"},
+		{`label == "default(x)"`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			result, err := Eval(tt.expr, params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := result.Interface(); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestInFunc(t *testing.T) {
+	params := H{
+		"status":  2,
+		"id":      int64(7),
+		"role":    "admin",
+		"roles":   []string{"admin", "owner"},
+		"ids":     []int64{1, 7},
+		"allowed": map[string]bool{"owner": true},
+		"nothing": nil,
+		"user":    H{"role": "guest"},
+		"label":   "a in b",
+	}.AsParam()
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`in(status, 1, 2, 3)`, true},
+		{`in(status, 4, 5)`, false},
+		{`status in (1, 2, 3)`, true},
+		{`status in (4)`, false},
+		{`id in ids`, true},
+		{`id in (1, 2)`, false},
+		{`in(role, "viewer", "admin")`, true},
+		{`role in roles`, true},
+		{`role in allowed`, false},
+		{`user.role in ("guest", "viewer")`, true},
+		{`in(status, "1, 2,3")`, true},
+		{`in(role, "viewer,owner")`, false},
+		{`nothing in (1, 2)`, false},
+		{`in(nothing, nothing)`, false},
+		{`status in (1, 2) and role in roles`, true},
+		{`status in ()`, false},
+		{`!(status in (1, 2))`, false},
+		// the string literals are not rewritten.
+		{`label == "a in b"`, true},
+		{`label in ("a in b", "c in (d)")`, true},
+		{`role == "status in (1, 2)"`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			result, err := Eval(tt.expr, params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Bool() != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, result.Bool())
+			}
+		})
+	}
+}
+
+func TestComparisonKeywords(t *testing.T) {
+	params := H{
+		"age":    20,
+		"name":   "gt lt",
+		"eq":     1,
+		"ltv":    5,
+		"user":   H{"gt": 3},
+		"status": 2,
+	}.AsParam()
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`age gt 18`, true},
+		{`age gte 20`, true},
+		{`age lt 18`, false},
+		{`age lte 20`, true},
+		{`age eq 20`, true},
+		{`age neq 20`, false},
+		{`age gt 18 and age lt 30`, true},
+		{`(age) gt 30 or status eq 2`, true},
+		{`name eq "gt lt"`, true},
+		{`name neq "x"`, true},
+		{`eq eq 1`, true},
+		{`eq != 0`, true},
+		{`ltv gt 4`, true},
+		{`user.gt gte 3`, true},
+		{`len(name) lte 5`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			result, err := Eval(tt.expr, params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Bool() != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, result.Bool())
+			}
+		})
+	}
+}
+
+func TestComparisonKeywordPretreatment(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{`age gt 18`, `age > 18`},
+		{`age gte 18`, `age >= 18`},
+		{`a.lte lte b.gt`, `a.lte <= b.gt`},
+		{`name eq "a eq b"`, `name == "a eq b"`},
+		{"name eq `gt`", "name == `gt`"},
+		{`gteq != nil`, `gteq != nil`},
+		{`lt(a, b)`, `lt(a, b)`},
+		{`x neq 'y'`, `x != 'y'`},
+	}
+	for _, tt := range tests {
+		got, err := comparisonKeywordPretreatment.PretreatmentExpr(tt.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.expr, tt.want, got)
+		}
+	}
+}
@@ -20,8 +20,10 @@ import (
 	"context"
 	"os"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"unicode"
 
 	"github.com/go-juicedev/juice/internal/reflectlite"
@@ -67,6 +69,16 @@ type Parameter interface {
 	Get(name string) (reflect.Value, bool)
 }
 
+// StructFieldParameter is a Parameter which reports the struct fields that the names are resolved from.
+// It lets the callers inspect the tags of the fields, for example the json option of the column tag.
+type StructFieldParameter interface {
+	Parameter
+
+	// StructField returns the struct field which the named parameter is resolved from.
+	// It returns false if the named parameter is not a field of a struct.
+	StructField(name string) (reflect.StructField, bool)
+}
+
 // NoOPParameter is a no-op parameter.
 // Its does nothing when calling the Get method.
 type NoOPParameter struct{}
@@ -98,6 +110,27 @@ func (g ParamGroup) Get(name string) (reflect.Value, bool) {
 	return reflect.Value{}, false
 }
 
+// make sure that ParamGroup implements StructFieldParameter.
+var _ StructFieldParameter = (ParamGroup)(nil)
+
+// StructField implements StructFieldParameter.
+// The field is reported by the parameter which resolves the name, the same one as Get.
+func (g ParamGroup) StructField(name string) (reflect.StructField, bool) {
+	for _, p := range g {
+		if p == nil {
+			continue
+		}
+		if _, ok := p.Get(name); !ok {
+			continue
+		}
+		if sp, ok := p.(StructFieldParameter); ok {
+			return sp.StructField(name)
+		}
+		return reflect.StructField{}, false
+	}
+	return reflect.StructField{}, false
+}
+
 // make sure that structParameter implements Parameter.
 var _ Parameter = (*structParameter)(nil)
 
@@ -222,12 +255,63 @@ func (g *genericParameter) Get(name string) (value reflect.Value, exists bool) {
 	return value, exists
 }
 
+// StructField implements StructFieldParameter.
+func (g *genericParameter) StructField(name string) (reflect.StructField, bool) {
+	owner := g.Value
+	if index := strings.LastIndexByte(name, '.'); index >= 0 {
+		var exists bool
+		if owner, exists = g.Get(name[:index]); !exists {
+			return reflect.StructField{}, false
+		}
+		name = name[index+1:]
+	}
+	owner = reflectlite.Unwrap(owner)
+	if owner.Kind() != reflect.Struct || len(name) == 0 {
+		return reflect.StructField{}, false
+	}
+	// the same lookup as structParameter.Get
+	if unicode.IsUpper(rune(name[0])) {
+		return owner.Type().FieldByName(name)
+	}
+	indexes, ok := reflectlite.TypeFrom(owner.Type()).GetFieldIndexesFromTag(defaultParamKey, name)
+	if !ok {
+		return reflect.StructField{}, false
+	}
+	return owner.Type().FieldByIndex(indexes), true
+}
+
+// make sure that genericParameter implements StructFieldParameter.
+var _ StructFieldParameter = (*genericParameter)(nil)
+
+// ValueParameter is a Parameter which resolves the names against a value, the same way
+// as the parameters created by NewGenericParam, but the value is never wrapped as a map.
+// It can be reset to another value, so that one parameter is reused for many values,
+// like the elements of a collection.
+// The zero value resolves no names.
+type ValueParameter struct {
+	genericParameter
+}
+
+// Reset sets the value which the names are resolved against.
+func (v *ValueParameter) Reset(value reflect.Value) {
+	v.Value = value
+	clear(v.cache)
+}
+
+// make sure that ValueParameter implements StructFieldParameter.
+var _ StructFieldParameter = (*ValueParameter)(nil)
+
 // NewGenericParam creates a generic parameter.
+// if the value is already a Parameter, it will be returned directly.
 // if the value is not a map, struct, slice or array, then wrap it as a map.
 func NewGenericParam(v any, wrapKey string) Parameter {
 	if v == nil {
 		return noOPParameter
 	}
+	// if the value is already a Parameter, use it directly
+	if param, ok := v.(Parameter); ok {
+		return param
+	}
 	value := reflect.ValueOf(v)
 
 	tp := reflectlite.IndirectType(value.Type())
@@ -250,6 +334,53 @@ func NewParameter(v Param) Parameter {
 	return NewGenericParam(v, "")
 }
 
+// make sure that SyncMapParameter implements Parameter.
+var _ Parameter = (*SyncMapParameter)(nil)
+
+// SyncMapParameter is a Parameter backed by a sync.Map, for the long-lived param sources
+// which are shared across goroutines, for example a cache of config values used by ${}.
+// The zero value is ready to use, and it must not be copied after first use.
+//
+// Get and Keys are safe to call while other goroutines Store and Delete the entries,
+// so a query being built sees every key either before or after a concurrent write,
+// but not a torn one. Only the entries are guarded: the stored values must not be
+// mutated in place, replace them with Store instead.
+// Only the entries with string keys are visible as parameters.
+type SyncMapParameter struct {
+	sync.Map
+}
+
+// Get implements Parameter.
+// The first segment of the name is loaded from the map, the rest is resolved
+// against the loaded value, the same as a map parameter.
+func (p *SyncMapParameter) Get(name string) (reflect.Value, bool) {
+	key, rest, nested := strings.Cut(name, ".")
+	entry, ok := p.Load(key)
+	if !ok {
+		return reflect.Value{}, false
+	}
+	if !nested {
+		// keep the interface type, the same as the values of H.
+		return reflect.ValueOf(&entry).Elem(), true
+	}
+	// a fresh generic parameter, its cache must not be shared between goroutines.
+	return (&genericParameter{Value: reflect.ValueOf(entry)}).get(rest)
+}
+
+// Keys returns the string keys of the map, sorted.
+// It is a snapshot, the keys stored or deleted concurrently may be missed.
+func (p *SyncMapParameter) Keys() []string {
+	var keys []string
+	p.Range(func(key, _ any) bool {
+		if name, ok := key.(string); ok {
+			keys = append(keys, name)
+		}
+		return true
+	})
+	slices.Sort(keys)
+	return keys
+}
+
 // H is a shortcut for map[string]any
 type H map[string]any
 
@@ -23,6 +23,7 @@ import (
 	"encoding/gob"
 	"encoding/hex"
 	"errors"
+	"strings"
 
 	"github.com/go-juicedev/juice/cache"
 	"github.com/go-juicedev/juice/driver"
@@ -98,6 +99,20 @@ type sqlRowsExecutor struct {
 	statement        Statement
 	statementHandler StatementHandler
 	driver           driver.Driver
+	// columnNames is the mapping of the untagged fields of the results, see Engine.SetColumnNameMapper.
+	columnNames *columnNameMapping
+	// times is the time policy of the results, see Engine.UseUTCTime and Engine.SetDefaultTimeLocation.
+	times *timePolicy
+}
+
+// columnNameMapping returns the mapping of the untagged fields of the results, nil if not set.
+func (e *sqlRowsExecutor) columnNameMapping() *columnNameMapping {
+	return e.columnNames
+}
+
+// timePolicy returns the time policy of the results, nil if not set.
+func (e *sqlRowsExecutor) timePolicy() *timePolicy {
+	return e.times
 }
 
 // QueryContext executes the query and returns the result.
@@ -107,7 +122,15 @@ func (e *sqlRowsExecutor) QueryContext(ctx context.Context, param Param) (*sql.R
 
 // ExecContext executes the query and returns the result.
 func (e *sqlRowsExecutor) ExecContext(ctx context.Context, param Param) (sql.Result, error) {
-	return e.statementHandler.ExecContext(ctx, e.Statement(), param)
+	result, err := e.statementHandler.ExecContext(ctx, e.Statement(), param)
+	if err != nil {
+		return result, err
+	}
+	// the update statements with the version attribute fail if no row is affected.
+	if err = checkVersionLock(e.Statement(), result); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 // Statement returns the xmlSQLStatement.
@@ -116,6 +139,14 @@ func (e *sqlRowsExecutor) Statement() Statement { return e.statement }
 // Driver returns the driver of the sqlRowsExecutor.
 func (e *sqlRowsExecutor) Driver() driver.Driver { return e.driver }
 
+// build builds the statement the same way as the statement handler executes it.
+func (e *sqlRowsExecutor) build(ctx context.Context, param Param) (string, []any, error) {
+	if handler, ok := e.statementHandler.(statementBuildHandler); ok {
+		return handler.buildStatement(ctx, e.Statement(), param)
+	}
+	return e.Statement().Build(e.Driver().Translator(), param)
+}
+
 // ensure that the sqlRowsExecutor implements the SQLRowsExecutor interface.
 var _ SQLRowsExecutor = (*sqlRowsExecutor)(nil)
 
@@ -140,10 +171,35 @@ var CacheKeyFunc cacheKeyFunc = func(stmt Statement, query string, args []any) (
 	return hex.EncodeToString(writer.Sum(nil)), nil
 }
 
+// statementNamespace returns the mapper namespace of the statement, which scopes its cached results.
+func statementNamespace(statement Statement) string {
+	name := statement.Name()
+	if index := strings.LastIndex(name, "."); index > 0 {
+		return name[:index]
+	}
+	return name
+}
+
+// shouldFlushCache reports whether the cached results of the namespace of the statement
+// should be flushed after the statement is executed.
+// It is controlled by the flushCache attribute of the statement, which is true for
+// the statements which write and false for the ones which read by default.
+func shouldFlushCache(statement Statement) bool {
+	switch statement.Attribute("flushCache") {
+	case "true":
+		return true
+	case "false":
+		return false
+	default:
+		return statement.Action().ForWrite()
+	}
+}
+
 // GenericExecutor is a generic sqlRowsExecutor.
 type GenericExecutor[T any] struct {
 	SQLRowsExecutor
 	cache cache.ScopeCache
+	hooks ResultHookGroup[T]
 }
 
 // QueryContext executes the query and returns the scanner.
@@ -154,12 +210,15 @@ func (e *GenericExecutor[T]) QueryContext(ctx context.Context, p Param) (result
 	}
 	statement := e.Statement()
 	// build the query and args
-	query, args, err := statement.Build(e.Driver().Translator(), p)
+	query, args, err := e.build(ctx, p)
 	if err != nil {
 		return
 	}
-	// if cache enabled
-	cacheEnabled := e.cache != nil && statement.Attribute("useCache") != "false"
+	flushCache := e.cache != nil && shouldFlushCache(statement)
+
+	// if cache enabled, only the results of the statements which read are cached.
+	// The results of the statements which flush the cache are not cached, since they are flushed right away.
+	cacheEnabled := e.cache != nil && !flushCache && statement.Action().ForRead() && statement.Attribute("useCache") != "false"
 
 	// cacheKey is the key which is used to get the result and put the result to the scopeCache.
 	var cacheKey string
@@ -183,6 +242,8 @@ func (e *GenericExecutor[T]) QueryContext(ctx context.Context, p Param) (result
 		if err != nil {
 			return
 		}
+		// prefix the key with the namespace, so that the results of the namespace can be flushed together.
+		cacheKey = cache.NamespaceKey(statementNamespace(statement), cacheKey)
 
 		// try to get the result from the scopeCache
 		if err = e.cache.Get(ctx, cacheKey, &result); err == nil {
@@ -202,11 +263,29 @@ func (e *GenericExecutor[T]) QueryContext(ctx context.Context, p Param) (result
 	// if cache enabled
 	if cacheEnabled {
 		// put the result to the scopeCache
-		err = e.cache.Set(ctx, cacheKey, result)
+		if err = e.cache.Set(ctx, cacheKey, result); err != nil {
+			return
+		}
+	}
+	// the statement may write data, like INSERT ... RETURNING, so flush the cache as ExecContext does.
+	if flushCache {
+		err = e.cache.FlushNamespace(ctx, statementNamespace(statement))
 	}
 	return
 }
 
+// build builds the query and args of the statement.
+// The query and args are the same as the executed ones when the
+// SQLRowsExecutor is able to build them, so that the cache key is accurate.
+func (e *GenericExecutor[T]) build(ctx context.Context, p Param) (string, []any, error) {
+	if exe, ok := e.SQLRowsExecutor.(interface {
+		build(ctx context.Context, param Param) (string, []any, error)
+	}); ok {
+		return exe.build(ctx, p)
+	}
+	return e.Statement().Build(e.Driver().Translator(), p)
+}
+
 func (e *GenericExecutor[T]) queryContext(param Param) GenericQueryHandler[T] {
 	return func(ctx context.Context, query string, args ...any) (result T, err error) {
 		statement := e.Statement()
@@ -218,16 +297,43 @@ func (e *GenericExecutor[T]) queryContext(param Param) GenericQueryHandler[T] {
 			if !errors.Is(err, ErrResultMapNotSet) {
 				return result, err
 			}
+			if partialResults(ctx) {
+				retMap = partialResultMap[T]()
+			}
+			if maxRows := statementMaxRows(statement); maxRows > 0 {
+				retMap = maxRowsResultMap[T](retMap, maxRows)
+			}
+			if exe, ok := e.SQLRowsExecutor.(interface{ columnNameMapping() *columnNameMapping }); ok {
+				retMap = columnNameResultMap[T](retMap, exe.columnNameMapping())
+			}
+			if exe, ok := e.SQLRowsExecutor.(interface{ timePolicy() *timePolicy }); ok {
+				retMap = timeResultMap[T](retMap, exe.timePolicy())
+			}
 		}
 
 		// try to query the database.
+		ctx, release := withRowsReleaser(ctx)
+		defer release()
 		rows, err := e.SQLRowsExecutor.QueryContext(ctx, param)
 		if err != nil {
 			return result, err
 		}
 		defer func() { _ = rows.Close() }()
 
-		return BindWithResultMap[T](rows, retMap)
+		result, err = BindWithResultMap[T](rows, retMap)
+		if err != nil {
+			// no rows is not an error when the policy is NoRowsZero.
+			if errors.Is(err, sql.ErrNoRows) && noRowsPolicy(ctx, statement) == NoRowsZero {
+				var zero T
+				return zero, nil
+			}
+			return result, err
+		}
+		// post-process the mapped result before returning.
+		if err = e.hooks.Invoke(ctx, statement, &result); err != nil {
+			return result, err
+		}
+		return result, nil
 	}
 }
 
@@ -241,12 +347,28 @@ func (e *GenericExecutor[_]) ExecContext(ctx context.Context, p Param) (result s
 	if err != nil {
 		return
 	}
-	// if flushCache is true, flush the cache.
-	if flushCache := e.cache != nil && e.Statement().Attribute("flushCache") != "false"; flushCache {
-		err = e.cache.Flush(ctx)
+	// if flushCache is true, flush the cached results of the namespace of the statement.
+	if e.cache != nil && shouldFlushCache(e.Statement()) {
+		err = e.cache.FlushNamespace(ctx, statementNamespace(e.Statement()))
 	}
 	return
 }
 
+// AffectedContext executes the query with the executor and returns the number of rows affected.
+// It folds the errors of ExecContext and RowsAffected into one, for example:
+//
+//	affected, err := juice.AffectedContext(ctx, juice.NewGenericManager[any](engine).Object(UpdateUser), user)
+//
+// Any Executor or SQLRowsExecutor can be used.
+func AffectedContext(ctx context.Context, executor interface {
+	ExecContext(ctx context.Context, param Param) (sql.Result, error)
+}, param Param) (int64, error) {
+	result, err := executor.ExecContext(ctx, param)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 // ensure GenericExecutor implements Executor.
 var _ Executor[any] = (*GenericExecutor[any])(nil)
@@ -0,0 +1,274 @@
+package juice
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-juicedev/juice/cache"
+	juicedriver "github.com/go-juicedev/juice/driver"
+)
+
+func TestAffectedContext(t *testing.T) {
+	db, state := newFakeDB(t, fakeResultSet{})
+	state.execResult = driver.RowsAffected(3)
+	executor := &GenericExecutor[any]{
+		SQLRowsExecutor: newFakeExecutor(db, newFakeStatement("UPDATE user SET status = 1")),
+	}
+	affected, err := AffectedContext(context.Background(), executor, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if affected != 3 {
+		t.Errorf("unexpected rows affected: %d", affected)
+	}
+
+	errExec := errors.New("exec failed")
+	if _, err = AffectedContext(context.Background(), inValidExecutor(errExec), nil); !errors.Is(err, errExec) {
+		t.Errorf("expected exec error, got %v", err)
+	}
+}
+
+func TestGenericExecutor_QueryContext_WriteAction(t *testing.T) {
+	db, state := newFakeDB(t, fakeResultSet{
+		columns: []string{"id", "name"},
+		rows:    [][]driver.Value{{int64(1), "a"}},
+	})
+	statement := parseTestStatement(t, Select, `<select id="ArchiveUsers" action="delete">
+		WITH archived AS (DELETE FROM user RETURNING id, name) SELECT id, name FROM archived
+	</select>`)
+	scopeCache := cache.InMemoryScopeCache()
+	ctx := context.Background()
+	stale := cache.NamespaceKey("main.UserMapper", "stale")
+	if err := scopeCache.Set(ctx, stale, 1); err != nil {
+		t.Fatal(err)
+	}
+	executor := &GenericExecutor[[]hookUser]{
+		SQLRowsExecutor: newFakeExecutor(db, statement),
+		cache:           scopeCache,
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := executor.QueryContext(ctx, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the result of a write statement is never cached.
+	if len(state.executions) != 2 {
+		t.Errorf("expected 2 executions, got %d", len(state.executions))
+	}
+	if err := scopeCache.Get(ctx, stale, new(int)); !errors.Is(err, cache.ErrCacheNotFound) {
+		t.Errorf("expected the cache to be flushed, got %v", err)
+	}
+}
+
+// namespaceRecordingCache is a scopeCache which records the namespaces of the flushes.
+type namespaceRecordingCache struct {
+	cache.ScopeCache
+	flushed []string
+}
+
+func (n *namespaceRecordingCache) FlushNamespace(ctx context.Context, namespace string) error {
+	n.flushed = append(n.flushed, namespace)
+	return n.ScopeCache.FlushNamespace(ctx, namespace)
+}
+
+// failingSetCache is a scopeCache whose Set always fails.
+type failingSetCache struct {
+	namespaceRecordingCache
+	err error
+}
+
+func (f *failingSetCache) Set(context.Context, string, any) error { return f.err }
+
+func TestGenericExecutor_QueryContext_CacheSetError(t *testing.T) {
+	db, _ := newFakeDB(t, fakeResultSet{
+		columns: []string{"id", "name"},
+		rows:    [][]driver.Value{{int64(1), "a"}},
+	})
+	errSet := errors.New("set failed")
+	scopeCache := &failingSetCache{namespaceRecordingCache: namespaceRecordingCache{ScopeCache: cache.InMemoryScopeCache()}, err: errSet}
+	executor := &GenericExecutor[[]hookUser]{
+		SQLRowsExecutor: newFakeExecutor(db, parseTestStatement(t, Select, `<select id="QueryUsers">SELECT id, name FROM user</select>`)),
+		cache:           scopeCache,
+	}
+	if _, err := executor.QueryContext(context.Background(), nil); !errors.Is(err, errSet) {
+		t.Errorf("expected the cache error, got %v", err)
+	}
+}
+
+func TestGenericExecutor_QueryContext_FlushCache(t *testing.T) {
+	db, state := newFakeDB(t, fakeResultSet{
+		columns: []string{"id", "name"},
+		rows:    [][]driver.Value{{int64(1), "a"}},
+	})
+	scopeCache := &failingSetCache{namespaceRecordingCache: namespaceRecordingCache{ScopeCache: cache.InMemoryScopeCache()}, err: errors.New("unexpected set")}
+	executor := &GenericExecutor[[]hookUser]{
+		SQLRowsExecutor: newFakeExecutor(db, parseTestStatement(t, Select, `<select id="QueryUsers" flushCache="true">SELECT id, name FROM user</select>`)),
+		cache:           scopeCache,
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := executor.QueryContext(context.Background(), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the result is not cached, and the namespace is flushed by every query.
+	if len(state.executions) != 2 {
+		t.Errorf("expected 2 executions, got %d", len(state.executions))
+	}
+	if len(scopeCache.flushed) != 2 || scopeCache.flushed[0] != "main.UserMapper" {
+		t.Errorf("expected the namespace main.UserMapper to be flushed, got %v", scopeCache.flushed)
+	}
+}
+
+func TestGenericExecutor_ExecContext_FlushNamespace(t *testing.T) {
+	db, _ := newFakeDB(t, fakeResultSet{})
+	statement := newFakeStatement("UPDATE user SET status = 1")
+	statement.action = Update
+	scopeCache := &namespaceRecordingCache{ScopeCache: cache.InMemoryScopeCache()}
+	// the generic manager uses the scopeCache of the CacheManager.
+	manager := NewGenericManager[any](NewCacheManager(nil, scopeCache)).(*genericManager[any])
+	if manager.cache != scopeCache {
+		t.Fatal("expected the generic manager to use the scopeCache of the CacheManager")
+	}
+	executor := &GenericExecutor[any]{
+		SQLRowsExecutor: newFakeExecutor(db, statement),
+		cache:           manager.cache,
+	}
+	if _, err := executor.ExecContext(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(scopeCache.flushed) != 1 || scopeCache.flushed[0] != "main.UserMapper" {
+		t.Errorf("expected the namespace main.UserMapper to be flushed, got %v", scopeCache.flushed)
+	}
+}
+
+func TestShouldFlushCache(t *testing.T) {
+	tests := []struct {
+		action     Action
+		flushCache string
+		want       bool
+	}{
+		{Select, "", false},
+		{Select, "true", true},
+		{Insert, "", true},
+		{Update, "", true},
+		{Delete, "false", false},
+	}
+	for _, tt := range tests {
+		statement := newFakeStatement("")
+		statement.action = tt.action
+		if tt.flushCache != "" {
+			statement.setAttribute("flushCache", tt.flushCache)
+		}
+		if got := shouldFlushCache(statement); got != tt.want {
+			t.Errorf("%s with flushCache %q: expected %v, got %v", tt.action, tt.flushCache, tt.want, got)
+		}
+	}
+}
+
+// cancelScanUser cancels the query context when the first row is scanned.
+type cancelScanUser struct {
+	ID int64
+}
+
+var cancelScan context.CancelFunc
+
+func (u *cancelScanUser) ScanRows(rows *sql.Rows) error {
+	if cancelScan != nil {
+		cancelScan()
+		cancelScan = nil
+		// wait for database/sql to close the rows of the canceled context.
+		time.Sleep(10 * time.Millisecond)
+	}
+	return rows.Scan(&u.ID)
+}
+
+func TestGenericExecutor_QueryContext_CanceledMidScan(t *testing.T) {
+	resultSet := fakeResultSet{columns: []string{"id"}}
+	for i := 0; i < 100; i++ {
+		resultSet.rows = append(resultSet.rows, []driver.Value{int64(i)})
+	}
+	db, _ := newFakeDB(t, resultSet)
+	executor := &GenericExecutor[[]*cancelScanUser]{
+		SQLRowsExecutor: newFakeExecutor(db, newFakeStatement("SELECT id FROM user")),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cancelScan = cancel
+	if _, err := executor.QueryContext(ctx, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context canceled, got %v", err)
+	}
+	if inUse := db.Stats().InUse; inUse != 0 {
+		t.Errorf("expected the connection to be released, %d in use", inUse)
+	}
+}
+
+// rejectRowsMiddleware is a middleware which fails after the query, without closing the rows.
+type rejectRowsMiddleware struct {
+	err error
+}
+
+func (m rejectRowsMiddleware) QueryContext(_ Statement, next QueryHandler) QueryHandler {
+	return func(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+		rows, _ := next(ctx, query, args...)
+		return rows, m.err
+	}
+}
+
+func (m rejectRowsMiddleware) ExecContext(_ Statement, next ExecHandler) ExecHandler {
+	return next
+}
+
+func TestStatementHandler_QueryContext_ClosesRowsOnError(t *testing.T) {
+	db, _ := newFakeDB(t, fakeResultSet{
+		columns: []string{"id"},
+		rows:    [][]driver.Value{{int64(1)}},
+	})
+	errRejected := errors.New("rejected")
+	rejectRows := MiddlewareGroup{rejectRowsMiddleware{err: errRejected}}
+	drv := juicedriver.MySQLDriver{}
+	handlers := map[string]StatementHandler{
+		"rows":     &SQLRowsStatementHandler{driver: drv, middlewares: rejectRows, session: db},
+		"prepared": &PreparedStatementHandler{driver: drv, middlewares: rejectRows, session: db},
+	}
+	for name, handler := range handlers {
+		rows, err := handler.QueryContext(context.Background(), newFakeStatement("SELECT id FROM user"), nil)
+		if !errors.Is(err, errRejected) || rows != nil {
+			t.Errorf("%s: unexpected result %v %v", name, rows, err)
+		}
+		if closer, ok := handler.(interface{ Close() error }); ok {
+			_ = closer.Close()
+		}
+		if inUse := db.Stats().InUse; inUse != 0 {
+			t.Errorf("%s: expected the connection to be released, %d in use", name, inUse)
+		}
+	}
+}
+
+func TestSQLRowsExecutor_NamedArgs(t *testing.T) {
+	db, state := newFakeDB(t, fakeResultSet{})
+	drv := juicedriver.SQLServerDriver{NamedArgs: true}
+	executor := &sqlRowsExecutor{
+		statement:        newFakeStatement("SELECT * FROM user WHERE (owner = #{id} OR creator = #{id}) AND name = #{user.name}"),
+		statementHandler: NewDefaultStatementHandler(drv, db),
+		driver:           drv,
+	}
+	rows, err := executor.QueryContext(context.Background(), H{"id": 1, "user": H{"name": "a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = rows.Close()
+	if len(state.executions) != 1 {
+		t.Fatalf("unexpected executions: %v", state.executions)
+	}
+	execution := state.executions[0]
+	if execution.query != "SELECT * FROM user WHERE (owner = @id OR creator = @id) AND name = @user_name" {
+		t.Errorf("unexpected query: %s", execution.query)
+	}
+	if len(execution.args) != 2 || execution.names[0] != "id" || execution.names[1] != "user_name" {
+		t.Errorf("unexpected args: %v %v", execution.names, execution.args)
+	}
+}
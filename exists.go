@@ -0,0 +1,92 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-juicedev/juice/driver"
+)
+
+// ErrExistsRequiresSelect is returned by ExistsContext when the statement is not a select statement.
+var ErrExistsRequiresSelect = errors.New("exists: statement is not a select statement")
+
+// existsStatement is a Statement which builds the exists query of the data statement.
+type existsStatement struct {
+	Statement
+	driver driver.Driver
+}
+
+// ResultMap implements Statement.
+// The result of the exists query is always scanned by the default result map.
+func (e existsStatement) ResultMap() (ResultMap, error) { return nil, ErrResultMapNotSet }
+
+// Build implements Statement.
+// The args of the data statement are passed through unchanged.
+func (e existsStatement) Build(translator driver.Translator, param Param) (string, []any, error) {
+	query, args, err := e.Statement.Build(translator, param)
+	if err != nil {
+		return "", nil, err
+	}
+	return e.derive(translator, query, args)
+}
+
+// source implements derivedStatement.
+func (e existsStatement) source() Statement { return e.Statement }
+
+// derive implements derivedStatement.
+func (e existsStatement) derive(_ driver.Translator, query string, args []any) (string, []any, error) {
+	return driver.ExistsQuery(e.driver, query), args, nil
+}
+
+// ensure existsStatement implements derivedStatement.
+var _ derivedStatement = existsStatement{} // compile time check
+
+// ExistsContext executes the statement of the executor wrapped by the exists query of the driver,
+// see driver.ExistsQuery, and reports whether it returns any rows, for example:
+//
+//	exists, err := juice.ExistsContext(ctx, engine.Object(QueryUserByEmail), param)
+//
+// Only the existence is fetched from the database instead of the rows.
+// It returns ErrExistsRequiresSelect if the statement is not a select statement.
+func ExistsContext(ctx context.Context, executor SQLRowsExecutor, param Param) (bool, error) {
+	if exe, ok := isInvalidExecutor(executor); ok {
+		return false, exe.err
+	}
+	exe, ok := executor.(*sqlRowsExecutor)
+	if !ok {
+		return false, errors.New("exists: unsupported executor")
+	}
+	if action := exe.statement.Action(); action != Select {
+		return false, fmt.Errorf("%w: %s is a %s statement", ErrExistsRequiresSelect, exe.statement.Name(), action)
+	}
+	existsExecutor := &GenericExecutor[bool]{
+		SQLRowsExecutor: &sqlRowsExecutor{
+			statement:        existsStatement{Statement: exe.statement, driver: exe.driver},
+			statementHandler: exe.statementHandler,
+			driver:           exe.driver,
+		},
+	}
+	return existsExecutor.QueryContext(ctx, param)
+}
+
+// ExistsContext reports whether the statement of the executor returns any rows, see ExistsContext.
+func (e *GenericExecutor[T]) ExistsContext(ctx context.Context, param Param) (bool, error) {
+	return ExistsContext(ctx, e.SQLRowsExecutor, param)
+}
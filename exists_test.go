@@ -0,0 +1,77 @@
+package juice
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+
+	juicedriver "github.com/go-juicedev/juice/driver"
+)
+
+func TestExistsContext(t *testing.T) {
+	db, state := newFakeDB(t, fakeResultSet{
+		columns: []string{"EXISTS"},
+		rows:    [][]driver.Value{{int64(1)}},
+	})
+	executor := newFakeExecutor(db, newFakeStatement("SELECT id FROM user WHERE email = #{email}"))
+	exists, err := ExistsContext(context.Background(), executor, H{"email": "a@b.c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exists {
+		t.Error("expected exists")
+	}
+	if len(state.executions) != 1 || state.executions[0].query != "SELECT EXISTS(SELECT id FROM user WHERE email = ?)" {
+		t.Fatalf("unexpected executions: %v", state.executions)
+	}
+	if !reflect.DeepEqual(state.executions[0].args, []driver.Value{"a@b.c"}) {
+		t.Errorf("unexpected args: %v", state.executions[0].args)
+	}
+}
+
+func TestExistsContext_SoftDelete(t *testing.T) {
+	stmt := parseTestStatement(t, Select, `<select id="QueryUser" softDelete="true">
+		SELECT * FROM user WHERE id = #{id}
+	</select>`)
+	builder := statementBuilder{interceptors: StatementInterceptorGroup{SoftDeleteInterceptor{}}}
+	drv := juicedriver.MySQLDriver{}
+
+	// the predicate is appended to the data query before it is wrapped.
+	query, args, err := builder.build(context.Background(), drv.Translator(), existsStatement{Statement: stmt, driver: drv}, H{"id": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT EXISTS(SELECT * FROM user WHERE (id = ?) AND deleted_at IS NULL)" || !reflect.DeepEqual(args, []any{1}) {
+		t.Errorf("unexpected result: %s %v", query, args)
+	}
+}
+
+func TestGenericExecutor_ExistsContext(t *testing.T) {
+	db, _ := newFakeDB(t, fakeResultSet{
+		columns: []string{"EXISTS"},
+		rows:    [][]driver.Value{{int64(0)}},
+	})
+	executor := &GenericExecutor[[]int64]{SQLRowsExecutor: newFakeExecutor(db, newFakeStatement("SELECT id FROM user"))}
+	exists, err := executor.ExistsContext(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists {
+		t.Error("expected not exists")
+	}
+}
+
+func TestExistsContext_NotSelect(t *testing.T) {
+	db, state := newFakeDB(t, fakeResultSet{})
+	statement := newFakeStatement("DELETE FROM user")
+	statement.action = Delete
+	_, err := ExistsContext(context.Background(), newFakeExecutor(db, statement), nil)
+	if !errors.Is(err, ErrExistsRequiresSelect) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(state.executions) != 0 {
+		t.Errorf("unexpected executions: %v", state.executions)
+	}
+}
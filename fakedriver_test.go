@@ -0,0 +1,243 @@
+package juice
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	juicedriver "github.com/go-juicedev/juice/driver"
+)
+
+// fakeResultSet is the result set returned by the fake driver for every query.
+type fakeResultSet struct {
+	columns   []string
+	scanTypes []reflect.Type
+	rows      [][]driver.Value
+}
+
+// fakeExecution records a statement executed by the fake driver.
+type fakeExecution struct {
+	query string
+	args  []driver.Value
+	// names is the names of the args, empty for the positional args.
+	names []string
+}
+
+// fakeDB is the in-memory state of a database opened with the fake driver.
+type fakeDB struct {
+	mu         sync.Mutex
+	resultSet  fakeResultSet
+	executions []fakeExecution
+	queryErr   error
+	// queryErrs are returned by the queries in order, before queryErr.
+	queryErrs  []error
+	execResult driver.Result
+	// execResults are returned by the executions in order, before execResult.
+	execResults []driver.Result
+	commits     int
+	rollbacks   int
+}
+
+func (f *fakeDB) record(query string, args []driver.NamedValue) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	values := make([]driver.Value, len(args))
+	names := make([]string, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+		names[i] = arg.Name
+	}
+	f.executions = append(f.executions, fakeExecution{query: query, args: values, names: names})
+}
+
+// fakeDriver is a database/sql driver which serves the registered fakeDB by dsn.
+type fakeDriver struct{}
+
+var (
+	fakeDBs   sync.Map
+	fakeDBSeq atomic.Int64
+)
+
+func init() {
+	sql.Register("juice_fake", fakeDriver{})
+}
+
+// newFakeDB opens a new *sql.DB backed by the fake driver which returns the given result set.
+func newFakeDB(t testing.TB, resultSet fakeResultSet) (*sql.DB, *fakeDB) {
+	t.Helper()
+	dsn := strconv.FormatInt(fakeDBSeq.Add(1), 10)
+	state := &fakeDB{resultSet: resultSet}
+	fakeDBs.Store(dsn, state)
+	db, err := sql.Open("juice_fake", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+		fakeDBs.Delete(dsn)
+	})
+	return db, state
+}
+
+// queryFakeRows runs a query on a fake database which returns the given result set.
+func queryFakeRows(t testing.TB, resultSet fakeResultSet) *sql.Rows {
+	t.Helper()
+	db, _ := newFakeDB(t, resultSet)
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = rows.Close() })
+	return rows
+}
+
+// newFakeStatement creates a select statement which always builds the given query.
+func newFakeStatement(query string) *xmlSQLStatement {
+	return &xmlSQLStatement{
+		mapper: &Mapper{namespace: "main.UserMapper", mappers: &Mappers{}},
+		action: Select,
+		id:     "QueryUser",
+		Nodes:  NodeGroup{NewTextNode(query)},
+	}
+}
+
+// newFakeExecutor creates a SQLRowsExecutor which executes the statement on the given fake database.
+func newFakeExecutor(db *sql.DB, statement Statement) SQLRowsExecutor {
+	drv := juicedriver.MySQLDriver{}
+	return &sqlRowsExecutor{
+		statement:        statement,
+		statementHandler: NewDefaultStatementHandler(drv, db),
+		driver:           drv,
+	}
+}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	state, ok := fakeDBs.Load(dsn)
+	if !ok {
+		return nil, errors.New("fake database not found")
+	}
+	return &fakeConn{db: state.(*fakeDB)}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{db: c.db}, nil }
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.record(query, args)
+	c.db.mu.Lock()
+	if len(c.db.queryErrs) > 0 {
+		err := c.db.queryErrs[0]
+		c.db.queryErrs = c.db.queryErrs[1:]
+		c.db.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		return &fakeRows{resultSet: c.db.resultSet}, nil
+	}
+	c.db.mu.Unlock()
+	if c.db.queryErr != nil {
+		return nil, c.db.queryErr
+	}
+	return &fakeRows{resultSet: c.db.resultSet}, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.record(query, args)
+	c.db.mu.Lock()
+	if len(c.db.execResults) > 0 {
+		result := c.db.execResults[0]
+		c.db.execResults = c.db.execResults[1:]
+		c.db.mu.Unlock()
+		return result, nil
+	}
+	c.db.mu.Unlock()
+	if c.db.execResult != nil {
+		return c.db.execResult, nil
+	}
+	return driver.RowsAffected(1), nil
+}
+
+type fakeTx struct {
+	db *fakeDB
+}
+
+func (t fakeTx) Commit() error {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+	t.db.commits++
+	return nil
+}
+
+func (t fakeTx) Rollback() error {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+	t.db.rollbacks++
+	return nil
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error { return nil }
+
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, namedValues(args))
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, namedValues(args))
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return named
+}
+
+type fakeRows struct {
+	resultSet fakeResultSet
+	cursor    int
+}
+
+func (r *fakeRows) Columns() []string { return r.resultSet.columns }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.cursor >= len(r.resultSet.rows) {
+		return io.EOF
+	}
+	copy(dest, r.resultSet.rows[r.cursor])
+	r.cursor++
+	return nil
+}
+
+// ColumnTypeScanType implements driver.RowsColumnTypeScanType.
+func (r *fakeRows) ColumnTypeScanType(index int) reflect.Type {
+	if index < len(r.resultSet.scanTypes) && r.resultSet.scanTypes[index] != nil {
+		return r.resultSet.scanTypes[index]
+	}
+	return reflect.TypeOf(new(any)).Elem()
+}
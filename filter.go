@@ -0,0 +1,229 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-juicedev/juice/driver"
+	"github.com/go-juicedev/juice/internal/reflectlite"
+)
+
+// ErrFilterOperatorUnsupported is an error that is returned when the filter tag of a field
+// declares an operator which is not supported by the FilterNode.
+var ErrFilterOperatorUnsupported = errors.New("unsupported filter operator")
+
+// FilterOperator is the comparison operator of a filter field.
+type FilterOperator string
+
+// The operators supported by the filter tag.
+const (
+	FilterEq   FilterOperator = "eq"
+	FilterNe   FilterOperator = "ne"
+	FilterGt   FilterOperator = "gt"
+	FilterGte  FilterOperator = "gte"
+	FilterLt   FilterOperator = "lt"
+	FilterLte  FilterOperator = "lte"
+	FilterLike FilterOperator = "like"
+	FilterIn   FilterOperator = "in"
+)
+
+// filterOperators is the sql operators of the filter operators.
+var filterOperators = map[FilterOperator]string{
+	FilterEq:   "=",
+	FilterNe:   "<>",
+	FilterGt:   ">",
+	FilterGte:  ">=",
+	FilterLt:   "<",
+	FilterLte:  "<=",
+	FilterLike: "LIKE",
+	FilterIn:   "IN",
+}
+
+// parseFilterTag parses the filter tag of a field, like "age,gte".
+// The operator defaults to eq when it is omitted.
+func parseFilterTag(tag string) (column string, operator FilterOperator, err error) {
+	column, op, _ := strings.Cut(tag, ",")
+	column, operator = strings.TrimSpace(column), FilterOperator(strings.TrimSpace(op))
+	if operator == "" {
+		operator = FilterEq
+	}
+	if _, ok := filterOperators[operator]; !ok {
+		return "", "", fmt.Errorf("%w: %s", ErrFilterOperatorUnsupported, op)
+	}
+	return column, operator, nil
+}
+
+// FilterNode generates the predicates of a WHERE clause from the fields of a struct parameter
+// with filter tags, so that a search statement doesn't need an <if> for each condition.
+// It is used inside a WhereNode, which removes the leading AND of the first predicate.
+//
+// Example XML:
+//
+//	<select id="SearchUsers">
+//	    SELECT * FROM users
+//	    <where>
+//	        <filter value="filter"/>
+//	    </where>
+//	</select>
+//
+// Example struct:
+//
+//	type UserFilter struct {
+//	    Name   string  `filter:"name,like"`
+//	    MinAge *int    `filter:"age,gte"`
+//	    IDs    []int64 `filter:"id,in"`
+//	    Status int     `filter:"status"`
+//	}
+//
+// Example result, with only the name and the ids of the filter set:
+//
+//	SELECT * FROM users WHERE name LIKE ? AND id IN (?, ?)
+//
+// The filter tag is the column followed by the operator, one of eq, ne, gt, gte, lt, lte,
+// like and in, and the operator defaults to eq. The value of the like operator is bound as it
+// is, so the wildcards are up to the caller. The in operator binds every element of the slice
+// or array field.
+//
+// The skip-empty policy: a field is skipped when it is a nil pointer, a nil or empty slice,
+// map or array, or any other zero value. A non-nil pointer is never skipped, even if it points
+// to the zero value, so that a filter on 0 or false is expressed by a pointer field.
+// The untagged anonymous struct fields are walked into.
+//
+// The struct must be named by the value, like juice.H{"filter": filter}, since the fields of
+// a struct passed as the whole parameter are not reachable by a name.
+type FilterNode struct {
+	// Value is the name of the struct parameter.
+	Value string
+}
+
+// Accept accepts parameters and returns query and arguments.
+func (n *FilterNode) Accept(translator driver.Translator, p Parameter) (query string, args []any, err error) {
+	value, exists := p.Get(n.Value)
+	if !exists {
+		return "", nil, fmt.Errorf("parameter %s not found", n.Value)
+	}
+	value = reflectlite.Unwrap(value)
+	if value.Kind() != reflect.Struct {
+		return "", nil, fmt.Errorf("filter %s: expected a struct", n.Value)
+	}
+	builder := getStringBuilder()
+	defer putStringBuilder(builder)
+	if err = n.writePredicates(builder, value, n.Value); err != nil {
+		return "", nil, fmt.Errorf("filter %s: %w", n.Value, err)
+	}
+	if builder.Len() == 0 {
+		return "", nil, nil
+	}
+	return NewTextNode(builder.String()).Accept(translator, p)
+}
+
+// writePredicates writes the predicates of the fields of the struct which are not skipped,
+// each one preceded by AND. path is the parameter name of the struct.
+func (n *FilterNode) writePredicates(builder *strings.Builder, value reflect.Value, path string) error {
+	tp := value.Type()
+	for i := 0; i < tp.NumField(); i++ {
+		field := tp.Field(i)
+		tag := field.Tag.Get("filter")
+		// the fields of the anonymous struct are promoted, so they are named the same as its own fields.
+		if deepScan := field.Anonymous && field.Type.Kind() == reflect.Struct && len(tag) == 0; deepScan {
+			if err := n.writePredicates(builder, value.Field(i), path); err != nil {
+				return err
+			}
+			continue
+		}
+		// the unexported fields can not be bound.
+		if !field.IsExported() || tag == "" || tag == "-" {
+			continue
+		}
+		column, operator, err := parseFilterTag(tag)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if column == "" {
+			return fmt.Errorf("field %s: filter column is empty", field.Name)
+		}
+		fieldValue := value.Field(i)
+		if filterFieldEmpty(fieldValue) {
+			continue
+		}
+		name := path + "." + field.Name
+		if builder.Len() > 0 {
+			builder.WriteString(" ")
+		}
+		builder.WriteString("AND ")
+		builder.WriteString(column)
+		builder.WriteString(" ")
+		builder.WriteString(filterOperators[operator])
+		if operator == FilterIn {
+			if err = writeFilterInPlaceholders(builder, fieldValue, name); err != nil {
+				return fmt.Errorf("field %s: %w", field.Name, err)
+			}
+			continue
+		}
+		builder.WriteString(" #{")
+		builder.WriteString(name)
+		builder.WriteString("}")
+	}
+	return nil
+}
+
+// writeFilterInPlaceholders writes the parenthesized placeholders of the elements of the slice or array.
+func writeFilterInPlaceholders(builder *strings.Builder, value reflect.Value, name string) error {
+	value = reflectlite.Unwrap(value)
+	if kind := value.Kind(); kind != reflect.Slice && kind != reflect.Array {
+		return fmt.Errorf("in operator expects a slice or an array, got %s", value.Type())
+	}
+	builder.WriteString(" (")
+	for i := 0; i < value.Len(); i++ {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString("#{")
+		builder.WriteString(name)
+		builder.WriteString(".")
+		builder.WriteString(strconv.Itoa(i))
+		builder.WriteString("}")
+	}
+	builder.WriteString(")")
+	return nil
+}
+
+// filterFieldEmpty reports whether the field is skipped by the FilterNode.
+func filterFieldEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return true
+		}
+		// a pointer to a slice is still empty without elements, since they can not be bound.
+		if elem := reflectlite.Unwrap(value); elem.Kind() == reflect.Slice || elem.Kind() == reflect.Array {
+			return elem.Len() == 0
+		}
+		return false
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return value.Len() == 0
+	default:
+		return value.IsZero()
+	}
+}
+
+var _ Node = (*FilterNode)(nil)
@@ -0,0 +1,108 @@
+package juice
+
+import (
+	"encoding/xml"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-juicedev/juice/driver"
+)
+
+type filterPaging struct {
+	Status int `filter:"status,ne"`
+}
+
+type userFilter struct {
+	filterPaging
+	Name    string  `filter:"name,like"`
+	MinAge  *int    `filter:"age,gte"`
+	MaxAge  int     `filter:"age,lt"`
+	IDs     []int64 `filter:"id,in"`
+	Role    string  `filter:"role"`
+	Ignored string
+	secret  string `filter:"secret"`
+}
+
+func TestFilterNode(t *testing.T) {
+	stmt := parseTestStatement(t, Select, `<select id="SearchUsers">
+		SELECT * FROM users <where><filter value="filter"/></where>
+	</select>`)
+
+	zero := 0
+	filter := userFilter{Name: "%eat%", MinAge: &zero, IDs: []int64{1, 2}, secret: "x"}
+	query, args, err := stmt.Build(driver.PostgresDriver{}.Translator(), H{"filter": &filter})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM users WHERE name LIKE $1 AND age >= $2 AND id IN ($3, $4)" {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if !reflect.DeepEqual(args, []any{"%eat%", &zero, int64(1), int64(2)}) {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	filter = userFilter{filterPaging: filterPaging{Status: 2}, MaxAge: 30, Role: "admin"}
+	query, args, err = stmt.Build(driver.MySQLDriver{}.Translator(), H{"filter": filter})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM users WHERE status <> ? AND age < ? AND role = ?" {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if !reflect.DeepEqual(args, []any{2, 30, "admin"}) {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	// all the fields are empty, so the where clause is dropped.
+	query, _, err = stmt.Build(driver.MySQLDriver{}.Translator(), H{"filter": userFilter{IDs: []int64{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM users" {
+		t.Errorf("unexpected query: %s", query)
+	}
+
+	names, err := stmt.ParameterNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"filter"}) {
+		t.Errorf("unexpected names: %v", names)
+	}
+}
+
+func TestFilterNode_Errors(t *testing.T) {
+	node := &FilterNode{Value: "filter"}
+	translator := driver.MySQLDriver{}.Translator()
+	if _, _, err := node.Accept(translator, H{}.AsParam()); err == nil {
+		t.Error("expected parameter not found error")
+	}
+	if _, _, err := node.Accept(translator, H{"filter": 1}.AsParam()); err == nil {
+		t.Error("expected struct error")
+	}
+
+	type unknownOperator struct {
+		Age int `filter:"age,between"`
+	}
+	_, _, err := node.Accept(translator, H{"filter": unknownOperator{Age: 1}}.AsParam())
+	if !errors.Is(err, ErrFilterOperatorUnsupported) {
+		t.Errorf("expected ErrFilterOperatorUnsupported, got %v", err)
+	}
+
+	type scalarIn struct {
+		ID int `filter:"id,in"`
+	}
+	if _, _, err = node.Accept(translator, H{"filter": scalarIn{ID: 1}}.AsParam()); err == nil {
+		t.Error("expected in operator error")
+	}
+
+	parser := &XMLMappersElementParser{}
+	decoder := xml.NewDecoder(strings.NewReader(`<select id="a">SELECT * FROM users <where><filter/></where></select>`))
+	token, _ := decoder.Token()
+	stmt := &xmlSQLStatement{mapper: &Mapper{namespace: "main.UserMapper", mappers: &Mappers{}}, action: Select}
+	if err = parser.parseStatement(stmt, decoder, token.(xml.StartElement)); err == nil {
+		t.Error("expected value required error")
+	}
+}
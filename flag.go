@@ -0,0 +1,113 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/go-juicedev/juice/eval"
+)
+
+// FlagProvider reports whether the feature flags are enabled, for the if nodes with
+// the flag attribute, so that the queries can be toggled without redeploying the mappers:
+//
+//	engine.UseFlagProvider(juice.FlagProviderFunc(func(ctx context.Context, name string) bool {
+//	    return flags.IsEnabled(ctx, name)
+//	}))
+//
+//	<select id="SearchProducts">
+//	    select * from product
+//	    <if flag="newSearchPath">
+//	        where match(name) against(#{keyword})
+//	    <else/>
+//	        where name like concat('%', #{keyword}, '%')
+//	    </if>
+//	</select>
+//
+// The flags are evaluated every time the statement is built. Without a provider,
+// all the flags are disabled.
+type FlagProvider interface {
+	// Enabled reports whether the named flag is enabled in the given context.
+	// It returns false for the unknown flags.
+	Enabled(ctx context.Context, name string) bool
+}
+
+// FlagProviderFunc is an adapter to allow the use of ordinary functions as FlagProvider.
+type FlagProviderFunc func(ctx context.Context, name string) bool
+
+// Enabled implements FlagProvider.
+func (f FlagProviderFunc) Enabled(ctx context.Context, name string) bool {
+	return f(ctx, name)
+}
+
+// StaticFlagProvider is a FlagProvider with fixed flags, the flags not in the map are disabled.
+type StaticFlagProvider map[string]bool
+
+// Enabled implements FlagProvider.
+func (s StaticFlagProvider) Enabled(_ context.Context, name string) bool {
+	return s[name]
+}
+
+// flagParamPrefix is the prefix of the parameter names which the flags are provided as.
+const flagParamPrefix = "_flags."
+
+// ensure flagParameterProvider implements ParameterProvider.
+var _ ParameterProvider = (*flagParameterProvider)(nil) // compile time check
+
+// flagParameterProvider provides the flags of the FlagProvider to the statements.
+type flagParameterProvider struct {
+	flags FlagProvider
+}
+
+// Provide implements ParameterProvider.
+func (f flagParameterProvider) Provide(ctx context.Context) (Parameter, error) {
+	return flagParameter{ctx: ctx, flags: f.flags}, nil
+}
+
+// flagParameter is a Parameter which resolves the names prefixed by flagParamPrefix
+// to the flags of the context.
+type flagParameter struct {
+	ctx   context.Context
+	flags FlagProvider
+}
+
+// Get implements Parameter.
+func (f flagParameter) Get(name string) (reflect.Value, bool) {
+	flag, ok := strings.CutPrefix(name, flagParamPrefix)
+	if !ok {
+		return reflect.Value{}, false
+	}
+	return reflect.ValueOf(f.flags.Enabled(f.ctx, flag)), true
+}
+
+// ensure flagExpression implements eval.Expression.
+var _ eval.Expression = flagExpression("") // compile time check
+
+// flagExpression is the condition of an if node with the flag attribute.
+type flagExpression string
+
+// Execute implements eval.Expression.
+// The flag is disabled if no FlagProvider is used.
+func (f flagExpression) Execute(params eval.Parameter) (eval.Value, error) {
+	value, ok := params.Get(flagParamPrefix + string(f))
+	if !ok {
+		return reflect.ValueOf(false), nil
+	}
+	return value, nil
+}
@@ -0,0 +1,84 @@
+package juice
+
+import (
+	"context"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-juicedev/juice/driver"
+)
+
+func TestIfNode_Flag(t *testing.T) {
+	stmt := parseTestStatement(t, Select, `<select id="SearchProducts">
+		SELECT * FROM product WHERE
+		<if flag="newSearchPath">
+			match(name) against(#{keyword})
+		<else/>
+			name LIKE #{keyword}
+		</if>
+	</select>`)
+
+	type tenantKey struct{}
+	flags := FlagProviderFunc(func(ctx context.Context, name string) bool {
+		return name == "newSearchPath" && ctx.Value(tenantKey{}) == "beta"
+	})
+	builder := statementBuilder{paramProviders: ParameterProviderGroup{flagParameterProvider{flags: flags}}}
+	translator := driver.MySQLDriver{}.Translator()
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "beta")
+	query, args, err := builder.build(ctx, translator, stmt, H{"keyword": "apple"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM product WHERE match(name) against(?)" || !reflect.DeepEqual(args, []any{"apple"}) {
+		t.Errorf("unexpected result: %s %v", query, args)
+	}
+
+	// the flag is disabled in the other contexts.
+	query, _, err = builder.build(context.Background(), translator, stmt, H{"keyword": "apple"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM product WHERE name LIKE ?" {
+		t.Errorf("unexpected query: %s", query)
+	}
+
+	// all the flags are disabled without a provider.
+	query, _, err = statementBuilder{}.build(ctx, translator, stmt, H{"keyword": "apple"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM product WHERE name LIKE ?" {
+		t.Errorf("unexpected query: %s", query)
+	}
+}
+
+func TestIfNode_FlagStatic(t *testing.T) {
+	node := &IfNode{expr: flagExpression("a"), Nodes: NodeGroup{NewTextNode("a = 1")}}
+	param := flagParameter{ctx: context.Background(), flags: StaticFlagProvider{"a": true, "b": false}}
+	query, _, err := node.Accept(driver.MySQLDriver{}.Translator(), param)
+	if err != nil || query != "a = 1" {
+		t.Errorf("unexpected result: %q %v", query, err)
+	}
+	node.expr = flagExpression("unknown")
+	if query, _, err = node.Accept(driver.MySQLDriver{}.Translator(), param); err != nil || query != "" {
+		t.Errorf("unexpected result: %q %v", query, err)
+	}
+}
+
+func TestIfNode_FlagInvalid(t *testing.T) {
+	parser := &XMLMappersElementParser{}
+	for _, content := range []string{
+		`<select id="a">SELECT 1 <if flag="a" test="b">AND 1</if></select>`,
+		`<select id="a">SELECT 1 <if>AND 1</if></select>`,
+	} {
+		decoder := xml.NewDecoder(strings.NewReader(content))
+		token, _ := decoder.Token()
+		stmt := &xmlSQLStatement{mapper: &Mapper{namespace: "main.UserMapper", mappers: &Mappers{}}, action: Select}
+		if err := parser.parseStatement(stmt, decoder, token.(xml.StartElement)); err == nil {
+			t.Errorf("expected error for %s", content)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-juicedev/juice/internal/reflectlite"
+)
+
+// returningKeysKey is the context key of the returningKeys.
+type returningKeysKey struct{}
+
+// returningKeys collects the generated keys returned by the RETURNING clause of an insert
+// statement. The useGeneratedKeysMiddleware puts it into the context, so that the exec
+// handlers run the statement as a query and scan the keys, see driver.KeyReturner.
+type returningKeys struct {
+	ids []int64
+}
+
+// withReturningKeys returns a new context which asks the exec handlers to collect the returned keys.
+func withReturningKeys(ctx context.Context) (context.Context, *returningKeys) {
+	keys := &returningKeys{}
+	return context.WithValue(ctx, returningKeysKey{}, keys), keys
+}
+
+// returningKeysFromContext returns the returningKeys of the context, or nil.
+func returningKeysFromContext(ctx context.Context) *returningKeys {
+	keys, _ := ctx.Value(returningKeysKey{}).(*returningKeys)
+	return keys
+}
+
+// execReturning runs the insert statement by the query function, and collects the keys of the rows.
+func (r *returningKeys) execReturning(query func() (*sql.Rows, error)) (sql.Result, error) {
+	rows, err := query()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		r.ids = append(r.ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return returningResult(r.ids), nil
+}
+
+// ensure returningResult implements sql.Result.
+var _ sql.Result = (returningResult)(nil) // compile time check
+
+// returningResult is the sql.Result of an insert statement with a RETURNING clause.
+type returningResult []int64
+
+// LastInsertId implements sql.Result.
+// It returns the key of the last inserted row.
+func (r returningResult) LastInsertId() (int64, error) {
+	if len(r) == 0 {
+		return 0, errors.New("no rows inserted")
+	}
+	return r[len(r)-1], nil
+}
+
+// RowsAffected implements sql.Result.
+func (r returningResult) RowsAffected() (int64, error) {
+	return int64(len(r)), nil
+}
+
+// ensure batchResult implements sql.Result.
+var _ sql.Result = (batchResult)(nil) // compile time check
+
+// batchResult is the sql.Result of an insert statement executed in batches.
+type batchResult []sql.Result
+
+// LastInsertId implements sql.Result.
+// It returns the last insert id of the last batch.
+func (b batchResult) LastInsertId() (int64, error) {
+	if len(b) == 0 {
+		return 0, errors.New("no batches executed")
+	}
+	return b[len(b)-1].LastInsertId()
+}
+
+// RowsAffected implements sql.Result.
+// It returns the sum of the rows affected by all the batches.
+func (b batchResult) RowsAffected() (int64, error) {
+	var total int64
+	for _, result := range b {
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		total += affected
+	}
+	return total, nil
+}
+
+// keyElementType returns the struct type of the param, or of the elements of the slice param.
+func keyElementType(param reflect.Value) (reflect.Type, bool) {
+	tp := reflectlite.IndirectType(param.Type())
+	switch tp.Kind() {
+	case reflect.Struct:
+		return tp, true
+	case reflect.Slice, reflect.Array:
+		tp = reflectlite.IndirectType(tp.Elem())
+		return tp, tp.Kind() == reflect.Struct
+	default:
+		return nil, false
+	}
+}
+
+// keyFieldIndexes returns the field indexes of the key property of the struct type.
+func keyFieldIndexes(tp reflect.Type, keyProperty string) ([]int, bool) {
+	if len(keyProperty) > 0 {
+		return findFieldIndexesFromProperties(tp, strings.Split(keyProperty, ".")...)
+	}
+	return findFieldIndexesFromProperties(tp)
+}
+
+// returningKeyColumn returns the column of the generated keys of the insert statement,
+// the keyColumn attribute, or the column tag of the key property.
+func returningKeyColumn(stmt Statement, elementType reflect.Type) (string, error) {
+	if column := stmt.Attribute("keyColumn"); column != "" {
+		return column, nil
+	}
+	indexes, ok := keyFieldIndexes(elementType, stmt.Attribute("keyProperty"))
+	if !ok {
+		return "", errors.New("useGeneratedKeys is true, but the key property is not found")
+	}
+	column, _ := parseColumnTag(elementType.FieldByIndex(indexes).Tag.Get("column"))
+	if column == "" || column == "-" {
+		return "", errors.New("useGeneratedKeys is true, but the key column is unknown, set the keyColumn attribute")
+	}
+	return column, nil
+}
+
+// returningKeyGenerator sets the keys returned by the RETURNING clause to the struct,
+// or to the elements of the slice in order.
+type returningKeyGenerator struct {
+	ids         []int64
+	keyProperty string
+}
+
+// GenerateKeyTo implements selectKeyGenerator.
+func (r returningKeyGenerator) GenerateKeyTo(v reflect.Value) error {
+	elementType, ok := keyElementType(v)
+	if !ok {
+		return errStructPointerOrSliceArrayRequired
+	}
+	indexes, ok := keyFieldIndexes(elementType, r.keyProperty)
+	if !ok {
+		return nil
+	}
+	if v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Struct {
+		if len(r.ids) != 1 {
+			return fmt.Errorf("expected 1 generated key, got %d", len(r.ids))
+		}
+		return setGeneratedKey(v.Elem(), indexes, r.keyProperty, r.ids[0])
+	}
+	v = reflect.Indirect(v)
+	if v.Kind() == reflect.Struct {
+		return ErrPointerRequired
+	}
+	if v.Len() != len(r.ids) {
+		return fmt.Errorf("expected %d generated keys, got %d", v.Len(), len(r.ids))
+	}
+	for i, id := range r.ids {
+		if err := setGeneratedKey(reflect.Indirect(v.Index(i)), indexes, r.keyProperty, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setGeneratedKey sets the id to the key field of the struct.
+func setGeneratedKey(value reflect.Value, indexes []int, keyProperty string, id int64) error {
+	value = value.FieldByIndex(indexes)
+	if !value.CanInt() {
+		return fmt.Errorf("can not convert %s to int", keyProperty)
+	}
+	if !value.CanSet() {
+		return fmt.Errorf("can not set %s", keyProperty)
+	}
+	value.SetInt(id)
+	return nil
+}
@@ -0,0 +1,130 @@
+package juice
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+
+	juicedriver "github.com/go-juicedev/juice/driver"
+)
+
+// fakeInsertResult is the result of an insert statement of the fake driver.
+type fakeInsertResult struct {
+	id, rows int64
+}
+
+func (r fakeInsertResult) LastInsertId() (int64, error) { return r.id, nil }
+
+func (r fakeInsertResult) RowsAffected() (int64, error) { return r.rows, nil }
+
+type generatedKeysUser struct {
+	ID   int64  `column:"id" autoincr:"true"`
+	Name string `column:"name" param:"name"`
+}
+
+const generatedKeysInsert = `INSERT INTO user (name) VALUES
+	<foreach collection="param" item="u" separator=",">(#{u.name})</foreach>`
+
+func TestUseGeneratedKeys_Batches(t *testing.T) {
+	stmt := parseTestStatement(t, Insert, `<insert id="CreateUsers" useGeneratedKeys="true" batchSize="2">`+generatedKeysInsert+`</insert>`)
+	db, state := newFakeDB(t, fakeResultSet{})
+	// MySQL reports the key of the first row of each batch.
+	state.execResults = []driver.Result{fakeInsertResult{id: 10, rows: 2}, fakeInsertResult{id: 20, rows: 1}}
+
+	drv := juicedriver.MySQLDriver{}
+	handler := NewDefaultStatementHandler(drv, db, &useGeneratedKeysMiddleware{driver: drv})
+	users := []generatedKeysUser{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	result, err := handler.ExecContext(context.Background(), stmt, users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []int64{10, 11, 20} {
+		if users[i].ID != expected {
+			t.Errorf("users[%d]: expected id %d, got %d", i, expected, users[i].ID)
+		}
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected != 3 {
+		t.Errorf("unexpected rows affected: %d %v", affected, err)
+	}
+	if id, err := result.LastInsertId(); err != nil || id != 20 {
+		t.Errorf("unexpected last insert id: %d %v", id, err)
+	}
+}
+
+func TestUseGeneratedKeys_Decremental(t *testing.T) {
+	stmt := parseTestStatement(t, Insert, `<insert id="CreateUsers" useGeneratedKeys="true" batchInsertIDGenerateStrategy="DECREMENTAL">`+generatedKeysInsert+`</insert>`)
+	db, state := newFakeDB(t, fakeResultSet{})
+	state.execResult = fakeInsertResult{id: 10, rows: 3}
+
+	drv := juicedriver.MySQLDriver{}
+	handler := NewDefaultStatementHandler(drv, db, &useGeneratedKeysMiddleware{driver: drv})
+	users := []*generatedKeysUser{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	if _, err := handler.ExecContext(context.Background(), stmt, users); err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []int64{8, 9, 10} {
+		if users[i].ID != expected {
+			t.Errorf("users[%d]: expected id %d, got %d", i, expected, users[i].ID)
+		}
+	}
+}
+
+func TestUseGeneratedKeys_Returning(t *testing.T) {
+	stmt := parseTestStatement(t, Insert, `<insert id="CreateUsers" useGeneratedKeys="true">`+generatedKeysInsert+`</insert>`)
+	db, state := newFakeDB(t, fakeResultSet{
+		columns: []string{"id"},
+		rows:    [][]driver.Value{{int64(7)}, {int64(8)}, {int64(9)}},
+	})
+
+	drv := juicedriver.PostgresDriver{}
+	handler := NewDefaultStatementHandler(drv, db, &useGeneratedKeysMiddleware{driver: drv})
+	users := []*generatedKeysUser{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	result, err := handler.ExecContext(context.Background(), stmt, users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query := state.executions[0].query; !strings.HasSuffix(query, "RETURNING id") {
+		t.Errorf("unexpected query: %s", query)
+	}
+	for i, expected := range []int64{7, 8, 9} {
+		if users[i].ID != expected {
+			t.Errorf("users[%d]: expected id %d, got %d", i, expected, users[i].ID)
+		}
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected != 3 {
+		t.Errorf("unexpected rows affected: %d %v", affected, err)
+	}
+
+	// the rows don't match the elements.
+	if _, err = handler.ExecContext(context.Background(), stmt, users[:2]); err == nil {
+		t.Error("expected error for the mismatched keys")
+	}
+}
+
+func TestUseGeneratedKeys_ReturningKeyColumn(t *testing.T) {
+	stmt := parseTestStatement(t, Insert, `<insert id="CreateUser" useGeneratedKeys="true" keyProperty="ID" keyColumn="user_id">
+		INSERT INTO user (name) VALUES (#{Name})
+	</insert>`)
+	db, state := newFakeDB(t, fakeResultSet{columns: []string{"user_id"}, rows: [][]driver.Value{{int64(42)}}})
+
+	drv := juicedriver.PostgresDriver{}
+	handler := NewDefaultStatementHandler(drv, db, &useGeneratedKeysMiddleware{driver: drv})
+	user := &struct {
+		ID   int64
+		Name string
+	}{Name: "a"}
+	if _, err := handler.ExecContext(context.Background(), stmt, user); err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != 42 {
+		t.Errorf("unexpected id: %d", user.ID)
+	}
+	if query := state.executions[0].query; query != "INSERT INTO user (name) VALUES ($1) RETURNING user_id" {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if !reflect.DeepEqual(state.executions[0].args, []driver.Value{"a"}) {
+		t.Errorf("unexpected args: %v", state.executions[0].args)
+	}
+}
@@ -77,12 +77,17 @@ var _ QueryHandler = SessionQueryHandler
 // SessionExecHandler is the default ExecHandler.
 // It will get the session from the context.
 // And use the session to exec the database.
+// The result is a CachedResult, so that it can be read by the middlewares and the caller.
 func SessionExecHandler(ctx context.Context, query string, args ...any) (sql.Result, error) {
 	sess, err := session.FromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return sess.ExecContext(ctx, query, args...)
+	// the insert statement with a RETURNING clause is run as a query, see useGeneratedKeysMiddleware.
+	if keys := returningKeysFromContext(ctx); keys != nil {
+		return keys.execReturning(func() (*sql.Rows, error) { return sess.QueryContext(ctx, query, args...) })
+	}
+	return cachedExecResult(sess.ExecContext(ctx, query, args...))
 }
 
 // ensure SessionExecHandler implements ExecHandler
@@ -0,0 +1,235 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+)
+
+// HTTPConfigurationOptions is the options of the HTTPConfiguration.
+type HTTPConfigurationOptions struct {
+	// Client is the http client to fetch the configuration, http.DefaultClient if nil.
+	Client *http.Client
+
+	// Header is the additional header of the requests, like the authorization.
+	Header http.Header
+
+	// RefreshInterval is the interval of the periodic refresh.
+	// The configuration is only fetched once if it is not positive.
+	RefreshInterval time.Duration
+
+	// OnRefreshError is called when a periodic refresh fails, the last good configuration is kept.
+	OnRefreshError func(err error)
+}
+
+// ensure HTTPConfiguration implements IConfiguration.
+var _ IConfiguration = (*HTTPConfiguration)(nil) // compile time check
+
+// HTTPConfiguration is a ReloadableConfiguration which is fetched from an http endpoint,
+// for the centralized query management across the services.
+//
+// The resources of the mappers are resolved against the url of the configuration, so the
+// mapper files can be served by the same config server:
+//
+//	cfg, err := juice.NewHTTPConfiguration("https://config.example.com/juice/config.xml", juice.HTTPConfigurationOptions{
+//	    RefreshInterval: time.Minute,
+//	    OnRefreshError:  func(err error) { log.Println(err) },
+//	})
+//	if err != nil {
+//	    // handle error
+//	}
+//	defer cfg.Close()
+//
+// The configuration is cached by its ETag, the server can reply 304 Not Modified to skip
+// the parsing. Note that the mapper files are only fetched again when the configuration itself
+// is modified. If the fetching or the parsing fails, the last good configuration is kept.
+type HTTPConfiguration struct {
+	*ReloadableConfiguration
+
+	url     *url.URL
+	client  *http.Client
+	header  http.Header
+	onError func(err error)
+
+	// etag is the ETag of the current configuration, guarded by the reloads.
+	etag string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewHTTPConfiguration fetches the configuration from the url, and refreshes it periodically
+// if the RefreshInterval of the options is positive.
+func NewHTTPConfiguration(rawURL string, options HTTPConfigurationOptions) (*HTTPConfiguration, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("juice: invalid configuration url schema %q", u.Scheme)
+	}
+	cfg := &HTTPConfiguration{
+		url:     u,
+		client:  options.Client,
+		header:  options.Header,
+		onError: options.OnRefreshError,
+	}
+	if cfg.client == nil {
+		cfg.client = http.DefaultClient
+	}
+	cfg.ctx, cfg.cancel = context.WithCancel(context.Background())
+	if cfg.ReloadableConfiguration, err = NewReloadableConfiguration(cfg.load); err != nil {
+		cfg.cancel()
+		return nil, err
+	}
+	if options.RefreshInterval > 0 {
+		cfg.wg.Add(1)
+		go cfg.refresh(options.RefreshInterval)
+	}
+	return cfg, nil
+}
+
+// load fetches the configuration, it returns the current one if the configuration is not modified.
+func (c *HTTPConfiguration) load() (IConfiguration, error) {
+	resp, err := c.get(c.url, c.etag)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		if current := c.current.Load(); current != nil {
+			return current.IConfiguration, nil
+		}
+		return nil, fmt.Errorf("juice: fetch configuration %s: unexpected status %s", c.url, resp.Status)
+	case http.StatusOK:
+	default:
+		return nil, fmt.Errorf("juice: fetch configuration %s: unexpected status %s", c.url, resp.Status)
+	}
+	cfg, err := parseXMLConfiguration(httpFS{configuration: c}, resp.Body, false)
+	if err != nil {
+		return nil, err
+	}
+	// the etag is kept only when the configuration is good,
+	// otherwise the broken one would be reported as not modified.
+	c.etag = resp.Header.Get("ETag")
+	return cfg, nil
+}
+
+// get sends a GET request to the url, with the If-None-Match header if the etag is not empty.
+func (c *HTTPConfiguration) get(u *url.URL, etag string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	return c.client.Do(req)
+}
+
+// refresh reloads the configuration every interval until the configuration is closed.
+func (c *HTTPConfiguration) refresh(interval time.Duration) {
+	defer c.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Reload(); err != nil && c.onError != nil && c.ctx.Err() == nil {
+				c.onError(err)
+			}
+		}
+	}
+}
+
+// Close stops the periodic refresh, the current configuration is still usable.
+func (c *HTTPConfiguration) Close() error {
+	c.cancel()
+	c.wg.Wait()
+	return nil
+}
+
+// httpFS is a fs.FS which fetches the files relative to the url of the configuration.
+type httpFS struct {
+	configuration *HTTPConfiguration
+}
+
+// Open implements fs.FS.
+func (f httpFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	resp, err := f.configuration.get(f.configuration.url.ResolveReference(&url.URL{Path: name}), "")
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	defer func() { _ = resp.Body.Close() }()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	default:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: errors.New("unexpected status " + resp.Status)}
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &httpFile{Reader: bytes.NewReader(data), name: path.Base(name), size: int64(len(data))}, nil
+}
+
+// httpFile is a fetched file of the httpFS.
+type httpFile struct {
+	*bytes.Reader
+	name string
+	size int64
+}
+
+// Stat implements fs.File.
+func (f *httpFile) Stat() (fs.FileInfo, error) { return httpFileInfo{file: f}, nil }
+
+// Close implements fs.File.
+func (f *httpFile) Close() error { return nil }
+
+// httpFileInfo is the fs.FileInfo of the httpFile.
+type httpFileInfo struct {
+	file *httpFile
+}
+
+func (i httpFileInfo) Name() string       { return i.file.name }
+func (i httpFileInfo) Size() int64        { return i.file.size }
+func (i httpFileInfo) Mode() fs.FileMode  { return 0444 }
+func (i httpFileInfo) ModTime() time.Time { return time.Time{} }
+func (i httpFileInfo) IsDir() bool        { return false }
+func (i httpFileInfo) Sys() any           { return nil }
@@ -0,0 +1,176 @@
+package juice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-juicedev/juice/driver"
+)
+
+const httpConfigXML = `<?xml version="1.0" encoding="UTF-8"?>
+<configuration>
+    <environments default="prod">
+        <environment id="prod">
+            <dataSource>fake</dataSource>
+            <driver>fake</driver>
+        </environment>
+    </environments>
+    <mappers>
+        <mapper resource="mappers/mapper.xml"/>
+    </mappers>
+</configuration>`
+
+// httpConfigServer serves the configuration with the ETag of its version.
+type httpConfigServer struct {
+	mu       sync.Mutex
+	version  string
+	mapper   []byte
+	fail     bool
+	modified atomic.Int32
+}
+
+func (s *httpConfigServer) set(version string, mapper []byte, fail bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.version, s.mapper, s.fail = version, mapper, fail
+}
+
+func (s *httpConfigServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.URL.Path {
+	case "/juice/config.xml":
+		if s.fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if r.Header.Get("If-None-Match") == s.version {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		s.modified.Add(1)
+		w.Header().Set("ETag", s.version)
+		_, _ = w.Write([]byte(httpConfigXML))
+	case "/juice/mappers/mapper.xml":
+		_, _ = w.Write(s.mapper)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// countingTransport counts the requests sent by the client.
+type countingTransport struct {
+	http.RoundTripper
+	requests atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	return t.RoundTripper.RoundTrip(req)
+}
+
+func TestHTTPConfiguration(t *testing.T) {
+	source := &httpConfigServer{}
+	source.set(`"v1"`, reloadableMapperXML("SELECT * FROM user_v1"), false)
+	server := httptest.NewServer(source)
+	defer server.Close()
+
+	cfg, err := NewHTTPConfiguration(server.URL+"/juice/config.xml", HTTPConfigurationOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = cfg.Close() }()
+	translator := driver.MySQLDriver{}.Translator()
+	assertQuery := func(want string) {
+		t.Helper()
+		stmt, err := cfg.GetStatement("main.Repository.QueryUser")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if query, _, _ := stmt.Build(translator, nil); query != want {
+			t.Errorf("unexpected query: %s", query)
+		}
+	}
+	assertQuery("SELECT * FROM user_v1")
+
+	// not modified, the current configuration is kept without parsing.
+	current := cfg.Current()
+	if err = cfg.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Current() != current || source.modified.Load() != 1 {
+		t.Error("expected the configuration not to be fetched again")
+	}
+
+	// the fetching fails, the last good configuration is kept.
+	source.set(`"v2"`, reloadableMapperXML("SELECT * FROM user_v2"), true)
+	if err = cfg.Reload(); err == nil {
+		t.Fatal("expected error")
+	}
+	assertQuery("SELECT * FROM user_v1")
+
+	// the broken configuration is not cached by its etag.
+	source.set(`"v2"`, []byte("<mapper namespace=\"main.Repository\"><select>"), false)
+	if err = cfg.Reload(); err == nil {
+		t.Fatal("expected error")
+	}
+	assertQuery("SELECT * FROM user_v1")
+
+	source.set(`"v2"`, reloadableMapperXML("SELECT * FROM user_v2"), false)
+	if err = cfg.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	assertQuery("SELECT * FROM user_v2")
+}
+
+func TestHTTPConfiguration_Refresh(t *testing.T) {
+	source := &httpConfigServer{}
+	source.set(`"v1"`, reloadableMapperXML("SELECT * FROM user_v1"), false)
+	server := httptest.NewServer(source)
+	defer server.Close()
+
+	// the requests are counted by the client, Close waits for the refresh to return,
+	// so no request can be sent after it.
+	transport := &countingTransport{RoundTripper: http.DefaultTransport}
+	errs := make(chan error, 16)
+	cfg, err := NewHTTPConfiguration(server.URL+"/juice/config.xml", HTTPConfigurationOptions{
+		Client:          &http.Client{Transport: transport},
+		RefreshInterval: time.Millisecond,
+		OnRefreshError: func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	source.set(`"v1"`, nil, true)
+	select {
+	case <-errs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the refresh to fail")
+	}
+	if err = cfg.Close(); err != nil {
+		t.Fatal(err)
+	}
+	requests := transport.requests.Load()
+	time.Sleep(10 * time.Millisecond)
+	if transport.requests.Load() != requests {
+		t.Error("expected the refresh to be stopped")
+	}
+	if _, err = cfg.GetStatement("main.Repository.QueryUser"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNewHTTPConfiguration_InvalidSchema(t *testing.T) {
+	if _, err := NewHTTPConfiguration("ftp://example.com/config.xml", HTTPConfigurationOptions{}); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import "context"
+
+// StatementInterceptor intercepts the built SQL of the statements right before execution.
+// Unlike the SQLRewriter, it receives the context of the execution, so that it can be scoped
+// by the caller, and it is meant to act on the statements selected by their attributes:
+//
+//	type auditInterceptor struct{}
+//
+//	func (auditInterceptor) Intercept(ctx context.Context, stmt Statement, query string, args []any) (string, []any, error) {
+//	    if stmt.Attribute("audit") != "true" {
+//	        return query, args, nil
+//	    }
+//	    return "/* audit */ " + query, args, nil
+//	}
+//
+// The interceptors run before the rewriters, see SoftDeleteInterceptor for a built-in one.
+type StatementInterceptor interface {
+	// Intercept returns the intercepted query and args.
+	// Returning an error aborts the execution of the statement.
+	Intercept(ctx context.Context, stmt Statement, query string, args []any) (string, []any, error)
+}
+
+// StatementInterceptorFunc is an adapter to allow the use of ordinary functions as StatementInterceptor.
+type StatementInterceptorFunc func(ctx context.Context, stmt Statement, query string, args []any) (string, []any, error)
+
+// Intercept implements StatementInterceptor.
+func (f StatementInterceptorFunc) Intercept(ctx context.Context, stmt Statement, query string, args []any) (string, []any, error) {
+	return f(ctx, stmt, query, args)
+}
+
+// ensure StatementInterceptorGroup implements StatementInterceptor.
+var _ StatementInterceptor = StatementInterceptorGroup(nil) // compile time check
+
+// StatementInterceptorGroup is a chain of StatementInterceptor.
+type StatementInterceptorGroup []StatementInterceptor
+
+// Intercept implements StatementInterceptor.
+// Each interceptor receives the output of the previous one, in the order they were added.
+func (g StatementInterceptorGroup) Intercept(ctx context.Context, stmt Statement, query string, args []any) (string, []any, error) {
+	var err error
+	for _, interceptor := range g {
+		query, args, err = interceptor.Intercept(ctx, stmt, query, args)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	return query, args, nil
+}
@@ -244,3 +244,10 @@ func (t *Trie[T]) GetByPrefix(prefix string) []KeyValue[T] {
 	t.collectValues(current, prefix, &result)
 	return result
 }
+
+// All returns all key-value pairs in the trie
+func (t *Trie[T]) All() []KeyValue[T] {
+	result := make([]KeyValue[T], 0, t.size)
+	t.collectValues(t.root, "", &result)
+	return result
+}
@@ -16,7 +16,11 @@ limitations under the License.
 
 package reflectlite
 
-import "reflect"
+import (
+	"cmp"
+	"reflect"
+	"slices"
+)
 
 // Unwrap returns the value of the element if the type is a pointer or interface type.
 func Unwrap(value reflect.Value) reflect.Value {
@@ -40,6 +44,28 @@ func NilAble(v reflect.Value) bool {
 	return false
 }
 
+// SortMapKeys sorts the keys of a map in ascending order when the key type is ordered,
+// which are integers, floats and strings. It reports whether the keys are sorted.
+// Keys of other types are left in their original order.
+func SortMapKeys(keys []reflect.Value) bool {
+	if len(keys) == 0 {
+		return true
+	}
+	switch keys[0].Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		slices.SortFunc(keys, func(a, b reflect.Value) int { return cmp.Compare(a.Int(), b.Int()) })
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		slices.SortFunc(keys, func(a, b reflect.Value) int { return cmp.Compare(a.Uint(), b.Uint()) })
+	case reflect.Float32, reflect.Float64:
+		slices.SortFunc(keys, func(a, b reflect.Value) int { return cmp.Compare(a.Float(), b.Float()) })
+	case reflect.String:
+		slices.SortFunc(keys, func(a, b reflect.Value) int { return cmp.Compare(a.String(), b.String()) })
+	default:
+		return false
+	}
+	return true
+}
+
 func IndirectKind(v reflect.Value) reflect.Kind {
 	return IndirectType(v.Type()).Kind()
 }
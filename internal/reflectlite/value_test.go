@@ -77,3 +77,33 @@ func TestValue_GetFieldIndexesFromTag(t *testing.T) {
 		t.Errorf("expected nil indexes, got %v", indexes)
 	}
 }
+
+func TestSortMapKeys(t *testing.T) {
+	value := reflect.ValueOf(map[string]int{"c": 3, "a": 1, "b": 2})
+	keys := value.MapKeys()
+	if !SortMapKeys(keys) {
+		t.Fatal("string keys should be sorted")
+	}
+	for i, expected := range []string{"a", "b", "c"} {
+		if keys[i].String() != expected {
+			t.Errorf("unexpected key at %d: %s", i, keys[i].String())
+		}
+	}
+
+	value = reflect.ValueOf(map[int64]int{3: 3, -1: 1, 2: 2})
+	keys = value.MapKeys()
+	if !SortMapKeys(keys) {
+		t.Fatal("int keys should be sorted")
+	}
+	for i, expected := range []int64{-1, 2, 3} {
+		if keys[i].Int() != expected {
+			t.Errorf("unexpected key at %d: %d", i, keys[i].Int())
+		}
+	}
+
+	type key struct{ id int }
+	keys = reflect.ValueOf(map[key]int{{1}: 1, {2}: 2}).MapKeys()
+	if SortMapKeys(keys) {
+		t.Error("struct keys should not be sorted")
+	}
+}
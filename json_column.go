@@ -0,0 +1,109 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-juicedev/juice/eval"
+	"github.com/go-juicedev/juice/internal/reflectlite"
+)
+
+// jsonColumnOption is the option of the column tag which marks the field as a JSON column.
+//
+//	type User struct {
+//	    ID   int64          `column:"id"`
+//	    Meta map[string]any `column:"meta,json"`
+//	}
+//
+// The field is unmarshalled from the JSON column when scanned, and marshalled to JSON
+// when it is bound by a #{} placeholder. A NULL column leaves the field zero, and a nil
+// field is bound as NULL.
+const jsonColumnOption = "json"
+
+// parseColumnTag returns the column name of the column tag, and whether it is a JSON column.
+func parseColumnTag(tag string) (name string, isJSON bool) {
+	name, _, _ = strings.Cut(tag, ",")
+	return name, columnTagOption(tag, jsonColumnOption)
+}
+
+// ensure jsonColumn implements sql.Scanner.
+var _ sql.Scanner = (*jsonColumn)(nil) // compile time check
+
+// jsonColumn is a scan destination which unmarshals the JSON column into the field.
+type jsonColumn struct {
+	field reflect.Value
+}
+
+// Scan implements sql.Scanner.
+func (j jsonColumn) Scan(src any) error {
+	j.field.SetZero()
+	var data []byte
+	switch value := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = value
+	case string:
+		data = []byte(value)
+	default:
+		return fmt.Errorf("juice: can not unmarshal JSON column from %T", src)
+	}
+	return json.Unmarshal(data, j.field.Addr().Interface())
+}
+
+// parameterArg returns the argument of the named parameter bound by a #{} placeholder.
+// The fields of the JSON columns are marshalled to JSON strings, the values of the types
+// with a registered TypeHandler are converted by the handler,
+// and the values of the registered enum types are validated.
+func parameterArg(p Parameter, name string, value reflect.Value) (any, error) {
+	if err := validateEnum(name, value); err != nil {
+		return nil, err
+	}
+	if !isJSONParameter(p, name) {
+		if handler, typed := typeHandlerOf(value); handler != nil {
+			return handler.value(typed)
+		}
+		return value.Interface(), nil
+	}
+	if reflectlite.NilAble(value) && (!value.IsValid() || value.IsNil()) {
+		return nil, nil
+	}
+	data, err := json.Marshal(value.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("parameter %s: %w", name, err)
+	}
+	return string(data), nil
+}
+
+// isJSONParameter reports whether the named parameter is a field of a JSON column.
+func isJSONParameter(p Parameter, name string) bool {
+	sp, ok := p.(eval.StructFieldParameter)
+	if !ok {
+		return false
+	}
+	field, ok := sp.StructField(name)
+	if !ok {
+		return false
+	}
+	_, isJSON := parseColumnTag(field.Tag.Get("column"))
+	return isJSON
+}
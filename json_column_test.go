@@ -0,0 +1,88 @@
+package juice
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	juicedriver "github.com/go-juicedev/juice/driver"
+)
+
+type jsonUserMeta struct {
+	Tags  []string `json:"tags"`
+	Level int      `json:"level"`
+}
+
+type jsonUser struct {
+	ID   int64         `column:"id" param:"id"`
+	Meta *jsonUserMeta `column:"meta,json" param:"meta"`
+	Tags []string      `column:"tags,json"`
+}
+
+func TestParseColumnTag(t *testing.T) {
+	tests := []struct {
+		tag    string
+		name   string
+		isJSON bool
+	}{
+		{"id", "id", false},
+		{"meta,json", "meta", true},
+		{"meta,omitempty,json", "meta", true},
+		{"meta,jsonb", "meta", false},
+	}
+	for _, tt := range tests {
+		name, isJSON := parseColumnTag(tt.tag)
+		if name != tt.name || isJSON != tt.isJSON {
+			t.Errorf("%s: unexpected result: %s %v", tt.tag, name, isJSON)
+		}
+	}
+}
+
+func TestJSONColumn_Scan(t *testing.T) {
+	db, _ := newFakeDB(t, fakeResultSet{
+		columns: []string{"id", "meta", "tags"},
+		rows: [][]driver.Value{
+			{int64(1), []byte(`{"tags":["a","b"],"level":3}`), `["x"]`},
+			{int64(2), nil, nil},
+		},
+	})
+	executor := &GenericExecutor[[]jsonUser]{
+		SQLRowsExecutor: newFakeExecutor(db, newFakeStatement("SELECT id, meta, tags FROM user")),
+	}
+	users, err := executor.QueryContext(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []jsonUser{
+		{ID: 1, Meta: &jsonUserMeta{Tags: []string{"a", "b"}, Level: 3}, Tags: []string{"x"}},
+		{ID: 2},
+	}
+	if !reflect.DeepEqual(users, expected) {
+		t.Errorf("unexpected users: %+v", users)
+	}
+}
+
+func TestJSONColumn_Bind(t *testing.T) {
+	drv := juicedriver.MySQLDriver{}
+	node := NewTextNode("UPDATE user SET meta = #{meta}, tags = #{Tags} WHERE id = #{id}")
+	user := jsonUser{ID: 1, Meta: &jsonUserMeta{Tags: []string{"a"}, Level: 1}, Tags: []string{"x"}}
+	_, args, err := node.Accept(drv.Translator(), newGenericParam(user, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []any{`{"tags":["a"],"level":1}`, `["x"]`, int64(1)}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	// nil fields are bound as NULL, and nested fields are resolved as well.
+	node = NewTextNode("INSERT INTO user (meta) VALUES (#{user.meta})")
+	_, args, err = node.Accept(drv.Translator(), H{"user": jsonUser{}}.AsParam())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(args) != 1 || args[0] != nil {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
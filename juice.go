@@ -19,9 +19,11 @@ package juice
 import (
 	"context"
 	"database/sql"
+	"errors"
 
 	"github.com/go-juicedev/juice/cache"
 	"github.com/go-juicedev/juice/driver"
+	"github.com/go-juicedev/juice/session"
 )
 
 // Engine is the implementation of Manager interface and the core of juice.
@@ -48,6 +50,42 @@ type Engine struct {
 	// It is used to intercept the execution of the statements
 	// like logging, tracing, etc.
 	middlewares MiddlewareGroup
+
+	// rewriters is the sql rewriters of the engine
+	// It is used to rewrite the built sql and args before the middlewares
+	// like injecting a tenant predicate, rewriting table names, etc.
+	rewriters SQLRewriterGroup
+
+	// interceptors is the statement interceptors of the engine
+	// It is used to intercept the built sql of the statements selected by their attributes
+	// like filtering out the soft deleted rows, etc.
+	interceptors StatementInterceptorGroup
+
+	// paramProviders is the parameter providers of the engine
+	// It is used to provide the ambient parameters to every statement
+	// like the current user id, the tenant, etc.
+	paramProviders ParameterProviderGroup
+
+	// variants is the query variants of the engine
+	// It is used to route the select statements to their alternatives
+	// for the query performance experiments.
+	variants queryVariants
+
+	// poolMonitor is the connection pool monitor of the engine
+	// It is started by MonitorPool and stopped by Close.
+	poolMonitor *poolMonitor
+
+	// environmentDBs is the database connections of the other environments
+	// They are opened by PingEnvironmentContext and closed by Close.
+	environmentDBs environmentDBs
+
+	// columnNames is the mapping of the untagged struct fields of the results
+	// It is set by SetColumnNameMapper, nil if the untagged fields are skipped.
+	columnNames *columnNameMapping
+
+	// times is the time policy of the engine
+	// It is set by UseUTCTime and SetDefaultTimeLocation, nil if the times are passed through.
+	times *timePolicy
 }
 
 // sqlRowsExecutor represents a mapper sqlRowsExecutor with the given parameters
@@ -56,14 +94,36 @@ func (e *Engine) executor(v any) (*sqlRowsExecutor, error) {
 	if err != nil {
 		return nil, err
 	}
-	handler := NewDefaultStatementHandler(e.driver, e.DB(), e.middlewares...)
+	sess, drv, err := e.dataSource(stat)
+	if err != nil {
+		return nil, err
+	}
+	handler := e.statementHandler(drv, sess)
 	return &sqlRowsExecutor{
 		statement:        stat,
 		statementHandler: handler,
-		driver:           e.driver,
+		driver:           drv,
+		columnNames:      e.columnNames,
+		times:            e.times,
 	}, nil
 }
 
+// statementHandler returns a StatementHandler which executes the statements with the given driver and session.
+func (e *Engine) statementHandler(drv driver.Driver, sess session.Session) StatementHandler {
+	return &DefaultStatementHandler{
+		driver:      drv,
+		middlewares: e.middlewares,
+		builder: statementBuilder{
+			paramProviders: e.paramProviders,
+			interceptors:   e.interceptors,
+			rewriters:      e.rewriters,
+			variants:       e.variants,
+			times:          e.times,
+		},
+		session: sess,
+	}
+}
+
 // Object implements the Manager interface
 func (e *Engine) Object(v any) SQLRowsExecutor {
 	exe, err := e.executor(v)
@@ -117,6 +177,33 @@ func (e *Engine) Use(middleware Middleware) {
 	e.middlewares = append(e.middlewares, middleware)
 }
 
+// UseSQLRewriter adds a SQLRewriter to the engine.
+// The rewriters are called in the order they were added,
+// after the statement is built and before the middlewares are executed.
+func (e *Engine) UseSQLRewriter(rewriter SQLRewriter) {
+	e.rewriters = append(e.rewriters, rewriter)
+}
+
+// UseStatementInterceptor adds a StatementInterceptor to the engine.
+// The interceptors are called in the order they were added,
+// after the statement is built and before the rewriters are executed.
+func (e *Engine) UseStatementInterceptor(interceptor StatementInterceptor) {
+	e.interceptors = append(e.interceptors, interceptor)
+}
+
+// UseParameterProvider adds a ParameterProvider to the engine.
+// The provided parameters are merged with the per-call parameter with lower precedence.
+// The providers added earlier take precedence over the later ones.
+func (e *Engine) UseParameterProvider(provider ParameterProvider) {
+	e.paramProviders = append(e.paramProviders, provider)
+}
+
+// UseFlagProvider sets the FlagProvider of the if nodes with the flag attribute.
+// The flags are provided the same way as the parameters of a ParameterProvider.
+func (e *Engine) UseFlagProvider(provider FlagProvider) {
+	e.UseParameterProvider(flagParameterProvider{flags: provider})
+}
+
 // DB returns the database connection of the engine
 func (e *Engine) DB() *sql.DB {
 	return e.db
@@ -127,12 +214,41 @@ func (e *Engine) Driver() driver.Driver {
 	return e.driver
 }
 
+// MonitorPool starts a background monitor which samples the statistics of the connection pool
+// every interval, and calls the handler when the pool is saturated, so that the operators get
+// early warning before the queries time out. For example:
+//
+//	engine.MonitorPool(func(saturation juice.PoolSaturation) {
+//	    log.Printf("pool saturated: in use %d/%d, waited %d",
+//	        saturation.Stats.InUse, saturation.Stats.MaxOpenConnections, saturation.WaitCount)
+//	}, juice.PoolMonitorOptions{Interval: 5 * time.Second})
+//
+// The monitor is stopped when the engine is closed. Calling it again replaces the previous monitor.
+// It is not goroutine safe, so it should be called before the engine is used.
+func (e *Engine) MonitorPool(handler PoolSaturationHandler, options PoolMonitorOptions) {
+	if handler == nil {
+		panic("pool saturation handler is nil")
+	}
+	if e.poolMonitor != nil {
+		e.poolMonitor.close()
+	}
+	e.poolMonitor = newPoolMonitor(e.db.Stats, handler, options)
+	e.poolMonitor.start()
+}
+
 // Close closes the database connection if it is not nil.
+// It also stops the pool monitor started by MonitorPool,
+// and closes the connections of the other environments opened by PingContext.
 func (e *Engine) Close() error {
+	if e.poolMonitor != nil {
+		e.poolMonitor.close()
+		e.poolMonitor = nil
+	}
+	err := e.environmentDBs.close()
 	if e.db != nil {
-		return e.db.Close()
+		err = errors.Join(e.db.Close(), err)
 	}
-	return nil
+	return err
 }
 
 // SetLocker sets the locker of the engine
@@ -174,7 +290,7 @@ func New(configuration IConfiguration) (*Engine, error) {
 		return nil, err
 	}
 	// add the default middlewares
-	engine.Use(&useGeneratedKeysMiddleware{})
+	engine.Use(&useGeneratedKeysMiddleware{driver: engine.driver})
 	return engine, nil
 }
 
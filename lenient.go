@@ -0,0 +1,77 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import "github.com/go-juicedev/juice/driver"
+
+// lenientExpressionsKey is the setting and statement attribute name which enables the lenient
+// mode of the expressions, for the forward compatibility with the expressions which are not
+// supported by the evaluator yet:
+//
+//	<settings>
+//	    <setting name="lenientExpressions" value="true"/>
+//	</settings>
+//
+// Or per statement:
+//
+//	<select id="QueryUsers" lenientExpressions="true">...</select>
+//
+// In lenient mode, the conditions of the if and when nodes whose expressions are not supported,
+// see eval.ErrUnsupportedExpression, are treated as false instead of failing the build.
+// The other contexts, like the foreach collections, the #{} parameters, the ${} substitutions
+// and the bind values, always fail, since they can not be skipped safely. The other errors,
+// like the undefined identifiers, always fail too. The default mode is strict.
+const lenientExpressionsKey = "lenientExpressions"
+
+// lenientTranslator is a driver.Translator which carries the lenient mode of the statement to the nodes.
+type lenientTranslator struct {
+	driver.Translator
+}
+
+// lenientExpressions implements lenientChecker.
+func (lenientTranslator) lenientExpressions() bool { return true }
+
+// Unwrap returns the wrapped translator, so that the capabilities of the driver are reachable.
+func (t lenientTranslator) Unwrap() driver.Translator {
+	return t.Translator
+}
+
+// lenientChecker reports whether the expressions are evaluated in lenient mode.
+type lenientChecker interface {
+	lenientExpressions() bool
+}
+
+// ensure lenientTranslator implements lenientChecker.
+var _ lenientChecker = lenientTranslator{} // compile time check
+
+// lenientExpressions reports whether the translator carries the lenient mode.
+func lenientExpressions(translator driver.Translator) bool {
+	checker, ok := driver.TranslatorAs[lenientChecker](translator)
+	return ok && checker.lenientExpressions()
+}
+
+// statementLenientExpressions reports whether the lenient mode is enabled for the statement,
+// the attribute of the statement takes precedence over the setting.
+func statementLenientExpressions(statement Statement) bool {
+	if attribute := statement.Attribute(lenientExpressionsKey); attribute != "" {
+		return StringValue(attribute).Bool()
+	}
+	if cfg := statement.Configuration(); cfg != nil {
+		return cfg.Settings().Get(lenientExpressionsKey).Bool()
+	}
+	return false
+}
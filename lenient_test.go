@@ -0,0 +1,56 @@
+package juice
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-juicedev/juice/driver"
+	"github.com/go-juicedev/juice/eval"
+)
+
+func TestLenientExpressions(t *testing.T) {
+	stmt := parseTestStatement(t, Select, `<select id="QueryUsers">
+		SELECT * FROM user
+		<where>
+			<if test="[]int{1}">AND debug = 1</if>
+			<choose>
+				<when test="func() {}">AND id = #{id}</when>
+				<otherwise>AND status = 1</otherwise>
+			</choose>
+		</where>
+	</select>`)
+	translator := driver.MySQLDriver{}.Translator()
+
+	// strict by default.
+	if _, _, err := stmt.Build(translator, H{"id": 1}); !errors.Is(err, eval.ErrUnsupportedExpression) {
+		t.Fatalf("expected ErrUnsupportedExpression, got %v", err)
+	}
+
+	stmt.mapper.mappers.cfg = &Configuration{settings: keyValueSettingProvider{lenientExpressionsKey: "true"}}
+	query, _, err := stmt.Build(translator, H{"id": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM user WHERE status = 1" {
+		t.Errorf("unexpected query: %s", query)
+	}
+
+	// the attribute of the statement takes precedence over the setting.
+	stmt.setAttribute(lenientExpressionsKey, "false")
+	if _, _, err = stmt.Build(translator, H{"id": 1}); !errors.Is(err, eval.ErrUnsupportedExpression) {
+		t.Errorf("expected ErrUnsupportedExpression, got %v", err)
+	}
+}
+
+func TestLenientExpressions_OtherErrors(t *testing.T) {
+	stmt := parseTestStatement(t, Select, `<select id="QueryUsers" lenientExpressions="true">
+		SELECT * FROM user
+		<where>
+			<if test="unknown > 0">AND id = #{id}</if>
+		</where>
+	</select>`)
+	// the undefined identifiers are not tolerated.
+	if _, _, err := stmt.Build(driver.MySQLDriver{}.Translator(), H{"id": 1}); err == nil || errors.Is(err, eval.ErrUnsupportedExpression) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"context"
+	"errors"
+)
+
+// LoadMany loads the values of the given keys with one query and indexes them by key.
+// It is designed for dataloader-style patterns to avoid N+1 queries.
+//
+// The keys are passed to the executor as the parameter, so the statement
+// should iterate over them with a foreach node, for example:
+//
+//	<select id="GetUsersByIDs">
+//	    SELECT * FROM user WHERE id IN
+//	    <foreach collection="param" item="id" open="(" separator="," close=")">
+//	        #{id}
+//	    </foreach>
+//	</select>
+//
+// Use the paramName attribute of the statement to rename the collection.
+//
+// The keyOf function returns the key of a loaded value.
+// Keys without any matching value are absent from the returned map.
+// If more than one value has the same key, the last one wins.
+// If keys is empty, no query is executed and an empty map is returned.
+func LoadMany[K comparable, V any](ctx context.Context, executor Executor[[]V], keys []K, keyOf func(V) K) (map[K]V, error) {
+	if executor == nil {
+		return nil, ErrInvalidExecutor
+	}
+	if keyOf == nil {
+		return nil, errors.New("juice: keyOf function is nil")
+	}
+	if len(keys) == 0 {
+		return map[K]V{}, nil
+	}
+	values, err := executor.QueryContext(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[K]V, len(values))
+	for _, value := range values {
+		result[keyOf(value)] = value
+	}
+	return result, nil
+}
@@ -0,0 +1,78 @@
+package juice
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/go-juicedev/juice/driver"
+)
+
+type user struct {
+	ID   int64
+	Name string
+}
+
+// staticExecutor is an Executor which always returns the given result.
+type staticExecutor[T any] struct {
+	result T
+	param  Param
+	called bool
+}
+
+func (s *staticExecutor[T]) QueryContext(_ context.Context, param Param) (T, error) {
+	s.param = param
+	s.called = true
+	return s.result, nil
+}
+
+func (s *staticExecutor[T]) ExecContext(context.Context, Param) (sql.Result, error) {
+	return nil, nil
+}
+
+func (s *staticExecutor[T]) Statement() Statement { return nil }
+
+func (s *staticExecutor[T]) Driver() driver.Driver { return nil }
+
+func TestLoadMany(t *testing.T) {
+	executor := &staticExecutor[[]user]{result: []user{
+		{ID: 1, Name: "a"},
+		{ID: 2, Name: "b"},
+		{ID: 2, Name: "c"},
+	}}
+	keys := []int64{1, 2, 3}
+	result, err := LoadMany(context.Background(), executor, keys, func(u user) int64 { return u.ID })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("unexpected result: %v", result)
+	}
+	if result[1].Name != "a" {
+		t.Errorf("unexpected value of key 1: %v", result[1])
+	}
+	// the last one wins
+	if result[2].Name != "c" {
+		t.Errorf("unexpected value of key 2: %v", result[2])
+	}
+	if _, exists := result[3]; exists {
+		t.Error("missing key should be absent")
+	}
+	if param, ok := executor.param.([]int64); !ok || len(param) != 3 {
+		t.Errorf("unexpected param: %v", executor.param)
+	}
+}
+
+func TestLoadManyEmptyKeys(t *testing.T) {
+	executor := &staticExecutor[[]user]{}
+	result, err := LoadMany(context.Background(), executor, nil, func(u user) int64 { return u.ID })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result == nil || len(result) != 0 {
+		t.Errorf("unexpected result: %v", result)
+	}
+	if executor.called {
+		t.Error("executor should not be called with empty keys")
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-juicedev/juice/session"
+)
+
+const (
+	// localeParamKey is the name of the parameter of the locale of the context.
+	localeParamKey = "_locale"
+
+	// timeZoneParamKey is the name of the parameter of the time zone of the context.
+	timeZoneParamKey = "_timezone"
+)
+
+// ErrTimeZoneOutsideTransaction is an error that is returned by the TimeZoneMiddleware
+// when a statement with a context time zone is not executed in a transaction.
+var ErrTimeZoneOutsideTransaction = errors.New("time zone requires a transaction")
+
+type localeKey struct{}
+
+type timeZoneKey struct{}
+
+// ContextWithLocale returns a new context with the given locale, like "de-DE".
+// It is provided to the statements as #{_locale} by the LocaleParameterProvider.
+func ContextWithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFromContext returns the locale from the context.
+// It returns false if no locale is set.
+func LocaleFromContext(ctx context.Context) (string, bool) {
+	locale, ok := ctx.Value(localeKey{}).(string)
+	return locale, ok
+}
+
+// ContextWithTimeZone returns a new context with the given time zone.
+// It is provided to the statements as #{_timezone} by the LocaleParameterProvider,
+// and is set as the time zone of the transaction by the TimeZoneMiddleware.
+func ContextWithTimeZone(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, timeZoneKey{}, loc)
+}
+
+// TimeZoneFromContext returns the time zone from the context.
+// It returns false if no time zone is set.
+func TimeZoneFromContext(ctx context.Context) (*time.Location, bool) {
+	loc, ok := ctx.Value(timeZoneKey{}).(*time.Location)
+	return loc, ok && loc != nil
+}
+
+// ensure LocaleParameterProvider implements ParameterProvider.
+var _ ParameterProvider = (*LocaleParameterProvider)(nil) // compile time check
+
+// LocaleParameterProvider is a ParameterProvider which provides the locale and the
+// time zone of the context as #{_locale} and #{_timezone}, the name of the time zone
+// like "Europe/Berlin". Only the ones set in the context are provided:
+//
+//	engine.UseParameterProvider(juice.LocaleParameterProvider{})
+//
+//	<select id="QueryProducts">
+//	    select * from product order by name collate #{_locale}
+//	</select>
+type LocaleParameterProvider struct{}
+
+// Provide implements ParameterProvider.
+func (LocaleParameterProvider) Provide(ctx context.Context) (Parameter, error) {
+	provided := make(H, 2)
+	if locale, ok := LocaleFromContext(ctx); ok {
+		provided[localeParamKey] = locale
+	}
+	if loc, ok := TimeZoneFromContext(ctx); ok {
+		provided[timeZoneParamKey] = loc.String()
+	}
+	return provided.AsParam(), nil
+}
+
+// PostgresTimeZone returns the statement which sets the time zone of the current
+// transaction of PostgreSQL, it is reset when the transaction ends.
+func PostgresTimeZone(loc *time.Location) (query string, args []any) {
+	return "SELECT set_config('TimeZone', $1, true)", []any{loc.String()}
+}
+
+// ensure TimeZoneMiddleware implements Middleware.
+var _ Middleware = (*TimeZoneMiddleware)(nil) // compile time check
+
+// TimeZoneMiddleware is a middleware that sets the time zone of the context before
+// the statements are executed, so that the functions like now() and the conversions of
+// the timestamps are evaluated in the time zone of the caller:
+//
+//	engine.Use(juice.TimeZoneMiddleware{Statement: juice.PostgresTimeZone})
+//
+//	ctx = juice.ContextWithTimeZone(ctx, loc)
+//	err = engine.WithTx(ctx, func(tx juice.TxManager) error { ... })
+//
+// The time zone is a setting of the connection, while the connections of *sql.DB are
+// pooled and reused by the other callers. So it is only set in a transaction, which pins
+// one connection, and the Statement must be scoped to the transaction, like the one of
+// PostgresTimeZone, so that the connection is reset when it is returned to the pool.
+// The session-scoped statements, like SET time_zone of MySQL, must not be used since
+// they leak to the next user of the connection.
+//
+// ErrTimeZoneOutsideTransaction is returned if the context has a time zone but the
+// statement is not executed in a transaction. The contexts without a time zone are
+// passed through.
+type TimeZoneMiddleware struct {
+	// Statement returns the transaction-scoped statement which sets the time zone.
+	Statement func(loc *time.Location) (query string, args []any)
+}
+
+// QueryContext implements Middleware.
+// QueryContext will set the time zone of the transaction before the query.
+func (m TimeZoneMiddleware) QueryContext(_ Statement, next QueryHandler) QueryHandler {
+	return func(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+		if err := m.setTimeZone(ctx); err != nil {
+			return nil, err
+		}
+		return next(ctx, query, args...)
+	}
+}
+
+// ExecContext implements Middleware.
+// ExecContext will set the time zone of the transaction before the exec.
+func (m TimeZoneMiddleware) ExecContext(_ Statement, next ExecHandler) ExecHandler {
+	return func(ctx context.Context, query string, args ...any) (sql.Result, error) {
+		if err := m.setTimeZone(ctx); err != nil {
+			return nil, err
+		}
+		return next(ctx, query, args...)
+	}
+}
+
+// setTimeZone sets the time zone of the context on the transaction of the context.
+func (m TimeZoneMiddleware) setTimeZone(ctx context.Context) error {
+	loc, ok := TimeZoneFromContext(ctx)
+	if !ok || m.Statement == nil {
+		return nil
+	}
+	sess, err := session.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok = sess.(session.Transaction); !ok {
+		return ErrTimeZoneOutsideTransaction
+	}
+	query, args := m.Statement(loc)
+	_, err = sess.ExecContext(ctx, query, args...)
+	return err
+}
@@ -0,0 +1,76 @@
+package juice
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-juicedev/juice/session"
+)
+
+func TestLocaleParameterProvider(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	ctx := ContextWithTimeZone(ContextWithLocale(context.Background(), "de-DE"), loc)
+	param, err := LocaleParameterProvider{}.Provide(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, ok := param.Get("_locale"); !ok || value.Interface() != "de-DE" {
+		t.Errorf("unexpected locale: %v", value)
+	}
+	if value, ok := param.Get("_timezone"); !ok || value.Interface() != "Europe/Berlin" {
+		t.Errorf("unexpected time zone: %v", value)
+	}
+
+	param, err = LocaleParameterProvider{}.Provide(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := param.Get("_locale"); ok {
+		t.Error("expected no locale without the context value")
+	}
+}
+
+func TestTimeZoneMiddleware(t *testing.T) {
+	db, state := newFakeDB(t, fakeResultSet{})
+	middleware := TimeZoneMiddleware{Statement: PostgresTimeZone}
+	stmt := newFakeStatement("UPDATE user SET name = $1")
+	handler := middleware.ExecContext(stmt, func(ctx context.Context, query string, args ...any) (sql.Result, error) {
+		sess, err := session.FromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return sess.ExecContext(ctx, query, args...)
+	})
+	ctx := ContextWithTimeZone(context.Background(), time.UTC)
+
+	// the time zone is not set outside a transaction.
+	if _, err := handler(session.WithContext(ctx, db), "UPDATE user SET name = $1", "alice"); !errors.Is(err, ErrTimeZoneOutsideTransaction) {
+		t.Errorf("expected ErrTimeZoneOutsideTransaction, got %v", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err = handler(session.WithContext(ctx, tx), "UPDATE user SET name = $1", "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if len(state.executions) != 2 {
+		t.Fatalf("expected 2 executions, got %d", len(state.executions))
+	}
+	if setTimeZone := state.executions[0]; setTimeZone.query != "SELECT set_config('TimeZone', $1, true)" || setTimeZone.args[0] != "UTC" {
+		t.Errorf("unexpected time zone statement: %v", setTimeZone)
+	}
+
+	// the contexts without a time zone are passed through.
+	if _, err = handler(session.WithContext(context.Background(), db), "UPDATE user SET name = $1", "bob"); err != nil {
+		t.Fatal(err)
+	}
+}
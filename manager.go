@@ -35,10 +35,11 @@ type GenericManager[T any] interface {
 }
 
 // NewGenericManager returns a new GenericManager.
-func NewGenericManager[T any](manager Manager) GenericManager[T] {
-	m := &genericManager[T]{Manager: manager}
-	if tcm, ok := manager.(TxCacheManager); ok {
-		m.cache = tcm.Cache()
+// The hooks are invoked in order with the mapped result of every query executed by the GenericManager.
+func NewGenericManager[T any](manager Manager, hooks ...ResultHook[T]) GenericManager[T] {
+	m := &genericManager[T]{Manager: manager, hooks: hooks}
+	if cm, ok := manager.(CacheManager); ok {
+		m.cache = cm.Cache()
 	}
 	return m
 }
@@ -47,6 +48,7 @@ func NewGenericManager[T any](manager Manager) GenericManager[T] {
 type genericManager[T any] struct {
 	Manager
 	cache cache.ScopeCache
+	hooks ResultHookGroup[T]
 }
 
 // Object implements the GenericManager interface.
@@ -54,6 +56,7 @@ func (s *genericManager[T]) Object(v any) Executor[T] {
 	exe := &GenericExecutor[T]{
 		SQLRowsExecutor: s.Manager.Object(v),
 		cache:           s.cache,
+		hooks:           s.hooks,
 	}
 	return exe
 }
@@ -95,12 +98,17 @@ func (t *txManager) Object(v any) SQLRowsExecutor {
 	if err != nil {
 		return inValidExecutor(err)
 	}
+	if err = checkTxDataSource(t.engine.configuration, stat); err != nil {
+		return inValidExecutor(err)
+	}
 	drv := t.engine.driver
-	handler := NewDefaultStatementHandler(drv, t.tx, t.engine.middlewares...)
+	handler := t.engine.statementHandler(drv, t.tx)
 	return &sqlRowsExecutor{
 		statement:        stat,
 		statementHandler: handler,
 		driver:           drv,
+		columnNames:      t.engine.columnNames,
+		times:            t.engine.times,
 	}
 }
 
@@ -136,6 +144,35 @@ func (t *txManager) Rollback() error {
 	return t.tx.Rollback()
 }
 
+// CacheManager defines a manager whose query results are cached by its scopeCache,
+// when it is used by NewGenericManager.
+type CacheManager interface {
+	Manager
+	Cache() cache.ScopeCache
+}
+
+// cacheManager implements the CacheManager interface.
+type cacheManager struct {
+	Manager
+	cache cache.ScopeCache
+}
+
+// Cache returns the scopeCache of the CacheManager.
+func (c *cacheManager) Cache() cache.ScopeCache {
+	return c.cache
+}
+
+// NewCacheManager returns a new CacheManager, whose scopeCache is used out of the transactions,
+// like a second-level cache shared by the instances of an application. For example:
+//
+//	manager := juice.NewCacheManager(engine, cache.RedisScopeCache(client, cache.RedisWithTTL(time.Minute)))
+//	users, err := juice.NewGenericManager[[]User](manager).Object(QueryUsers).QueryContext(ctx, param)
+//
+// The cached results of a mapper namespace are flushed after a write statement of the namespace is executed.
+func NewCacheManager(manager Manager, cache cache.ScopeCache) CacheManager {
+	return &cacheManager{Manager: manager, cache: cache}
+}
+
 // TxCacheManager defines a transactional scopeCache manager whose scopeCache can be accessed.
 // All queries in the transaction will be cached.
 // scopeCache.Flush() will be called after Commit() or Rollback().
@@ -155,10 +155,36 @@ func (m *Mappers) GetSQLNodeByID(id string) (Node, error) {
 	return node, nil
 }
 
+// StatementID is the typed id of a statement, which is the namespace of its mapper and its id
+// joined by a dot, with the prefix of the mappers if any. It can be passed to Object and
+// GetStatement in place of the string id.
+//
+// The generated code declares the ids of the parsed mappers as constants, so that a typo is
+// caught by the compiler instead of failing at runtime with ErrStatementNotFound:
+//
+//	const (
+//	    UserMapperQueryUser juice.StatementID = "main.UserMapper.QueryUser"
+//	)
+//
+//	user, err := juice.NewGenericManager[User](engine).Object(UserMapperQueryUser).QueryContext(ctx, param)
+//
+// The string ids are still accepted.
+type StatementID string
+
+// StatementID returns the id as a string.
+func (id StatementID) StatementID() string {
+	return string(id)
+}
+
+// String implements fmt.Stringer.
+func (id StatementID) String() string {
+	return string(id)
+}
+
 // GetStatement try to one the xmlSQLStatement from the Mappers with the given interface
 func (m *Mappers) GetStatement(v any) (Statement, error) {
 	var id string
-	// if the interface is StatementIDGetter, use the StatementID() method to get the id
+	// if the interface is StatementIDGetter, like StatementID, use the StatementID() method to get the id
 	// or if the interface is a string type, use the string as the id
 	// otherwise, use the reflection to get the id
 	switch t := v.(type) {
@@ -0,0 +1,30 @@
+package juice
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMappers_GetStatement_StatementID(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config/juice.xml":          {Data: []byte(reloadableConfigXML)},
+		"config/mappers/mapper.xml": {Data: reloadableMapperXML("SELECT * FROM user")},
+	}
+	cfg, err := NewXMLConfigurationWithFS(fsys, "config/juice.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const queryUser StatementID = "main.Repository.QueryUser"
+	stmt, err := cfg.GetStatement(queryUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stmt.Name() != queryUser.String() {
+		t.Errorf("unexpected statement: %s", stmt.Name())
+	}
+	_, err = cfg.GetStatement(StatementID("main.Repository.QueryUsers"))
+	if !errors.As(err, new(*ErrStatementNotFound)) {
+		t.Errorf("expected ErrStatementNotFound, got %v", err)
+	}
+}
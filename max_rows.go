@@ -0,0 +1,76 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// maxRowsKey is the setting and statement attribute name which caps the number of rows
+// mapped from the result of a select statement, as a safety net against the missing LIMITs:
+//
+//	<settings>
+//	    <setting name="maxRows" value="10000"/>
+//	</settings>
+//
+// Or per statement, which takes precedence over the setting, where 0 turns the cap off:
+//
+//	<select id="ExportUsers" maxRows="0">...</select>
+//
+// The cap applies to the slice results mapped by the default result maps, a single row result
+// is already guarded by ErrTooManyRows. The rows returned by SQLRowsExecutor are not capped.
+const maxRowsKey = "maxRows"
+
+// ErrMaxRowsExceeded is an error that is returned when the result of a select statement has
+// more rows than the maxRows setting or attribute allows.
+var ErrMaxRowsExceeded = errors.New("juice: max rows exceeded")
+
+// maxRowsError returns the error of a result which has more than maxRows rows.
+func maxRowsError(maxRows int) error {
+	return fmt.Errorf("%w: more than %d rows", ErrMaxRowsExceeded, maxRows)
+}
+
+// statementMaxRows returns the row cap of the statement, 0 means no cap.
+func statementMaxRows(statement Statement) int {
+	if !statement.Action().ForRead() {
+		return 0
+	}
+	value := StringValue(statement.Attribute(maxRowsKey))
+	if value == "" {
+		if cfg := statement.Configuration(); cfg != nil {
+			value = cfg.Settings().Get(maxRowsKey)
+		}
+	}
+	return max(int(value.Int64()), 0)
+}
+
+// maxRowsResultMap returns the default result map of T which fails once more than maxRows rows
+// are mapped, it keeps the partial results mode of the given result map.
+// It returns the given result map if T is not a slice.
+func maxRowsResultMap[T any](resultMap ResultMap, maxRows int) ResultMap {
+	tp := reflect.TypeOf((*T)(nil)).Elem()
+	if tp.Kind() != reflect.Slice {
+		return resultMap
+	}
+	if isStringAnyMap(tp.Elem()) {
+		return MapResultMap{MaxRows: maxRows}
+	}
+	partial, _ := resultMap.(MultiRowsResultMap)
+	return MultiRowsResultMap{Partial: partial.Partial, MaxRows: maxRows}
+}
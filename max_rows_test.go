@@ -0,0 +1,86 @@
+package juice
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	juicedriver "github.com/go-juicedev/juice/driver"
+)
+
+func TestMaxRows(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config/juice.xml": {Data: []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<configuration>
+    <environments default="prod">
+        <environment id="prod">
+            <dataSource>fake</dataSource>
+            <driver>fake</driver>
+        </environment>
+    </environments>
+    <settings>
+        <setting name="maxRows" value="2"/>
+    </settings>
+    <mappers pattern="mappers/*.xml"/>
+</configuration>`)},
+		"config/mappers/mapper.xml": {Data: []byte(`<?xml version="1.0" encoding="utf-8" ?>
+<mapper namespace="main.Repository">
+    <select id="QueryUsers">SELECT id FROM user</select>
+    <select id="ExportUsers" maxRows="0">SELECT id FROM user</select>
+    <select id="QueryFewUsers" maxRows="3">SELECT id FROM user</select>
+</mapper>`)},
+	}
+	cfg, err := NewXMLConfigurationWithFS(fsys, "config/juice.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, _ := newFakeDB(t, fakeResultSet{
+		columns: []string{"id"},
+		rows:    [][]driver.Value{{int64(1)}, {int64(2)}, {int64(3)}},
+	})
+	engine := &Engine{configuration: cfg, driver: juicedriver.MySQLDriver{}, db: db, rw: &NoOpRWMutex{}}
+	ctx := context.Background()
+
+	type user struct {
+		ID int64 `column:"id"`
+	}
+	_, err = NewGenericManager[[]user](engine).Object("main.Repository.QueryUsers").QueryContext(ctx, nil)
+	if !errors.Is(err, ErrMaxRowsExceeded) {
+		t.Errorf("expected ErrMaxRowsExceeded, got %v", err)
+	}
+	_, err = NewGenericManager[[]int64](engine).Object("main.Repository.QueryUsers").QueryContext(ctx, nil)
+	if !errors.Is(err, ErrMaxRowsExceeded) {
+		t.Errorf("expected ErrMaxRowsExceeded for scalars, got %v", err)
+	}
+	_, err = NewGenericManager[[]map[string]any](engine).Object("main.Repository.QueryUsers").QueryContext(ctx, nil)
+	if !errors.Is(err, ErrMaxRowsExceeded) {
+		t.Errorf("expected ErrMaxRowsExceeded for maps, got %v", err)
+	}
+
+	// the attribute of the statement takes precedence over the setting.
+	for _, id := range []string{"main.Repository.ExportUsers", "main.Repository.QueryFewUsers"} {
+		users, err := NewGenericManager[[]user](engine).Object(id).QueryContext(ctx, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+		if len(users) != 3 {
+			t.Errorf("%s: expected 3 users, got %d", id, len(users))
+		}
+	}
+}
+
+func TestMultiRowsResultMap_MaxRows(t *testing.T) {
+	rows := queryFakeRows(t, fakeResultSet{
+		columns: []string{"id"},
+		rows:    [][]driver.Value{{int64(1)}, {int64(2)}},
+	})
+	ids, err := BindWithResultMap[[]int64](rows, MultiRowsResultMap{MaxRows: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 {
+		t.Errorf("expected 2 ids, got %v", ids)
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+)
+
+// ErrNamespaceConflict indicates that the same mapper namespace is declared by more than one configuration.
+var ErrNamespaceConflict = errors.New("mapper namespace conflict")
+
+// ErrEnvironmentConflict indicates that the environments of the configurations are incompatible.
+var ErrEnvironmentConflict = errors.New("environment conflict")
+
+// MergeConfigurations merges the configurations into one configuration,
+// which lets the modules of an application contribute their own mappers to a single engine.
+//
+// The configurations must be created by NewXMLConfiguration, NewXMLConfigurationWithFS
+// or parsed by XMLParser, and they must not be used on their own after merging,
+// since their mappers are bound to the merged configuration.
+//
+// The following rules are applied:
+//   - mapper namespaces must be unique across all the configurations, otherwise ErrNamespaceConflict is returned.
+//   - environments with the same id must have the same driver, data source and properties,
+//     and the default environments must agree, otherwise ErrEnvironmentConflict is returned.
+//     A configuration without environments, like the one of a plugin, is always compatible.
+//   - settings are merged, the setting of the configuration given first takes precedence.
+func MergeConfigurations(configurations ...IConfiguration) (IConfiguration, error) {
+	merged := &mergedConfiguration{
+		environments: &environments{envs: make(map[string]*Environment)},
+		settings:     make(keyValueSettingProvider),
+	}
+	namespaces := make(map[string]struct{})
+	for _, configuration := range configurations {
+		var cfg *Configuration
+		switch c := configuration.(type) {
+		case *Configuration:
+			cfg = c
+		case Configuration:
+			cfg = &c
+		default:
+			return nil, fmt.Errorf("can not merge configuration of type %T", configuration)
+		}
+		if err := merged.mergeEnvironments(cfg.environments); err != nil {
+			return nil, err
+		}
+		for name, value := range cfg.settings {
+			if _, exists := merged.settings[name]; !exists {
+				merged.settings[name] = value
+			}
+		}
+		if cfg.mappers == nil {
+			continue
+		}
+		if cfg.mappers.mappers != nil {
+			for _, kv := range cfg.mappers.mappers.All() {
+				if _, exists := namespaces[kv.Key]; exists {
+					return nil, fmt.Errorf("%w: mapper %s is declared more than once", ErrNamespaceConflict, kv.Key)
+				}
+				namespaces[kv.Key] = struct{}{}
+			}
+		}
+		merged.mappers = append(merged.mappers, cfg.mappers)
+	}
+	// bind the mappers to the merged configuration after all checks passed.
+	for _, mappers := range merged.mappers {
+		mappers.cfg = merged
+	}
+	return merged, nil
+}
+
+// mergedConfiguration is a configuration composed of the mappers of several configurations.
+type mergedConfiguration struct {
+	environments *environments
+	settings     keyValueSettingProvider
+	mappers      []*Mappers
+}
+
+// mergeEnvironments merges the environments into the merged configuration.
+func (m *mergedConfiguration) mergeEnvironments(envs *environments) error {
+	if envs == nil {
+		return nil
+	}
+	if defaultEnv := envs.Attribute("default"); defaultEnv != "" {
+		current := m.environments.Attribute("default")
+		if current != "" && current != defaultEnv {
+			return fmt.Errorf("%w: default environment %s and %s", ErrEnvironmentConflict, current, defaultEnv)
+		}
+		m.environments.setAttr("default", defaultEnv)
+	}
+	for id, env := range envs.envs {
+		current, exists := m.environments.envs[id]
+		if !exists {
+			m.environments.envs[id] = env
+			continue
+		}
+		if current.Driver != env.Driver || current.DataSource != env.DataSource || !maps.Equal(current.Properties, env.Properties) {
+			return fmt.Errorf("%w: environment %s has different driver, data source or properties", ErrEnvironmentConflict, id)
+		}
+	}
+	for key, value := range envs.attr {
+		if _, exists := m.environments.attr[key]; !exists {
+			m.environments.setAttr(key, value)
+		}
+	}
+	return nil
+}
+
+// Environments returns the merged environments.
+func (m *mergedConfiguration) Environments() EnvironmentProvider {
+	return m.environments
+}
+
+// Settings returns the merged settings.
+func (m *mergedConfiguration) Settings() SettingProvider {
+	return m.settings
+}
+
+// GetStatement returns the statement of the given value from the mappers which declare its namespace.
+func (m *mergedConfiguration) GetStatement(v any) (Statement, error) {
+	var err error
+	for _, mappers := range m.mappers {
+		var statement Statement
+		statement, err = mappers.GetStatement(v)
+		if err == nil {
+			return statement, nil
+		}
+		var notFound ErrMapperNotFound
+		if !errors.As(err, &notFound) {
+			return nil, err
+		}
+	}
+	if err == nil {
+		err = errors.New("no mappers configured")
+	}
+	return nil, err
+}
@@ -0,0 +1,107 @@
+package juice
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func mergeTestConfiguration(t *testing.T, environments, settings, namespace string) IConfiguration {
+	t.Helper()
+	fsys := fstest.MapFS{
+		"juice.xml": {Data: []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<configuration>` + environments + settings + `
+    <mappers><mapper resource="mapper.xml"/></mappers>
+</configuration>`)},
+		"mapper.xml": {Data: []byte(`<?xml version="1.0" encoding="utf-8" ?>
+<mapper namespace="` + namespace + `">
+    <select id="QueryUser">SELECT * FROM user</select>
+</mapper>`)},
+	}
+	cfg, err := NewXMLConfigurationWithFS(fsys, "juice.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+const mergeTestEnvironments = `
+    <environments default="prod">
+        <environment id="prod">
+            <dataSource>fake</dataSource>
+            <driver>fake</driver>
+        </environment>
+    </environments>`
+
+func TestMergeConfigurations(t *testing.T) {
+	app := mergeTestConfiguration(t, mergeTestEnvironments, `
+    <settings><setting name="debug" value="false"/></settings>`, "main.UserMapper")
+	plugin := mergeTestConfiguration(t, "", `
+    <settings><setting name="debug" value="true"/><setting name="maxLimit" value="100"/></settings>`, "plugin.OrderMapper")
+
+	merged, err := MergeConfigurations(app, plugin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"main.UserMapper.QueryUser", "plugin.OrderMapper.QueryUser"} {
+		statement, err := merged.GetStatement(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if statement.Configuration() != merged {
+			t.Errorf("statement %s is not bound to the merged configuration", id)
+		}
+	}
+	if _, err = merged.GetStatement("other.Mapper.QueryUser"); !errors.As(err, new(ErrMapperNotFound)) {
+		t.Errorf("expected ErrMapperNotFound, got %v", err)
+	}
+	if debug := merged.Settings().Get("debug").Bool(); debug {
+		t.Error("expected the setting of the first configuration to take precedence")
+	}
+	if maxLimit := merged.Settings().Get("maxLimit").Int64(); maxLimit != 100 {
+		t.Errorf("expected maxLimit 100, got %d", maxLimit)
+	}
+	env, err := merged.Environments().Use(merged.Environments().Attribute("default"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env.Driver != "fake" {
+		t.Errorf("unexpected driver %s", env.Driver)
+	}
+}
+
+func TestMergeConfigurations_NamespaceConflict(t *testing.T) {
+	app := mergeTestConfiguration(t, mergeTestEnvironments, "", "main.UserMapper")
+	plugin := mergeTestConfiguration(t, "", "", "main.UserMapper")
+	if _, err := MergeConfigurations(app, plugin); !errors.Is(err, ErrNamespaceConflict) {
+		t.Errorf("expected ErrNamespaceConflict, got %v", err)
+	}
+}
+
+func TestMergeConfigurations_EnvironmentConflict(t *testing.T) {
+	app := mergeTestConfiguration(t, mergeTestEnvironments, "", "main.UserMapper")
+	tests := map[string]string{
+		"data source": `
+    <environments default="prod">
+        <environment id="prod">
+            <dataSource>other</dataSource>
+            <driver>fake</driver>
+        </environment>
+    </environments>`,
+		"default": `
+    <environments default="dev">
+        <environment id="dev">
+            <dataSource>fake</dataSource>
+            <driver>fake</driver>
+        </environment>
+    </environments>`,
+	}
+	for name, environments := range tests {
+		t.Run(name, func(t *testing.T) {
+			plugin := mergeTestConfiguration(t, environments, "", "plugin.OrderMapper")
+			if _, err := MergeConfigurations(app, plugin); !errors.Is(err, ErrEnvironmentConflict) {
+				t.Errorf("expected ErrEnvironmentConflict, got %v", err)
+			}
+		})
+	}
+}
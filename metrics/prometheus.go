@@ -0,0 +1,122 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics provides middlewares which export the metrics of the executed statements.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/go-juicedev/juice"
+)
+
+// ensure PrometheusMiddleware implements juice.Middleware.
+var _ juice.Middleware = (*PrometheusMiddleware)(nil) // compile time check
+
+// PrometheusMiddleware is a middleware that records the metrics of the executed statements.
+//
+// The following collectors are registered:
+//   - juice_statement_duration_seconds: histogram of the execution latency
+//   - juice_statement_errors_total: counter of the failed executions
+//   - juice_statement_rows_affected_total: counter of the rows affected by exec statements
+//
+// All of them are labeled by the fully qualified statement name and its action.
+// The raw SQL is never used as a label to keep the cardinality bounded.
+//
+// Usage:
+//
+//	middleware, err := metrics.NewPrometheusMiddleware(prometheus.DefaultRegisterer)
+//	if err != nil {
+//	    // handle error
+//	}
+//	engine.Use(middleware)
+type PrometheusMiddleware struct {
+	duration     *prometheus.HistogramVec
+	errors       *prometheus.CounterVec
+	rowsAffected *prometheus.CounterVec
+}
+
+// statementLabels are the labels of all collectors.
+var statementLabels = []string{"statement", "action"}
+
+// NewPrometheusMiddleware creates a PrometheusMiddleware and registers its collectors to the given registerer.
+func NewPrometheusMiddleware(reg prometheus.Registerer) (*PrometheusMiddleware, error) {
+	m := &PrometheusMiddleware{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "juice",
+			Subsystem: "statement",
+			Name:      "duration_seconds",
+			Help:      "Latency of the executed statements.",
+			Buckets:   prometheus.DefBuckets,
+		}, statementLabels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "juice",
+			Subsystem: "statement",
+			Name:      "errors_total",
+			Help:      "Number of the failed statement executions.",
+		}, statementLabels),
+		rowsAffected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "juice",
+			Subsystem: "statement",
+			Name:      "rows_affected_total",
+			Help:      "Number of the rows affected by the exec statements.",
+		}, statementLabels),
+	}
+	for _, collector := range []prometheus.Collector{m.duration, m.errors, m.rowsAffected} {
+		if err := reg.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// QueryContext implements juice.Middleware.
+// QueryContext will record the latency and the error of the query statement.
+func (m *PrometheusMiddleware) QueryContext(stmt juice.Statement, next juice.QueryHandler) juice.QueryHandler {
+	labels := prometheus.Labels{"statement": stmt.Name(), "action": stmt.Action().String()}
+	return func(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+		start := time.Now()
+		rows, err := next(ctx, query, args...)
+		m.duration.With(labels).Observe(time.Since(start).Seconds())
+		if err != nil {
+			m.errors.With(labels).Inc()
+		}
+		return rows, err
+	}
+}
+
+// ExecContext implements juice.Middleware.
+// ExecContext will record the latency, the error and the rows affected of the exec statement.
+func (m *PrometheusMiddleware) ExecContext(stmt juice.Statement, next juice.ExecHandler) juice.ExecHandler {
+	labels := prometheus.Labels{"statement": stmt.Name(), "action": stmt.Action().String()}
+	return func(ctx context.Context, query string, args ...any) (sql.Result, error) {
+		start := time.Now()
+		result, err := next(ctx, query, args...)
+		m.duration.With(labels).Observe(time.Since(start).Seconds())
+		if err != nil {
+			m.errors.With(labels).Inc()
+			return result, err
+		}
+		if rowsAffected, rowsErr := result.RowsAffected(); rowsErr == nil && rowsAffected > 0 {
+			m.rowsAffected.With(labels).Add(float64(rowsAffected))
+		}
+		return result, nil
+	}
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/go-juicedev/juice"
+	"github.com/go-juicedev/juice/driver"
+)
+
+type statement struct {
+	name   string
+	action juice.Action
+}
+
+func (s statement) ID() string                          { return s.name }
+func (s statement) Name() string                        { return s.name }
+func (s statement) Attribute(string) string             { return "" }
+func (s statement) Action() juice.Action                { return s.action }
+func (s statement) Configuration() juice.IConfiguration { return nil }
+func (s statement) ResultMap() (juice.ResultMap, error) { return nil, juice.ErrResultMapNotSet }
+func (s statement) Build(driver.Translator, juice.Param) (string, []any, error) {
+	return "", nil, nil
+}
+
+type result int64
+
+func (r result) LastInsertId() (int64, error) { return 0, nil }
+func (r result) RowsAffected() (int64, error) { return int64(r), nil }
+
+func TestPrometheusMiddleware(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	middleware, err := NewPrometheusMiddleware(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt := statement{name: "main.UserMapper.UpdateUser", action: juice.Update}
+
+	exec := middleware.ExecContext(stmt, func(context.Context, string, ...any) (sql.Result, error) {
+		return result(3), nil
+	})
+	if _, err = exec(context.Background(), "UPDATE user SET name = ?", "a"); err != nil {
+		t.Fatal(err)
+	}
+	failed := middleware.ExecContext(stmt, func(context.Context, string, ...any) (sql.Result, error) {
+		return nil, errors.New("failed")
+	})
+	if _, err = failed(context.Background(), "UPDATE user SET name = ?", "a"); err == nil {
+		t.Fatal("expected error")
+	}
+
+	labels := prometheus.Labels{"statement": stmt.name, "action": "update"}
+	if value := testutil.ToFloat64(middleware.rowsAffected.With(labels)); value != 3 {
+		t.Errorf("unexpected rows affected: %v", value)
+	}
+	if value := testutil.ToFloat64(middleware.errors.With(labels)); value != 1 {
+		t.Errorf("unexpected errors: %v", value)
+	}
+	if count := testutil.CollectAndCount(middleware.duration); count != 1 {
+		t.Errorf("unexpected duration series: %d", count)
+	}
+}
+
+func TestNewPrometheusMiddlewareDuplicateRegister(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	if _, err := NewPrometheusMiddleware(reg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewPrometheusMiddleware(reg); err == nil {
+		t.Error("expected duplicate register error")
+	}
+}
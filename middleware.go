@@ -21,9 +21,13 @@ import (
 	"database/sql"
 	"errors"
 	"log"
+	"log/slog"
 	"reflect"
+	"slices"
 	"strconv"
 	"time"
+
+	"github.com/go-juicedev/juice/driver"
 )
 
 // Middleware is a wrapper of QueryHandler and ExecHandler.
@@ -67,10 +71,38 @@ func (m MiddlewareGroup) ExecContext(stmt Statement, next ExecHandler) ExecHandl
 // logger is a default logger for debug.
 var logger = log.New(log.Writer(), "[juice] ", log.Flags())
 
+// logArgs returns the args to log, the args which implement slog.LogValuer are replaced
+// with their resolved LogValue, so that the types control their own safe representation:
+//
+//	type Password string
+//
+//	func (Password) LogValue() slog.Value { return slog.StringValue("******") }
+//
+// The args are returned as they are if none of them implements slog.LogValuer.
+// The executed args are never changed.
+func logArgs(args []any) []any {
+	var logged []any
+	for i, arg := range args {
+		valuer, ok := arg.(slog.LogValuer)
+		if !ok {
+			continue
+		}
+		if logged == nil {
+			logged = slices.Clone(args)
+		}
+		logged[i] = slog.AnyValue(valuer).Resolve().Any()
+	}
+	if logged == nil {
+		return args
+	}
+	return logged
+}
+
 // ensure DebugMiddleware implements Middleware.
 var _ Middleware = (*DebugMiddleware)(nil) // compile time check
 
 // DebugMiddleware is a middleware that prints the sql xmlSQLStatement and the execution time.
+// Set the formatSQL setting or statement attribute to true to pretty print the logged sql, see FormatSQL.
 type DebugMiddleware struct{}
 
 // QueryContext implements Middleware.
@@ -84,7 +116,7 @@ func (m *DebugMiddleware) QueryContext(stmt Statement, next QueryHandler) QueryH
 		start := time.Now()
 		rows, err := next(ctx, query, args...)
 		spent := time.Since(start)
-		logger.Printf("\x1b[33m[%s]\x1b[0m \x1b[32m %s\x1b[0m \x1b[38m %v\x1b[0m \x1b[31m %v\x1b[0m\n", stmt.Name(), query, args, spent)
+		logger.Printf("\x1b[33m[%s]\x1b[0m \x1b[32m %s\x1b[0m \x1b[38m %v\x1b[0m \x1b[31m %v\x1b[0m\n", stmt.Name(), m.logQuery(stmt, query), logArgs(args), spent)
 		return rows, err
 	}
 }
@@ -100,11 +132,24 @@ func (m *DebugMiddleware) ExecContext(stmt Statement, next ExecHandler) ExecHand
 		start := time.Now()
 		rows, err := next(ctx, query, args...)
 		spent := time.Since(start)
-		logger.Printf("\x1b[33m[%s]\x1b[0m \x1b[32m %s\x1b[0m \x1b[38m %v\x1b[0m \x1b[31m %v\x1b[0m\n", stmt.Name(), query, args, spent)
+		logger.Printf("\x1b[33m[%s]\x1b[0m \x1b[32m %s\x1b[0m \x1b[38m %v\x1b[0m \x1b[31m %v\x1b[0m\n", stmt.Name(), m.logQuery(stmt, query), logArgs(args), spent)
 		return rows, err
 	}
 }
 
+// logQuery returns the query to log, which is pretty printed by FormatSQL if the formatSQL
+// attribute of the statement or the formatSQL setting is true. The executed query is not affected.
+func (m *DebugMiddleware) logQuery(stmt Statement, query string) string {
+	format := stmt.Attribute(formatSQLSetting)
+	if format == "" {
+		format = stmt.Configuration().Settings().Get(formatSQLSetting).String()
+	}
+	if format != "true" {
+		return query
+	}
+	return FormatSQL(query)
+}
+
 // isDeBugMode returns true if the debug mode is on.
 // Default debug mode is on.
 // You can turn off the debug mode by setting the debug tag to false in the mapper xmlSQLStatement attribute or the configuration.
@@ -121,48 +166,177 @@ func (m *DebugMiddleware) isDeBugMode(stmt Statement) bool {
 	return true
 }
 
+// defaultStatementTimeoutSetting is the setting name of the engine default timeout
+// in milliseconds, which is used by the statements without the timeout attribute.
+const defaultStatementTimeoutSetting = "defaultStatementTimeout"
+
+// statementTimeoutKey is the context key of the statement timeout override.
+type statementTimeoutKey struct{}
+
+// WithStatementTimeout returns a context which overrides the timeout of the statements
+// executed with it, for example a normally-fast statement used by a report export:
+//
+//	ctx = juice.WithStatementTimeout(ctx, time.Minute)
+//	rows, err := engine.Object(ExportUsers).QueryContext(ctx, param)
+//
+// It is applied by the TimeoutMiddleware.
+func WithStatementTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, statementTimeoutKey{}, timeout)
+}
+
+// statementTimeoutFromContext returns the statement timeout override of the context.
+func statementTimeoutFromContext(ctx context.Context) (time.Duration, bool) {
+	timeout, ok := ctx.Value(statementTimeoutKey{}).(time.Duration)
+	return timeout, ok
+}
+
 // ensure TimeoutMiddleware implements Middleware
 var _ Middleware = (*TimeoutMiddleware)(nil) // compile time check
 
 // TimeoutMiddleware is a middleware that sets the timeout for the sql xmlSQLStatement.
+//
+// The timeout is chosen in the following order:
+//  1. the override of the context set by WithStatementTimeout
+//  2. the timeout attribute of the statement in milliseconds
+//  3. the defaultStatementTimeout setting of the engine in milliseconds
+//  4. the deadline of the caller's context
+//
+// A non-positive timeout means no timeout is set by this level.
+// Note that the derived context never outlives the deadline of the caller's context.
 type TimeoutMiddleware struct{}
 
 // QueryContext implements Middleware.
 // QueryContext will set the timeout for the sql xmlSQLStatement.
 func (t TimeoutMiddleware) QueryContext(stmt Statement, next QueryHandler) QueryHandler {
 	timeout := t.getTimeout(stmt)
-	if timeout <= 0 {
-		return next
-	}
 	return func(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
-		ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Millisecond)
-		defer cancel()
-		return next(ctx, query, args...)
+		ctx, cancel := t.withTimeout(ctx, timeout)
+		rows, err := queryRows(ctx, next, query, args)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		// the rows are closed by database/sql once the context is done, so the context
+		// must not be canceled before they are consumed. It is released when the executor
+		// closes the rows, or by its deadline if the rows are returned to the caller.
+		releaseWithRows(ctx, cancel)
+		return rows, nil
 	}
 }
 
+type rowsReleaserKey struct{}
+
+// rowsReleaser collects the functions which release the resources of the rows of a query,
+// like the timeout context of the TimeoutMiddleware, which must outlive the query itself.
+type rowsReleaser struct {
+	releases []func()
+}
+
+// withRowsReleaser returns a context which collects the release functions of the rows queried with it,
+// and the function which calls them. The caller must call it after the rows are closed.
+func withRowsReleaser(ctx context.Context) (context.Context, func()) {
+	releaser := &rowsReleaser{}
+	return context.WithValue(ctx, rowsReleaserKey{}, releaser), func() {
+		for _, release := range releaser.releases {
+			release()
+		}
+		releaser.releases = nil
+	}
+}
+
+// releaseWithRows registers the release function to the rows releaser of the context.
+// It reports false if the context has no releaser, the rows are returned to the caller
+// of the executor then, and the function is not called.
+func releaseWithRows(ctx context.Context, release func()) bool {
+	releaser, ok := ctx.Value(rowsReleaserKey{}).(*rowsReleaser)
+	if ok {
+		releaser.releases = append(releaser.releases, release)
+	}
+	return ok
+}
+
 // ExecContext implements Middleware.
 // ExecContext will set the timeout for the sql xmlSQLStatement.
 func (t TimeoutMiddleware) ExecContext(stmt Statement, next ExecHandler) ExecHandler {
 	timeout := t.getTimeout(stmt)
-	if timeout <= 0 {
-		return next
-	}
 	return func(ctx context.Context, query string, args ...any) (sql.Result, error) {
-		ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Millisecond)
+		ctx, cancel := t.withTimeout(ctx, timeout)
 		defer cancel()
 		return next(ctx, query, args...)
 	}
 }
 
-// getTimeout returns the timeout from the xmlSQLStatement.
-func (t TimeoutMiddleware) getTimeout(stmt Statement) (timeout int64) {
+// withTimeout derives the execution context with the timeout override of the context,
+// or the timeout of the statement if no override is set.
+func (t TimeoutMiddleware) withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if override, ok := statementTimeoutFromContext(ctx); ok {
+		timeout = override
+	}
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// getTimeout returns the timeout from the xmlSQLStatement, or the engine default timeout.
+func (t TimeoutMiddleware) getTimeout(stmt Statement) time.Duration {
 	timeoutAttr := stmt.Attribute("timeout")
 	if timeoutAttr == "" {
-		return
+		if cfg := stmt.Configuration(); cfg != nil {
+			timeoutAttr = cfg.Settings().Get(defaultStatementTimeoutSetting).String()
+		}
 	}
-	timeout, _ = strconv.ParseInt(timeoutAttr, 10, 64)
-	return
+	timeout, _ := strconv.ParseInt(timeoutAttr, 10, 64)
+	return time.Duration(timeout) * time.Millisecond
+}
+
+// ensure ErrorTranslateMiddleware implements Middleware
+var _ Middleware = (*ErrorTranslateMiddleware)(nil) // compile time check
+
+// ErrorTranslateMiddleware is a middleware that translates the driver-specific errors
+// into the typed errors of juice, so that they can be checked portably:
+//
+//	engine.Use(&juice.ErrorTranslateMiddleware{Driver: engine.Driver()})
+//
+//	_, err := engine.Object(CreateUser).ExecContext(ctx, user)
+//	if errors.Is(err, juice.ErrDuplicateKey) {
+//	    // handle the conflict
+//	}
+//
+// The constraint violations are translated as ClassifyError does, but only by the given driver.
+// If the driver doesn't implement driver.ErrorClassifier, the errors are passed through unchanged.
+// If the driver is nil, the errors are classified by all the registered drivers.
+type ErrorTranslateMiddleware struct {
+	Driver driver.Driver
+}
+
+// QueryContext implements Middleware.
+// QueryContext will translate the error of the query.
+func (m ErrorTranslateMiddleware) QueryContext(_ Statement, next QueryHandler) QueryHandler {
+	return func(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+		rows, err := next(ctx, query, args...)
+		return rows, m.translate(err)
+	}
+}
+
+// ExecContext implements Middleware.
+// ExecContext will translate the error of the exec.
+func (m ErrorTranslateMiddleware) ExecContext(_ Statement, next ExecHandler) ExecHandler {
+	return func(ctx context.Context, query string, args ...any) (sql.Result, error) {
+		result, err := next(ctx, query, args...)
+		return result, m.translate(err)
+	}
+}
+
+// translate returns the typed error of the driver error, or the error itself.
+func (m ErrorTranslateMiddleware) translate(err error) error {
+	if err == nil {
+		return nil
+	}
+	if m.Driver == nil {
+		return ClassifyError(err)
+	}
+	return classifyError(m.Driver, err)
 }
 
 // ensure useGeneratedKeysMiddleware implements Middleware
@@ -174,7 +348,20 @@ var errStructPointerOrSliceArrayRequired = errors.New(
 )
 
 // useGeneratedKeysMiddleware is a middleware that set the last insert id to the struct.
-type useGeneratedKeysMiddleware struct{}
+//
+// When the param is a slice, the keys are assigned to the elements in order. The last insert
+// id is the key of the first inserted row on most databases, like MySQL, and the keys of the
+// following rows are incremented by the keyIncrement attribute. The DECREMENTAL
+// batchInsertIDGenerateStrategy is for the databases whose last insert id is the key of the
+// last inserted row, like SQLite, the last element receives it and the keys of the elements
+// before it are decremented by the keyIncrement attribute.
+//
+// When the driver implements driver.KeyReturner, like PostgreSQL which has no last insert id,
+// a RETURNING clause of the keyColumn attribute, or the column tag of the key property, is
+// appended to the statement, and every inserted row receives its returned key.
+type useGeneratedKeysMiddleware struct {
+	driver driver.Driver
+}
 
 // QueryContext implements Middleware.
 // return the result directly and do nothing.
@@ -197,25 +384,20 @@ func (m *useGeneratedKeysMiddleware) ExecContext(stmt Statement, next ExecHandle
 	if !useGeneratedKeys {
 		return next
 	}
+	if returner, ok := m.driver.(driver.KeyReturner); ok {
+		return m.execReturning(stmt, returner, next)
+	}
 	return func(ctx context.Context, query string, args ...any) (sql.Result, error) {
 		result, err := next(ctx, query, args...)
 		if err != nil {
 			return nil, err
 		}
 
+		// on most databases, the last insert ID is the first row affected.
 		id, err := result.LastInsertId()
 		if err != nil {
 			return nil, err
 		}
-		rowsAffected, err := result.RowsAffected()
-		if err != nil {
-			return nil, err
-		}
-		// on most databases, the last insert ID is the first row affected.
-		// calculate the last insert ID by the number of rows affected.
-		if rowsAffected > 1 {
-			id = id + rowsAffected - 1
-		}
 		// try to get param from context
 		// ParamCtxInjectorExecutor is already set in middlewares, so the param should be in the context.
 		param := ParamFromContext(ctx)
@@ -262,3 +444,33 @@ func (m *useGeneratedKeysMiddleware) ExecContext(stmt Statement, next ExecHandle
 		return result, nil
 	}
 }
+
+// execReturning returns the ExecHandler which appends the RETURNING clause of the key column
+// to the statement, and sets the returned keys to the param.
+func (m *useGeneratedKeysMiddleware) execReturning(stmt Statement, returner driver.KeyReturner, next ExecHandler) ExecHandler {
+	return func(ctx context.Context, query string, args ...any) (sql.Result, error) {
+		param := ParamFromContext(ctx)
+		if param == nil {
+			return nil, errors.New("useGeneratedKeys is true, but the param is nil")
+		}
+		rv := reflect.ValueOf(param)
+		elementType, ok := keyElementType(rv)
+		if !ok {
+			return nil, errStructPointerOrSliceArrayRequired
+		}
+		column, err := returningKeyColumn(stmt, elementType)
+		if err != nil {
+			return nil, err
+		}
+		ctx, keys := withReturningKeys(ctx)
+		result, err := next(ctx, query+" "+returner.ReturningClause(column), args...)
+		if err != nil {
+			return nil, err
+		}
+		keyGenerator := returningKeyGenerator{ids: keys.ids, keyProperty: stmt.Attribute("keyProperty")}
+		if err = keyGenerator.GenerateKeyTo(rv); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+}
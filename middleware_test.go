@@ -0,0 +1,165 @@
+package juice
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	juicedriver "github.com/go-juicedev/juice/driver"
+)
+
+func TestTimeoutMiddleware_Precedence(t *testing.T) {
+	cfg := &Configuration{settings: keyValueSettingProvider{defaultStatementTimeoutSetting: "3000"}}
+	newStatement := func(timeout string) *xmlSQLStatement {
+		stmt := newFakeStatement("UPDATE user SET name = #{name}")
+		stmt.mapper.mappers.cfg = cfg
+		if timeout != "" {
+			stmt.attrs = map[string]string{"timeout": timeout}
+		}
+		return stmt
+	}
+	// remaining returns the timeout of the context derived by the middleware.
+	remaining := func(ctx context.Context, stmt Statement) time.Duration {
+		var timeout time.Duration
+		handler := TimeoutMiddleware{}.ExecContext(stmt, func(ctx context.Context, _ string, _ ...any) (sql.Result, error) {
+			if deadline, ok := ctx.Deadline(); ok {
+				timeout = time.Until(deadline)
+			}
+			return nil, nil
+		})
+		_, _ = handler(ctx, "")
+		return timeout
+	}
+	within := func(got, want time.Duration) bool {
+		return got <= want && got > want-time.Second
+	}
+
+	ctx := context.Background()
+	if got := remaining(ctx, newStatement("")); !within(got, 3*time.Second) {
+		t.Errorf("engine default: unexpected timeout %v", got)
+	}
+	if got := remaining(ctx, newStatement("2000")); !within(got, 2*time.Second) {
+		t.Errorf("statement attribute: unexpected timeout %v", got)
+	}
+	override := WithStatementTimeout(ctx, time.Minute)
+	if got := remaining(override, newStatement("2000")); !within(got, time.Minute) {
+		t.Errorf("context override: unexpected timeout %v", got)
+	}
+	// the override never outlives the deadline of the caller.
+	deadline, cancel := context.WithTimeout(override, 5*time.Second)
+	defer cancel()
+	if got := remaining(deadline, newStatement("2000")); !within(got, 5*time.Second) {
+		t.Errorf("caller deadline: unexpected timeout %v", got)
+	}
+	// a non-positive override disables the timeout of the statement.
+	if got := remaining(WithStatementTimeout(ctx, 0), newStatement("2000")); got != 0 {
+		t.Errorf("disabled: unexpected timeout %v", got)
+	}
+}
+
+// duplicateEntryError mimics the duplicate entry error of go-sql-driver/mysql.
+type duplicateEntryError struct {
+	Number uint16
+}
+
+func (e *duplicateEntryError) Error() string { return "Error 1062 (23000): Duplicate entry" }
+
+func TestErrorTranslateMiddleware(t *testing.T) {
+	middleware := ErrorTranslateMiddleware{Driver: juicedriver.MySQLDriver{}}
+	original := &duplicateEntryError{Number: 1062}
+	handler := middleware.ExecContext(newFakeStatement("INSERT"), func(context.Context, string, ...any) (sql.Result, error) {
+		return nil, original
+	})
+	_, err := handler(context.Background(), "INSERT")
+	if !errors.Is(err, ErrDuplicateKey) {
+		t.Fatalf("expected ErrDuplicateKey, got %v", err)
+	}
+	var driverErr *duplicateEntryError
+	if !errors.As(err, &driverErr) || driverErr != original {
+		t.Errorf("expected the original error to be wrapped, got %v", err)
+	}
+
+	other := errors.New("connection refused")
+	handler = middleware.ExecContext(newFakeStatement("INSERT"), func(context.Context, string, ...any) (sql.Result, error) {
+		return nil, other
+	})
+	if _, err = handler(context.Background(), "INSERT"); err != other {
+		t.Errorf("expected the error to pass through, got %v", err)
+	}
+}
+
+func TestTimeoutMiddleware_QueryRows(t *testing.T) {
+	db, _ := newFakeDB(t, fakeResultSet{
+		columns: []string{"id"},
+		rows:    [][]driver.Value{{int64(1)}, {int64(2)}},
+	})
+	stmt := newFakeStatement("SELECT id FROM user")
+	handler := TimeoutMiddleware{}.QueryContext(stmt, func(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+		return db.QueryContext(ctx, query, args...)
+	})
+	rows, err := handler(WithStatementTimeout(context.Background(), time.Minute), "SELECT id FROM user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = rows.Close() }()
+	// the rows outlive the handler, they are not closed by the timeout context.
+	time.Sleep(10 * time.Millisecond)
+	var count int
+	for rows.Next() {
+		count++
+	}
+	if err = rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 rows, got %d", count)
+	}
+}
+
+func TestTimeoutMiddleware_ReleaseWithRows(t *testing.T) {
+	db, _ := newFakeDB(t, fakeResultSet{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}})
+	stmt := newFakeStatement("SELECT id FROM user")
+	var queryCtx context.Context
+	handler := TimeoutMiddleware{}.QueryContext(stmt, func(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+		queryCtx = ctx
+		return db.QueryContext(ctx, query, args...)
+	})
+	ctx, release := withRowsReleaser(WithStatementTimeout(context.Background(), time.Minute))
+	rows, err := handler(ctx, "SELECT id FROM user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queryCtx.Err() != nil {
+		t.Fatal("expected the context to outlive the query")
+	}
+	_ = rows.Close()
+	release()
+	// the timeout context is released with the rows instead of by its deadline.
+	if !errors.Is(queryCtx.Err(), context.Canceled) {
+		t.Errorf("expected the context to be canceled, got %v", queryCtx.Err())
+	}
+}
+
+func TestDebugMiddleware_LogValuer(t *testing.T) {
+	var output bytes.Buffer
+	writer := logger.Writer()
+	logger.SetOutput(&output)
+	defer logger.SetOutput(writer)
+
+	stmt := newFakeStatement("UPDATE user SET password = #{password}")
+	stmt.mapper.mappers.cfg = &Configuration{}
+	handler := (&DebugMiddleware{}).ExecContext(stmt, func(context.Context, string, ...any) (sql.Result, error) {
+		return nil, nil
+	})
+	if _, err := handler(context.Background(), "UPDATE user SET password = ?", auditPassword("secret")); err != nil {
+		t.Fatal(err)
+	}
+	if log := output.String(); strings.Contains(log, "secret") || !strings.Contains(log, "******") {
+		t.Errorf("unexpected log: %s", log)
+	}
+}
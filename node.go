@@ -17,12 +17,18 @@ limitations under the License.
 package juice
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/go-juicedev/juice/eval"
+	"github.com/go-juicedev/juice/internal/reflectlite"
 
 	"github.com/go-juicedev/juice/driver"
 )
@@ -173,7 +179,7 @@ func (c *TextNode) Accept(translator driver.Translator, p Parameter) (query stri
 	if err != nil {
 		return "", nil, err
 	}
-	query, err = c.replaceTextSubstitution(query, p)
+	query, err = c.replaceTextSubstitution(query, translator, p)
 	if err != nil {
 		return "", nil, err
 	}
@@ -192,14 +198,48 @@ func (c *TextNode) replaceHolder(query string, args []interface{}, translator dr
 		if !exists {
 			return "", nil, fmt.Errorf("parameter %s not found", name)
 		}
+		value, err := normalizeParameter(name, value)
+		if err != nil {
+			return "", nil, err
+		}
+		// the value may be expanded into several args, see ArgsExtractor.
+		extracted, ok, err := extractArgs(p, name, value)
+		if err != nil {
+			return "", nil, err
+		}
+		if ok {
+			placeholders := make([]string, len(extracted))
+			for i := range extracted {
+				placeholders[i] = translator.Translate(name)
+			}
+			query = strings.Replace(query, matched, strings.Join(placeholders, ", "), 1)
+			args = append(args, extracted...)
+			continue
+		}
+		arg, err := parameterArg(p, name, value)
+		if err != nil {
+			return "", nil, err
+		}
+		// the repeated parameter shares the named arg of its first occurrence.
+		if binder, ok := driver.TranslatorAs[driver.NamedArgsBinder](translator); ok {
+			placeholder, reused := binder.Bind(name, arg)
+			query = strings.Replace(query, matched, placeholder, 1)
+			if !reused {
+				args = append(args, arg)
+			}
+			continue
+		}
 		query = strings.Replace(query, matched, translator.Translate(name), 1)
-		args = append(args, value.Interface())
+		args = append(args, arg)
 	}
 	return query, args, nil
 }
 
 // replaceTextSubstitution replaces text substitution.
-func (c *TextNode) replaceTextSubstitution(query string, p Parameter) (string, error) {
+// The substituted values are checked by the translator if it is a substitutionChecker.
+func (c *TextNode) replaceTextSubstitution(query string, translator driver.Translator, p Parameter) (string, error) {
+	checker, checked := driver.TranslatorAs[substitutionChecker](translator)
+	quoter, quoting := driver.TranslatorAs[substitutionQuoter](translator)
 	for _, sub := range c.textSubstitution {
 		if len(sub) != 2 {
 			return "", fmt.Errorf("invalid text substitution %v", sub)
@@ -209,7 +249,19 @@ func (c *TextNode) replaceTextSubstitution(query string, p Parameter) (string, e
 		if !exists {
 			return "", fmt.Errorf("parameter %s not found", name)
 		}
-		query = strings.Replace(query, matched, reflectValueToString(value), 1)
+		text := reflectValueToString(value)
+		if checked {
+			if err := checker.checkSubstitution(name, text); err != nil {
+				return "", err
+			}
+		}
+		if quoting {
+			var err error
+			if text, err = quoter.quoteSubstitution(text); err != nil {
+				return "", fmt.Errorf("parameter %s: %w", name, err)
+			}
+		}
+		query = strings.Replace(query, matched, text, 1)
 	}
 	return query, nil
 }
@@ -233,6 +285,10 @@ func NewTextNode(str string) Node {
 type ConditionNode struct {
 	expr  eval.Expression
 	Nodes NodeGroup
+
+	// Else is the nodes after the <else/> element of an if node.
+	// They are rendered when the condition is false.
+	Else NodeGroup
 }
 
 // Parse compiles the given expression string into an evaluable expression.
@@ -256,16 +312,29 @@ func (c *ConditionNode) Parse(test string) (err error) {
 // Accept accepts parameters and returns query and arguments.
 // Accept implements Node interface.
 func (c *ConditionNode) Accept(translator driver.Translator, p Parameter) (query string, args []any, err error) {
-	matched, err := c.Match(p)
+	matched, err := c.accepted(translator, p)
 	if err != nil {
 		return "", nil, err
 	}
 	if !matched {
-		return "", nil, nil
+		if len(c.Else) == 0 {
+			return "", nil, nil
+		}
+		return c.Else.Accept(translator, p)
 	}
 	return c.Nodes.Accept(translator, p)
 }
 
+// accepted reports whether the condition is true when the node is accepted by the translator.
+// The unsupported expressions are not matched in lenient mode, see lenientExpressionsKey.
+func (c *ConditionNode) accepted(translator driver.Translator, p Parameter) (bool, error) {
+	matched, err := c.Match(p)
+	if err != nil && (!errors.Is(err, eval.ErrUnsupportedExpression) || !lenientExpressions(translator)) {
+		return false, err
+	}
+	return matched, nil
+}
+
 // Match evaluates if the condition is true based on the provided parameter.
 // It handles different types of values and converts them to boolean results:
 //   - Bool: returns the boolean value directly
@@ -277,6 +346,8 @@ func (c *ConditionNode) Match(p Parameter) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	// values from a map[string]any are wrapped by an interface
+	value = reflectlite.Unwrap(value)
 	switch value.Kind() {
 	case reflect.Bool:
 		return value.Bool(), nil
@@ -309,6 +380,21 @@ var _ Node = (*ConditionNode)(nil)
 //	    AND id = #{id}
 //	</if>
 //
+// An <else/> element splits the content into the taken and the not-taken part:
+//
+//	<if test="id > 0">
+//	    AND id = #{id}
+//	<else/>
+//	    AND status = 1
+//	</if>
+//
+// With the flag attribute instead of test, the condition is the feature flag
+// reported by the FlagProvider of the engine:
+//
+//	<if flag="newSearchPath">
+//	    AND match(name) against(#{keyword})
+//	</if>
+//
 // See ConditionNode for detailed behavior of condition evaluation.
 type IfNode = ConditionNode
 
@@ -322,40 +408,57 @@ type WhereNode struct {
 
 // Accept processes the WHERE clause and its conditions.
 // It handles several special cases:
-//  1. Removes leading "AND" or "OR" from the first condition
+//  1. Removes leading "AND" or "OR" from the first condition, in any case and followed by any whitespace
 //  2. Ensures the clause starts with "WHERE" if not already present
 //  3. Properly handles spacing between conditions
 //
+// A leading "NOT" is kept, since it negates the first condition.
+//
 // Examples:
 //
 //	Input:  "AND id = ?"        -> Output: "WHERE id = ?"
-//	Input:  "OR name = ?"       -> Output: "WHERE name = ?"
+//	Input:  "or\tname = ?"      -> Output: "WHERE name = ?"
 //	Input:  "WHERE age > ?"     -> Output: "WHERE age > ?"
 //	Input:  "status = ?"        -> Output: "WHERE status = ?"
+//	Input:  "AND NOT deleted"   -> Output: "WHERE NOT deleted"
 func (w WhereNode) Accept(translator driver.Translator, p Parameter) (query string, args []any, err error) {
 	query, args, err = w.Nodes.Accept(translator, p)
 	if err != nil {
 		return "", nil, err
 	}
 
+	// normalize the leading whitespace, like the tabs and newlines left by the skipped conditions.
+	query = strings.TrimLeftFunc(query, unicode.IsSpace)
 	if query == "" {
 		return "", args, nil
 	}
-	// A space is required at the end; otherwise, it is meaningless.
-	switch {
-	case strings.HasPrefix(query, "and ") || strings.HasPrefix(query, "AND "):
-		query = query[4:]
-	case strings.HasPrefix(query, "or ") || strings.HasPrefix(query, "OR "):
-		query = query[3:]
+	// The keyword must be followed by whitespace of any kind; otherwise, it is a part of a word.
+	if rest, ok := cutLeadingKeyword(query, "AND"); ok {
+		query = rest
+	} else if rest, ok = cutLeadingKeyword(query, "OR"); ok {
+		query = rest
 	}
 
-	// A space is required at the end; otherwise, it is meaningless.
-	if !(strings.HasPrefix(query, "where ") || strings.HasPrefix(query, "WHERE ")) {
+	if _, ok := cutLeadingKeyword(query, "WHERE"); !ok {
 		query = "WHERE " + query
 	}
 	return
 }
 
+// cutLeadingKeyword removes the leading keyword of the query case-insensitively,
+// along with the whitespace after it. It reports false if the query doesn't start
+// with the keyword followed by whitespace.
+func cutLeadingKeyword(query, keyword string) (string, bool) {
+	if len(query) <= len(keyword) || !strings.EqualFold(query[:len(keyword)], keyword) {
+		return query, false
+	}
+	rest := strings.TrimLeftFunc(query[len(keyword):], unicode.IsSpace)
+	if len(rest) == len(query)-len(keyword) {
+		return query, false
+	}
+	return rest, true
+}
+
 var _ Node = (*WhereNode)(nil)
 
 // TrimNode handles SQL fragment cleanup by managing prefixes, suffixes, and their overrides.
@@ -368,6 +471,10 @@ var _ Node = (*WhereNode)(nil)
 //   - PrefixOverrides: Strings to remove if found at the start
 //   - Suffix: String to append to the result if content exists
 //   - SuffixOverrides: Strings to remove if found at the end
+//   - CaseInsensitive: Whether the overrides are matched case-insensitively
+//
+// The longest matching override is removed, so overlapping overrides like "AND|AND NOT"
+// remove "AND NOT" from "AND NOT deleted", no matter the order they are declared in.
 //
 // Common use cases:
 //  1. Removing leading AND/OR from WHERE clauses
@@ -395,6 +502,7 @@ type TrimNode struct {
 	PrefixOverrides []string
 	Suffix          string
 	SuffixOverrides []string
+	CaseInsensitive bool
 }
 
 // Accept accepts parameters and returns query and arguments.
@@ -409,23 +517,13 @@ func (t TrimNode) Accept(translator driver.Translator, p Parameter) (query strin
 	}
 
 	// Handle prefix overrides before adding prefix
-	if len(t.PrefixOverrides) > 0 {
-		for _, prefix := range t.PrefixOverrides {
-			if strings.HasPrefix(query, prefix) {
-				query = query[len(prefix):]
-				break
-			}
-		}
+	if n := t.longestOverride(t.PrefixOverrides, query, false); n > 0 {
+		query = query[n:]
 	}
 
 	// Handle suffix overrides before adding suffix
-	if len(t.SuffixOverrides) > 0 {
-		for _, suffix := range t.SuffixOverrides {
-			if strings.HasSuffix(query, suffix) {
-				query = query[:len(query)-len(suffix)]
-				break
-			}
-		}
+	if n := t.longestOverride(t.SuffixOverrides, query, true); n > 0 {
+		query = query[:len(query)-n]
 	}
 
 	// Build final query with prefix and suffix
@@ -445,6 +543,25 @@ func (t TrimNode) Accept(translator driver.Translator, p Parameter) (query strin
 	return builder.String(), args, nil
 }
 
+// longestOverride returns the length of the longest override found at the start of the query,
+// or at the end if suffix is true, and 0 if none is found.
+func (t TrimNode) longestOverride(overrides []string, query string, suffix bool) int {
+	var longest int
+	for _, override := range overrides {
+		if len(override) <= longest || len(override) > len(query) {
+			continue
+		}
+		part := query[:len(override)]
+		if suffix {
+			part = query[len(query)-len(override):]
+		}
+		if part == override || t.CaseInsensitive && strings.EqualFold(part, override) {
+			longest = len(override)
+		}
+	}
+	return longest
+}
+
 var _ Node = (*TrimNode)(nil)
 
 // ForeachNode represents a dynamic SQL fragment that iterates over a collection.
@@ -482,6 +599,49 @@ var _ Node = (*TrimNode)(nil)
 //     id = #{id}
 //     </foreach>
 //
+// Iteration state:
+//
+// Besides the item and the index, every iteration exposes two boolean
+// parameters which report the position of the current element:
+//   - __first: true for the first element
+//   - __last: true for the last element
+//
+// They can be used to emit different SQL for the edges of the collection:
+//
+//	<foreach collection="list" item="item">
+//	  <if test="!__first">,</if>#{item}
+//	</foreach>
+//
+// In nested foreach nodes, they always refer to the innermost iteration.
+//
+// Nil collections:
+//
+// An empty or nil slice, array or map always yields no SQL and no arguments.
+// With nilable="true", a missing collection parameter or a nil value is treated as
+// an empty collection too, which is convenient for the optional filters:
+//
+//	<foreach collection="ids" item="id" open="AND id IN (" separator="," close=")" nilable="true">
+//	  #{id}
+//	</foreach>
+//
+// Without it, they are reported as errors.
+//
+// String collections:
+//
+// With split, a string collection is split by the separator, and the parts trimmed of
+// whitespace are iterated as the string items. The empty parts are skipped, so that an
+// empty string yields no SQL and "1,2," yields the items "1" and "2":
+//
+//	<foreach collection="ids" item="id" split="," open="(" separator="," close=")">
+//	  #{id}
+//	</foreach>
+//
+// Map ordering:
+//
+// When the collection is a map whose key type is ordered (integers, floats and strings),
+// the keys are iterated in ascending order, so that the generated SQL is deterministic.
+// Maps with other key types are iterated in Go's map order.
+//
 // Example results:
 //
 //	Input collection: [1, 2, 3]
@@ -495,6 +655,12 @@ type ForeachNode struct {
 	Open       string
 	Close      string
 	Separator  string
+
+	// Nilable reports whether a missing or nil collection is treated as an empty collection.
+	Nilable bool
+
+	// Split is the separator of the parts of a string collection, which is not iterable if empty.
+	Split string
 }
 
 // Accept accepts parameters and returns query and arguments.
@@ -508,6 +674,9 @@ func (f ForeachNode) Accept(translator driver.Translator, p Parameter) (query st
 	// one collection from parameter
 	value, exists := p.Get(f.Collection)
 	if !exists {
+		if f.Nilable {
+			return "", nil, nil
+		}
 		return "", nil, fmt.Errorf("collection %s not found", f.Collection)
 	}
 
@@ -521,16 +690,50 @@ func (f ForeachNode) Accept(translator driver.Translator, p Parameter) (query st
 		value = value.Elem()
 	}
 
+	// nil is treated as an empty collection.
+	if f.Nilable && isNilCollection(value) {
+		return "", nil, nil
+	}
+
 	switch value.Kind() {
 	case reflect.Array, reflect.Slice:
 		return f.acceptSlice(value, translator, p)
 	case reflect.Map:
 		return f.acceptMap(value, translator, p)
+	case reflect.String:
+		if f.Split != "" {
+			return f.acceptSlice(reflect.ValueOf(splitCollection(value.String(), f.Split)), translator, p)
+		}
+		fallthrough
 	default:
 		return "", nil, fmt.Errorf("collection %s is not a slice or map", f.Collection)
 	}
 }
 
+// splitCollection splits the string collection by the separator into the parts trimmed of whitespace,
+// the empty parts are skipped.
+func splitCollection(collection, separator string) []string {
+	parts := make([]string, 0, strings.Count(collection, separator)+1)
+	for _, part := range strings.Split(collection, separator) {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// isNilCollection reports whether the value is nil, or a nil pointer, slice or map.
+func isNilCollection(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Pointer, reflect.Slice, reflect.Map:
+		return value.IsNil()
+	default:
+		return false
+	}
+}
+
 func (f ForeachNode) acceptSlice(value reflect.Value, translator driver.Translator, p Parameter) (query string, args []any, err error) {
 	sliceLength := value.Len()
 
@@ -545,15 +748,15 @@ func (f ForeachNode) acceptSlice(value reflect.Value, translator driver.Translat
 
 	end := sliceLength - 1
 
-	// group wraps parameter
-	// nil is for placeholder
-	group := eval.ParamGroup{nil, p}
+	// the iteration parameter is reused by all the elements.
+	iteration := &foreachParameter{node: &f}
 
-	for i := 0; i < sliceLength; i++ {
+	// group wraps parameter, it is converted to a Parameter once.
+	var group Parameter = eval.ParamGroup{iteration, p}
 
-		item := value.Index(i).Interface()
+	for i := 0; i < sliceLength; i++ {
 
-		group[0] = eval.H{f.Item: item, f.Index: i}.AsParam()
+		iteration.reset(value.Index(i), reflect.ValueOf(i), i == 0, i == end)
 
 		for _, node := range f.Nodes {
 			q, a, err := node.Accept(translator, group)
@@ -571,6 +774,12 @@ func (f ForeachNode) acceptSlice(value reflect.Value, translator driver.Translat
 		if i < end {
 			builder.WriteString(f.Separator)
 		}
+
+		// the elements are expected to be the same size as the first one.
+		if i == 0 && end > 0 {
+			args = slices.Grow(args, len(args)*end)
+			builder.Grow(builder.Len() * end)
+		}
 	}
 
 	// if sliceLength is not zero, add close
@@ -582,6 +791,10 @@ func (f ForeachNode) acceptSlice(value reflect.Value, translator driver.Translat
 func (f ForeachNode) acceptMap(value reflect.Value, translator driver.Translator, p Parameter) (query string, args []any, err error) {
 	keys := value.MapKeys()
 
+	// iterate in a deterministic order, so that the generated SQL is stable
+	// and the prepared statements can be reused.
+	reflectlite.SortMapKeys(keys)
+
 	if len(keys) == 0 {
 		return "", nil, nil
 	}
@@ -595,15 +808,15 @@ func (f ForeachNode) acceptMap(value reflect.Value, translator driver.Translator
 
 	var index int
 
-	// group wraps parameter
-	// nil is for placeholder
-	group := eval.ParamGroup{nil, p}
+	// the iteration parameter is reused by all the entries.
+	iteration := &foreachParameter{node: &f}
 
-	for _, key := range keys {
+	// group wraps parameter, it is converted to a Parameter once.
+	var group Parameter = eval.ParamGroup{iteration, p}
 
-		item := value.MapIndex(key).Interface()
+	for _, key := range keys {
 
-		group[0] = eval.H{f.Item: item, f.Index: key.Interface()}.AsParam()
+		iteration.reset(value.MapIndex(key), key, index == 0, index == end)
 
 		for _, node := range f.Nodes {
 			q, a, err := node.Accept(translator, group)
@@ -622,6 +835,12 @@ func (f ForeachNode) acceptMap(value reflect.Value, translator driver.Translator
 			builder.WriteString(f.Separator)
 		}
 
+		// the entries are expected to be the same size as the first one.
+		if index == 0 && end > 0 {
+			args = slices.Grow(args, len(args)*end)
+			builder.Grow(builder.Len() * end)
+		}
+
 		index++
 	}
 
@@ -632,6 +851,81 @@ func (f ForeachNode) acceptMap(value reflect.Value, translator driver.Translator
 
 var _ Node = (*ForeachNode)(nil)
 
+const (
+	// ForeachFirstKey is the parameter name which reports whether
+	// the current element is the first one of the foreach collection.
+	ForeachFirstKey = "__first"
+
+	// ForeachLastKey is the parameter name which reports whether
+	// the current element is the last one of the foreach collection.
+	ForeachLastKey = "__last"
+)
+
+// foreachParameter is the parameter of the current iteration of a foreach node, which
+// resolves the item, the index, ForeachFirstKey and ForeachLastKey.
+// It is reset for each element instead of allocated, since a large collection, like
+// the ids of an IN clause, would allocate a parameter for every element.
+// The index is only exposed when the index name is set,
+// so that it never shadows a user parameter named "".
+type foreachParameter struct {
+	node        *ForeachNode
+	first, last bool
+
+	// item and index resolve the item and the index, and their dotted names like item.name.
+	item, index eval.ValueParameter
+}
+
+// reset sets the parameter to the given element.
+func (f *foreachParameter) reset(item, index reflect.Value, first, last bool) {
+	f.item.Reset(item)
+	f.index.Reset(index)
+	f.first, f.last = first, last
+}
+
+// nested returns the parameter which resolves the rest of the dotted name.
+func (f *foreachParameter) nested(name string) (*eval.ValueParameter, string, bool) {
+	root, rest, ok := strings.Cut(name, ".")
+	switch {
+	case !ok:
+		return nil, "", false
+	case root == f.node.Item:
+		return &f.item, rest, true
+	case f.node.Index != "" && root == f.node.Index:
+		return &f.index, rest, true
+	default:
+		return nil, "", false
+	}
+}
+
+// Get implements Parameter.
+func (f *foreachParameter) Get(name string) (reflect.Value, bool) {
+	switch {
+	case name == f.node.Item:
+		return f.item.Value, true
+	case name == ForeachFirstKey:
+		return reflect.ValueOf(f.first), true
+	case name == ForeachLastKey:
+		return reflect.ValueOf(f.last), true
+	case f.node.Index != "" && name == f.node.Index:
+		return f.index.Value, true
+	}
+	if param, rest, ok := f.nested(name); ok {
+		return param.Get(rest)
+	}
+	return reflect.Value{}, false
+}
+
+// StructField implements eval.StructFieldParameter.
+func (f *foreachParameter) StructField(name string) (reflect.StructField, bool) {
+	if param, rest, ok := f.nested(name); ok {
+		return param.StructField(rest)
+	}
+	return reflect.StructField{}, false
+}
+
+// ensure foreachParameter implements eval.StructFieldParameter.
+var _ eval.StructFieldParameter = (*foreachParameter)(nil) // compile time check
+
 // SetNode represents an SQL SET clause for UPDATE statements.
 // It manages a group of assignment expressions and automatically handles
 // the comma separators and SET prefix.
@@ -767,6 +1061,11 @@ var _ Node = (*SQLNode)(nil)
 //	  WHERE status = #{status}
 //	</select>
 //
+// The optional test attribute includes the fragment only when the condition holds,
+// with the same semantics as the test of an if node:
+//
+//	<include refid="tenantFilter" test="tenantId != 0"/>
+//
 // Features:
 //   - Enables SQL fragment reuse
 //   - Supports cross-mapper references
@@ -786,10 +1085,20 @@ type IncludeNode struct {
 	sqlNode Node
 	mapper  *Mapper
 	refId   string
+
+	// condition is the test of the include node, the fragment is included only if it holds.
+	// It is nil if the include node has no test.
+	condition *ConditionNode
 }
 
 // Accept accepts parameters and returns query and arguments.
 func (i *IncludeNode) Accept(translator driver.Translator, p Parameter) (query string, args []any, err error) {
+	if i.condition != nil {
+		matched, err := i.condition.accepted(translator, p)
+		if err != nil || !matched {
+			return "", nil, err
+		}
+	}
 	if i.sqlNode == nil {
 		// lazy loading
 		// does it need to be thread safe?
@@ -830,7 +1139,8 @@ var _ Node = (*IncludeNode)(nil)
 //  1. Evaluates each <when> condition in order
 //  2. Executes SQL from first matching condition
 //  3. If no conditions match, executes <otherwise> if present
-//  4. If no conditions match and no otherwise, returns empty result
+//  4. If no conditions match and no otherwise, returns empty result,
+//     or ErrNoChooseBranchMatched when the choose is marked as required="true"
 //
 // Usage scenarios:
 //  1. Complex conditional logic in WHERE clauses
@@ -852,6 +1162,11 @@ var _ Node = (*IncludeNode)(nil)
 type ChooseNode struct {
 	WhenNodes     []Node
 	OtherwiseNode Node
+
+	// Required reports whether one of the branches must be matched.
+	// It is useful to turn a misconfigured choose, which silently drops
+	// its SQL fragment, into a loud failure.
+	Required bool
 }
 
 // Accept accepts parameters and returns query and arguments.
@@ -870,6 +1185,9 @@ func (c ChooseNode) Accept(translator driver.Translator, p Parameter) (query str
 	if c.OtherwiseNode != nil {
 		return c.OtherwiseNode.Accept(translator, p)
 	}
+	if c.Required {
+		return "", nil, ErrNoChooseBranchMatched
+	}
 	return "", nil, nil
 }
 
@@ -964,10 +1282,218 @@ func (o OtherwiseNode) Accept(translator driver.Translator, p Parameter) (query
 
 var _ Node = (*OtherwiseNode)(nil)
 
+// maxLimitSetting is the setting name of the global maximum of LimitNode.
+const maxLimitSetting = "maxLimit"
+
+// LimitNode emits a LIMIT clause whose requested size is clamped to a maximum,
+// preventing clients from requesting huge pages.
+//
+// Example XML:
+//
+//	<limit value="pageSize" offset="offset" max="100"/>
+//
+// Example results:
+//
+//	pageSize = 20:   LIMIT ? OFFSET ?   (args: 20, offset)
+//	pageSize = 1000: LIMIT ? OFFSET ?   (args: 100, offset)
+//
+// The clause is rendered by the dialect of the driver, see driver.PaginationClause,
+// like OFFSET ? ROWS FETCH NEXT ? ROWS ONLY of SQL Server and Oracle. The offset is
+// zero if the offset attribute is not given. Negative sizes and offsets are rejected.
+//
+// The maximum is taken from the max attribute, or the maxLimit setting
+// of the configuration if the attribute is not given:
+//
+//	<settings>
+//	    <setting name="maxLimit" value="100"/>
+//	</settings>
+//
+// The requested size is not clamped when neither of them is set.
+type LimitNode struct {
+	// Value is the name of the parameter of the requested size.
+	Value string
+
+	// Offset is the name of the parameter of the offset, optional.
+	Offset string
+
+	// Max is the maximum size, zero means using the maxLimit setting.
+	Max int64
+
+	mapper *Mapper
+}
+
+// Accept accepts parameters and returns query and arguments.
+func (l *LimitNode) Accept(translator driver.Translator, p Parameter) (query string, args []any, err error) {
+	value, exists := p.Get(l.Value)
+	if !exists {
+		return "", nil, fmt.Errorf("parameter %s not found", l.Value)
+	}
+	limit, err := limitValue(value)
+	if err != nil {
+		return "", nil, fmt.Errorf("limit %s: %w", l.Value, err)
+	}
+	// some databases, like SQLite, treat a negative limit as no limit.
+	if limit < 0 {
+		return "", nil, fmt.Errorf("limit %s: negative value %d", l.Value, limit)
+	}
+	if maxLimit := l.max(); maxLimit > 0 {
+		limit = min(limit, maxLimit)
+	}
+	var offset int64
+	if l.Offset != "" {
+		value, exists := p.Get(l.Offset)
+		if !exists {
+			return "", nil, fmt.Errorf("parameter %s not found", l.Offset)
+		}
+		if offset, err = limitValue(value); err != nil {
+			return "", nil, fmt.Errorf("offset %s: %w", l.Offset, err)
+		}
+		if offset < 0 {
+			return "", nil, fmt.Errorf("offset %s: negative value %d", l.Offset, offset)
+		}
+	}
+	query, args = driver.PaginationClause(limitTranslator{Translator: translator, limit: l}, limit, offset)
+	return query, args, nil
+}
+
+// limitTranslator is a driver.Translator which translates the placeholders of the pagination
+// clause by the parameter names of the LimitNode, so that the named args keep their names.
+type limitTranslator struct {
+	driver.Translator
+	limit *LimitNode
+}
+
+// Translate implements driver.Translator.
+func (t limitTranslator) Translate(matched string) string {
+	switch {
+	case matched == "limit":
+		matched = t.limit.Value
+	case matched == "offset" && t.limit.Offset != "":
+		matched = t.limit.Offset
+	}
+	return t.Translator.Translate(matched)
+}
+
+// Unwrap returns the wrapped translator, so that the capabilities of the driver are reachable.
+func (t limitTranslator) Unwrap() driver.Translator {
+	return t.Translator
+}
+
+// max returns the maximum size of the limit.
+func (l *LimitNode) max() int64 {
+	if l.Max > 0 {
+		return l.Max
+	}
+	if l.mapper == nil || l.mapper.mappers == nil || l.mapper.mappers.Configuration() == nil {
+		return 0
+	}
+	return l.mapper.mappers.Configuration().Settings().Get(maxLimitSetting).Int64()
+}
+
+// limitValue converts the requested size to int64.
+func limitValue(value reflect.Value) (int64, error) {
+	value = reflectlite.Unwrap(value)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if value.Uint() > math.MaxInt64 {
+			return math.MaxInt64, nil
+		}
+		return int64(value.Uint()), nil
+	default:
+		return 0, fmt.Errorf("expected an integer, got %s", value.Kind())
+	}
+}
+
+var _ Node = (*LimitNode)(nil)
+
+// defaultPartitionLayout is the default time layout of the suffix of PartitionNode.
+const defaultPartitionLayout = "2006_01"
+
+// PartitionNode emits the quoted name of a time-partitioned table, like events_2024_01,
+// whose suffix is formatted from a time parameter.
+// It is a safe alternative to building the table name with ${}.
+//
+// Example XML:
+//
+//	<partition table="events" value="createdAt" layout="2006_01" pattern="events_\d{4}_\d{2}"/>
+//
+// Example results:
+//
+//	createdAt = 2024-01-15 (MySQL):    `events_2024_01`
+//	createdAt = 2024-01-15 (Postgres): "events_2024_01"
+//
+// The table name is the table, an underscore and the time formatted by the layout,
+// which defaults to 2006_01. The whole name must match the pattern, otherwise
+// ErrPartitionNotAllowed is returned. The name is quoted by the driver, and
+// ErrIdentifierQuotingUnsupported is returned if the driver can not quote it.
+type PartitionNode struct {
+	// Table is the name of the table without the suffix.
+	Table string
+
+	// Value is the name of the time.Time parameter of the suffix.
+	Value string
+
+	// Layout is the time layout of the suffix.
+	Layout string
+
+	// Pattern is the pattern which the whole table name must match.
+	Pattern *regexp.Regexp
+}
+
+// Accept accepts parameters and returns query and arguments.
+func (n *PartitionNode) Accept(translator driver.Translator, p Parameter) (query string, args []any, err error) {
+	value, exists := p.Get(n.Value)
+	if !exists {
+		return "", nil, fmt.Errorf("parameter %s not found", n.Value)
+	}
+	value = reflectlite.Unwrap(value)
+	if !value.IsValid() || value.Type() != timeType {
+		return "", nil, fmt.Errorf("partition %s: expected a time.Time", n.Value)
+	}
+	layout := n.Layout
+	if layout == "" {
+		layout = defaultPartitionLayout
+	}
+	table := n.Table + "_" + value.Interface().(time.Time).Format(layout)
+	if n.Pattern == nil || !n.Pattern.MatchString(table) {
+		return "", nil, fmt.Errorf("%w: %q", ErrPartitionNotAllowed, table)
+	}
+	quoted, ok := driver.QuoteIdentifier(translator, table)
+	if !ok {
+		return "", nil, ErrIdentifierQuotingUnsupported
+	}
+	return quoted, nil, nil
+}
+
+var _ Node = (*PartitionNode)(nil)
+
 // valueItem is a element of ValuesNode.
 type valueItem struct {
 	column string
 	value  string
+
+	// raw reports whether the value is a raw SQL expression, like NOW() or DEFAULT,
+	// which is emitted as it is without any binding.
+	raw bool
+}
+
+// validate checks that all the parameters referenced by the value can be resolved.
+// It returns a valuesColumnSourceError naming the column if not.
+func (v valueItem) validate(p Parameter) error {
+	// the raw value binds nothing.
+	if v.raw {
+		return nil
+	}
+	for _, regex := range []*regexp.Regexp{paramRegex, formatRegexp} {
+		for _, matched := range regex.FindAllStringSubmatch(v.value, -1) {
+			if _, exists := p.Get(matched[1]); !exists {
+				return &valuesColumnSourceError{column: v.column, param: matched[1]}
+			}
+		}
+	}
+	return nil
 }
 
 // ValuesNode is a node of values.
@@ -979,6 +1505,9 @@ func (v ValuesNode) Accept(translator driver.Translator, param Parameter) (query
 	if len(v) == 0 {
 		return "", nil, nil
 	}
+	if err = v.validate(param); err != nil {
+		return "", nil, err
+	}
 	builder := getStringBuilder()
 	defer putStringBuilder(builder)
 	builder.WriteString("(")
@@ -990,6 +1519,16 @@ func (v ValuesNode) Accept(translator driver.Translator, param Parameter) (query
 	return node.Accept(translator, param)
 }
 
+// validate checks that the source parameters of all columns can be resolved.
+func (v ValuesNode) validate(param Parameter) error {
+	for _, item := range v {
+		if err := item.validate(param); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // columns returns columns of values.
 func (v ValuesNode) columns() string {
 	columns := make([]string, 0, len(v))
@@ -1008,6 +1547,108 @@ func (v ValuesNode) values() string {
 	return strings.Join(values, ", ")
 }
 
+// MultiRowsValuesNode is a ValuesNode which expands into a multi-row VALUES clause
+// when the parameter is a slice or array of structs or maps.
+//
+// For example, with the XML below:
+//
+//	<insert id="BatchInsertUser">
+//	    INSERT INTO user
+//	    <values>
+//	        <value column="id"/>
+//	        <value column="name"/>
+//	    </values>
+//	</insert>
+//
+// inserting []User{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}} produces:
+//
+//	INSERT INTO user (id, name) VALUES (?, ?), (?, ?)
+//
+// Each element is bound with the declared values, and falls back to the whole
+// parameter when a name can not be found in the element.
+// Otherwise, it behaves the same as ValuesNode.
+//
+// It works with the batchSize attribute of the insert statement, which splits
+// the collection into batches, and each batch is expanded into a multi-row VALUES clause.
+type MultiRowsValuesNode struct {
+	ValuesNode
+
+	// Collection is the name of the parameter which holds the rows.
+	// It is the paramName attribute of the statement, or the default param key.
+	Collection string
+}
+
+// Accept accepts parameters and returns query and arguments.
+func (m MultiRowsValuesNode) Accept(translator driver.Translator, param Parameter) (query string, args []any, err error) {
+	if len(m.ValuesNode) == 0 {
+		return "", nil, nil
+	}
+	rows, ok := m.rows(param)
+	if !ok {
+		return m.ValuesNode.Accept(translator, param)
+	}
+	if rows.Len() == 0 {
+		return "", nil, fmt.Errorf("values: collection %s is empty", m.Collection)
+	}
+	builder := getStringBuilder()
+	defer putStringBuilder(builder)
+	builder.WriteString("(")
+	builder.WriteString(m.columns())
+	builder.WriteString(") VALUES ")
+
+	row := NewTextNode("(" + m.values() + ")")
+
+	// group wraps parameter
+	// nil is for placeholder
+	group := eval.ParamGroup{nil, param}
+
+	for i := 0; i < rows.Len(); i++ {
+		group[0] = eval.NewGenericParam(rows.Index(i).Interface(), "")
+		if err = m.validate(group); err != nil {
+			return "", nil, fmt.Errorf("values row %d: %w", i, err)
+		}
+		q, a, err := row.Accept(translator, group)
+		if err != nil {
+			return "", nil, err
+		}
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString(q)
+		args = append(args, a...)
+	}
+	return builder.String(), args, nil
+}
+
+// rows returns the rows to insert if the collection is a slice or array of structs or maps.
+func (m MultiRowsValuesNode) rows(param Parameter) (reflect.Value, bool) {
+	value, exists := param.Get(m.Collection)
+	if !exists {
+		return reflect.Value{}, false
+	}
+	value = reflectlite.Unwrap(value)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+	default:
+		return reflect.Value{}, false
+	}
+	switch reflectlite.IndirectType(value.Type().Elem()).Kind() {
+	case reflect.Struct, reflect.Map:
+		return value, true
+	case reflect.Interface:
+		// []any, check the first element
+		if value.Len() > 0 {
+			switch reflectlite.Unwrap(value.Index(0)).Kind() {
+			case reflect.Struct, reflect.Map:
+				return value, true
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
+
+var _ Node = (*MultiRowsValuesNode)(nil)
+
 // selectFieldAliasItem is a element of SelectFieldAliasNode.
 type selectFieldAliasItem struct {
 	column string
@@ -17,9 +17,18 @@ limitations under the License.
 package juice
 
 import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-juicedev/juice/driver"
+	"github.com/go-juicedev/juice/eval"
 )
 
 func TestForeachNode_Accept(t *testing.T) {
@@ -99,6 +108,28 @@ func TestForeachMapNode_Accept(t *testing.T) {
 	}
 }
 
+func TestForeachMapNode_SortedKeys(t *testing.T) {
+	drv := driver.MySQLDriver{}
+	node := ForeachNode{
+		Nodes:      []Node{NewTextNode("#{index} = #{item}")},
+		Item:       "item",
+		Index:      "index",
+		Collection: "map",
+		Separator:  ", ",
+	}
+	params := H{"map": map[string]any{"c": 3, "a": 1, "d": 4, "b": 2}}
+	for i := 0; i < 10; i++ {
+		_, args, err := node.Accept(drv.Translator(), params.AsParam())
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []any{"a", 1, "b", 2, "c", 3, "d", 4}
+		if !reflect.DeepEqual(args, expected) {
+			t.Fatalf("unexpected args: %v", args)
+		}
+	}
+}
+
 func TestIfNode_Accept(t *testing.T) {
 	drv := driver.MySQLDriver{}
 	node1 := NewTextNode("select * from user where id = #{id}")
@@ -202,6 +233,50 @@ func TestWhereNode_Accept(t *testing.T) {
 
 }
 
+func TestWhereNode_AcceptLeadingKeyword(t *testing.T) {
+	drv := driver.MySQLDriver{}
+	tests := map[string]string{
+		"and\tid = 1":            "WHERE id = 1",
+		"AND  id = 1":            "WHERE id = 1",
+		"Or\nid = 1":             "WHERE id = 1",
+		"\n\t AND id = 1":        "WHERE id = 1",
+		"AND NOT deleted":        "WHERE NOT deleted",
+		"NOT deleted":            "WHERE NOT deleted",
+		"where\tid = 1":          "where\tid = 1",
+		"android_id = 1":         "WHERE android_id = 1",
+		"order_id = 1":           "WHERE order_id = 1",
+		"\t  ":                   "",
+		"ANDROID = 1 AND id = 1": "WHERE ANDROID = 1 AND id = 1",
+	}
+	for input, expected := range tests {
+		node := WhereNode{Nodes: NodeGroup{pureTextNode(input)}}
+		query, _, err := node.Accept(drv.Translator(), newGenericParam(H{}, ""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if query != expected {
+			t.Errorf("input %q: expected %q, got %q", input, expected, query)
+		}
+	}
+}
+
+func TestWhereNode_AcceptWhitespaceLedConditions(t *testing.T) {
+	stmt := parseTestStatement(t, Select, "<select id=\"QueryUsers\">SELECT * FROM user<where>"+
+		"<if test=\"id > 0\">\n\t\tand\tid = #{id}</if>"+
+		"<if test=\"len(name) > 0\">\n\t\tOR\tname = #{name}</if>"+
+		"</where></select>")
+	query, args, err := stmt.Build(driver.MySQLDriver{}.Translator(), H{"id": 0, "name": "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM user WHERE name = ?" {
+		t.Errorf("unexpected query %q", query)
+	}
+	if len(args) != 1 || args[0] != "a" {
+		t.Errorf("unexpected args %v", args)
+	}
+}
+
 func TestTrimNode_Accept(t *testing.T) {
 	drv := driver.MySQLDriver{}
 	node1 := NewTextNode("name,")
@@ -238,6 +313,46 @@ func TestTrimNode_Accept(t *testing.T) {
 
 }
 
+func TestTrimNode_LongestOverride(t *testing.T) {
+	drv := driver.MySQLDriver{}
+	node := &TrimNode{
+		Nodes:           []Node{NewTextNode("AND NOT deleted")},
+		Prefix:          "WHERE ",
+		PrefixOverrides: []string{"AND", "AND NOT"},
+	}
+	query, _, err := node.Accept(drv.Translator(), H{}.AsParam())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "WHERE  deleted" {
+		t.Errorf("unexpected query: %q", query)
+	}
+}
+
+func TestTrimNode_CaseInsensitive(t *testing.T) {
+	drv := driver.MySQLDriver{}
+	node := &TrimNode{
+		Nodes:           []Node{NewTextNode("and not deleted, name = #{name} Or")},
+		PrefixOverrides: []string{"AND", "AND NOT"},
+		SuffixOverrides: []string{"OR"},
+	}
+	query, _, err := node.Accept(drv.Translator(), H{"name": "a"}.AsParam())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "and not deleted, name = ? Or" {
+		t.Errorf("unexpected query: %q", query)
+	}
+	node.CaseInsensitive = true
+	query, _, err = node.Accept(drv.Translator(), H{"name": "a"}.AsParam())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != " deleted, name = ? " {
+		t.Errorf("unexpected query: %q", query)
+	}
+}
+
 func TestSetNode_Accept(t *testing.T) {
 	drv := driver.MySQLDriver{}
 	node1 := NewTextNode("id = #{id},")
@@ -269,3 +384,517 @@ func TestSetNode_Accept(t *testing.T) {
 		return
 	}
 }
+
+func TestForeachNode_FirstLast(t *testing.T) {
+	drv := driver.MySQLDriver{}
+	notFirst := &IfNode{Nodes: []Node{NewTextNode(",")}}
+	if err := notFirst.Parse("!__first"); err != nil {
+		t.Fatal(err)
+	}
+	last := &IfNode{Nodes: []Node{NewTextNode("/* last */")}}
+	if err := last.Parse("__last"); err != nil {
+		t.Fatal(err)
+	}
+	node := ForeachNode{
+		Nodes:      []Node{notFirst, NewTextNode("#{item}"), last},
+		Item:       "item",
+		Collection: "list",
+	}
+	params := H{"list": []int{1, 2, 3}}
+	query, args, err := node.Accept(drv.Translator(), params.AsParam())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "?,?,?/* last */" {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if len(args) != 3 {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
+
+func TestForeachNode_Nilable(t *testing.T) {
+	drv := driver.MySQLDriver{}
+	node := ForeachNode{
+		Nodes:      []Node{NewTextNode("#{id}")},
+		Item:       "id",
+		Collection: "ids",
+		Open:       "AND id IN (",
+		Separator:  ",",
+		Close:      ")",
+		Nilable:    true,
+	}
+	tests := []struct {
+		name  string
+		param H
+	}{
+		{"absent key", H{}},
+		{"nil", H{"ids": nil}},
+		{"nil slice", H{"ids": []int(nil)}},
+		{"nil map", H{"ids": map[string]int(nil)}},
+		{"nil pointer", H{"ids": (*[]int)(nil)}},
+	}
+	for _, tt := range tests {
+		query, args, err := node.Accept(drv.Translator(), tt.param.AsParam())
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if query != "" || len(args) != 0 {
+			t.Errorf("%s: unexpected result: %q %v", tt.name, query, args)
+		}
+	}
+
+	query, args, err := node.Accept(drv.Translator(), H{"ids": []int{1, 2}}.AsParam())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "AND id IN (?,?)" || len(args) != 2 {
+		t.Errorf("unexpected result: %q %v", query, args)
+	}
+
+	// not nilable by default
+	node.Nilable = false
+	if _, _, err = node.Accept(drv.Translator(), H{}.AsParam()); err == nil {
+		t.Error("absent key: expected error")
+	}
+	if _, _, err = node.Accept(drv.Translator(), H{"ids": nil}.AsParam()); err == nil {
+		t.Error("nil: expected error")
+	}
+	// nil slices and maps are always empty collections.
+	for _, param := range []H{{"ids": []int(nil)}, {"ids": map[string]int(nil)}} {
+		if query, _, err = node.Accept(drv.Translator(), param.AsParam()); err != nil || query != "" {
+			t.Errorf("unexpected result: %q %v", query, err)
+		}
+	}
+}
+
+func TestForeachNode_Split(t *testing.T) {
+	drv := driver.MySQLDriver{}
+	node := ForeachNode{
+		Nodes:      []Node{NewTextNode("#{id}")},
+		Item:       "id",
+		Collection: "ids",
+		Open:       "(",
+		Separator:  ",",
+		Close:      ")",
+		Split:      ",",
+	}
+	tests := []struct {
+		name  string
+		ids   string
+		query string
+		args  []any
+	}{
+		{"parts", " 1, 2 ,3", "(?,?,?)", []any{"1", "2", "3"}},
+		{"trailing comma", "1,2,", "(?,?)", []any{"1", "2"}},
+		{"empty string", "", "", nil},
+		{"separators only", " , ,", "", nil},
+	}
+	for _, tt := range tests {
+		query, args, err := node.Accept(drv.Translator(), H{"ids": tt.ids}.AsParam())
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if query != tt.query || !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%s: unexpected result: %q %v", tt.name, query, args)
+		}
+	}
+
+	// the string collection is not iterable without split.
+	node.Split = ""
+	if _, _, err := node.Accept(drv.Translator(), H{"ids": "1,2"}.AsParam()); err == nil {
+		t.Error("expected error for string collection without split")
+	}
+}
+
+func TestForeachNode_IndexNotSet(t *testing.T) {
+	node := ForeachNode{Item: "item", Collection: "list"}
+	iteration := &foreachParameter{node: &node}
+	iteration.reset(reflect.ValueOf(1), reflect.ValueOf(0), true, true)
+	if _, exists := iteration.Get(""); exists {
+		t.Error("index should not be exposed when it is not set")
+	}
+}
+
+func TestForeachNode_Nested(t *testing.T) {
+	type tag struct {
+		Name string `param:"name"`
+	}
+	type post struct {
+		ID   int   `param:"id"`
+		Tags []tag `param:"tags"`
+	}
+	inner := ForeachNode{Collection: "post.tags", Item: "tag", Separator: ",", Nodes: []Node{NewTextNode("(#{post.id}, #{tag.name}, #{i})")}}
+	outer := ForeachNode{Collection: "posts", Item: "post", Index: "i", Separator: ",", Nodes: []Node{inner}}
+	posts := []post{{ID: 1, Tags: []tag{{Name: "a"}, {Name: "b"}}}, {ID: 2, Tags: []tag{{Name: "c"}}}}
+	query, args, err := outer.Accept(driver.MySQLDriver{}.Translator(), H{"posts": posts}.AsParam())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "(?, ?, ?),(?, ?, ?),(?, ?, ?)" {
+		t.Errorf("unexpected query: %s", query)
+	}
+	expected := []any{1, "a", 0, 1, "b", 0, 2, "c", 1}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
+
+func TestChooseNode_Required(t *testing.T) {
+	drv := driver.MySQLDriver{}
+	decoder := xml.NewDecoder(strings.NewReader(`<choose required="true"><when test="id > 0">id = #{id}</when></choose>`))
+	token, err := decoder.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser := &XMLMappersElementParser{}
+	node, err := parser.parseTags(&Mapper{}, decoder, token.(xml.StartElement))
+	if err != nil {
+		t.Fatal(err)
+	}
+	query, args, err := node.Accept(drv.Translator(), H{"id": 1}.AsParam())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "id = ?" || len(args) != 1 {
+		t.Errorf("unexpected query: %s %v", query, args)
+	}
+	_, _, err = node.Accept(drv.Translator(), H{"id": 0}.AsParam())
+	if !errors.Is(err, ErrNoChooseBranchMatched) {
+		t.Errorf("expected ErrNoChooseBranchMatched, got %v", err)
+	}
+
+	// not required by default
+	chooseNode := node.(*ChooseNode)
+	chooseNode.Required = false
+	query, _, err = chooseNode.Accept(drv.Translator(), H{"id": 0}.AsParam())
+	if err != nil || query != "" {
+		t.Errorf("unexpected result: %q %v", query, err)
+	}
+}
+
+func TestIfNode_Else(t *testing.T) {
+	drv := driver.MySQLDriver{}
+	decoder := xml.NewDecoder(strings.NewReader(`<if test="id > 0">id = #{id}<else/>status = #{status}</if>`))
+	token, err := decoder.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser := &XMLMappersElementParser{}
+	node, err := parser.parseTags(&Mapper{}, decoder, token.(xml.StartElement))
+	if err != nil {
+		t.Fatal(err)
+	}
+	query, args, err := node.Accept(drv.Translator(), H{"id": 1, "status": 2}.AsParam())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "id = ?" || !reflect.DeepEqual(args, []any{1}) {
+		t.Errorf("unexpected result: %s %v", query, args)
+	}
+	query, args, err = node.Accept(drv.Translator(), H{"id": 0, "status": 2}.AsParam())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "status = ?" || !reflect.DeepEqual(args, []any{2}) {
+		t.Errorf("unexpected result: %s %v", query, args)
+	}
+
+	decoder = xml.NewDecoder(strings.NewReader(`<if test="id > 0">a<else/>b<else/>c</if>`))
+	if token, err = decoder.Token(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = parser.parseTags(&Mapper{}, decoder, token.(xml.StartElement)); err == nil {
+		t.Error("expected error for duplicate else")
+	}
+}
+
+func TestMultiRowsValuesNode_Accept(t *testing.T) {
+	type row struct {
+		ID   int64  `param:"id"`
+		Name string `param:"name"`
+	}
+	drv := driver.MySQLDriver{}
+	node := MultiRowsValuesNode{
+		ValuesNode: ValuesNode{
+			{column: "id", value: "#{id}"},
+			{column: "name", value: "#{name}"},
+			{column: "created_by", value: "#{operator}"},
+		},
+		Collection: "param",
+	}
+	newParam := func(rows any) Parameter {
+		return eval.ParamGroup{eval.NewGenericParam(rows, ""), H{"operator": "admin"}.AsParam()}
+	}
+
+	// single struct
+	query, args, err := node.Accept(drv.Translator(), newParam(row{ID: 1, Name: "a"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "(id, name, created_by) VALUES (?, ?, ?)" || !reflect.DeepEqual(args, []any{int64(1), "a", "admin"}) {
+		t.Errorf("unexpected result: %s %v", query, args)
+	}
+
+	for _, count := range []int{1, 2, 10} {
+		rows := make([]row, 0, count)
+		expectedArgs := make([]any, 0, count*3)
+		placeholders := make([]string, 0, count)
+		for i := 0; i < count; i++ {
+			rows = append(rows, row{ID: int64(i), Name: strconv.Itoa(i)})
+			expectedArgs = append(expectedArgs, int64(i), strconv.Itoa(i), "admin")
+			placeholders = append(placeholders, "(?, ?, ?)")
+		}
+		query, args, err = node.Accept(drv.Translator(), newParam(rows))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := "(id, name, created_by) VALUES " + strings.Join(placeholders, ", "); query != expected {
+			t.Errorf("unexpected query for %d rows: %s", count, query)
+		}
+		if !reflect.DeepEqual(args, expectedArgs) {
+			t.Errorf("unexpected args for %d rows: %v", count, args)
+		}
+	}
+
+	if _, _, err = node.Accept(drv.Translator(), newParam([]row{})); err == nil {
+		t.Error("expected error for empty collection")
+	}
+}
+
+func TestMultiRowsValuesNode_BatchSize(t *testing.T) {
+	type row struct {
+		ID int64 `param:"id"`
+	}
+	db, state := newFakeDB(t, fakeResultSet{})
+	statement := &xmlSQLStatement{
+		mapper: &Mapper{namespace: "main.UserMapper", mappers: &Mappers{}},
+		action: Insert,
+		id:     "BatchInsertUser",
+		Nodes: NodeGroup{
+			NewTextNode("INSERT INTO user"),
+			&MultiRowsValuesNode{ValuesNode: ValuesNode{{column: "id", value: "#{id}"}}, Collection: "param"},
+		},
+	}
+	statement.setAttribute("batchSize", "2")
+	handler := NewDefaultStatementHandler(driver.MySQLDriver{}, db)
+	rows := []row{{1}, {2}, {3}, {4}, {5}}
+	if _, err := handler.ExecContext(context.Background(), statement, rows); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"INSERT INTO user (id) VALUES (?), (?)",
+		"INSERT INTO user (id) VALUES (?), (?)",
+		"INSERT INTO user (id) VALUES (?)",
+	}
+	if len(state.executions) != len(expected) {
+		t.Fatalf("unexpected executions: %v", state.executions)
+	}
+	for i, execution := range state.executions {
+		if execution.query != expected[i] {
+			t.Errorf("unexpected query at %d: %s", i, execution.query)
+		}
+	}
+}
+
+func TestValuesNode_MissingSourceColumn(t *testing.T) {
+	drv := driver.MySQLDriver{}
+	node := ValuesNode{
+		{column: "id", value: "#{id}"},
+		{column: "name", value: "#{name}"},
+	}
+	_, _, err := node.Accept(drv.Translator(), H{"id": 1}.AsParam())
+	var sourceErr *valuesColumnSourceError
+	if !errors.As(err, &sourceErr) {
+		t.Fatalf("expected valuesColumnSourceError, got %v", err)
+	}
+	if sourceErr.column != "name" || sourceErr.param != "name" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	type row struct {
+		ID int64 `param:"id"`
+	}
+	multiRowsNode := MultiRowsValuesNode{ValuesNode: node, Collection: "param"}
+	_, _, err = multiRowsNode.Accept(drv.Translator(), eval.NewGenericParam([]row{{1}}, ""))
+	if !errors.As(err, &sourceErr) || sourceErr.column != "name" {
+		t.Errorf("expected valuesColumnSourceError of column name, got %v", err)
+	}
+	if err.Error() != "values row 0: values column name: source parameter name not found" {
+		t.Errorf("unexpected error message: %v", err)
+	}
+}
+
+func TestLimitNode_Accept(t *testing.T) {
+	drv := driver.MySQLDriver{}
+	node := &LimitNode{Value: "pageSize", Offset: "offset", Max: 100}
+
+	query, args, err := node.Accept(drv.Translator(), H{"pageSize": 20, "offset": 40}.AsParam())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "LIMIT ? OFFSET ?" || !reflect.DeepEqual(args, []any{int64(20), int64(40)}) {
+		t.Errorf("unexpected result: %s %v", query, args)
+	}
+
+	// over-limit requests are clamped
+	_, args, err = node.Accept(drv.Translator(), H{"pageSize": uint(1000), "offset": 0}.AsParam())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args[0] != int64(100) {
+		t.Errorf("expected limit clamped to 100, got %v", args[0])
+	}
+
+	if _, _, err = node.Accept(drv.Translator(), H{"pageSize": "10", "offset": 0}.AsParam()); err == nil {
+		t.Error("expected error for non-integer limit")
+	}
+
+	// negative values would mean no limit for some databases.
+	if _, _, err = node.Accept(drv.Translator(), H{"pageSize": -1, "offset": 0}.AsParam()); err == nil {
+		t.Error("expected error for negative limit")
+	}
+	if _, _, err = node.Accept(drv.Translator(), H{"pageSize": 10, "offset": -1}.AsParam()); err == nil {
+		t.Error("expected error for negative offset")
+	}
+}
+
+func TestLimitNode_Dialect(t *testing.T) {
+	node := &LimitNode{Value: "pageSize", Offset: "offset"}
+	param := H{"pageSize": 20, "offset": 40}.AsParam()
+
+	query, args, err := node.Accept(driver.SQLServerDriver{}.Translator(), param)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "OFFSET @p1 ROWS FETCH NEXT @p2 ROWS ONLY" || !reflect.DeepEqual(args, []any{int64(40), int64(20)}) {
+		t.Errorf("unexpected result: %s %v", query, args)
+	}
+
+	// the named args are named by the parameters.
+	query, _, err = node.Accept(driver.SQLServerDriver{NamedArgs: true}.Translator(), param)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "OFFSET @offset ROWS FETCH NEXT @pageSize ROWS ONLY" {
+		t.Errorf("unexpected query: %s", query)
+	}
+}
+
+func TestLimitNode_MaxLimitSetting(t *testing.T) {
+	drv := driver.MySQLDriver{}
+	cfg := &Configuration{settings: keyValueSettingProvider{"maxLimit": "50"}}
+	mapper := &Mapper{namespace: "main.UserMapper", mappers: &Mappers{cfg: cfg}}
+	node := &LimitNode{Value: "pageSize", mapper: mapper}
+	query, args, err := node.Accept(drv.Translator(), H{"pageSize": 80}.AsParam())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "LIMIT ? OFFSET ?" || !reflect.DeepEqual(args, []any{int64(50), int64(0)}) {
+		t.Errorf("unexpected result: %s %v", query, args)
+	}
+}
+
+func TestPartitionNode_Accept(t *testing.T) {
+	node := &PartitionNode{Table: "events", Value: "createdAt", Pattern: regexp.MustCompile(`^events_\d{4}_\d{2}$`)}
+	createdAt := time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC)
+
+	query, args, err := node.Accept(driver.MySQLDriver{}.Translator(), H{"createdAt": createdAt}.AsParam())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "`events_2024_01`" || len(args) != 0 {
+		t.Errorf("unexpected result: %s %v", query, args)
+	}
+
+	query, _, err = node.Accept(driver.PostgresDriver{}.Translator(), H{"createdAt": &createdAt}.AsParam())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != `"events_2024_01"` {
+		t.Errorf("unexpected result: %s", query)
+	}
+
+	node.Layout = "2006_01_02"
+	if _, _, err = node.Accept(driver.MySQLDriver{}.Translator(), H{"createdAt": createdAt}.AsParam()); !errors.Is(err, ErrPartitionNotAllowed) {
+		t.Errorf("expected ErrPartitionNotAllowed, got %v", err)
+	}
+
+	if _, _, err = node.Accept(driver.MySQLDriver{}.Translator(), H{"createdAt": "2024_01"}.AsParam()); err == nil {
+		t.Error("expected error for non-time value")
+	}
+
+	node.Layout = ""
+	translator := driver.TranslateFunc(func(string) string { return "?" })
+	if _, _, err = node.Accept(translator, H{"createdAt": createdAt}.AsParam()); !errors.Is(err, ErrIdentifierQuotingUnsupported) {
+		t.Errorf("expected ErrIdentifierQuotingUnsupported, got %v", err)
+	}
+}
+
+func BenchmarkForeachNode(b *testing.B) {
+	type user struct {
+		ID   int64  `param:"id"`
+		Name string `param:"name"`
+	}
+	ids := make([]int64, 10000)
+	users := make([]user, 10000)
+	for i := range ids {
+		ids[i] = int64(i)
+		users[i] = user{ID: int64(i), Name: "eatmoreapple"}
+	}
+	translator := driver.MySQLDriver{}.Translator()
+
+	b.Run("in", func(b *testing.B) {
+		node := ForeachNode{Collection: "ids", Item: "id", Open: "(", Close: ")", Separator: ", ", Nodes: []Node{NewTextNode("#{id}")}}
+		param := H{"ids": ids}.AsParam()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, _, err := node.Accept(translator, param); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("values", func(b *testing.B) {
+		node := ForeachNode{Collection: "users", Item: "user", Index: "i", Separator: ", ", Nodes: []Node{NewTextNode("(#{i}, #{user.id}, #{user.name})")}}
+		param := H{"users": users}.AsParam()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, _, err := node.Accept(translator, param); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestIncludeNode_Test(t *testing.T) {
+	stmt := parseTestStatement(t, Select, `<select id="QueryUsers">
+		SELECT * FROM user WHERE status = 1 <include refid="byTenant" test="tenantId != 0"/>
+	</select>`)
+	stmt.mapper.sqlNodes = map[string]*SQLNode{
+		"byTenant": {id: "byTenant", nodes: NodeGroup{NewTextNode("AND tenant_id = #{tenantId}")}},
+	}
+	translator := driver.MySQLDriver{}.Translator()
+
+	query, args, err := stmt.Build(translator, eval.H{"tenantId": 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM user WHERE status = 1" || len(args) != 0 {
+		t.Errorf("unexpected query: %s, %v", query, args)
+	}
+
+	query, args, err = stmt.Build(translator, eval.H{"tenantId": 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM user WHERE status = 1 AND tenant_id = ?" || len(args) != 1 || args[0] != 7 {
+		t.Errorf("unexpected query: %s, %v", query, args)
+	}
+
+	if _, _, err = stmt.Build(translator, eval.H{}); err == nil {
+		t.Error("expected error for undefined identifier")
+	}
+}
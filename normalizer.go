@@ -0,0 +1,161 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ParameterNormalizer transforms the values of the named parameters before they are bound
+// by the #{} placeholders, like trimming the strings or lower casing the emails, so that
+// the data shapes are enforced at the persistence boundary instead of each call.
+type ParameterNormalizer interface {
+	// Normalize returns the normalized value of the named parameter.
+	// An error aborts the statement.
+	Normalize(name string, value any) (any, error)
+}
+
+// ParameterNormalizerFunc is an adapter to allow the use of ordinary functions as ParameterNormalizer.
+type ParameterNormalizerFunc func(name string, value any) (any, error)
+
+// Normalize implements ParameterNormalizer.
+func (f ParameterNormalizerFunc) Normalize(name string, value any) (any, error) {
+	return f(name, value)
+}
+
+// TrimSpaceNormalizer removes the leading and trailing white spaces of the string values.
+// The values of other types are returned as they are.
+var TrimSpaceNormalizer ParameterNormalizer = stringNormalizer(strings.TrimSpace)
+
+// LowerCaseNormalizer lower cases the string values.
+// The values of other types are returned as they are.
+var LowerCaseNormalizer ParameterNormalizer = stringNormalizer(strings.ToLower)
+
+// stringNormalizer is a ParameterNormalizer which transforms the string values.
+type stringNormalizer func(string) string
+
+// Normalize implements ParameterNormalizer.
+func (s stringNormalizer) Normalize(_ string, value any) (any, error) {
+	if text, ok := value.(string); ok {
+		return s(text), nil
+	}
+	return value, nil
+}
+
+// parameterNormalizerEntry is a registered ParameterNormalizer with its name pattern.
+type parameterNormalizerEntry struct {
+	pattern    string
+	normalizer ParameterNormalizer
+
+	// match is the pattern with the dots replaced by the separator of path.Match.
+	match string
+}
+
+var (
+	// parameterNormalizers holds the registered normalizers in the registration order.
+	// It is replaced on registration, so that the lookups don't need the lock.
+	parameterNormalizers atomic.Pointer[[]parameterNormalizerEntry]
+
+	// parameterNormalizersMu serializes the registrations.
+	parameterNormalizersMu sync.Mutex
+)
+
+// RegisterParameterNormalizer registers a ParameterNormalizer of the parameters whose names
+// match the pattern, with the syntax of path.Match where the separator is the dot:
+// "email" matches the parameter email only, "*.email" matches user.email but not email,
+// and "*" matches all the parameters without a dot.
+//
+// The normalizers are off by default. A parameter is normalized by all the matching
+// normalizers in the order they are registered, each one receives the value returned by
+// the previous one. The normalized values are bound the same way as the original ones,
+// by the type handlers, and validated if they are of the registered enum types.
+func RegisterParameterNormalizer(pattern string, normalizer ParameterNormalizer) {
+	if normalizer == nil {
+		panic("juice: parameter normalizer is nil")
+	}
+	match := strings.ReplaceAll(pattern, ".", "/")
+	if _, err := path.Match(match, ""); err != nil {
+		panic(fmt.Sprintf("juice: invalid parameter normalizer pattern %q: %v", pattern, err))
+	}
+	parameterNormalizersMu.Lock()
+	defer parameterNormalizersMu.Unlock()
+	var entries []parameterNormalizerEntry
+	if current := parameterNormalizers.Load(); current != nil {
+		entries = slices.Clone(*current)
+	}
+	entries = append(entries, parameterNormalizerEntry{pattern: pattern, normalizer: normalizer, match: match})
+	parameterNormalizers.Store(&entries)
+}
+
+// UnregisterParameterNormalizer removes all the normalizers registered with the pattern.
+func UnregisterParameterNormalizer(pattern string) {
+	parameterNormalizersMu.Lock()
+	defer parameterNormalizersMu.Unlock()
+	current := parameterNormalizers.Load()
+	if current == nil {
+		return
+	}
+	entries := slices.DeleteFunc(slices.Clone(*current), func(entry parameterNormalizerEntry) bool {
+		return entry.pattern == pattern
+	})
+	parameterNormalizers.Store(&entries)
+}
+
+// normalizeParameter applies the registered normalizers matching the name to the value.
+func normalizeParameter(name string, value reflect.Value) (reflect.Value, error) {
+	entries := parameterNormalizers.Load()
+	if entries == nil || len(*entries) == 0 {
+		return value, nil
+	}
+	var (
+		normalized any
+		matched    bool
+	)
+	// the dot is the separator of the names, instead of the slash of the paths.
+	target := strings.ReplaceAll(name, ".", "/")
+	for _, entry := range *entries {
+		if ok, _ := path.Match(entry.match, target); !ok {
+			continue
+		}
+		if !matched {
+			normalized, matched = valueInterface(value), true
+		}
+		var err error
+		if normalized, err = entry.normalizer.Normalize(name, normalized); err != nil {
+			return reflect.Value{}, fmt.Errorf("parameter %s: %w", name, err)
+		}
+	}
+	if !matched {
+		return value, nil
+	}
+	// keep the interface type, so that a nil value is still valid.
+	return reflect.ValueOf(&normalized).Elem(), nil
+}
+
+// valueInterface returns the interface of the value, nil for the invalid one.
+func valueInterface(value reflect.Value) any {
+	if !value.IsValid() {
+		return nil
+	}
+	return value.Interface()
+}
@@ -0,0 +1,69 @@
+package juice
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-juicedev/juice/driver"
+)
+
+func TestParameterNormalizer(t *testing.T) {
+	RegisterParameterNormalizer("*", TrimSpaceNormalizer)
+	RegisterParameterNormalizer("*.email", TrimSpaceNormalizer)
+	RegisterParameterNormalizer("*.email", LowerCaseNormalizer)
+	t.Cleanup(func() {
+		UnregisterParameterNormalizer("*")
+		UnregisterParameterNormalizer("*.email")
+	})
+
+	type User struct {
+		Email string `param:"email"`
+		Name  string `param:"name"`
+	}
+	node := NewTextNode("INSERT INTO user (name, email, nick, age) VALUES (#{user.name}, #{user.email}, #{nick}, #{age})")
+	param := H{"user": User{Email: " Foo@Example.COM ", Name: " foo "}, "nick": " bar ", "age": 18}
+	query, args, err := node.Accept(driver.MySQLDriver{}.Translator(), param.AsParam())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "INSERT INTO user (name, email, nick, age) VALUES (?, ?, ?, ?)" {
+		t.Errorf("unexpected query: %s", query)
+	}
+	// user.name doesn't match any pattern, * doesn't match the dotted names.
+	if !reflect.DeepEqual(args, []any{" foo ", "foo@example.com", "bar", 18}) {
+		t.Errorf("unexpected args: %q", args)
+	}
+
+	UnregisterParameterNormalizer("*")
+	if _, args, err = node.Accept(driver.MySQLDriver{}.Translator(), param.AsParam()); err != nil || args[2] != " bar " {
+		t.Errorf("unexpected result: %q %v", args, err)
+	}
+}
+
+func TestParameterNormalizer_Error(t *testing.T) {
+	errInvalid := errors.New("invalid")
+	RegisterParameterNormalizer("code", ParameterNormalizerFunc(func(name string, value any) (any, error) {
+		if value == nil {
+			return nil, nil
+		}
+		return nil, errInvalid
+	}))
+	t.Cleanup(func() { UnregisterParameterNormalizer("code") })
+
+	node := NewTextNode("SELECT * FROM t WHERE code = #{code}")
+	if _, _, err := node.Accept(driver.MySQLDriver{}.Translator(), H{"code": "a"}.AsParam()); !errors.Is(err, errInvalid) {
+		t.Errorf("expected errInvalid, got %v", err)
+	}
+	_, args, err := node.Accept(driver.MySQLDriver{}.Translator(), H{"code": nil}.AsParam())
+	if err != nil || len(args) != 1 || args[0] != nil {
+		t.Errorf("unexpected result: %v %v", args, err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for the invalid pattern")
+		}
+	}()
+	RegisterParameterNormalizer("[", TrimSpaceNormalizer)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"context"
+)
+
+// NoRowsPolicy defines how a GenericExecutor handles a query which returns no rows
+// while a single row is expected.
+type NoRowsPolicy string
+
+const (
+	// NoRowsError returns sql.ErrNoRows. It is the default policy.
+	NoRowsError NoRowsPolicy = "error"
+
+	// NoRowsZero returns the zero value of the result type without error,
+	// which is nil for pointer results.
+	NoRowsZero NoRowsPolicy = "zero"
+)
+
+// Valid reports whether the policy is one of the known policies.
+func (p NoRowsPolicy) Valid() bool {
+	switch p {
+	case NoRowsError, NoRowsZero:
+		return true
+	default:
+		return false
+	}
+}
+
+// noRowsAttribute is the attribute of the NoRowsPolicy of a statement.
+const noRowsAttribute = "noRows"
+
+type noRowsPolicyKey struct{}
+
+// ContextWithNoRowsPolicy returns a new context with the given NoRowsPolicy.
+// It overrides the noRows attribute of the statement for the queries executed with the context.
+func ContextWithNoRowsPolicy(ctx context.Context, policy NoRowsPolicy) context.Context {
+	return context.WithValue(ctx, noRowsPolicyKey{}, policy)
+}
+
+// NoRowsPolicyFromContext returns the NoRowsPolicy from the context.
+func NoRowsPolicyFromContext(ctx context.Context) (NoRowsPolicy, bool) {
+	policy, ok := ctx.Value(noRowsPolicyKey{}).(NoRowsPolicy)
+	return policy, ok
+}
+
+// noRowsPolicy returns the NoRowsPolicy of the query.
+// The policy from the context takes precedence over the noRows attribute of the statement,
+// for example:
+//
+//	<select id="GetUserByID" noRows="zero">
+//	    select * from user where id = #{id}
+//	</select>
+//
+// NoRowsError is returned if neither is set.
+func noRowsPolicy(ctx context.Context, stmt Statement) NoRowsPolicy {
+	if policy, ok := NoRowsPolicyFromContext(ctx); ok {
+		return policy
+	}
+	if policy := NoRowsPolicy(stmt.Attribute(noRowsAttribute)); policy != "" {
+		return policy
+	}
+	return NoRowsError
+}
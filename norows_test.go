@@ -0,0 +1,55 @@
+package juice
+
+import (
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGenericExecutor_NoRowsPolicy(t *testing.T) {
+	db, _ := newFakeDB(t, fakeResultSet{columns: []string{"id", "name"}})
+	statement := newFakeStatement("SELECT id, name FROM user WHERE id = 1")
+	executor := &GenericExecutor[*hookUser]{SQLRowsExecutor: newFakeExecutor(db, statement)}
+
+	// default policy
+	if _, err := executor.QueryContext(context.Background(), nil); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows, got %v", err)
+	}
+
+	// per-statement policy
+	statement.setAttribute("noRows", string(NoRowsZero))
+	user, err := executor.QueryContext(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user != nil {
+		t.Errorf("expected nil user, got %v", user)
+	}
+
+	// per-call policy overrides the statement
+	ctx := ContextWithNoRowsPolicy(context.Background(), NoRowsError)
+	if _, err = executor.QueryContext(ctx, nil); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows, got %v", err)
+	}
+}
+
+func TestXMLSQLStatement_InvalidNoRows(t *testing.T) {
+	decoder := xml.NewDecoder(strings.NewReader(`<select id="GetUserByID" noRows="zeros">SELECT * FROM user</select>`))
+	token, err := decoder.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt := &xmlSQLStatement{mapper: &Mapper{namespace: "main.UserMapper", mappers: &Mappers{}}, action: Select}
+	err = (&XMLMappersElementParser{}).parseStatement(stmt, decoder, token.(xml.StartElement))
+	if err == nil || !strings.Contains(err.Error(), "GetUserByID") || !strings.Contains(err.Error(), `"zeros"`) {
+		t.Errorf("expected error naming the statement, got %v", err)
+	}
+
+	stmt = parseTestStatement(t, Select, `<select id="GetUserByID" noRows="zero">SELECT * FROM user</select>`)
+	if policy := noRowsPolicy(context.Background(), stmt); policy != NoRowsZero {
+		t.Errorf("unexpected policy: %s", policy)
+	}
+}
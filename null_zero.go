@@ -0,0 +1,124 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+// nullZeroColumnOption is the option of the column tag which scans a NULL column as the zero value
+// of the field, instead of failing, so that the nullable columns can be mapped to the plain fields:
+//
+//	type User struct {
+//	    ID       int64   `column:"id"`
+//	    Nickname string  `column:"nickname,nullzero"`
+//	    Score    float64 `column:"score,nullzero"`
+//	}
+//
+// It applies to the fields of the string, bool, integer and float kinds, and time.Time, other
+// fields ignore it. The fields which implement sql.Scanner handle NULL by themselves.
+// Use SetNullZero to apply it to all the fields.
+const nullZeroColumnOption = "nullzero"
+
+// nullZeroEnabled is the global switch of the nullzero option, see SetNullZero.
+var nullZeroEnabled atomic.Bool
+
+// SetNullZero sets whether NULL columns are scanned as the zero values of all the struct fields,
+// as if they were tagged with the nullzero option. It is off by default.
+func SetNullZero(enabled bool) {
+	nullZeroEnabled.Store(enabled)
+}
+
+// nullZeroScannable reports whether the nullzero option applies to the fields of the type.
+func nullZeroScannable(tp reflect.Type) bool {
+	if tp == timeType {
+		return true
+	}
+	if reflect.PointerTo(tp).Implements(scannerType) {
+		return false
+	}
+	switch tp.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// ensure nullZeroColumn implements sql.Scanner.
+var _ sql.Scanner = (*nullZeroColumn)(nil) // compile time check
+
+// nullZeroColumn is a scan destination which sets the field to its zero value when the column is NULL.
+// The other values are converted by database/sql as if they were scanned into the field directly.
+type nullZeroColumn struct {
+	field reflect.Value
+}
+
+// Scan implements sql.Scanner.
+func (n nullZeroColumn) Scan(src any) error {
+	if src == nil {
+		n.field.SetZero()
+		return nil
+	}
+	var (
+		value reflect.Value
+		err   error
+	)
+	switch kind := n.field.Kind(); {
+	case n.field.Type() == timeType:
+		value, err = scanNull[time.Time](src)
+	case kind == reflect.String:
+		value, err = scanNull[string](src)
+	case kind == reflect.Bool:
+		value, err = scanNull[bool](src)
+	case n.field.CanInt():
+		if value, err = scanNull[int64](src); err == nil && n.field.OverflowInt(value.Int()) {
+			err = fmt.Errorf("juice: converting %v to %s: value out of range", src, n.field.Type())
+		}
+	case n.field.CanUint():
+		if value, err = scanNull[uint64](src); err == nil && n.field.OverflowUint(value.Uint()) {
+			err = fmt.Errorf("juice: converting %v to %s: value out of range", src, n.field.Type())
+		}
+	case n.field.CanFloat():
+		if value, err = scanNull[float64](src); err == nil && n.field.OverflowFloat(value.Float()) {
+			err = fmt.Errorf("juice: converting %v to %s: value out of range", src, n.field.Type())
+		}
+	default:
+		err = fmt.Errorf("juice: can not scan %T into %s with the nullzero option", src, n.field.Type())
+	}
+	if err != nil {
+		return err
+	}
+	n.field.Set(value.Convert(n.field.Type()))
+	return nil
+}
+
+// scanNull converts the non-NULL src to T the same way as database/sql does.
+func scanNull[T any](src any) (reflect.Value, error) {
+	var null sql.Null[T]
+	if err := null.Scan(src); err != nil {
+		return reflect.Value{}, err
+	}
+	return reflect.ValueOf(null.V), nil
+}
@@ -0,0 +1,78 @@
+package juice
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+func TestNullZeroColumn(t *testing.T) {
+	type Level int8
+	type User struct {
+		ID        int64     `column:"id"`
+		Name      string    `column:"name,nullzero"`
+		Age       int       `column:"age,nullzero"`
+		Level     Level     `column:"level,nullzero"`
+		Score     float64   `column:"score,nullzero"`
+		CreatedAt time.Time `column:"created_at,nullzero"`
+	}
+	now := time.Now()
+	rows := queryFakeRows(t, fakeResultSet{
+		columns: []string{"id", "name", "age", "level", "score", "created_at"},
+		rows: [][]driver.Value{
+			{int64(1), nil, nil, nil, nil, nil},
+			{int64(2), "eatmoreapple", int64(18), int64(3), 9.5, now},
+		},
+	})
+	users, err := List[User](rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("expected 2 users, got %d", len(users))
+	}
+	if users[0] != (User{ID: 1}) {
+		t.Errorf("expected zero values, got %+v", users[0])
+	}
+	want := User{ID: 2, Name: "eatmoreapple", Age: 18, Level: 3, Score: 9.5, CreatedAt: now}
+	if users[1] != want {
+		t.Errorf("unexpected user: %+v", users[1])
+	}
+}
+
+func TestNullZeroColumn_Overflow(t *testing.T) {
+	type User struct {
+		Level int8 `column:"level,nullzero"`
+	}
+	rows := queryFakeRows(t, fakeResultSet{
+		columns: []string{"level"},
+		rows:    [][]driver.Value{{int64(1000)}},
+	})
+	if _, err := List[User](rows); err == nil {
+		t.Error("expected out of range error")
+	}
+}
+
+func TestSetNullZero(t *testing.T) {
+	type User struct {
+		ID   int64  `column:"id"`
+		Name string `column:"name"`
+	}
+	resultSet := fakeResultSet{
+		columns: []string{"id", "name"},
+		rows:    [][]driver.Value{{int64(1), nil}},
+	}
+	if _, err := List[User](queryFakeRows(t, resultSet)); err == nil {
+		t.Fatal("expected error scanning NULL without the nullzero option")
+	}
+
+	SetNullZero(true)
+	defer SetNullZero(false)
+	users, err := List[User](queryFakeRows(t, resultSet))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || users[0] != (User{ID: 1}) {
+		t.Errorf("unexpected users: %+v", users)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-juicedev/juice/driver"
+)
+
+// versionAttribute is the attribute of the update statements which enables the optimistic
+// locking by the version column with the given name:
+//
+//	<update id="UpdateUser" version="version">
+//	    UPDATE user
+//	    <set>
+//	        <if test='name != ""'>name = #{name},</if>
+//	        <if test="age > 0">age = #{age},</if>
+//	    </set>
+//	    WHERE id = #{id}
+//	</update>
+//
+//	UPDATE user SET version = version + 1, name = ?, age = ? WHERE (id = ?) AND version = ?
+//
+// The version is read by the statement as the parameter with the same name, the column is
+// incremented at the start of the SET clause, which is rendered by the statement or a <set>
+// element, so the statement must not set the column itself, and the WHERE clause is appended
+// with the version predicate, see AppendWherePredicate. The clauses after the WHERE clause,
+// like LIMIT, must not contain placeholders, since the arg of the version is the last one.
+//
+// The execution returns ErrOptimisticLock if no row is affected, which means the row has been
+// changed by another writer since the version was read, or it doesn't exist.
+const versionAttribute = "version"
+
+// ErrOptimisticLock is returned by the update statements with the version attribute
+// which affect no rows.
+var ErrOptimisticLock = errors.New("juice: optimistic lock failed, the row has been changed or does not exist")
+
+// statementVersionColumn returns the version column of the update statement, empty if it is not set.
+func statementVersionColumn(statement Statement) string {
+	if statement.Action() != Update {
+		return ""
+	}
+	return statement.Attribute(versionAttribute)
+}
+
+// appendVersionLock increments the version column in the SET clause of the query, and appends
+// the predicate of the current version to its WHERE clause.
+func appendVersionLock(translator driver.Translator, param Parameter, query string, args []any, column string) (string, []any, error) {
+	value, exists := param.Get(column)
+	if !exists {
+		return "", nil, fmt.Errorf("version parameter %s not found", column)
+	}
+	set := -1
+	for _, token := range scanSQLKeywords(query) {
+		if token.word == "SET" {
+			set = token.pos + len("SET")
+			break
+		}
+	}
+	if set < 0 {
+		return "", nil, fmt.Errorf("version: no SET clause in %q", query)
+	}
+	query = query[:set] + " " + column + " = " + column + " + 1," + query[set:]
+	predicate := column + " = " + translator.Translate(column)
+	query, err := AppendWherePredicate(query, predicate)
+	if err != nil {
+		return "", nil, err
+	}
+	tail := query[strings.LastIndex(query, predicate)+len(predicate):]
+	_, named := driver.TranslatorAs[driver.NamedArgsBinder](translator)
+	if countPlaceholders(tail, named) > 0 {
+		return "", nil, fmt.Errorf("version: placeholders after the WHERE clause in %q", query)
+	}
+	return query, append(args, value.Interface()), nil
+}
+
+// checkVersionLock returns ErrOptimisticLock if the result of the update statement
+// with the version attribute affects no rows.
+func checkVersionLock(statement Statement, result sql.Result) error {
+	if statementVersionColumn(statement) == "" {
+		return nil
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w: %s", ErrOptimisticLock, statement.Name())
+	}
+	return nil
+}
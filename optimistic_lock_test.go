@@ -0,0 +1,72 @@
+package juice
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+
+	juicedriver "github.com/go-juicedev/juice/driver"
+)
+
+func TestXMLSQLStatement_Version(t *testing.T) {
+	stmt := parseTestStatement(t, Update, `<update id="UpdateUser" version="version">
+		UPDATE user
+		<set>
+			<if test='name != ""'>name = #{name},</if>
+		</set>
+		WHERE id = #{id} OR email = #{email}
+	</update>`)
+
+	query, args, err := stmt.Build(juicedriver.PostgresDriver{}.Translator(), H{"id": 1, "email": "a@b.c", "name": "a", "version": 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "UPDATE user SET version = version + 1, name = $1 WHERE (id = $2 OR email = $3) AND version = $4" {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if !reflect.DeepEqual(args, []any{"a", 1, "a@b.c", 3}) {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	if _, _, err = stmt.Build(juicedriver.MySQLDriver{}.Translator(), H{"id": 1, "email": "a@b.c", "name": "a"}); err == nil {
+		t.Error("expected error for missing version")
+	}
+}
+
+func TestXMLSQLStatement_VersionTrailingPlaceholder(t *testing.T) {
+	stmt := parseTestStatement(t, Update, `<update id="UpdateUser" version="version">
+		UPDATE user SET name = #{name} WHERE id = #{id} LIMIT #{limit}
+	</update>`)
+	_, _, err := stmt.Build(juicedriver.MySQLDriver{}.Translator(), H{"id": 1, "name": "a", "limit": 1, "version": 3})
+	if err == nil {
+		t.Error("expected error for the placeholder after the WHERE clause")
+	}
+
+	// the named placeholders are recognized too.
+	_, _, err = stmt.Build(juicedriver.SQLServerDriver{NamedArgs: true}.Translator(), H{"id": 1, "name": "a", "limit": 1, "version": 3})
+	if err == nil {
+		t.Error("expected error for the named placeholder after the WHERE clause")
+	}
+}
+
+func TestSQLRowsExecutor_OptimisticLock(t *testing.T) {
+	db, state := newFakeDB(t, fakeResultSet{})
+	state.execResults = []driver.Result{driver.RowsAffected(1), driver.RowsAffected(0)}
+	stmt := parseTestStatement(t, Update, `<update id="UpdateUser" version="version">
+		UPDATE user SET name = #{name} WHERE id = #{id}
+	</update>`)
+	executor := newFakeExecutor(db, stmt)
+	param := H{"id": 1, "name": "a", "version": 3}
+
+	if _, err := executor.ExecContext(context.Background(), param); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := executor.ExecContext(context.Background(), param); !errors.Is(err, ErrOptimisticLock) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if query := state.executions[0].query; query != "UPDATE user SET version = version + 1, name = ? WHERE (id = ?) AND version = ?" {
+		t.Errorf("unexpected query: %s", query)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-juicedev/juice/driver"
+)
+
+// ErrStatementAlreadyPaginated is an error that is returned when a statement which
+// already contains a LIMIT, OFFSET or FETCH clause is paginated.
+var ErrStatementAlreadyPaginated = errors.New("statement already contains a pagination clause")
+
+// paginatedStatement is a Statement which appends the pagination clause of the driver to the built query.
+type paginatedStatement struct {
+	Statement
+	driver driver.Driver
+	limit  int64
+	offset int64
+}
+
+// Build implements Statement.
+// The pagination clause is translated by the same translator as the statement,
+// so that its placeholders are numbered after the ones of the statement.
+func (p paginatedStatement) Build(translator driver.Translator, param Param) (string, []any, error) {
+	paginator, ok := p.driver.(driver.Paginator)
+	if !ok {
+		return "", nil, fmt.Errorf("paginate: driver %T does not support pagination", p.driver)
+	}
+	query, args, err := p.Statement.Build(translator, param)
+	if err != nil {
+		return "", nil, err
+	}
+	// the pagination clause goes before the locking clause, like FOR UPDATE.
+	tail := len(query)
+	for _, token := range scanSQLKeywords(query) {
+		switch token.word {
+		case "LIMIT", "OFFSET", "FETCH":
+			return "", nil, ErrStatementAlreadyPaginated
+		case "FOR":
+			tail = min(tail, token.pos)
+		}
+	}
+	clause, paginationArgs := paginator.Paginate(translator, p.limit, p.offset)
+	paginated := strings.TrimSpace(query[:tail]) + " " + clause
+	if tail < len(query) {
+		paginated += " " + query[tail:]
+	}
+	return paginated, append(args, paginationArgs...), nil
+}
+
+// paginate returns a SQLRowsExecutor which executes the paginated statement of the executor.
+func paginate(executor SQLRowsExecutor, limit, offset int) SQLRowsExecutor {
+	if _, ok := isInvalidExecutor(executor); ok {
+		return executor
+	}
+	if limit < 0 || offset < 0 {
+		return inValidExecutor(fmt.Errorf("paginate: invalid limit %d or offset %d", limit, offset))
+	}
+	exe, ok := executor.(*sqlRowsExecutor)
+	if !ok {
+		return inValidExecutor(errors.New("paginate: unsupported executor"))
+	}
+	return &sqlRowsExecutor{
+		statement: paginatedStatement{
+			Statement: exe.statement,
+			driver:    exe.driver,
+			limit:     int64(limit),
+			offset:    int64(offset),
+		},
+		statementHandler: exe.statementHandler,
+		driver:           exe.driver,
+		columnNames:      exe.columnNames,
+		times:            exe.times,
+	}
+}
+
+// Paginate returns a copy of the executor whose queries are paginated by the driver of the executor,
+// which skips offset rows and returns at most limit rows. For example, with the MySQL driver:
+//
+//	SELECT * FROM user WHERE status = ? LIMIT ? OFFSET ?
+//
+// and with the SQL Server driver:
+//
+//	SELECT * FROM user WHERE status = @p1 ORDER BY id OFFSET @p2 ROWS FETCH NEXT @p3 ROWS ONLY
+//
+// The statement must not contain a LIMIT, OFFSET or FETCH clause itself,
+// otherwise ErrStatementAlreadyPaginated is returned when it is executed.
+func (e *GenericExecutor[T]) Paginate(limit, offset int) *GenericExecutor[T] {
+	return &GenericExecutor[T]{
+		SQLRowsExecutor: paginate(e.SQLRowsExecutor, limit, offset),
+		cache:           e.cache,
+		hooks:           e.hooks,
+	}
+}
+
+// Paginate paginates the executor with GenericExecutor.Paginate, for example:
+//
+//	users, err := juice.Paginate(juice.NewGenericManager[[]User](engine).Object(QueryUsers), 20, 40).QueryContext(ctx, param)
+func Paginate[T any](executor Executor[T], limit, offset int) Executor[T] {
+	exe, ok := executor.(*GenericExecutor[T])
+	if !ok {
+		return &GenericExecutor[T]{SQLRowsExecutor: inValidExecutor(errors.New("paginate: unsupported executor"))}
+	}
+	return exe.Paginate(limit, offset)
+}
@@ -0,0 +1,77 @@
+package juice
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+
+	juicedriver "github.com/go-juicedev/juice/driver"
+)
+
+func TestPaginatedStatement_Build(t *testing.T) {
+	stmt := newFakeStatement("SELECT * FROM user WHERE status = #{status} ORDER BY id")
+	tests := []struct {
+		driver juicedriver.Driver
+		query  string
+		args   []any
+	}{
+		{juicedriver.MySQLDriver{}, "SELECT * FROM user WHERE status = ? ORDER BY id LIMIT ? OFFSET ?", []any{1, int64(10), int64(20)}},
+		{juicedriver.PostgresDriver{}, "SELECT * FROM user WHERE status = $1 ORDER BY id LIMIT $2 OFFSET $3", []any{1, int64(10), int64(20)}},
+		{juicedriver.SQLServerDriver{}, "SELECT * FROM user WHERE status = @p1 ORDER BY id OFFSET @p2 ROWS FETCH NEXT @p3 ROWS ONLY", []any{1, int64(20), int64(10)}},
+	}
+	for _, tt := range tests {
+		paginated := paginatedStatement{Statement: stmt, driver: tt.driver, limit: 10, offset: 20}
+		query, args, err := paginated.Build(tt.driver.Translator(), H{"status": 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if query != tt.query {
+			t.Errorf("unexpected query: %s", query)
+		}
+		if !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("unexpected args: %v", args)
+		}
+	}
+}
+
+func TestPaginatedStatement_AlreadyPaginated(t *testing.T) {
+	drv := juicedriver.MySQLDriver{}
+	paginated := paginatedStatement{
+		Statement: newFakeStatement("SELECT * FROM user LIMIT #{limit}"),
+		driver:    drv,
+		limit:     10,
+	}
+	if _, _, err := paginated.Build(drv.Translator(), H{"limit": 1}); !errors.Is(err, ErrStatementAlreadyPaginated) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	// limit in a subquery is not a pagination clause of the statement.
+	paginated.Statement = newFakeStatement("SELECT * FROM (SELECT * FROM user LIMIT 100) AS u")
+	if _, _, err := paginated.Build(drv.Translator(), H{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	db, state := newFakeDB(t, fakeResultSet{
+		columns: []string{"id", "name"},
+		rows:    [][]driver.Value{{int64(1), "eatmoreapple"}},
+	})
+	executor := &GenericExecutor[[]hookUser]{
+		SQLRowsExecutor: newFakeExecutor(db, newFakeStatement("SELECT id, name FROM user WHERE status = #{status}")),
+	}
+	users, err := Paginate[[]hookUser](executor, 10, 20).QueryContext(context.Background(), H{"status": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || users[0].Name != "eatmoreapple" {
+		t.Errorf("unexpected users: %v", users)
+	}
+	if len(state.executions) != 1 || state.executions[0].query != "SELECT id, name FROM user WHERE status = ? LIMIT ? OFFSET ?" {
+		t.Errorf("unexpected executions: %v", state.executions)
+	}
+	if _, err = executor.Paginate(-1, 0).QueryContext(context.Background(), H{"status": 1}); !errors.Is(err, ErrInvalidExecutor) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -15,6 +15,9 @@ type Parameter = eval.Parameter
 // H is an alias of eval.H.
 type H = eval.H
 
+// SyncMapParameter is an alias of eval.SyncMapParameter.
+type SyncMapParameter = eval.SyncMapParameter
+
 // ParamFromContext returns the parameter from the context.
 func ParamFromContext(ctx context.Context) Param {
 	return eval.ParamFromContext(ctx)
@@ -0,0 +1,87 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"context"
+
+	"github.com/go-juicedev/juice/eval"
+)
+
+// ParameterProvider provides ambient parameters, like the current user id, the tenant
+// or the request time, which are available to every statement without passing them
+// explicitly each call.
+//
+// The provided parameters are merged with the per-call parameter with lower precedence,
+// so the per-call parameter always wins when both of them have the same name.
+//
+// Usage:
+//
+//	engine.UseParameterProvider(juice.ParameterProviderFunc(func(ctx context.Context) (juice.Parameter, error) {
+//	    return juice.H{"ctx": juice.H{"userId": UserIDFromContext(ctx)}}.AsParam(), nil
+//	}))
+//
+// Then `#{ctx.userId}` can be used in every statement.
+type ParameterProvider interface {
+	// Provide returns the parameter derived from the given context.
+	Provide(ctx context.Context) (Parameter, error)
+}
+
+// ParameterProviderFunc is an adapter to allow the use of ordinary functions as ParameterProvider.
+type ParameterProviderFunc func(ctx context.Context) (Parameter, error)
+
+// Provide implements ParameterProvider.
+func (f ParameterProviderFunc) Provide(ctx context.Context) (Parameter, error) {
+	return f(ctx)
+}
+
+// ensure ParameterProviderGroup implements ParameterProvider.
+var _ ParameterProvider = (ParameterProviderGroup)(nil) // compile time check
+
+// ParameterProviderGroup is a group of ParameterProvider.
+// The providers registered earlier take precedence over the later ones.
+type ParameterProviderGroup []ParameterProvider
+
+// Provide implements ParameterProvider.
+// It stops at the first provider which returns an error.
+func (g ParameterProviderGroup) Provide(ctx context.Context) (Parameter, error) {
+	group := make(eval.ParamGroup, 0, len(g))
+	for _, provider := range g {
+		if provider == nil {
+			continue
+		}
+		param, err := provider.Provide(ctx)
+		if err != nil {
+			return nil, err
+		}
+		group = append(group, param)
+	}
+	return group, nil
+}
+
+// merge merges the provided parameters with the given per-call parameter of the statement.
+// The per-call parameter takes precedence over the provided ones.
+func (g ParameterProviderGroup) merge(ctx context.Context, statement Statement, param Param) (Param, error) {
+	if len(g) == 0 {
+		return param, nil
+	}
+	provided, err := g.Provide(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return eval.ParamGroup{newGenericParam(param, statement.Attribute("paramName")), provided}, nil
+}
@@ -0,0 +1,65 @@
+package juice
+
+import (
+	"context"
+	sqldriver "database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-juicedev/juice/driver"
+)
+
+type tenantKey struct{}
+
+func TestParameterProvider(t *testing.T) {
+	db, state := newFakeDB(t, fakeResultSet{})
+	drv := driver.MySQLDriver{}
+	executor := &sqlRowsExecutor{
+		statement: newFakeStatement("SELECT * FROM user WHERE tenant_id = #{ctx.tenantId} AND id = #{id}"),
+		statementHandler: &DefaultStatementHandler{
+			driver: drv,
+			builder: statementBuilder{
+				paramProviders: ParameterProviderGroup{
+					ParameterProviderFunc(func(ctx context.Context) (Parameter, error) {
+						return H{"ctx": H{"tenantId": ctx.Value(tenantKey{})}, "id": 0}.AsParam(), nil
+					}),
+				},
+			},
+			session: db,
+		},
+		driver: drv,
+	}
+	ctx := context.WithValue(context.Background(), tenantKey{}, 7)
+	rows, err := executor.QueryContext(ctx, H{"id": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = rows.Close()
+	if len(state.executions) != 1 {
+		t.Fatalf("unexpected executions: %v", state.executions)
+	}
+	// the per-call parameter takes precedence over the provided ones
+	if args := state.executions[0].args; !reflect.DeepEqual(args, []sqldriver.Value{int64(7), int64(1)}) {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	// the cache key is built with the provided parameters too
+	query, args, err := executor.build(ctx, H{"id": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM user WHERE tenant_id = ? AND id = ?" || !reflect.DeepEqual(args, []any{7, 1}) {
+		t.Errorf("unexpected build result: %s %v", query, args)
+	}
+}
+
+func TestParameterProviderGroup_Error(t *testing.T) {
+	errProvide := errors.New("no tenant")
+	group := ParameterProviderGroup{
+		ParameterProviderFunc(func(context.Context) (Parameter, error) { return nil, errProvide }),
+	}
+	if _, err := group.merge(context.Background(), newFakeStatement("SELECT 1"), nil); !errors.Is(err, errProvide) {
+		t.Errorf("expected provide error, got %v", err)
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// ErrParameterNamesUnsupported is an error that is returned when the statement
+// can not report the parameters it references.
+var ErrParameterNamesUnsupported = errors.New("statement does not support parameter names")
+
+// ParameterNames returns the names of the parameters referenced by the #{} and ${}
+// placeholders of the node tree, sorted and without duplicates.
+//
+// The tree is walked through all the dynamic nodes, no matter whether their conditions
+// hold, and the included sql fragments are resolved by their mapper.
+// The names scoped by a foreach node, which are its item, its index, __first and __last,
+// are not reported, the collection of the foreach node is reported instead.
+func ParameterNames(node Node) ([]string, error) {
+	walker := &parameterNamesWalker{names: make(map[string]struct{})}
+	if err := walker.walk(node, nil); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(walker.names))
+	for name := range walker.names {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// StatementParameterNames returns the names of the parameters referenced by the statement.
+// It returns ErrParameterNamesUnsupported if the statement is not built from nodes.
+func StatementParameterNames(statement Statement) ([]string, error) {
+	named, ok := statement.(interface{ ParameterNames() ([]string, error) })
+	if !ok {
+		return nil, ErrParameterNamesUnsupported
+	}
+	return named.ParameterNames()
+}
+
+// parameterNamesWalker walks the node tree and collects the parameter names.
+type parameterNamesWalker struct {
+	names map[string]struct{}
+}
+
+// add adds the name unless its root is scoped by an enclosing foreach node.
+func (w *parameterNamesWalker) add(name string, scoped []string) {
+	root, _, _ := strings.Cut(name, ".")
+	if slices.Contains(scoped, root) {
+		return
+	}
+	w.names[name] = struct{}{}
+}
+
+// addMatches adds the names captured by the placeholder matches.
+func (w *parameterNamesWalker) addMatches(matches [][]string, scoped []string) {
+	for _, matched := range matches {
+		if len(matched) == 2 {
+			w.add(matched[1], scoped)
+		}
+	}
+}
+
+// walkGroup walks the nodes in order.
+func (w *parameterNamesWalker) walkGroup(nodes []Node, scoped []string) error {
+	for _, node := range nodes {
+		if err := w.walk(node, scoped); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walk collects the parameter names of the node, scoped is the names declared by the enclosing foreach nodes.
+func (w *parameterNamesWalker) walk(node Node, scoped []string) error {
+	switch n := node.(type) {
+	case nil, pureTextNode, SelectFieldAliasNode:
+		return nil
+	case *TextNode:
+		w.addMatches(n.placeholder, scoped)
+		w.addMatches(n.textSubstitution, scoped)
+	case NodeGroup:
+		return w.walkGroup(n, scoped)
+	case *ConditionNode:
+		if err := w.walkGroup(n.Nodes, scoped); err != nil {
+			return err
+		}
+		return w.walkGroup(n.Else, scoped)
+	case WhereNode:
+		return w.walkGroup(n.Nodes, scoped)
+	case *WhereNode:
+		return w.walkGroup(n.Nodes, scoped)
+	case TrimNode:
+		return w.walkGroup(n.Nodes, scoped)
+	case *TrimNode:
+		return w.walkGroup(n.Nodes, scoped)
+	case SetNode:
+		return w.walkGroup(n.Nodes, scoped)
+	case *SetNode:
+		return w.walkGroup(n.Nodes, scoped)
+	case OtherwiseNode:
+		return w.walkGroup(n.Nodes, scoped)
+	case *OtherwiseNode:
+		return w.walkGroup(n.Nodes, scoped)
+	case ForeachNode:
+		return w.walkForeach(&n, scoped)
+	case *ForeachNode:
+		return w.walkForeach(n, scoped)
+	case ChooseNode:
+		return w.walkChoose(&n, scoped)
+	case *ChooseNode:
+		return w.walkChoose(n, scoped)
+	case SQLNode:
+		return w.walkGroup(n.nodes, scoped)
+	case *SQLNode:
+		return w.walkGroup(n.nodes, scoped)
+	case *IncludeNode:
+		sqlNode := n.sqlNode
+		if sqlNode == nil {
+			var err error
+			if sqlNode, err = n.mapper.GetSQLNodeByID(n.refId); err != nil {
+				return err
+			}
+		}
+		return w.walk(sqlNode, scoped)
+	case *LimitNode:
+		w.add(n.Value, scoped)
+		if n.Offset != "" {
+			w.add(n.Offset, scoped)
+		}
+	case *PartitionNode:
+		w.add(n.Value, scoped)
+	case *SetFieldsNode:
+		w.add(n.Value, scoped)
+	case *FilterNode:
+		w.add(n.Value, scoped)
+	case ValuesNode:
+		w.walkValues(n, scoped)
+	case *ValuesNode:
+		w.walkValues(*n, scoped)
+	case MultiRowsValuesNode:
+		// the values items are resolved against the rows, so only the collection is reported.
+		w.add(n.Collection, scoped)
+	case *MultiRowsValuesNode:
+		w.add(n.Collection, scoped)
+	}
+	return nil
+}
+
+// walkForeach reports the collection and walks the body with the names declared by the foreach node.
+func (w *parameterNamesWalker) walkForeach(foreach *ForeachNode, scoped []string) error {
+	w.add(foreach.Collection, scoped)
+	inner := append(slices.Clip(scoped), ForeachFirstKey, ForeachLastKey)
+	if foreach.Item != "" {
+		inner = append(inner, foreach.Item)
+	}
+	if foreach.Index != "" {
+		inner = append(inner, foreach.Index)
+	}
+	return w.walkGroup(foreach.Nodes, inner)
+}
+
+// walkChoose walks all the branches of the choose node.
+func (w *parameterNamesWalker) walkChoose(choose *ChooseNode, scoped []string) error {
+	if err := w.walkGroup(choose.WhenNodes, scoped); err != nil {
+		return err
+	}
+	return w.walk(choose.OtherwiseNode, scoped)
+}
+
+// walkValues collects the parameter names of the values items.
+func (w *parameterNamesWalker) walkValues(values ValuesNode, scoped []string) {
+	for _, item := range values {
+		w.addMatches(paramRegex.FindAllStringSubmatch(item.value, -1), scoped)
+		w.addMatches(formatRegexp.FindAllStringSubmatch(item.value, -1), scoped)
+	}
+}
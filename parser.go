@@ -24,9 +24,11 @@ import (
 	"io/fs"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/go-juicedev/juice/driver"
 	"github.com/go-juicedev/juice/eval"
 )
 
@@ -161,6 +163,10 @@ func (p *XMLEnvironmentsElementParser) parseEnvironment(decoder *xml.Decoder, to
 				if err != nil {
 					return nil, err
 				}
+			case "property":
+				if err = p.parseProperty(env, decoder, token, provider); err != nil {
+					return nil, err
+				}
 			}
 		case xml.EndElement:
 			if token.Name.Local == "environment" {
@@ -171,6 +177,35 @@ func (p *XMLEnvironmentsElementParser) parseEnvironment(decoder *xml.Decoder, to
 	return nil, &nodeUnclosedError{nodeName: "environment"}
 }
 
+// parseProperty parses the <property name="..." value="..."/> element of the environment.
+// The value is resolved by the value provider of the environment.
+func (p *XMLEnvironmentsElementParser) parseProperty(env *Environment, decoder *xml.Decoder, token xml.StartElement, provider EnvValueProvider) error {
+	var name, value string
+	for _, attr := range token.Attr {
+		switch attr.Name.Local {
+		case "name":
+			name = attr.Value
+		case "value":
+			value = attr.Value
+		}
+	}
+	if name == "" {
+		return &nodeAttributeRequiredError{nodeName: "property", attrName: "name"}
+	}
+	if _, exists := env.Properties[name]; exists {
+		return fmt.Errorf("duplicate property %s of environment %s", name, env.ID())
+	}
+	value, err := provider.Get(value)
+	if err != nil {
+		return err
+	}
+	if env.Properties == nil {
+		env.Properties = make(map[string]string)
+	}
+	env.Properties[name] = value
+	return decoder.Skip()
+}
+
 func (p *XMLEnvironmentsElementParser) parseEnvironments(decoder *xml.Decoder, token xml.StartElement) (*environments, error) {
 	var envs environments
 	for _, attr := range token.Attr {
@@ -218,7 +253,7 @@ func (p *XMLSettingsElementParser) MatchElement(token xml.StartElement) bool {
 }
 
 func (p *XMLSettingsElementParser) ParseElement(parser *XMLParser, decoder *xml.Decoder, token xml.StartElement) error {
-	settings, err := p.parseSettings(decoder)
+	settings, err := p.parseSettings(decoder, token)
 	if err != nil {
 		return err
 	}
@@ -226,13 +261,15 @@ func (p *XMLSettingsElementParser) ParseElement(parser *XMLParser, decoder *xml.
 	return nil
 }
 
-func (p *XMLSettingsElementParser) parseSettings(decoder *xml.Decoder) (keyValueSettingProvider, error) {
-	var setting []settingItem
-	if err := decoder.DecodeElement(&setting, nil); err != nil {
+func (p *XMLSettingsElementParser) parseSettings(decoder *xml.Decoder, token xml.StartElement) (keyValueSettingProvider, error) {
+	var element struct {
+		Settings []settingItem `xml:"setting"`
+	}
+	if err := decoder.DecodeElement(&element, &token); err != nil {
 		return nil, err
 	}
-	var settings = make(keyValueSettingProvider, len(setting))
-	for _, s := range setting {
+	var settings = make(keyValueSettingProvider, len(element.Settings))
+	for _, s := range element.Settings {
 		if _, ok := settings[s.Name]; ok {
 			return nil, fmt.Errorf("duplicate setting name: %s", s.Name)
 		}
@@ -255,8 +292,9 @@ func (p *XMLMappersElementParser) ParseElement(parser *XMLParser, decoder *xml.D
 	if err != nil {
 		return err
 	}
-	mappers.cfg = parser.configuration
 	parser.configuration.mappers = mappers
+	// share the configuration, so that the statements can resolve the other statements by it.
+	mappers.cfg = &parser.configuration
 	return nil
 }
 
@@ -503,6 +541,30 @@ func (p *XMLMappersElementParser) parseStatement(stmt *xmlSQLStatement, decoder
 	} else {
 		stmt.id = id
 	}
+	// element is the action derived from the element name, which decides the allowed child nodes.
+	element := stmt.action
+	if action, ok := stmt.attrs["action"]; ok {
+		switch Action(action) {
+		case Select, Insert, Update, Delete:
+			stmt.action = Action(action)
+		default:
+			return fmt.Errorf("%s xmlSQLStatement %s has invalid action %q", element, stmt.id, action)
+		}
+	}
+	if lock, ok := stmt.attrs[lockAttribute]; ok {
+		if element != Select {
+			return fmt.Errorf("lock attribute only support select xmlSQLStatement")
+		}
+		if !driver.LockMode(lock).Valid() {
+			return fmt.Errorf("%s xmlSQLStatement %s has invalid lock %q", element, stmt.id, lock)
+		}
+	}
+	if noRows, ok := stmt.attrs[noRowsAttribute]; ok && !NoRowsPolicy(noRows).Valid() {
+		return fmt.Errorf("%s xmlSQLStatement %s has invalid noRows %q", element, stmt.id, noRows)
+	}
+	if _, ok := stmt.attrs[resultTypeAttribute]; ok && element != Select {
+		return fmt.Errorf("resultType attribute only support select xmlSQLStatement")
+	}
 	for {
 		token, err := decoder.Token()
 		if err != nil {
@@ -515,16 +577,38 @@ func (p *XMLMappersElementParser) parseStatement(stmt *xmlSQLStatement, decoder
 		case xml.StartElement:
 			switch token.Name.Local {
 			case "values":
-				if stmt.action != Insert {
+				if element != Insert {
 					return fmt.Errorf("values node only support insert xmlSQLStatement")
 				}
 				node, err := p.parseValuesNode(decoder)
 				if err != nil {
 					return err
 				}
-				stmt.Nodes = append(stmt.Nodes, node)
+				collection := stmt.Attribute("paramName")
+				if collection == "" {
+					collection = eval.DefaultParamKey()
+				}
+				stmt.Nodes = append(stmt.Nodes, &MultiRowsValuesNode{ValuesNode: node, Collection: collection})
+			case "param":
+				name, value, err := p.parseParamNode(decoder, token)
+				if err != nil {
+					return err
+				}
+				if stmt.defaults == nil {
+					stmt.defaults = make(eval.H)
+				}
+				stmt.defaults[name] = value
+			case "result":
+				if _, ok := stmt.attrs[resultTypeAttribute]; !ok {
+					return fmt.Errorf("result node requires the resultType attribute of the xmlSQLStatement")
+				}
+				result, err := p.parseResultNode(decoder, token)
+				if err != nil {
+					return err
+				}
+				stmt.results = append(stmt.results, result)
 			case "alias":
-				if stmt.action != Select {
+				if element != Select {
 					return fmt.Errorf("alias node only support select xmlSQLStatement")
 				}
 				node, err := p.parseAliasNode(decoder)
@@ -547,7 +631,7 @@ func (p *XMLMappersElementParser) parseStatement(stmt *xmlSQLStatement, decoder
 			}
 		case xml.EndElement:
 			switch token.Name.Local {
-			case stmt.action.String():
+			case element.String():
 				return nil
 			default:
 				return fmt.Errorf("unexpected end element: %s", token.Name.Local)
@@ -572,17 +656,165 @@ func (p *XMLMappersElementParser) parseTags(mapper *Mapper, decoder *xml.Decoder
 	case "include":
 		return p.parseInclude(mapper, decoder, token)
 	case "choose":
-		return p.parseChoose(mapper, decoder)
+		return p.parseChoose(mapper, decoder, token)
+	case "limit":
+		return p.parseLimit(mapper, decoder, token)
+	case "partition":
+		return p.parsePartition(decoder, token)
+	case "fields":
+		return p.parseFields(decoder, token)
+	case "filter":
+		return p.parseFilter(decoder, token)
 	}
 	return nil, fmt.Errorf("unknown tag: %s", token.Name.Local)
 }
 
+func (p *XMLMappersElementParser) parseLimit(mapper *Mapper, decoder *xml.Decoder, token xml.StartElement) (Node, error) {
+	limitNode := &LimitNode{mapper: mapper}
+	for _, attr := range token.Attr {
+		switch attr.Name.Local {
+		case "value":
+			limitNode.Value = attr.Value
+		case "offset":
+			limitNode.Offset = attr.Value
+		case "max":
+			maxLimit, err := strconv.ParseInt(attr.Value, 10, 64)
+			if err != nil || maxLimit <= 0 {
+				return nil, fmt.Errorf("limit max must be a positive integer: %s", attr.Value)
+			}
+			limitNode.Max = maxLimit
+		}
+	}
+	if limitNode.Value == "" {
+		return nil, &nodeAttributeRequiredError{nodeName: "limit", attrName: "value"}
+	}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		if end, ok := token.(xml.EndElement); ok && end.Name.Local == "limit" {
+			return limitNode, nil
+		}
+	}
+	return nil, &nodeUnclosedError{nodeName: "limit"}
+}
+
+func (p *XMLMappersElementParser) parsePartition(decoder *xml.Decoder, token xml.StartElement) (Node, error) {
+	partitionNode := &PartitionNode{}
+	var pattern string
+	for _, attr := range token.Attr {
+		switch attr.Name.Local {
+		case "table":
+			partitionNode.Table = attr.Value
+		case "value":
+			partitionNode.Value = attr.Value
+		case "layout":
+			partitionNode.Layout = attr.Value
+		case "pattern":
+			pattern = attr.Value
+		}
+	}
+	for _, required := range [][2]string{{"table", partitionNode.Table}, {"value", partitionNode.Value}, {"pattern", pattern}} {
+		if required[1] == "" {
+			return nil, &nodeAttributeRequiredError{nodeName: "partition", attrName: required[0]}
+		}
+	}
+	// the pattern must match the whole table name.
+	compiled, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("partition pattern %q: %w", pattern, err)
+	}
+	partitionNode.Pattern = compiled
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		if end, ok := token.(xml.EndElement); ok && end.Name.Local == "partition" {
+			return partitionNode, nil
+		}
+	}
+	return nil, &nodeUnclosedError{nodeName: "partition"}
+}
+
+func (p *XMLMappersElementParser) parseFields(decoder *xml.Decoder, token xml.StartElement) (Node, error) {
+	fieldsNode := &SetFieldsNode{}
+	for _, attr := range token.Attr {
+		switch attr.Name.Local {
+		case "value":
+			fieldsNode.Value = attr.Value
+		case "policy":
+			policy, exists := GetSetFieldsPolicy(attr.Value)
+			if !exists {
+				return nil, fmt.Errorf("fields policy %q not found", attr.Value)
+			}
+			fieldsNode.Policy = policy
+		case "exclude":
+			for _, column := range strings.Split(attr.Value, ",") {
+				if column = strings.TrimSpace(column); column != "" {
+					fieldsNode.Exclude = append(fieldsNode.Exclude, column)
+				}
+			}
+		}
+	}
+	if fieldsNode.Value == "" {
+		return nil, &nodeAttributeRequiredError{nodeName: "fields", attrName: "value"}
+	}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		if end, ok := token.(xml.EndElement); ok && end.Name.Local == "fields" {
+			return fieldsNode, nil
+		}
+	}
+	return nil, &nodeUnclosedError{nodeName: "fields"}
+}
+
+func (p *XMLMappersElementParser) parseFilter(decoder *xml.Decoder, token xml.StartElement) (Node, error) {
+	filterNode := &FilterNode{}
+	for _, attr := range token.Attr {
+		if attr.Name.Local == "value" {
+			filterNode.Value = attr.Value
+		}
+	}
+	if filterNode.Value == "" {
+		return nil, &nodeAttributeRequiredError{nodeName: "filter", attrName: "value"}
+	}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		if end, ok := token.(xml.EndElement); ok && end.Name.Local == "filter" {
+			return filterNode, nil
+		}
+	}
+	return nil, &nodeUnclosedError{nodeName: "filter"}
+}
+
 func (p *XMLMappersElementParser) parseInclude(mapper *Mapper, decoder *xml.Decoder, token xml.StartElement) (Node, error) {
-	var ref string
+	var ref, test string
 	for _, attr := range token.Attr {
 		switch attr.Name.Local {
 		case "refid":
 			ref = attr.Value
+		case "test":
+			test = attr.Value
 		}
 	}
 	if ref == "" {
@@ -596,6 +828,14 @@ func (p *XMLMappersElementParser) parseInclude(mapper *Mapper, decoder *xml.Deco
 
 	includeNode := &IncludeNode{sqlNode: sqlNode, mapper: mapper, refId: ref}
 
+	// the fragment is included only if the test holds.
+	if test != "" {
+		includeNode.condition = &ConditionNode{}
+		if err := includeNode.condition.Parse(test); err != nil {
+			return nil, err
+		}
+	}
+
 	for {
 		token, err := decoder.Token()
 		if err != nil {
@@ -649,21 +889,31 @@ func (p *XMLMappersElementParser) parseSet(mapper *Mapper, decoder *xml.Decoder)
 
 func (p *XMLMappersElementParser) parseIf(mapper *Mapper, decoder *xml.Decoder, token xml.StartElement) (Node, error) {
 	ifNode := &IfNode{}
-	var test string
+	var test, flag string
 	for _, attr := range token.Attr {
-		if attr.Name.Local == "test" {
+		switch attr.Name.Local {
+		case "test":
 			test = attr.Value
-			break
+		case "flag":
+			flag = attr.Value
 		}
 	}
-	if test == "" {
-		return nil, &nodeAttributeRequiredError{nodeName: "if", attrName: "test"}
-	}
-
-	// parse condition expression
-	if err := ifNode.Parse(test); err != nil {
-		return nil, err
+	switch {
+	case test != "" && flag != "":
+		return nil, &nodeAttributeConflictError{nodeName: "if", attrName: "test|flag"}
+	case flag != "":
+		// the condition is the feature flag, see FlagProvider.
+		ifNode.expr = flagExpression(flag)
+	case test == "":
+		return nil, &nodeAttributeRequiredError{nodeName: "if", attrName: "test|flag"}
+	default:
+		// parse condition expression
+		if err := ifNode.Parse(test); err != nil {
+			return nil, err
+		}
 	}
+	// the nodes after the <else/> element are appended to the else group.
+	nodes := &ifNode.Nodes
 	for {
 		token, err := decoder.Token()
 		if err != nil {
@@ -674,16 +924,23 @@ func (p *XMLMappersElementParser) parseIf(mapper *Mapper, decoder *xml.Decoder,
 		}
 		switch token := token.(type) {
 		case xml.StartElement:
+			if token.Name.Local == "else" {
+				if nodes == &ifNode.Else {
+					return nil, errors.New("node if has more than one else")
+				}
+				nodes = &ifNode.Else
+				continue
+			}
 			node, err := p.parseTags(mapper, decoder, token)
 			if err != nil {
 				return nil, err
 			}
-			ifNode.Nodes = append(ifNode.Nodes, node)
+			*nodes = append(*nodes, node)
 		case xml.CharData:
 			text := string(token)
 			if char := strings.TrimSpace(text); char != "" {
 				node := NewTextNode(char)
-				ifNode.Nodes = append(ifNode.Nodes, node)
+				*nodes = append(*nodes, node)
 			}
 		case xml.EndElement:
 			if token.Name.Local == "if" {
@@ -746,6 +1003,8 @@ func (p *XMLMappersElementParser) parseTrim(mapper *Mapper, decoder *xml.Decoder
 				suffixOverrides[i] = strings.TrimSpace(suffixOverrides[i])
 			}
 			trimNode.SuffixOverrides = suffixOverrides
+		case "caseInsensitive":
+			trimNode.CaseInsensitive = attr.Value == "true"
 		}
 	}
 	for {
@@ -788,6 +1047,10 @@ func (p *XMLMappersElementParser) parseForeach(mapper *Mapper, decoder *xml.Deco
 			foreachNode.Separator = attr.Value
 		case "close":
 			foreachNode.Close = attr.Value
+		case "nilable":
+			foreachNode.Nilable = attr.Value == "true"
+		case "split":
+			foreachNode.Split = attr.Value
 		}
 	}
 
@@ -828,8 +1091,13 @@ func (p *XMLMappersElementParser) parseForeach(mapper *Mapper, decoder *xml.Deco
 	return nil, &nodeUnclosedError{nodeName: "foreach"}
 }
 
-func (p *XMLMappersElementParser) parseChoose(mapper *Mapper, decoder *xml.Decoder) (Node, error) {
+func (p *XMLMappersElementParser) parseChoose(mapper *Mapper, decoder *xml.Decoder, token xml.StartElement) (Node, error) {
 	chooseNode := &ChooseNode{}
+	for _, attr := range token.Attr {
+		if attr.Name.Local == "required" {
+			chooseNode.Required = attr.Value == "true"
+		}
+	}
 	for {
 		token, err := decoder.Token()
 		if err != nil {
@@ -989,7 +1257,7 @@ func (p *XMLMappersElementParser) parseOtherwise(mapper *Mapper, decoder *xml.De
 	return nil, &nodeUnclosedError{nodeName: "otherwise"}
 }
 
-func (p *XMLMappersElementParser) parseValuesNode(decoder *xml.Decoder) (Node, error) {
+func (p *XMLMappersElementParser) parseValuesNode(decoder *xml.Decoder) (ValuesNode, error) {
 	var node = make(ValuesNode, 0)
 	for {
 		token, err := decoder.Token()
@@ -1018,19 +1286,45 @@ func (p *XMLMappersElementParser) parseValuesNode(decoder *xml.Decoder) (Node, e
 	return nil, &nodeUnclosedError{nodeName: "values"}
 }
 
+// parseValueNode parses the value node of the values node.
+//
+//	<value column="name"/>                      binds #{name}
+//	<value column="name" value="#{user.Name}"/> binds #{user.Name}
+//	<value column="created_at" raw="NOW()"/>    emits NOW() without binding
+//
+// The raw value is a SQL expression, it can not be used with the value attribute,
+// and it can not contain the #{} placeholders or the ${} substitutions.
 func (p *XMLMappersElementParser) parseValueNode(token xml.StartElement, decoder *xml.Decoder) (*valueItem, error) {
-	var ve valueItem
+	var (
+		ve       valueItem
+		raw      string
+		hasValue bool
+	)
 	for _, attr := range token.Attr {
 		switch attr.Name.Local {
 		case "value":
-			ve.value = attr.Value
+			ve.value, hasValue = attr.Value, true
 		case "column":
 			ve.column = attr.Value
+		case "raw":
+			raw, ve.raw = attr.Value, true
 		}
 	}
 	if ve.column == "" {
 		return nil, &nodeAttributeRequiredError{nodeName: "value", attrName: "column"}
 	}
+	if ve.raw {
+		if hasValue {
+			return nil, fmt.Errorf("value %s: raw and value attributes are exclusive", ve.column)
+		}
+		if strings.TrimSpace(raw) == "" {
+			return nil, &nodeAttributeRequiredError{nodeName: "value", attrName: "raw"}
+		}
+		if paramRegex.MatchString(raw) || formatRegexp.MatchString(raw) {
+			return nil, fmt.Errorf("value %s: raw value can not contain #{} or ${}", ve.column)
+		}
+		ve.value = raw
+	}
 	if ve.value == "" {
 		ve.value = fmt.Sprintf("#{%s}", ve.column)
 	}
@@ -1054,6 +1348,115 @@ func (p *XMLMappersElementParser) parseValueNode(token xml.StartElement, decoder
 	return nil, errors.New("value node requires value attribute to close")
 }
 
+// parseParamNode parses the param node which declares a default parameter of the statement.
+//
+//	<param name="limit" default="20"/>
+//
+// The type of the default value is given by the type attribute, which is one of
+// int, float, bool and string. If the type is not given, it is inferred from the
+// literal in the order of int, float, bool and string.
+func (p *XMLMappersElementParser) parseParamNode(decoder *xml.Decoder, token xml.StartElement) (string, any, error) {
+	var name, literal, typ string
+	var hasDefault bool
+	for _, attr := range token.Attr {
+		switch attr.Name.Local {
+		case "name":
+			name = attr.Value
+		case "default":
+			literal, hasDefault = attr.Value, true
+		case "type":
+			typ = attr.Value
+		}
+	}
+	if name == "" {
+		return "", nil, &nodeAttributeRequiredError{nodeName: "param", attrName: "name"}
+	}
+	if !hasDefault {
+		return "", nil, &nodeAttributeRequiredError{nodeName: "param", attrName: "default"}
+	}
+	value, err := parseParamLiteral(literal, typ)
+	if err != nil {
+		return "", nil, fmt.Errorf("param %s: %w", name, err)
+	}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return "", nil, err
+		}
+		if end, ok := token.(xml.EndElement); ok && end.Name.Local == "param" {
+			return name, value, nil
+		}
+	}
+	return "", nil, &nodeUnclosedError{nodeName: "param"}
+}
+
+// parseResultNode parses the result node which maps several columns to one field by a columns handler.
+func (p *XMLMappersElementParser) parseResultNode(decoder *xml.Decoder, token xml.StartElement) (columnsResult, error) {
+	var result columnsResult
+	for _, attr := range token.Attr {
+		switch attr.Name.Local {
+		case "property":
+			result.property = attr.Value
+		case "columns":
+			result.columns = splitResultColumns(attr.Value)
+		case "handler":
+			result.handler = attr.Value
+		}
+	}
+	if result.property == "" {
+		return result, &nodeAttributeRequiredError{nodeName: "result", attrName: "property"}
+	}
+	if len(result.columns) == 0 {
+		return result, &nodeAttributeRequiredError{nodeName: "result", attrName: "columns"}
+	}
+	if result.handler == "" {
+		return result, &nodeAttributeRequiredError{nodeName: "result", attrName: "handler"}
+	}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return result, err
+		}
+		if end, ok := token.(xml.EndElement); ok && end.Name.Local == "result" {
+			return result, nil
+		}
+	}
+	return result, &nodeUnclosedError{nodeName: "result"}
+}
+
+// parseParamLiteral parses the literal of the default parameter with the given type.
+func parseParamLiteral(literal, typ string) (any, error) {
+	switch typ {
+	case "int":
+		return strconv.ParseInt(literal, 10, 64)
+	case "float":
+		return strconv.ParseFloat(literal, 64)
+	case "bool":
+		return strconv.ParseBool(literal)
+	case "string":
+		return literal, nil
+	case "":
+		if value, err := strconv.ParseInt(literal, 10, 64); err == nil {
+			return value, nil
+		}
+		if value, err := strconv.ParseFloat(literal, 64); err == nil {
+			return value, nil
+		}
+		if value, err := strconv.ParseBool(literal); err == nil {
+			return value, nil
+		}
+		return literal, nil
+	default:
+		return nil, fmt.Errorf("unsupported param type %q", typ)
+	}
+}
+
 // parseAliasNode parses the alias node
 func (p *XMLMappersElementParser) parseAliasNode(decoder *xml.Decoder) (Node, error) {
 	var node = make(SelectFieldAliasNode, 0)
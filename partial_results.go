@@ -0,0 +1,120 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RowScanError is the error of a row which failed to scan in the partial results mode.
+type RowScanError struct {
+	// Row is the zero based index of the row in the result set.
+	Row int
+
+	// Err is the error of the scanning.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *RowScanError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+// Unwrap returns the error of the scanning.
+func (e *RowScanError) Unwrap() error {
+	return e.Err
+}
+
+// PartialResultError is returned with the partial results, when some rows failed to scan
+// in the partial results mode. The results hold the rows which are scanned successfully.
+type PartialResultError struct {
+	// Errors is the errors of the failed rows, in the order of the rows.
+	Errors []*RowScanError
+}
+
+// Error implements the error interface.
+func (e *PartialResultError) Error() string {
+	var builder strings.Builder
+	_, _ = fmt.Fprintf(&builder, "juice: %d rows failed to scan", len(e.Errors))
+	for _, err := range e.Errors {
+		builder.WriteString("; ")
+		builder.WriteString(err.Error())
+	}
+	return builder.String()
+}
+
+// Unwrap returns the errors of the failed rows, so that errors.Is and errors.As see through them.
+func (e *PartialResultError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// errorOrNil returns nil if no row failed, so that a nil *PartialResultError isn't returned as an error.
+func (e *PartialResultError) errorOrNil() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+// IsPartialResult reports whether the error is a PartialResultError, which means the results
+// hold the rows which are scanned successfully.
+func IsPartialResult(err error) bool {
+	var partial *PartialResultError
+	return errors.As(err, &partial)
+}
+
+type partialResultsKey struct{}
+
+// ContextWithPartialResults returns a new context which enables the partial results mode for the
+// multi-row queries executed with it:
+//
+//	users, err := executor.QueryContext(juice.ContextWithPartialResults(ctx), nil)
+//	if juice.IsPartialResult(err) {
+//	    // users holds the rows which are scanned successfully, err describes the failed ones.
+//	}
+//
+// A row which fails to scan is skipped, and the scanning goes on with the next rows.
+// The errors of the failed rows are returned as a PartialResultError with the other rows.
+// The errors which are not specific to a row, like the iteration errors, still fail the query.
+// The result hooks are not invoked for the partial results.
+func ContextWithPartialResults(ctx context.Context) context.Context {
+	return context.WithValue(ctx, partialResultsKey{}, true)
+}
+
+// partialResults reports whether the partial results mode is enabled by the context.
+func partialResults(ctx context.Context) bool {
+	enabled, _ := ctx.Value(partialResultsKey{}).(bool)
+	return enabled
+}
+
+// partialResultMap returns the ResultMap of the partial results mode for the result type,
+// it returns nil if the default result map of the type isn't a MultiRowsResultMap.
+func partialResultMap[T any]() ResultMap {
+	tp := reflect.TypeOf((*T)(nil)).Elem()
+	if tp.Kind() != reflect.Slice || isStringAnyMap(tp.Elem()) {
+		return nil
+	}
+	return MultiRowsResultMap{Partial: true}
+}
@@ -0,0 +1,80 @@
+package juice
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+type partialUser struct {
+	ID   int64  `column:"id"`
+	Name string `column:"name"`
+}
+
+var partialResultSet = fakeResultSet{
+	columns: []string{"id", "name"},
+	rows:    [][]driver.Value{{int64(1), "a"}, {"bad", "b"}, {int64(3), "c"}},
+}
+
+func TestGenericExecutor_QueryContext_PartialResults(t *testing.T) {
+	db, _ := newFakeDB(t, partialResultSet)
+	executor := &GenericExecutor[[]partialUser]{
+		SQLRowsExecutor: newFakeExecutor(db, newFakeStatement("SELECT id, name FROM user")),
+	}
+	users, err := executor.QueryContext(context.Background(), nil)
+	if err == nil || IsPartialResult(err) || users != nil {
+		t.Fatalf("expected fail fast, got %v, %v", users, err)
+	}
+
+	users, err = executor.QueryContext(ContextWithPartialResults(context.Background()), nil)
+	var partial *PartialResultError
+	if !errors.As(err, &partial) {
+		t.Fatalf("expected PartialResultError, got %v", err)
+	}
+	if len(partial.Errors) != 1 || partial.Errors[0].Row != 1 {
+		t.Errorf("unexpected row errors: %v", partial.Errors)
+	}
+	expected := []partialUser{{ID: 1, Name: "a"}, {ID: 3, Name: "c"}}
+	if !reflect.DeepEqual(users, expected) {
+		t.Errorf("unexpected users: %v", users)
+	}
+}
+
+type partialRowScanner struct {
+	ID int64
+}
+
+func (p *partialRowScanner) ScanRows(rows *sql.Rows) error {
+	var id any
+	var name string
+	if err := rows.Scan(&id, &name); err != nil {
+		return err
+	}
+	value, ok := id.(int64)
+	if !ok {
+		return strconv.ErrSyntax
+	}
+	p.ID = value
+	return nil
+}
+
+func TestMultiRowsResultMap_PartialRowScanner(t *testing.T) {
+	var users []*partialRowScanner
+	err := MultiRowsResultMap{Partial: true}.MapTo(reflect.ValueOf(&users), queryFakeRows(t, partialResultSet))
+	if !IsPartialResult(err) || !errors.Is(err, strconv.ErrSyntax) {
+		t.Fatalf("expected partial result error, got %v", err)
+	}
+	if len(users) != 2 || users[0].ID != 1 || users[1].ID != 3 {
+		t.Errorf("unexpected users: %v", users)
+	}
+
+	var all []*partialRowScanner
+	rows := queryFakeRows(t, fakeResultSet{columns: partialResultSet.columns, rows: [][]driver.Value{{int64(1), "a"}}})
+	if err = (MultiRowsResultMap{Partial: true}).MapTo(reflect.ValueOf(&all), rows); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
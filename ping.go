@@ -0,0 +1,117 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// environmentIDs is implemented by the environment providers which can list their environments.
+type environmentIDs interface {
+	IDs() []string
+}
+
+// environmentDBs is the database connections of the environments other than the default one.
+// They are opened lazily by the first ping and closed with the engine.
+type environmentDBs struct {
+	mu  sync.Mutex
+	dbs map[string]*sql.DB
+}
+
+// get returns the database connection of the environment, opening it if needed.
+func (e *environmentDBs) get(env *Environment) (*sql.DB, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if db, ok := e.dbs[env.ID()]; ok {
+		return db, nil
+	}
+	db, err := ConnectFromEnv(env)
+	if err != nil {
+		return nil, err
+	}
+	if e.dbs == nil {
+		e.dbs = make(map[string]*sql.DB)
+	}
+	e.dbs[env.ID()] = db
+	return db, nil
+}
+
+// close closes all the opened database connections.
+func (e *environmentDBs) close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var errs []error
+	for id, db := range e.dbs {
+		if err := db.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("environment %s: %w", id, err))
+		}
+	}
+	e.dbs = nil
+	return errors.Join(errs...)
+}
+
+// PingContext verifies that the database of every configured environment is reachable,
+// it is useful for the readiness probes:
+//
+//	http.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+//	    if err := engine.PingContext(r.Context()); err != nil {
+//	        http.Error(w, err.Error(), http.StatusServiceUnavailable)
+//	    }
+//	})
+//
+// The environments are pinged in the order of their identifiers, and the errors are
+// joined, each one prefixed with the identifier of its environment.
+// If the environment provider can not list its environments, only the default one is pinged.
+func (e *Engine) PingContext(ctx context.Context) error {
+	envs := e.configuration.Environments()
+	lister, ok := envs.(environmentIDs)
+	if !ok {
+		return e.PingEnvironmentContext(ctx, envs.Attribute("default"))
+	}
+	var errs []error
+	for _, id := range lister.IDs() {
+		if err := e.PingEnvironmentContext(ctx, id); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// PingEnvironmentContext verifies that the database of the environment with the given identifier is reachable.
+// The default environment is pinged through the connection pool of the engine, the others are connected
+// on the first ping and their connections are kept until the engine is closed.
+func (e *Engine) PingEnvironmentContext(ctx context.Context, id string) error {
+	envs := e.configuration.Environments()
+	db := e.db
+	if id != envs.Attribute("default") || db == nil {
+		env, err := envs.Use(id)
+		if err != nil {
+			return err
+		}
+		if db, err = e.environmentDBs.get(env); err != nil {
+			return fmt.Errorf("environment %s: %w", id, err)
+		}
+	}
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("environment %s: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package juice
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestEngine_PingContext(t *testing.T) {
+	fakeDBs.Store("ping_replica", &fakeDB{})
+	t.Cleanup(func() { fakeDBs.Delete("ping_replica") })
+
+	newEnv := func(id, dsn string) *Environment {
+		env := &Environment{Driver: "juice_fake", DataSource: dsn}
+		env.setAttr("id", id)
+		return env
+	}
+	envs := &environments{envs: map[string]*Environment{
+		"master":  newEnv("master", ""),
+		"replica": newEnv("replica", "ping_replica"),
+		"broken":  newEnv("broken", "ping_missing"),
+	}}
+	envs.setAttr("default", "master")
+
+	db, _ := newFakeDB(t, fakeResultSet{})
+	engine := &Engine{configuration: &Configuration{environments: envs}, db: db}
+	defer func() { _ = engine.Close() }()
+
+	ctx := context.Background()
+	if err := engine.PingEnvironmentContext(ctx, "master"); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.PingEnvironmentContext(ctx, "replica"); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.PingEnvironmentContext(ctx, "unknown"); err == nil {
+		t.Error("expected error for unknown environment")
+	}
+
+	err := engine.PingContext(ctx)
+	if err == nil {
+		t.Fatal("expected error for broken environment")
+	}
+	if msg := err.Error(); !strings.Contains(msg, "environment broken: ") || strings.Contains(msg, "replica") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	replica := engine.environmentDBs.dbs["replica"]
+	if replica == nil {
+		t.Fatal("expected replica connection to be kept")
+	}
+	if err = engine.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = replica.Ping(); err == nil {
+		t.Error("expected replica connection to be closed")
+	}
+}
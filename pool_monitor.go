@@ -0,0 +1,136 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultPoolMonitorInterval is the default sampling interval of the pool monitor.
+	defaultPoolMonitorInterval = 10 * time.Second
+
+	// defaultPoolInUseRatio is the default ratio of the in-use connections to the
+	// maximum open connections, above which the pool is considered saturated.
+	defaultPoolInUseRatio = 0.9
+)
+
+// PoolSaturation is the sample reported when the connection pool is saturated.
+type PoolSaturation struct {
+	// Stats is the sampled statistics of the pool.
+	Stats sql.DBStats
+
+	// WaitCount is the number of the connections waited for since the last sample.
+	WaitCount int64
+
+	// WaitDuration is the total time blocked waiting for connections since the last sample.
+	WaitDuration time.Duration
+}
+
+// PoolSaturationHandler is called by the pool monitor when the connection pool is saturated.
+// It is called in the goroutine of the monitor, so it should not block for long.
+type PoolSaturationHandler func(saturation PoolSaturation)
+
+// PoolMonitorOptions is the options of the pool monitor.
+type PoolMonitorOptions struct {
+	// Interval is the sampling interval, defaults to 10 seconds.
+	Interval time.Duration
+
+	// InUseRatio is the ratio of the in-use connections to the maximum open connections,
+	// above which the pool is considered saturated, defaults to 0.9.
+	// It only applies when the maximum open connections is limited.
+	InUseRatio float64
+}
+
+// poolMonitor samples the statistics of a connection pool in the background.
+type poolMonitor struct {
+	stats   func() sql.DBStats
+	handler PoolSaturationHandler
+	options PoolMonitorOptions
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// newPoolMonitor creates a poolMonitor with the defaults of the options applied.
+func newPoolMonitor(stats func() sql.DBStats, handler PoolSaturationHandler, options PoolMonitorOptions) *poolMonitor {
+	if options.Interval <= 0 {
+		options.Interval = defaultPoolMonitorInterval
+	}
+	if options.InUseRatio <= 0 {
+		options.InUseRatio = defaultPoolInUseRatio
+	}
+	return &poolMonitor{
+		stats:   stats,
+		handler: handler,
+		options: options,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// start starts sampling in a new goroutine.
+func (m *poolMonitor) start() {
+	go m.run()
+}
+
+func (m *poolMonitor) run() {
+	defer close(m.done)
+	ticker := time.NewTicker(m.options.Interval)
+	defer ticker.Stop()
+	last := m.stats()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			current := m.stats()
+			if saturation, saturated := m.check(last, current); saturated {
+				m.handler(saturation)
+			}
+			last = current
+		}
+	}
+}
+
+// check reports whether the pool is saturated since the last sample.
+// The pool is saturated when any connection is waited for, or the in-use connections
+// reach the InUseRatio of the maximum open connections.
+func (m *poolMonitor) check(last, current sql.DBStats) (PoolSaturation, bool) {
+	saturation := PoolSaturation{
+		Stats:        current,
+		WaitCount:    current.WaitCount - last.WaitCount,
+		WaitDuration: current.WaitDuration - last.WaitDuration,
+	}
+	if saturation.WaitCount > 0 {
+		return saturation, true
+	}
+	maxOpen := current.MaxOpenConnections
+	if maxOpen > 0 && float64(current.InUse) >= m.options.InUseRatio*float64(maxOpen) {
+		return saturation, true
+	}
+	return saturation, false
+}
+
+// close stops the sampling and waits for the goroutine to exit.
+func (m *poolMonitor) close() {
+	m.stopOnce.Do(func() { close(m.stop) })
+	<-m.done
+}
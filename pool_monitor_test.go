@@ -0,0 +1,73 @@
+package juice
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPoolMonitor_Check(t *testing.T) {
+	monitor := newPoolMonitor(nil, nil, PoolMonitorOptions{})
+	if monitor.options.Interval != defaultPoolMonitorInterval || monitor.options.InUseRatio != defaultPoolInUseRatio {
+		t.Errorf("unexpected options: %+v", monitor.options)
+	}
+	tests := []struct {
+		name      string
+		last      sql.DBStats
+		current   sql.DBStats
+		saturated bool
+	}{
+		{"idle", sql.DBStats{}, sql.DBStats{MaxOpenConnections: 10, InUse: 1}, false},
+		{"waiting", sql.DBStats{WaitCount: 3}, sql.DBStats{WaitCount: 5, WaitDuration: time.Second}, true},
+		{"in use near max", sql.DBStats{}, sql.DBStats{MaxOpenConnections: 10, InUse: 9}, true},
+		{"unlimited", sql.DBStats{}, sql.DBStats{InUse: 100}, false},
+	}
+	for _, tt := range tests {
+		saturation, saturated := monitor.check(tt.last, tt.current)
+		if saturated != tt.saturated {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.saturated, saturated)
+		}
+		if saturation.WaitCount != tt.current.WaitCount-tt.last.WaitCount {
+			t.Errorf("%s: unexpected wait count %d", tt.name, saturation.WaitCount)
+		}
+	}
+}
+
+func TestEngine_MonitorPool(t *testing.T) {
+	db, _ := newFakeDB(t, fakeResultSet{})
+	db.SetMaxOpenConns(1)
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	engine := &Engine{db: db}
+	var calls atomic.Int64
+	saturations := make(chan PoolSaturation, 1)
+	engine.MonitorPool(func(saturation PoolSaturation) {
+		if calls.Add(1) == 1 {
+			saturations <- saturation
+		}
+	}, PoolMonitorOptions{Interval: time.Millisecond})
+
+	select {
+	case saturation := <-saturations:
+		if saturation.Stats.InUse != 1 || saturation.Stats.MaxOpenConnections != 1 {
+			t.Errorf("unexpected stats: %+v", saturation.Stats)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the saturation to be reported")
+	}
+	if err = engine.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// no more samples after close
+	stopped := calls.Load()
+	time.Sleep(10 * time.Millisecond)
+	if calls.Load() != stopped {
+		t.Error("expected the monitor to be stopped")
+	}
+}
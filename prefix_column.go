@@ -0,0 +1,85 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"strings"
+	"unicode"
+)
+
+// prefixColumnOption is the option of the column tag which maps a named struct field
+// from the columns with a common prefix, without an explicit result map.
+//
+//	type User struct {
+//	    ID      int64   `column:"id"`
+//	    Name    string  `column:"name"`
+//	    Address Address `column:"addr,prefix"`
+//	}
+//
+//	type Address struct {
+//	    Street string `column:"street"`
+//	    City   string `column:"city"`
+//	}
+//
+//	SELECT u.id, u.name, a.street AS addr_street, a.city AS addr_city FROM ...
+//
+// The prefix is the name of the tag followed by an underscore. If the name is empty,
+// like `column:",prefix"`, it is derived from the field name in snake case, so that
+// HomeAddress has the prefix home_address_. The prefixes of the nested prefixed fields
+// are joined, and the field must be a struct, not a pointer to a struct.
+const prefixColumnOption = "prefix"
+
+// columnTagOption reports whether the column tag has the given option.
+func columnTagOption(tag, option string) bool {
+	_, options, _ := strings.Cut(tag, ",")
+	for options != "" {
+		var current string
+		current, options, _ = strings.Cut(options, ",")
+		if current == option {
+			return true
+		}
+	}
+	return false
+}
+
+// columnPrefix returns the column prefix of the prefixed field with the given tag name.
+func columnPrefix(fieldName, tagName string) string {
+	if tagName == "" {
+		tagName = snakeCase(fieldName)
+	}
+	return tagName + "_"
+}
+
+// snakeCase converts the Go identifier to snake case, HomeAddress to home_address
+// and UserID to user_id.
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var builder strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			previous := runes[i-1]
+			// a new word starts after a lower case letter or a digit,
+			// or at the last upper case letter of an acronym.
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) ||
+				unicode.IsUpper(previous) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+				builder.WriteByte('_')
+			}
+		}
+		builder.WriteRune(unicode.ToLower(r))
+	}
+	return builder.String()
+}
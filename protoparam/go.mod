@@ -0,0 +1,10 @@
+module github.com/go-juicedev/juice/protoparam
+
+go 1.23
+
+require (
+	github.com/go-juicedev/juice v0.0.0
+	google.golang.org/protobuf v1.34.2
+)
+
+replace github.com/go-juicedev/juice => ../
@@ -0,0 +1,126 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package protoparam provides a parameter which exposes the fields of a proto message
+// by their proto field names, so that proto request messages can be passed directly
+// as mapper parameters.
+//
+// It is kept in its own package to avoid forcing a proto dependency on everyone.
+package protoparam
+
+import (
+	"reflect"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/go-juicedev/juice/eval"
+)
+
+// ensure Parameter implements eval.Parameter.
+var _ eval.Parameter = (*Parameter)(nil) // compile time check
+
+// Parameter is an eval.Parameter which wraps a proto.Message.
+//
+// The fields are resolved by their proto field names (snake_case) with proto reflection,
+// for example `#{user_id}` or `<if test="user_id > 0">`. Nested messages are accessed
+// with dots, like `#{address.city}`.
+//
+// The values are resolved as below:
+//   - unset scalar fields resolve to their zero values, like proto3 does,
+//     so `user_id > 0` works without checking the presence first.
+//   - unset message fields resolve to a nil message, so `address != nil` can be used to check the presence.
+//     The fields of an unset message still resolve to their zero values.
+//   - enums resolve to their numbers as int32.
+//   - repeated fields resolve to []any and map fields resolve to map[K]any, which can be used in foreach.
+//   - unknown field names are not found.
+//
+// Usage:
+//
+//	engine.Object(UserRepository.QueryUser).QueryContext(ctx, protoparam.New(req))
+type Parameter struct {
+	message protoreflect.Message
+}
+
+// New creates a Parameter which wraps the given proto message.
+func New(message proto.Message) *Parameter {
+	return &Parameter{message: message.ProtoReflect()}
+}
+
+// Get implements eval.Parameter.
+func (p *Parameter) Get(name string) (reflect.Value, bool) {
+	message := p.message
+	items := strings.Split(name, ".")
+	for i, item := range items {
+		field := message.Descriptor().Fields().ByName(protoreflect.Name(item))
+		if field == nil {
+			return reflect.Value{}, false
+		}
+		// the last item is the value we want.
+		if i == len(items)-1 {
+			return fieldValue(message, field), true
+		}
+		// only message fields can be accessed by the next item.
+		if field.Message() == nil || field.IsList() || field.IsMap() {
+			return reflect.Value{}, false
+		}
+		// unset message returns an empty read-only message,
+		// so the fields of it are resolved to their zero values.
+		message = message.Get(field).Message()
+	}
+	return reflect.Value{}, false
+}
+
+// fieldValue returns the value of the field of the message.
+func fieldValue(message protoreflect.Message, field protoreflect.FieldDescriptor) reflect.Value {
+	value := message.Get(field)
+	switch {
+	case field.IsList():
+		list := value.List()
+		values := make([]any, list.Len())
+		for i := range values {
+			values[i] = singularValue(field, list.Get(i))
+		}
+		return reflect.ValueOf(values)
+	case field.IsMap():
+		mapValue := value.Map()
+		keyType := reflect.TypeOf(field.MapKey().Default().Interface())
+		values := reflect.MakeMapWithSize(reflect.MapOf(keyType, reflect.TypeOf((*any)(nil)).Elem()), mapValue.Len())
+		mapValue.Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+			values.SetMapIndex(reflect.ValueOf(key.Interface()), reflect.ValueOf(singularValue(field.MapValue(), value)))
+			return true
+		})
+		return values
+	case field.Message() != nil && !message.Has(field):
+		// typed nil message
+		return reflect.ValueOf(value.Message().Type().Zero().Interface())
+	default:
+		return reflect.ValueOf(singularValue(field, value))
+	}
+}
+
+// singularValue converts the singular proto value to its go value.
+func singularValue(field protoreflect.FieldDescriptor, value protoreflect.Value) any {
+	switch field.Kind() {
+	case protoreflect.EnumKind:
+		return int32(value.Enum())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return value.Message().Interface()
+	default:
+		return value.Interface()
+	}
+}
@@ -0,0 +1,91 @@
+package protoparam
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/sourcecontextpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/typepb"
+
+	"github.com/go-juicedev/juice/eval"
+)
+
+func TestParameter(t *testing.T) {
+	param := New(&typepb.Field{
+		Name:       "user_id",
+		Number:     1,
+		Kind:       typepb.Field_TYPE_INT64,
+		Options:    []*typepb.Option{{Name: "a"}, {Name: "b"}},
+		OneofIndex: 0,
+	})
+	value, ok := param.Get("number")
+	if !ok || value.Interface() != int32(1) {
+		t.Errorf("unexpected number: %v", value)
+	}
+	value, ok = param.Get("kind")
+	if !ok || value.Interface() != int32(typepb.Field_TYPE_INT64) {
+		t.Errorf("unexpected kind: %v", value)
+	}
+	// unset field resolves to zero value
+	value, ok = param.Get("json_name")
+	if !ok || value.Interface() != "" {
+		t.Errorf("unexpected json_name: %v", value)
+	}
+	value, ok = param.Get("options")
+	if !ok || value.Len() != 2 {
+		t.Fatalf("unexpected options: %v", value)
+	}
+	if _, ok = param.Get("Name"); ok {
+		t.Error("go field name should not be found")
+	}
+	if _, ok = param.Get("name.value"); ok {
+		t.Error("scalar field should not be accessed by the next item")
+	}
+}
+
+func TestParameterNestedMessage(t *testing.T) {
+	param := New(&typepb.Type{Name: "User"})
+	value, ok := param.Get("source_context")
+	if !ok {
+		t.Fatal("source_context not found")
+	}
+	if !value.IsNil() {
+		t.Errorf("unset message should be nil: %v", value)
+	}
+	// fields of unset message resolve to zero values
+	value, ok = param.Get("source_context.file_name")
+	if !ok || value.Interface() != "" {
+		t.Errorf("unexpected file_name: %v", value)
+	}
+
+	param = New(&typepb.Type{SourceContext: &sourcecontextpb.SourceContext{FileName: "user.proto"}})
+	value, ok = param.Get("source_context.file_name")
+	if !ok || value.Interface() != "user.proto" {
+		t.Errorf("unexpected file_name: %v", value)
+	}
+}
+
+func TestParameterMap(t *testing.T) {
+	param := New(&structpb.Struct{Fields: map[string]*structpb.Value{"a": structpb.NewNumberValue(1)}})
+	value, ok := param.Get("fields")
+	if !ok || value.Kind() != reflect.Map || value.Len() != 1 {
+		t.Fatalf("unexpected fields: %v", value)
+	}
+	if _, ok = value.MapIndex(reflect.ValueOf("a")).Interface().(*structpb.Value); !ok {
+		t.Errorf("unexpected map value: %v", value.MapIndex(reflect.ValueOf("a")))
+	}
+}
+
+func TestParameterEval(t *testing.T) {
+	param := eval.NewGenericParam(New(&typepb.Field{Number: 2, Name: "id"}), "")
+	for _, expr := range []string{`number > 0`, `name == "id"`, `json_name == ""`} {
+		value, err := eval.Eval(expr, param)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !value.Bool() {
+			t.Errorf("expected %s to be true", expr)
+		}
+	}
+}
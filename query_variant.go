@@ -0,0 +1,183 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// ErrQueryVariantIncompatible is an error that is returned when a query variant can not replace its statement.
+var ErrQueryVariantIncompatible = errors.New("juice: incompatible query variant")
+
+// QueryVariant routes the executions of a select statement to an alternative statement,
+// for the query performance experiments, like rolling out an optimized rewrite of a query
+// to a part of the traffic:
+//
+//	err := engine.UseQueryVariant(juice.QueryVariant{
+//	    Statement: "main.UserMapper.SearchUsers",
+//	    Variant:   "main.UserMapper.SearchUsersV2",
+//	    Enabled:   juice.SampleQueryVariant(0.1),
+//	    Observer:  observer,
+//	})
+//
+// The variant is built with the same parameters, and its rows are bound the same way as the
+// rows of the statement, so it must select a compatible result shape, the same columns with
+// the same types. The middlewares see the variant as the executed statement.
+type QueryVariant struct {
+	// Statement is the fully qualified id of the statement to replace, see Statement.Name.
+	Statement string
+
+	// Variant is the fully qualified id of the alternative statement.
+	Variant string
+
+	// Enabled decides for each execution whether the variant runs instead of the statement.
+	// If nil, the variant never runs.
+	Enabled func(ctx context.Context) bool
+
+	// Observer receives the observations of both the statement and the variant, to compare them.
+	// If nil, nothing is observed.
+	Observer QueryVariantObserver
+}
+
+// SampleQueryVariant returns a QueryVariant.Enabled function which runs the variant for the
+// given ratio of the executions, from 0 to 1.
+func SampleQueryVariant(ratio float64) func(ctx context.Context) bool {
+	return func(context.Context) bool {
+		return rand.Float64() < ratio
+	}
+}
+
+// QueryVariantObservation is an execution of a statement which has a QueryVariant.
+type QueryVariantObservation struct {
+	// Statement is the fully qualified id of the replaced statement.
+	Statement string
+
+	// Variant is the fully qualified id of the executed statement,
+	// which is the Statement itself when the variant didn't run.
+	Variant string
+
+	// Duration is the time taken until the rows are returned, their scanning is not included.
+	Duration time.Duration
+
+	// Err is the error returned by the query, if any.
+	Err error
+}
+
+// QueryVariantObserver receives the observations of the query variants, like a metrics collector
+// which compares the latency and the error rate of the variants.
+// Implementations must be safe for concurrent use.
+type QueryVariantObserver interface {
+	ObserveQueryVariant(ctx context.Context, observation QueryVariantObservation)
+}
+
+// QueryVariantObserverFunc is an adapter to allow the use of ordinary functions as QueryVariantObserver.
+type QueryVariantObserverFunc func(ctx context.Context, observation QueryVariantObservation)
+
+// ObserveQueryVariant implements QueryVariantObserver.
+func (f QueryVariantObserverFunc) ObserveQueryVariant(ctx context.Context, observation QueryVariantObservation) {
+	f(ctx, observation)
+}
+
+// queryVariants is the query variants keyed by the fully qualified id of their statements.
+type queryVariants map[string]QueryVariant
+
+// route returns the statement to execute instead of the given statement.
+// It returns the statement itself if it has no variant, or the variant is not enabled.
+func (v queryVariants) route(ctx context.Context, statement Statement) (Statement, error) {
+	variant, ok := v[statement.Name()]
+	if !ok || statement.Action() != Select || variant.Enabled == nil || !variant.Enabled(ctx) {
+		return statement, nil
+	}
+	alternative, err := statement.Configuration().GetStatement(variant.Variant)
+	if err != nil {
+		return nil, fmt.Errorf("query variant %s of %s: %w", variant.Variant, variant.Statement, err)
+	}
+	return variantStatement{Statement: alternative, origin: statement}, nil
+}
+
+// variantStatement is a query variant executed instead of its origin statement.
+type variantStatement struct {
+	Statement
+	origin Statement
+}
+
+// ensure queryVariantMiddleware implements Middleware.
+var _ Middleware = (*queryVariantMiddleware)(nil) // compile time check
+
+// queryVariantMiddleware observes the queries of the statements which have a QueryVariant.
+type queryVariantMiddleware struct {
+	variants queryVariants
+}
+
+// QueryContext implements Middleware.
+func (m *queryVariantMiddleware) QueryContext(stmt Statement, next QueryHandler) QueryHandler {
+	origin := stmt
+	if routed, ok := stmt.(variantStatement); ok {
+		origin = routed.origin
+	}
+	variant, ok := m.variants[origin.Name()]
+	if !ok || variant.Observer == nil {
+		return next
+	}
+	return func(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+		start := time.Now()
+		rows, err := next(ctx, query, args...)
+		variant.Observer.ObserveQueryVariant(ctx, QueryVariantObservation{
+			Statement: origin.Name(),
+			Variant:   stmt.Name(),
+			Duration:  time.Since(start),
+			Err:       err,
+		})
+		return rows, err
+	}
+}
+
+// ExecContext implements Middleware.
+func (m *queryVariantMiddleware) ExecContext(_ Statement, next ExecHandler) ExecHandler {
+	return next
+}
+
+// UseQueryVariant adds a QueryVariant to the engine, replacing the previous variant of its statement.
+// Both the statement and the variant must be select statements of the configuration, or
+// ErrQueryVariantIncompatible is returned.
+// It is not goroutine safe, so it should be called before the engine is used.
+func (e *Engine) UseQueryVariant(variant QueryVariant) error {
+	cfg := e.GetConfiguration()
+	for _, id := range []string{variant.Statement, variant.Variant} {
+		statement, err := cfg.GetStatement(id)
+		if err != nil {
+			return err
+		}
+		if statement.Action() != Select {
+			return fmt.Errorf("%w: %s is not a select statement", ErrQueryVariantIncompatible, id)
+		}
+	}
+	if variant.Statement == variant.Variant {
+		return fmt.Errorf("%w: %s replaces itself", ErrQueryVariantIncompatible, variant.Statement)
+	}
+	if e.variants == nil {
+		e.variants = make(queryVariants)
+		e.Use(&queryVariantMiddleware{variants: e.variants})
+	}
+	e.variants[variant.Statement] = variant
+	return nil
+}
@@ -0,0 +1,110 @@
+package juice
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-juicedev/juice/driver"
+)
+
+func newQueryVariantEngine(t *testing.T) (*Engine, *fakeDB) {
+	t.Helper()
+	fsys := fstest.MapFS{
+		"config/juice.xml": {Data: []byte(reloadableConfigXML)},
+		"config/mappers/mapper.xml": {Data: []byte(`<?xml version="1.0" encoding="utf-8" ?>
+<mapper namespace="main.Repository">
+    <select id="QueryUser">SELECT id FROM user WHERE id = #{id}</select>
+    <select id="QueryUserV2">SELECT id FROM user FORCE INDEX (primary) WHERE id = #{id}</select>
+    <update id="UpdateUser">UPDATE user SET name = #{name}</update>
+</mapper>`)},
+	}
+	cfg, err := NewXMLConfigurationWithFS(fsys, "config/juice.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, state := newFakeDB(t, fakeResultSet{columns: []string{"id"}})
+	engine := &Engine{configuration: cfg, driver: driver.MySQLDriver{}, db: db, rw: &NoOpRWMutex{}}
+	return engine, state
+}
+
+func TestEngine_UseQueryVariant(t *testing.T) {
+	engine, state := newQueryVariantEngine(t)
+	var (
+		mu           sync.Mutex
+		observations []QueryVariantObservation
+	)
+	enabled := true
+	err := engine.UseQueryVariant(QueryVariant{
+		Statement: "main.Repository.QueryUser",
+		Variant:   "main.Repository.QueryUserV2",
+		Enabled:   func(context.Context) bool { return enabled },
+		Observer: QueryVariantObserverFunc(func(_ context.Context, observation QueryVariantObservation) {
+			mu.Lock()
+			defer mu.Unlock()
+			observations = append(observations, observation)
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for _, variant := range []bool{true, false} {
+		enabled = variant
+		rows, err := engine.Object("main.Repository.QueryUser").QueryContext(ctx, map[string]any{"id": 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = rows.Close()
+	}
+	if len(state.executions) != 2 {
+		t.Fatalf("expected 2 executions, got %d", len(state.executions))
+	}
+	if query := state.executions[0].query; query != "SELECT id FROM user FORCE INDEX (primary) WHERE id = ?" {
+		t.Errorf("expected the variant query, got %s", query)
+	}
+	if query := state.executions[1].query; query != "SELECT id FROM user WHERE id = ?" {
+		t.Errorf("expected the statement query, got %s", query)
+	}
+	if len(observations) != 2 {
+		t.Fatalf("expected 2 observations, got %d", len(observations))
+	}
+	if observations[0].Statement != "main.Repository.QueryUser" || observations[0].Variant != "main.Repository.QueryUserV2" {
+		t.Errorf("unexpected variant observation: %+v", observations[0])
+	}
+	if observations[1].Variant != "main.Repository.QueryUser" {
+		t.Errorf("unexpected statement observation: %+v", observations[1])
+	}
+}
+
+func TestEngine_UseQueryVariant_Incompatible(t *testing.T) {
+	engine, _ := newQueryVariantEngine(t)
+	tests := []QueryVariant{
+		{Statement: "main.Repository.QueryUser", Variant: "main.Repository.UpdateUser"},
+		{Statement: "main.Repository.QueryUser", Variant: "main.Repository.QueryUser"},
+	}
+	for _, variant := range tests {
+		if err := engine.UseQueryVariant(variant); !errors.Is(err, ErrQueryVariantIncompatible) {
+			t.Errorf("expected ErrQueryVariantIncompatible for %s, got %v", variant.Variant, err)
+		}
+	}
+	if err := engine.UseQueryVariant(QueryVariant{Statement: "main.Repository.QueryUser", Variant: "main.Repository.Missing"}); err == nil {
+		t.Error("expected error for missing variant")
+	}
+	if len(engine.middlewares) != 0 {
+		t.Error("expected no middleware for the rejected variants")
+	}
+}
+
+func TestSampleQueryVariant(t *testing.T) {
+	ctx := context.Background()
+	if SampleQueryVariant(0)(ctx) {
+		t.Error("expected the variant never to run")
+	}
+	if !SampleQueryVariant(1)(ctx) {
+		t.Error("expected the variant always to run")
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrWriteInReadStatement is an error that is returned by the ReadOnlyMiddleware
+// when a read statement contains a write keyword.
+var ErrWriteInReadStatement = errors.New("write keyword in read statement")
+
+// writeKeywords are the keywords which mutate the data or the schema.
+//
+// REPLACE is not included, since it is also a common string function.
+var writeKeywords = map[string]struct{}{
+	"INSERT":   {},
+	"UPDATE":   {},
+	"DELETE":   {},
+	"MERGE":    {},
+	"UPSERT":   {},
+	"CREATE":   {},
+	"ALTER":    {},
+	"DROP":     {},
+	"TRUNCATE": {},
+	"RENAME":   {},
+	"GRANT":    {},
+	"REVOKE":   {},
+}
+
+// ensure ReadOnlyMiddleware implements Middleware.
+var _ Middleware = (*ReadOnlyMiddleware)(nil) // compile time check
+
+// ReadOnlyMiddleware is a middleware that rejects the read statements whose built query
+// contains a write keyword, like INSERT, UPDATE, DELETE or DDL, before they are executed.
+// It catches the mistakes where a <select> accidentally contains a mutation, for example
+// by a data-modifying CTE.
+//
+// The detection is keyword based and conservative:
+//   - string literals, quoted identifiers and comments are skipped, so that
+//     'please update me' and "delete" don't cause false positives.
+//   - the UPDATE of the locking read "FOR UPDATE" is allowed.
+//   - REPLACE is not detected, since it is also a string function.
+//
+// The statements whose action is not a read are not checked, so a <select> which
+// intentionally mutates can opt out with its action attribute:
+//
+//	<select id="ArchiveUsers" action="delete">...</select>
+type ReadOnlyMiddleware struct{}
+
+// QueryContext implements Middleware.
+// QueryContext will reject the query of a read statement which contains a write keyword.
+func (m ReadOnlyMiddleware) QueryContext(stmt Statement, next QueryHandler) QueryHandler {
+	if !stmt.Action().ForRead() {
+		return next
+	}
+	return func(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+		if err := m.check(stmt, query); err != nil {
+			return nil, err
+		}
+		return next(ctx, query, args...)
+	}
+}
+
+// ExecContext implements Middleware.
+// ExecContext will reject the query of a read statement which contains a write keyword.
+func (m ReadOnlyMiddleware) ExecContext(stmt Statement, next ExecHandler) ExecHandler {
+	if !stmt.Action().ForRead() {
+		return next
+	}
+	return func(ctx context.Context, query string, args ...any) (sql.Result, error) {
+		if err := m.check(stmt, query); err != nil {
+			return nil, err
+		}
+		return next(ctx, query, args...)
+	}
+}
+
+// check returns an error if the query contains a write keyword.
+func (m ReadOnlyMiddleware) check(stmt Statement, query string) error {
+	if keyword, found := findWriteKeyword(query); found {
+		return fmt.Errorf("%w: %s contains %s", ErrWriteInReadStatement, stmt.Name(), keyword)
+	}
+	return nil
+}
+
+// findWriteKeyword returns the first write keyword of the query, skipping the string literals,
+// the quoted identifiers and the comments.
+func findWriteKeyword(query string) (string, bool) {
+	var previous string
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(query, i)
+		case c == '[':
+			// the quoted identifier of SQL Server.
+			if end := strings.IndexByte(query[i:], ']'); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(query)
+			}
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			if end := strings.IndexByte(query[i:], '\n'); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(query)
+			}
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			if end := strings.Index(query[i+2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(query)
+			}
+		case isWordByte(c):
+			start := i
+			for i < len(query) && isWordByte(query[i]) {
+				i++
+			}
+			word := strings.ToUpper(query[start:i])
+			if _, ok := writeKeywords[word]; ok && !(word == "UPDATE" && previous == "FOR") {
+				return word, true
+			}
+			previous = word
+		default:
+			i++
+		}
+	}
+	return "", false
+}
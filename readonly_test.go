@@ -0,0 +1,61 @@
+package juice
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestFindWriteKeyword(t *testing.T) {
+	tests := []struct {
+		query   string
+		keyword string
+	}{
+		{"SELECT * FROM user WHERE id = ?", ""},
+		{"SELECT updated_at, deleted FROM user", ""},
+		{"SELECT * FROM user WHERE note = 'please update me' AND \"delete\" = 1", ""},
+		{"SELECT `drop`, [create] FROM user", ""},
+		{"SELECT * FROM user -- delete later\nWHERE id = ?", ""},
+		{"SELECT * FROM user /* insert into user */ WHERE id = ?", ""},
+		{"SELECT * FROM user WHERE name = 'it''s update' FOR UPDATE", ""},
+		{"SELECT REPLACE(name, 'a', 'b') FROM user", ""},
+		{"WITH archived AS (DELETE FROM user RETURNING *) SELECT * FROM archived", "DELETE"},
+		{"SELECT * FROM user; drop table user", "DROP"},
+		{"select * from user where id = ?; Insert into log values (1)", "INSERT"},
+	}
+	for _, tt := range tests {
+		keyword, found := findWriteKeyword(tt.query)
+		if keyword != tt.keyword || found != (tt.keyword != "") {
+			t.Errorf("%q: expected %q, got %q", tt.query, tt.keyword, keyword)
+		}
+	}
+}
+
+func TestReadOnlyMiddleware(t *testing.T) {
+	var executed bool
+	next := func(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+		executed = true
+		return nil, nil
+	}
+	query := "WITH archived AS (DELETE FROM user RETURNING *) SELECT * FROM archived"
+
+	stmt := newFakeStatement(query)
+	_, err := ReadOnlyMiddleware{}.QueryContext(stmt, next)(context.Background(), query)
+	if !errors.Is(err, ErrWriteInReadStatement) {
+		t.Errorf("expected ErrWriteInReadStatement, got %v", err)
+	}
+	if executed {
+		t.Error("expected the query not to be executed")
+	}
+
+	// the statements of a write action are not checked.
+	stmt.action = Delete
+	handler := ReadOnlyMiddleware{}.QueryContext(stmt, next)
+	if _, err = handler(context.Background(), query); err != nil {
+		t.Fatal(err)
+	}
+	if !executed {
+		t.Error("expected the query to be executed")
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"errors"
+	"io/fs"
+	"sync"
+	"sync/atomic"
+)
+
+// ensure ReloadableConfiguration implements IConfiguration.
+var _ IConfiguration = (*ReloadableConfiguration)(nil) // compile time check
+
+// configurationSnapshot holds one loaded configuration.
+type configurationSnapshot struct {
+	IConfiguration
+}
+
+// ReloadableConfiguration is a thread-safe IConfiguration whose mappers and settings
+// can be reloaded at runtime, without restarting the long-running service.
+//
+// Reload parses the configuration again and swaps it atomically. The statements resolved
+// after the reload use the new definitions, while the statements which are already resolved,
+// like the in-flight queries, keep using the old ones.
+//
+// The environments are reloaded too, but the database connections which are opened by the
+// engine are not reopened, so the changes of the environments take effect on the next engine.
+//
+// Usage:
+//
+//	cfg, err := juice.NewReloadableXMLConfiguration("config.xml")
+//	if err != nil {
+//	    // handle error
+//	}
+//	engine, err := juice.Default(cfg)
+//	...
+//	// when the mapper files change
+//	if err = cfg.Reload(); err != nil {
+//	    // the current configuration is kept
+//	}
+type ReloadableConfiguration struct {
+	current atomic.Pointer[configurationSnapshot]
+
+	// load parses the configuration.
+	load func() (IConfiguration, error)
+
+	// mu serializes the reloads.
+	mu sync.Mutex
+}
+
+// NewReloadableConfiguration creates a ReloadableConfiguration which is loaded by the given function.
+func NewReloadableConfiguration(load func() (IConfiguration, error)) (*ReloadableConfiguration, error) {
+	if load == nil {
+		return nil, errors.New("juice: configuration loader is nil")
+	}
+	cfg := &ReloadableConfiguration{load: load}
+	if err := cfg.Reload(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// NewReloadableXMLConfiguration creates a ReloadableConfiguration from an XML file.
+func NewReloadableXMLConfiguration(filename string) (*ReloadableConfiguration, error) {
+	return NewReloadableConfiguration(func() (IConfiguration, error) {
+		return NewXMLConfiguration(filename)
+	})
+}
+
+// NewReloadableXMLConfigurationWithFS creates a ReloadableConfiguration from an XML file of the fs.
+func NewReloadableXMLConfigurationWithFS(fs fs.FS, filename string) (*ReloadableConfiguration, error) {
+	return NewReloadableConfiguration(func() (IConfiguration, error) {
+		return NewXMLConfigurationWithFS(fs, filename)
+	})
+}
+
+// Reload parses the configuration again and replaces the current one atomically.
+// If the parsing fails, the current configuration is kept and the error is returned.
+func (c *ReloadableConfiguration) Reload() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cfg, err := c.load()
+	if err != nil {
+		return err
+	}
+	c.current.Store(&configurationSnapshot{IConfiguration: cfg})
+	return nil
+}
+
+// Current returns the current configuration.
+// The returned configuration is never changed by the later reloads.
+func (c *ReloadableConfiguration) Current() IConfiguration {
+	return c.current.Load().IConfiguration
+}
+
+// Environments implements IConfiguration.
+func (c *ReloadableConfiguration) Environments() EnvironmentProvider {
+	return c.Current().Environments()
+}
+
+// Settings implements IConfiguration.
+func (c *ReloadableConfiguration) Settings() SettingProvider {
+	return c.Current().Settings()
+}
+
+// GetStatement implements IConfiguration.
+func (c *ReloadableConfiguration) GetStatement(v any) (Statement, error) {
+	return c.Current().GetStatement(v)
+}
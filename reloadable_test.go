@@ -0,0 +1,75 @@
+package juice
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-juicedev/juice/driver"
+)
+
+const reloadableConfigXML = `<?xml version="1.0" encoding="UTF-8"?>
+<configuration>
+    <environments default="prod">
+        <environment id="prod">
+            <dataSource>fake</dataSource>
+            <driver>fake</driver>
+        </environment>
+    </environments>
+    <mappers pattern="mappers/*.xml"/>
+</configuration>`
+
+func reloadableMapperXML(query string) []byte {
+	return []byte(`<?xml version="1.0" encoding="utf-8" ?>
+<mapper namespace="main.Repository">
+    <select id="QueryUser">` + query + `</select>
+</mapper>`)
+}
+
+func TestReloadableConfiguration_Reload(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config/juice.xml":          {Data: []byte(reloadableConfigXML)},
+		"config/mappers/mapper.xml": {Data: reloadableMapperXML("SELECT * FROM user_v1")},
+	}
+	cfg, err := NewReloadableXMLConfigurationWithFS(fsys, "config/juice.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	translator := driver.MySQLDriver{}.Translator()
+	before, err := cfg.GetStatement("main.Repository.QueryUser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	current := cfg.Current()
+
+	fsys["config/mappers/mapper.xml"] = &fstest.MapFile{Data: reloadableMapperXML("SELECT * FROM user_v2")}
+	if err = cfg.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	after, err := cfg.GetStatement("main.Repository.QueryUser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query, _, _ := after.Build(translator, nil); query != "SELECT * FROM user_v2" {
+		t.Errorf("unexpected query after reload: %s", query)
+	}
+	// the statement resolved before the reload keeps the old definition.
+	if query, _, _ := before.Build(translator, nil); query != "SELECT * FROM user_v1" {
+		t.Errorf("unexpected query before reload: %s", query)
+	}
+	if cfg.Current() == current {
+		t.Error("expected the configuration to be replaced")
+	}
+
+	// a broken definition keeps the current configuration.
+	fsys["config/mappers/mapper.xml"] = &fstest.MapFile{Data: []byte("<mapper namespace=\"main.Repository\"><select>")}
+	if err = cfg.Reload(); err == nil {
+		t.Fatal("expected error")
+	}
+	stmt, err := cfg.GetStatement("main.Repository.QueryUser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query, _, _ := stmt.Build(translator, nil); query != "SELECT * FROM user_v2" {
+		t.Errorf("unexpected query: %s", query)
+	}
+}
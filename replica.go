@@ -0,0 +1,136 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/go-juicedev/juice/session"
+)
+
+// forcePrimaryAttribute is the attribute of the select statements which keeps them on the primary
+// database when the ReplicaMiddleware is used, like the reads which must see the latest writes:
+//
+//	<select id="QueryBalance" forcePrimary="true">...</select>
+const forcePrimaryAttribute = "forcePrimary"
+
+// ensure ReplicaMiddleware implements Middleware.
+var _ Middleware = (*ReplicaMiddleware)(nil) // compile time check
+
+// ReplicaMiddleware is a middleware that routes the read statements to the replica sessions,
+// while the write statements are executed by the primary session of the engine.
+// The replicas are chosen in turn.
+//
+// The select statements stay on the primary session when:
+//   - a transaction is active, since the session of the context is a transaction, which pins
+//     all its statements to the primary to read its own writes, see Transaction and Engine.Tx.
+//   - the statement opts out with forcePrimary="true".
+//   - the statement is a locking read, see the lock attribute.
+//
+// The statements with a dataSource attribute selecting another environment are executed
+// by the database of that environment, they are not routed to the replicas.
+//
+// The session is resolved at execution time from the context, so the middlewares added after
+// the ReplicaMiddleware see the replica session by session.FromContext.
+// Use Engine.UseReplicas to route to the databases of the configured environments.
+type ReplicaMiddleware struct {
+	// Replicas is the sessions of the replicas, like their *sql.DB.
+	// If empty, all the statements are executed by the primary session.
+	Replicas []session.Session
+
+	next atomic.Uint64
+}
+
+// QueryContext implements Middleware.
+// QueryContext will execute the read statement with a replica session unless it must stay on the primary.
+func (m *ReplicaMiddleware) QueryContext(stmt Statement, next QueryHandler) QueryHandler {
+	if !stmt.Action().ForRead() || len(m.Replicas) == 0 {
+		return next
+	}
+	if StringValue(stmt.Attribute(forcePrimaryAttribute)).Bool() || stmt.Attribute(lockAttribute) != "" {
+		return next
+	}
+	// the session of the context is the database of the data source, not the primary.
+	if _, ok := otherDataSource(stmt.Configuration(), stmt); ok {
+		return next
+	}
+	return func(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+		if sess, err := session.FromContext(ctx); err == nil {
+			if _, inTransaction := sess.(session.Transaction); inTransaction {
+				return next(ctx, query, args...)
+			}
+		}
+		ctx = session.WithContext(ctx, m.replica())
+		return next(ctx, query, args...)
+	}
+}
+
+// ExecContext implements Middleware.
+// ExecContext will always execute the statement with the primary session.
+func (m *ReplicaMiddleware) ExecContext(_ Statement, next ExecHandler) ExecHandler {
+	return next
+}
+
+// replica returns the next replica session.
+func (m *ReplicaMiddleware) replica() session.Session {
+	index := m.next.Add(1) - 1
+	return m.Replicas[index%uint64(len(m.Replicas))]
+}
+
+// UseReplicas adds a ReplicaMiddleware which routes the read statements to the databases
+// of the environments with the given identifiers, while the default environment of the
+// configuration stays the primary:
+//
+//	<environments default="primary">
+//	    <environment id="primary">...</environment>
+//	    <environment id="replica1">...</environment>
+//	    <environment id="replica2">...</environment>
+//	</environments>
+//
+//	if err := engine.UseReplicas("replica1", "replica2"); err != nil {
+//	    log.Fatal(err)
+//	}
+//
+// The replica databases are connected immediately and closed with the engine.
+// The statements executed in a transaction always use the primary, see ReplicaMiddleware.
+func (e *Engine) UseReplicas(ids ...string) error {
+	if len(ids) == 0 {
+		return errors.New("juice: no replica environments")
+	}
+	envs := e.configuration.Environments()
+	replicas := make([]session.Session, 0, len(ids))
+	for _, id := range ids {
+		if id == envs.Attribute("default") {
+			return fmt.Errorf("juice: environment %s is the primary", id)
+		}
+		env, err := envs.Use(id)
+		if err != nil {
+			return err
+		}
+		db, err := e.environmentDBs.get(env)
+		if err != nil {
+			return fmt.Errorf("environment %s: %w", id, err)
+		}
+		replicas = append(replicas, db)
+	}
+	e.Use(&ReplicaMiddleware{Replicas: replicas})
+	return nil
+}
@@ -0,0 +1,168 @@
+package juice
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-juicedev/juice/driver"
+)
+
+func TestEngine_UseReplicas(t *testing.T) {
+	replica := &fakeDB{}
+	fakeDBs.Store("replica_test", replica)
+	t.Cleanup(func() { fakeDBs.Delete("replica_test") })
+
+	fsys := fstest.MapFS{
+		"config/juice.xml": {Data: []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<configuration>
+    <environments default="primary">
+        <environment id="primary">
+            <dataSource>fake</dataSource>
+            <driver>fake</driver>
+        </environment>
+        <environment id="replica">
+            <dataSource>replica_test</dataSource>
+            <driver>juice_fake</driver>
+        </environment>
+    </environments>
+    <mappers pattern="mappers/*.xml"/>
+</configuration>`)},
+		"config/mappers/mapper.xml": {Data: []byte(`<?xml version="1.0" encoding="utf-8" ?>
+<mapper namespace="main.Repository">
+    <select id="QueryUser">SELECT id FROM user</select>
+    <select id="QueryBalance" forcePrimary="true">SELECT balance FROM user</select>
+    <select id="ClaimUser" lock="update">SELECT id FROM user</select>
+    <update id="UpdateUser">UPDATE user SET name = 'a'</update>
+</mapper>`)},
+	}
+	cfg, err := NewXMLConfigurationWithFS(fsys, "config/juice.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, primary := newFakeDB(t, fakeResultSet{})
+	engine := &Engine{configuration: cfg, driver: driver.MySQLDriver{}, db: db, rw: &NoOpRWMutex{}}
+	defer func() { _ = engine.Close() }()
+
+	if err = engine.UseReplicas("primary"); err == nil {
+		t.Error("expected error for the primary environment")
+	}
+	if err = engine.UseReplicas("unknown"); err == nil {
+		t.Error("expected error for unknown environment")
+	}
+	if err = engine.UseReplicas("replica"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	query := func(manager Manager, id string) {
+		t.Helper()
+		rows, err := manager.Object(id).QueryContext(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = rows.Close()
+	}
+	query(engine, "main.Repository.QueryUser")
+	query(engine, "main.Repository.QueryBalance")
+	query(engine, "main.Repository.ClaimUser")
+	if _, err = engine.Object("main.Repository.UpdateUser").ExecContext(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// the transaction pins the reads to the primary.
+	err = Transaction(ContextWithManager(ctx, engine), func(ctx context.Context) error {
+		rows, err := ManagerFromContext(ctx).Object("main.Repository.QueryUser").QueryContext(ctx, nil)
+		if err != nil {
+			return err
+		}
+		return rows.Close()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(replica.executions) != 1 || replica.executions[0].query != "SELECT id FROM user" {
+		t.Errorf("unexpected replica executions: %+v", replica.executions)
+	}
+	expected := []string{"SELECT balance FROM user", "SELECT id FROM user FOR UPDATE", "UPDATE user SET name = 'a'", "SELECT id FROM user"}
+	if len(primary.executions) != len(expected) {
+		t.Fatalf("unexpected primary executions: %+v", primary.executions)
+	}
+	for i, query := range expected {
+		if primary.executions[i].query != query {
+			t.Errorf("expected %s, got %s", query, primary.executions[i].query)
+		}
+	}
+	if primary.commits != 1 {
+		t.Errorf("expected the transaction to be committed, got %d commits", primary.commits)
+	}
+}
+
+func TestEngine_UseReplicas_DataSource(t *testing.T) {
+	driver.Register("juice_fake", driver.PostgresDriver{})
+	replica, analytics := &fakeDB{}, &fakeDB{}
+	fakeDBs.Store("replica_data_source_test", replica)
+	fakeDBs.Store("analytics_data_source_test", analytics)
+	t.Cleanup(func() {
+		fakeDBs.Delete("replica_data_source_test")
+		fakeDBs.Delete("analytics_data_source_test")
+	})
+
+	fsys := fstest.MapFS{
+		"config/juice.xml": {Data: []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<configuration>
+    <environments default="primary">
+        <environment id="primary">
+            <dataSource>fake</dataSource>
+            <driver>fake</driver>
+        </environment>
+        <environment id="replica">
+            <dataSource>replica_data_source_test</dataSource>
+            <driver>juice_fake</driver>
+        </environment>
+        <environment id="analytics">
+            <dataSource>analytics_data_source_test</dataSource>
+            <driver>juice_fake</driver>
+        </environment>
+    </environments>
+    <mappers pattern="mappers/*.xml"/>
+</configuration>`)},
+		"config/mappers/mapper.xml": {Data: []byte(`<?xml version="1.0" encoding="utf-8" ?>
+<mapper namespace="main.Repository">
+    <select id="QueryReport" dataSource="analytics">SELECT * FROM report WHERE day = #{day}</select>
+    <select id="QueryUser" dataSource="primary">SELECT id FROM user</select>
+</mapper>`)},
+	}
+	cfg, err := NewXMLConfigurationWithFS(fsys, "config/juice.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, primary := newFakeDB(t, fakeResultSet{})
+	engine := &Engine{configuration: cfg, driver: driver.MySQLDriver{}, db: db, rw: &NoOpRWMutex{}}
+	defer func() { _ = engine.Close() }()
+	if err = engine.UseReplicas("replica"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for _, id := range []string{"main.Repository.QueryReport", "main.Repository.QueryUser"} {
+		rows, err := engine.Object(id).QueryContext(ctx, H{"day": 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = rows.Close()
+	}
+
+	// the statement of another data source stays on its database,
+	// the one of the default environment is routed to the replica.
+	if len(analytics.executions) != 1 || analytics.executions[0].query != "SELECT * FROM report WHERE day = $1" {
+		t.Errorf("unexpected analytics executions: %+v", analytics.executions)
+	}
+	if len(replica.executions) != 1 || replica.executions[0].query != "SELECT id FROM user" {
+		t.Errorf("unexpected replica executions: %+v", replica.executions)
+	}
+	if len(primary.executions) != 0 {
+		t.Errorf("unexpected primary executions: %+v", primary.executions)
+	}
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"context"
+)
+
+// ResultHook is invoked after the rows are mapped into the result and before the result is returned.
+// It is used to post-process the results centrally, like decrypting a field or resolving a derived property.
+//
+// The result is passed by pointer, so the hook can mutate it in place or replace it entirely.
+// Returning an error from the hook fails the query with that error.
+//
+// When the statement result is cached, the hook runs before the result is put to the cache,
+// so the cached result is already processed and the hook is not invoked again for cache hits.
+type ResultHook[T any] func(ctx context.Context, stmt Statement, result *T) error
+
+// ResultHookGroup is a group of ResultHook.
+type ResultHookGroup[T any] []ResultHook[T]
+
+// Invoke invokes the hooks in order.
+// It stops at the first hook which returns an error.
+func (g ResultHookGroup[T]) Invoke(ctx context.Context, stmt Statement, result *T) error {
+	for _, hook := range g {
+		if hook == nil {
+			continue
+		}
+		if err := hook(ctx, stmt, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package juice
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type hookUser struct {
+	ID   int64  `column:"id"`
+	Name string `column:"name"`
+}
+
+func TestGenericExecutor_ResultHook(t *testing.T) {
+	db, _ := newFakeDB(t, fakeResultSet{
+		columns: []string{"id", "name"},
+		rows:    [][]driver.Value{{int64(1), "a"}, {int64(2), "b"}},
+	})
+	var hookStatement Statement
+	executor := &GenericExecutor[[]hookUser]{
+		SQLRowsExecutor: newFakeExecutor(db, newFakeStatement("SELECT id, name FROM user")),
+		hooks: ResultHookGroup[[]hookUser]{
+			func(_ context.Context, stmt Statement, users *[]hookUser) error {
+				hookStatement = stmt
+				for i := range *users {
+					(*users)[i].Name = strings.ToUpper((*users)[i].Name)
+				}
+				return nil
+			},
+			func(_ context.Context, _ Statement, users *[]hookUser) error {
+				*users = append(*users, hookUser{ID: 3, Name: "C"})
+				return nil
+			},
+		},
+	}
+	users, err := executor.QueryContext(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 3 || users[0].Name != "A" || users[1].Name != "B" || users[2].ID != 3 {
+		t.Errorf("unexpected users: %v", users)
+	}
+	if hookStatement == nil || hookStatement.Name() != "main.UserMapper.QueryUser" {
+		t.Errorf("unexpected statement: %v", hookStatement)
+	}
+}
+
+func TestGenericExecutor_ResultHookError(t *testing.T) {
+	db, _ := newFakeDB(t, fakeResultSet{
+		columns: []string{"id", "name"},
+		rows:    [][]driver.Value{{int64(1), "a"}},
+	})
+	errHook := errors.New("decrypt failed")
+	var called bool
+	executor := &GenericExecutor[hookUser]{
+		SQLRowsExecutor: newFakeExecutor(db, newFakeStatement("SELECT id, name FROM user")),
+		hooks: ResultHookGroup[hookUser]{
+			func(context.Context, Statement, *hookUser) error { return errHook },
+			func(context.Context, Statement, *hookUser) error {
+				called = true
+				return nil
+			},
+		},
+	}
+	if _, err := executor.QueryContext(context.Background(), nil); !errors.Is(err, errHook) {
+		t.Errorf("expected hook error, got %v", err)
+	}
+	if called {
+		t.Error("hook should not be called after an error")
+	}
+}
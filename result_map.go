@@ -22,11 +22,17 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
+	"strings"
+	"sync"
 )
 
 // ErrTooManyRows is returned when the result set has too many rows but excepted only one row.
 var ErrTooManyRows = errors.New("juice: too many rows in result set")
 
+// ErrTooManyColumns is returned when the result set has too many columns but excepted only one column.
+var ErrTooManyColumns = errors.New("juice: too many columns in result set")
+
 // ResultMap is an interface that defines a method for mapping database query results to Go data structures.
 type ResultMap interface {
 	// MapTo maps the data from the SQL row to the provided reflect.Value.
@@ -34,12 +40,18 @@ type ResultMap interface {
 }
 
 // SingleRowResultMap is a ResultMap that maps a rowDestination to a non-slice type.
-type SingleRowResultMap struct{}
+type SingleRowResultMap struct {
+	// mapping is the mapping of the untagged fields, see Engine.SetColumnNameMapper.
+	mapping *columnNameMapping
+
+	// times is the time policy of the scanned times, see timePolicy.
+	times *timePolicy
+}
 
 // MapTo implements ResultMapper interface.
 // It maps the data from the SQL row to the provided reflect.Value.
 // If more than one row is returned from the query, it returns an ErrTooManyRows error.
-func (SingleRowResultMap) MapTo(rv reflect.Value, rows *sql.Rows) error {
+func (m SingleRowResultMap) MapTo(rv reflect.Value, rows *sql.Rows) error {
 	// Validate input is a pointer
 	if rv.Kind() != reflect.Ptr {
 		return ErrPointerRequired
@@ -63,7 +75,7 @@ func (SingleRowResultMap) MapTo(rv reflect.Value, rows *sql.Rows) error {
 	targetValue := reflect.Indirect(rv)
 
 	// Create destination mapper
-	columnDest := &rowDestination{}
+	columnDest := &rowDestination{mapping: m.mapping, times: m.times}
 
 	// Map columns to struct fields and create scan destinations
 	dest, err := columnDest.Destination(targetValue, columns)
@@ -92,6 +104,20 @@ func (SingleRowResultMap) MapTo(rv reflect.Value, rows *sql.Rows) error {
 // MultiRowsResultMap is a ResultMap that maps a rowDestination to a slice type.
 type MultiRowsResultMap struct {
 	New func() reflect.Value
+
+	// Partial enables the partial results mode, the rows which fail to scan are skipped
+	// and their errors are returned as a PartialResultError with the other rows.
+	Partial bool
+
+	// MaxRows fails the mapping with ErrMaxRowsExceeded once the rows are more than it.
+	// Zero means no limit.
+	MaxRows int
+
+	// mapping is the mapping of the untagged fields, see Engine.SetColumnNameMapper.
+	mapping *columnNameMapping
+
+	// times is the time policy of the scanned times, see timePolicy.
+	times *timePolicy
 }
 
 // MapTo implements ResultMapper interface.
@@ -117,14 +143,14 @@ func (m MultiRowsResultMap) MapTo(rv reflect.Value, rows *sql.Rows) error {
 
 	// map the rows to values
 	values, err := m.mapRows(rows, isPointer, isElementImplementsScanner)
-	if err != nil {
+	if err != nil && !IsPartialResult(err) {
 		return err
 	}
 	target := rv.Elem()
 	// create slice with proper capacity and set the values
 	result := reflect.MakeSlice(target.Type(), 0, len(values))
 	target.Set(reflect.Append(result, values...))
-	return nil
+	return err
 }
 
 // validateInput validates that the input reflect.Value is a pointer to a slice
@@ -160,13 +186,22 @@ func (m MultiRowsResultMap) mapRows(rows *sql.Rows, isPointer bool, useScanner b
 func (m MultiRowsResultMap) mapWithRowScanner(rows *sql.Rows, isPointer bool) ([]reflect.Value, error) {
 	// Pre-allocate slice with an initial capacity
 	values := make([]reflect.Value, 0, 8)
+	partial := &PartialResultError{}
 
-	for rows.Next() {
+	for row := 0; rows.Next(); row++ {
+		if m.MaxRows > 0 && row >= m.MaxRows {
+			return nil, maxRowsError(m.MaxRows)
+		}
 		// Create a new instance. Since RowScanner is implemented with pointer receiver,
 		// we always create a pointer type and use it directly for scanning
 		newValue := m.New()
 		if err := newValue.Interface().(RowScanner).ScanRows(rows); err != nil {
-			return nil, fmt.Errorf("failed to scan row using RowScanner: %w", err)
+			err = fmt.Errorf("failed to scan row using RowScanner: %w", err)
+			if !m.Partial {
+				return nil, err
+			}
+			partial.Errors = append(partial.Errors, &RowScanError{Row: row, Err: err})
+			continue
 		}
 
 		if isPointer {
@@ -180,7 +215,7 @@ func (m MultiRowsResultMap) mapWithRowScanner(rows *sql.Rows, isPointer bool) ([
 		return nil, fmt.Errorf("error occurred while iterating rows: %w", err)
 	}
 
-	return values, nil
+	return values, partial.errorOrNil()
 }
 
 // mapWithColumnDestination maps rows using column destination
@@ -189,11 +224,15 @@ func (m MultiRowsResultMap) mapWithColumnDestination(rows *sql.Rows, isPointer b
 	if err != nil {
 		return nil, fmt.Errorf("failed to get columns: %w", err)
 	}
-	columnDest := &rowDestination{}
+	columnDest := &rowDestination{mapping: m.mapping, times: m.times}
 	// Pre-allocate slice with an initial capacity
 	values := make([]reflect.Value, 0, 8)
+	partial := &PartialResultError{}
 
-	for rows.Next() {
+	for row := 0; rows.Next(); row++ {
+		if m.MaxRows > 0 && row >= m.MaxRows {
+			return nil, maxRowsError(m.MaxRows)
+		}
 		// Create a new instance and get its underlying value for column mapping
 		newValue := m.New()
 		elementValue := newValue.Elem()
@@ -206,7 +245,12 @@ func (m MultiRowsResultMap) mapWithColumnDestination(rows *sql.Rows, isPointer b
 
 		// Scan the current row into the destinations
 		if err = rows.Scan(dest...); err != nil {
-			return nil, fmt.Errorf("failed to scan row: %w", err)
+			err = fmt.Errorf("failed to scan row: %w", err)
+			if !m.Partial {
+				return nil, err
+			}
+			partial.Errors = append(partial.Errors, &RowScanError{Row: row, Err: err})
+			continue
 		}
 
 		// Append either the pointer or the value based on the target type
@@ -221,7 +265,121 @@ func (m MultiRowsResultMap) mapWithColumnDestination(rows *sql.Rows, isPointer b
 		return nil, fmt.Errorf("error occurred while iterating rows: %w", err)
 	}
 
-	return values, nil
+	return values, partial.errorOrNil()
+}
+
+// MapResultMap is a ResultMap that maps the rows to maps keyed by column name.
+// It is useful for ad-hoc queries whose shape isn't known at compile time.
+//
+// The reflect.Value must be a pointer to a map[string]any for one row,
+// or a pointer to a slice of map[string]any for multiple rows.
+// The values are scanned with ColumnTypeDestination, so they have the Go types
+// reported by the driver, and NULL values are nil.
+type MapResultMap struct {
+	// MaxRows fails the mapping of multiple rows with ErrMaxRowsExceeded once the rows
+	// are more than it. Zero means no limit.
+	MaxRows int
+}
+
+// MapTo implements ResultMapper interface.
+func (m MapResultMap) MapTo(rv reflect.Value, rows *sql.Rows) error {
+	if rv.Kind() != reflect.Ptr {
+		return ErrPointerRequired
+	}
+	target := rv.Elem()
+	if isStringAnyMap(target.Type()) {
+		return m.mapOne(target, rows)
+	}
+	if target.Kind() == reflect.Slice && isStringAnyMap(target.Type().Elem()) {
+		return m.mapMany(target, rows)
+	}
+	return fmt.Errorf("expected pointer to map[string]any or []map[string]any, got %v", rv.Type())
+}
+
+// mapOne maps exactly one row into the target map.
+func (m MapResultMap) mapOne(target reflect.Value, rows *sql.Rows) error {
+	columns, destination, err := m.destination(rows)
+	if err != nil {
+		return err
+	}
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return fmt.Errorf("error occurred while fetching row: %w", err)
+		}
+		return sql.ErrNoRows
+	}
+	value, err := m.mapRow(target.Type(), columns, destination, rows)
+	if err != nil {
+		return err
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error occurred during row scanning: %w", err)
+	}
+	if rows.Next() {
+		return ErrTooManyRows
+	}
+	target.Set(value)
+	return nil
+}
+
+// mapMany maps all rows into the target slice of maps.
+func (m MapResultMap) mapMany(target reflect.Value, rows *sql.Rows) error {
+	columns, destination, err := m.destination(rows)
+	if err != nil {
+		return err
+	}
+	result := reflect.MakeSlice(target.Type(), 0, 8)
+	for rows.Next() {
+		if m.MaxRows > 0 && result.Len() >= m.MaxRows {
+			return maxRowsError(m.MaxRows)
+		}
+		value, err := m.mapRow(target.Type().Elem(), columns, destination, rows)
+		if err != nil {
+			return err
+		}
+		result = reflect.Append(result, value)
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error occurred while iterating rows: %w", err)
+	}
+	target.Set(result)
+	return nil
+}
+
+// destination returns the columns and the ColumnTypeDestination of the rows.
+func (m MapResultMap) destination(rows *sql.Rows) ([]string, *ColumnTypeDestination, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get columns: %w", err)
+	}
+	destination, err := ColumnTypeDestinationFromRows(rows)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get column types: %w", err)
+	}
+	return columns, destination, nil
+}
+
+// mapRow scans the current row into a new map of the given type.
+func (m MapResultMap) mapRow(mapType reflect.Type, columns []string, destination *ColumnTypeDestination, rows *sql.Rows) (reflect.Value, error) {
+	dest := destination.Destination()
+	if err := rows.Scan(dest...); err != nil {
+		return reflect.Value{}, fmt.Errorf("failed to scan row: %w", err)
+	}
+	values, err := destination.Values(dest)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("failed to get row values: %w", err)
+	}
+	row := reflect.MakeMapWithSize(mapType, len(columns))
+	for i, column := range columns {
+		// use the element of &values[i] to keep the nil values as typed any.
+		row.SetMapIndex(reflect.ValueOf(column), reflect.ValueOf(&values[i]).Elem())
+	}
+	return row, nil
+}
+
+// isStringAnyMap reports whether the type is a map[string]any, including the named ones like H.
+func isStringAnyMap(t reflect.Type) bool {
+	return t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && t.Elem() == anyType
 }
 
 // ColumnDestination is a column destination which can be used to scan a row.
@@ -241,6 +399,17 @@ type rowDestination struct {
 	// - Multiple integers represent nested struct field access
 	indexes [][]int
 
+	// jsonColumns reports whether the columns are scanned into the fields of JSON columns,
+	// which are tagged with the json option like `column:"meta,json"`.
+	jsonColumns []bool
+
+	// typeHandlers are the registered handlers of the field types of the columns, nil if not registered.
+	typeHandlers []typeHandler
+
+	// nullZero reports whether the NULL columns are scanned as the zero values of the fields,
+	// see nullZeroColumnOption. It is nil if none of the columns are.
+	nullZero []bool
+
 	// checked indicates whether the destination has been validated for sql.RawBytes.
 	// This flag helps avoid redundant checks for the same rowDestination instance.
 	checked bool
@@ -249,6 +418,12 @@ type rowDestination struct {
 	// corresponding struct fields. Each rowDestination instance maintains its
 	// own discard variable to ensure thread safety during concurrent scans.
 	discard any
+
+	// mapping is the mapping of the untagged fields, nil if they are skipped.
+	mapping *columnNameMapping
+
+	// times is the time policy of the time fields, nil if they are scanned as they are.
+	times *timePolicy
 }
 
 // Destination returns the destination for the given reflect value and column.
@@ -267,6 +442,10 @@ func (s *rowDestination) Destination(rv reflect.Value, columns []string) ([]any,
 }
 
 func (s *rowDestination) destinationForOneColumn(rv reflect.Value, columns []string) ([]any, error) {
+	// the registered type handler takes precedence
+	if handler := s.lookupTypeHandler(rv.Type()); handler != nil {
+		return []any{typeHandlerColumn{field: rv, handler: handler}}, nil
+	}
 	// if type is time.Time or implements sql.Scanner, we can scan it directly
 	if rv.Type() == timeType || rv.Type().Implements(scannerType) {
 		return []any{rv.Addr().Interface()}, nil
@@ -294,19 +473,118 @@ func (s *rowDestination) destinationForStruct(rv reflect.Value, columns []string
 	}
 	dest := make([]any, len(columns))
 	for i, indexes := range s.indexes {
-		if len(indexes) == 0 {
+		switch {
+		case len(indexes) == 0:
 			dest[i] = &s.discard
-		} else {
+		case s.jsonColumns[i]:
+			dest[i] = jsonColumn{field: rv.FieldByIndex(indexes)}
+		case s.typeHandlers[i] != nil:
+			dest[i] = typeHandlerColumn{field: rv.FieldByIndex(indexes), handler: s.typeHandlers[i]}
+		case s.nullZero != nil && s.nullZero[i]:
+			dest[i] = nullZeroColumn{field: rv.FieldByIndex(indexes)}
+		default:
 			dest[i] = rv.FieldByIndex(indexes).Addr().Interface()
 		}
 	}
 	return dest, nil
 }
 
+// lookupTypeHandler returns the handler of the type, the time policy takes precedence over the
+// registered handlers for time.Time.
+func (s *rowDestination) lookupTypeHandler(tp reflect.Type) typeHandler {
+	if tp == timeType {
+		if handler := s.times.scanHandler(); handler != nil {
+			return handler
+		}
+	}
+	return lookupTypeHandler(tp)
+}
+
 // setIndexes sets the indexes for the given reflect value and columns.
+// The indexes are resolved once per struct type and columns, see structColumnsCache.
 func (s *rowDestination) setIndexes(rv reflect.Value, columns []string) {
-	tp := rv.Type()
-	s.indexes = make([][]int, len(columns))
+	cached := cachedStructColumns(rv.Type(), columns, s.mapping)
+	// the cached slices are shared, they are never modified.
+	s.indexes = cached.indexes
+	s.jsonColumns = cached.jsonColumns
+	// the type handlers are looked up every time, since they can be registered at any time.
+	s.typeHandlers = make([]typeHandler, len(columns))
+	nullZero := nullZeroEnabled.Load()
+	for i, fieldType := range cached.fieldTypes {
+		if fieldType == nil {
+			continue
+		}
+		s.typeHandlers[i] = s.lookupTypeHandler(fieldType)
+		if cached.nullZeroable[i] && (nullZero || cached.nullZeroTagged[i]) {
+			if s.nullZero == nil {
+				s.nullZero = make([]bool, len(columns))
+			}
+			s.nullZero[i] = true
+		}
+	}
+}
+
+// structColumnsCache caches the *structColumns by structColumnsKey, so that the struct type is
+// walked only once for the queries which return the same columns, instead of once per query.
+// It cuts the cost of creating the destination of a struct with ten columns from about 4µs
+// and 20 allocations to about 1.5µs and 5 allocations, see BenchmarkRowDestination.
+//
+// The columns are keyed in their order rather than as a set, since the indexes are positional.
+// The cache is never evicted, the column lists of a program are bounded by its statements.
+var structColumnsCache sync.Map
+
+// structColumnsKey is the key of the structColumnsCache.
+type structColumnsKey struct {
+	tp reflect.Type
+	// columns is the columns joined by NUL, which can not be a part of a column name.
+	columns string
+	// mapping is the mapping of the untagged fields, see Engine.SetColumnNameMapper.
+	mapping *columnNameMapping
+}
+
+// structColumns is the resolved fields of the columns of a struct type.
+type structColumns struct {
+	// indexes is the field indexes of the columns, see rowDestination.indexes.
+	indexes [][]int
+
+	// jsonColumns reports whether the fields are JSON columns.
+	jsonColumns []bool
+
+	// fieldTypes is the types of the fields, nil for the columns without a field.
+	fieldTypes []reflect.Type
+
+	// nullZeroable reports whether the nullzero option applies to the types of the fields.
+	nullZeroable []bool
+
+	// nullZeroTagged reports whether the fields are tagged with the nullzero option.
+	nullZeroTagged []bool
+
+	// mapping is the mapping of the untagged fields, nil if they are skipped.
+	mapping *columnNameMapping
+}
+
+// cachedStructColumns returns the structColumns of the type and columns from the cache,
+// it resolves and caches them on the first use.
+func cachedStructColumns(tp reflect.Type, columns []string, mapping *columnNameMapping) *structColumns {
+	key := structColumnsKey{tp: tp, columns: strings.Join(columns, "\x00"), mapping: mapping}
+	if cached, ok := structColumnsCache.Load(key); ok {
+		return cached.(*structColumns)
+	}
+	cached, _ := structColumnsCache.LoadOrStore(key, newStructColumns(tp, columns, mapping))
+	return cached.(*structColumns)
+}
+
+// newStructColumns resolves the fields of the columns of the struct type.
+// The untagged fields are resolved by the mapping if it is not nil.
+func newStructColumns(tp reflect.Type, columns []string, mapping *columnNameMapping) *structColumns {
+	s := &structColumns{
+		mapping:        mapping,
+		indexes:        make([][]int, len(columns)),
+		jsonColumns:    make([]bool, len(columns)),
+		fieldTypes:     make([]reflect.Type, len(columns)),
+		nullZeroable:   make([]bool, len(columns)),
+		nullZeroTagged: make([]bool, len(columns)),
+	}
 
 	// columnIndex is a map to store the index of the column.
 	columnIndex := func() map[string]int {
@@ -317,11 +595,13 @@ func (s *rowDestination) setIndexes(rv reflect.Value, columns []string) {
 		return m
 	}()
 
-	s.findFromStruct(tp, columns, columnIndex, nil)
+	s.findFromStruct(tp, columns, columnIndex, nil, "")
+	return s
 }
 
 // findFromStruct finds the index from the given struct type.
-func (s *rowDestination) findFromStruct(tp reflect.Type, columns []string, columnIndex map[string]int, walk []int) {
+// prefix is the column prefix of the enclosing prefixed fields, see prefixColumnOption.
+func (s *structColumns) findFromStruct(tp reflect.Type, columns []string, columnIndex map[string]int, walk []int, prefix string) {
 
 	// finished is a helper function to check if the indexes completed or not.
 	finished := func() bool {
@@ -340,23 +620,38 @@ func (s *rowDestination) findFromStruct(tp reflect.Type, columns []string, colum
 			break
 		}
 		field := tp.Field(i)
-		tag := field.Tag.Get("column")
+		rawTag := field.Tag.Get("column")
+		tag, isJSON := parseColumnTag(rawTag)
+		// the prefixed struct field is mapped from the columns with its prefix.
+		if columnTagOption(rawTag, prefixColumnOption) && field.Type.Kind() == reflect.Struct {
+			s.findFromStruct(field.Type, columns, columnIndex, append(slices.Clip(walk), i), prefix+columnPrefix(field.Name, tag))
+			continue
+		}
+		// the untagged exported field is mapped by its name.
+		if tag == "" && !field.Anonymous && field.IsExported() && s.mapping != nil {
+			tag = s.mapping.mapper.ColumnName(field.Name)
+		}
 		// if the tag is empty or "-", we can skip it.
 		if skip := tag == "" && !field.Anonymous || tag == "-"; skip {
 			continue
 		}
 		// if the field is anonymous and the type is struct, we can walk into it.
 		if deepScan := field.Anonymous && field.Type.Kind() == reflect.Struct && len(tag) == 0; deepScan {
-			s.findFromStruct(field.Type, columns, columnIndex, append(walk, i))
+			s.findFromStruct(field.Type, columns, columnIndex, append(slices.Clip(walk), i), prefix)
 			continue
 		}
 		// find the index of the column
-		index, ok := columnIndex[tag]
+		index, ok := columnIndex[prefix+tag]
 		if !ok {
 			continue
 		}
 		// set the index
-		s.indexes[index] = append(walk, field.Index...)
+		// clip the walk, so that the indexes of the sibling fields don't share the array.
+		s.indexes[index] = append(slices.Clip(walk), field.Index...)
+		s.jsonColumns[index] = isJSON
+		s.fieldTypes[index] = field.Type
+		s.nullZeroable[index] = nullZeroScannable(field.Type)
+		s.nullZeroTagged[index] = columnTagOption(rawTag, nullZeroColumnOption)
 	}
 }
 
@@ -0,0 +1,198 @@
+package juice
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestMapResultMap_Many(t *testing.T) {
+	db, _ := newFakeDB(t, fakeResultSet{
+		columns:   []string{"id", "name"},
+		scanTypes: []reflect.Type{reflect.TypeOf(int64(0)), reflect.TypeOf(sql.RawBytes{})},
+		rows: [][]driver.Value{
+			{int64(1), []byte("a")},
+			{int64(2), nil},
+		},
+	})
+	executor := &GenericExecutor[[]map[string]any]{
+		SQLRowsExecutor: newFakeExecutor(db, newFakeStatement("SELECT id, name FROM user")),
+	}
+	result, err := executor.QueryContext(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []map[string]any{
+		{"id": int64(1), "name": "a"},
+		{"id": int64(2), "name": nil},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected result: %v", result)
+	}
+	if value, ok := result[1]["name"]; !ok || value != nil {
+		t.Errorf("NULL should be a nil map value: %v", value)
+	}
+}
+
+func TestMapResultMap_One(t *testing.T) {
+	db, _ := newFakeDB(t, fakeResultSet{
+		columns: []string{"id", "name"},
+		rows:    [][]driver.Value{{int64(1), "a"}},
+	})
+	executor := &GenericExecutor[H]{
+		SQLRowsExecutor: newFakeExecutor(db, newFakeStatement("SELECT id, name FROM user")),
+	}
+	result, err := executor.QueryContext(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result, H{"id": int64(1), "name": "a"}) {
+		t.Errorf("unexpected result: %v", result)
+	}
+
+	db, _ = newFakeDB(t, fakeResultSet{columns: []string{"id"}})
+	rows, err := db.Query("SELECT id FROM user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = rows.Close() }()
+	if _, err = BindWithResultMap[map[string]any](rows, nil); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows, got %v", err)
+	}
+}
+
+type prefixAddress struct {
+	Street string `column:"street"`
+	City   string `column:"city"`
+}
+
+type prefixUser struct {
+	ID          int64         `column:"id"`
+	Name        string        `column:"name"`
+	Address     prefixAddress `column:"addr,prefix"`
+	HomeAddress prefixAddress `column:",prefix"`
+	Company     struct {
+		Name    string        `column:"name"`
+		Address prefixAddress `column:"addr,prefix"`
+	} `column:"company,prefix"`
+}
+
+func TestRowDestination_PrefixedColumns(t *testing.T) {
+	db, _ := newFakeDB(t, fakeResultSet{
+		columns: []string{"id", "name", "addr_street", "addr_city", "home_address_city", "company_name", "company_addr_city"},
+		rows: [][]driver.Value{
+			{int64(1), "alice", "Main St", "Berlin", "Hamburg", "acme", "Munich"},
+		},
+	})
+	executor := &GenericExecutor[prefixUser]{
+		SQLRowsExecutor: newFakeExecutor(db, newFakeStatement("SELECT * FROM user")),
+	}
+	user, err := executor.QueryContext(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != 1 || user.Name != "alice" {
+		t.Errorf("unexpected user: %+v", user)
+	}
+	if user.Address != (prefixAddress{Street: "Main St", City: "Berlin"}) {
+		t.Errorf("unexpected address: %+v", user.Address)
+	}
+	if user.HomeAddress.City != "Hamburg" {
+		t.Errorf("unexpected home address: %+v", user.HomeAddress)
+	}
+	if user.Company.Name != "acme" || user.Company.Address.City != "Munich" {
+		t.Errorf("unexpected company: %+v", user.Company)
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"HomeAddress": "home_address",
+		"UserID":      "user_id",
+		"ID":          "id",
+		"Address2":    "address2",
+		"HTTPServer":  "http_server",
+	}
+	for name, expected := range tests {
+		if got := snakeCase(name); got != expected {
+			t.Errorf("%s: expected %s, got %s", name, expected, got)
+		}
+	}
+}
+
+func BenchmarkRowDestination(b *testing.B) {
+	type Audit struct {
+		CreatedAt string `column:"created_at"`
+		UpdatedAt string `column:"updated_at"`
+	}
+	type User struct {
+		Audit
+		ID       int64  `column:"id"`
+		Name     string `column:"name"`
+		Email    string `column:"email"`
+		Age      int    `column:"age"`
+		Status   int    `column:"status"`
+		Nickname string `column:"nickname"`
+		Avatar   string `column:"avatar"`
+		Bio      string `column:"bio"`
+	}
+	columns := []string{"id", "name", "email", "age", "status", "nickname", "avatar", "bio", "created_at", "updated_at"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		// a new destination is created for every query.
+		var user User
+		dest := &rowDestination{}
+		if _, err := dest.Destination(reflect.ValueOf(&user).Elem(), columns); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestRowDestination_CachedColumns(t *testing.T) {
+	type User struct {
+		ID   int64  `column:"id"`
+		Name string `column:"name"`
+		Age  int    `column:"age"`
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// the same type is queried with different column sets.
+			for _, columns := range [][]string{{"id", "name"}, {"name", "id", "age"}, {"age", "unknown"}} {
+				var user User
+				rv := reflect.ValueOf(&user).Elem()
+				dest, err := (&rowDestination{}).Destination(rv, columns)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				for j, column := range columns {
+					var want any
+					switch column {
+					case "id":
+						want = &user.ID
+					case "name":
+						want = &user.Name
+					case "age":
+						want = &user.Age
+					default:
+						if _, ok := dest[j].(*any); !ok {
+							t.Errorf("expected column %s to be discarded, got %T", column, dest[j])
+						}
+						continue
+					}
+					if dest[j] != want {
+						t.Errorf("unexpected destination of column %s in %v", column, columns)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// resultTypeAttribute is the attribute of the select statements which names the registered
+// type the rows are mapped to, see RegisterResultType:
+//
+//	<select id="ListUsers" resultType="User">
+//	    select id, name from user
+//	</select>
+//
+// The statement maps its rows with a ResultTypeMap of the type, which checks that every
+// selected column maps to a field before scanning.
+const resultTypeAttribute = "resultType"
+
+var (
+	// ErrResultTypeNotRegistered is an error that is returned when the result type of a statement is not registered.
+	ErrResultTypeNotRegistered = errors.New("juice: result type not registered")
+
+	// ErrUnmappedColumn is an error that is returned when a selected column doesn't map to the result type.
+	ErrUnmappedColumn = errors.New("juice: column not mapped to result type")
+)
+
+// resultTypeLibraries is a map of the result types by name.
+var resultTypeLibraries sync.Map
+
+// RegisterResultType registers the type of v with the name, which can be referenced
+// by the resultType attribute of the select statements:
+//
+//	juice.RegisterResultType("User", User{})
+//
+// A pointer is registered as its element type. Registering a name again replaces its type.
+func RegisterResultType(name string, v any) {
+	if len(name) == 0 {
+		panic("name is empty")
+	}
+	tp := reflect.TypeOf(v)
+	if tp == nil {
+		panic("juice: result type is nil")
+	}
+	for tp.Kind() == reflect.Ptr {
+		tp = tp.Elem()
+	}
+	resultTypeLibraries.Store(name, tp)
+}
+
+// GetResultType returns the result type registered with the name.
+func GetResultType(name string) (reflect.Type, bool) {
+	tp, exists := resultTypeLibraries.Load(name)
+	if !exists {
+		return nil, false
+	}
+	return tp.(reflect.Type), true
+}
+
+// ResultTypeMap is a ResultMap which maps the rows to a registered result type.
+//
+// The destination must be a pointer to the type, to a slice of the type or of pointers to it,
+// or to an any, which is set to a slice of the type holding all the rows. The last one makes
+// the statement mapped to its declared type even when the caller doesn't know it, like the
+// executors of any.
+//
+// Before scanning, it checks that every column maps to the type with ValidateResultColumns,
+// except the columns combined into the fields by the Results.
+type ResultTypeMap struct {
+	Type reflect.Type
+
+	// Results maps several columns to one field, see ColumnsResult.
+	Results []ColumnsResult
+}
+
+// MapTo implements ResultMap.
+func (m ResultTypeMap) MapTo(rv reflect.Value, rows *sql.Rows) error {
+	if rv.Kind() != reflect.Ptr {
+		return ErrPointerRequired
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("failed to get columns: %w", err)
+	}
+	if len(m.Results) > 0 {
+		return m.mapColumnsResults(rv, rows, columns)
+	}
+	if err = ValidateResultColumns(m.Type, columns); err != nil {
+		return err
+	}
+	target := rv.Elem()
+	switch target.Type() {
+	case anyType:
+		list := reflect.New(reflect.SliceOf(m.Type))
+		if err = (MultiRowsResultMap{}).MapTo(list, rows); err != nil {
+			return err
+		}
+		target.Set(list.Elem())
+		return nil
+	case m.Type, reflect.PointerTo(m.Type):
+		return SingleRowResultMap{}.MapTo(rv, rows)
+	case reflect.SliceOf(m.Type), reflect.SliceOf(reflect.PointerTo(m.Type)):
+		return MultiRowsResultMap{}.MapTo(rv, rows)
+	default:
+		return m.mismatch(target)
+	}
+}
+
+// mapColumnsResults maps the rows with the Results to the destination.
+func (m ResultTypeMap) mapColumnsResults(rv reflect.Value, rows *sql.Rows, columns []string) error {
+	row, err := newColumnsResultRow(m.Type, columns, m.Results)
+	if err != nil {
+		return err
+	}
+	if remaining := row.remainingColumns(); len(remaining) > 0 {
+		if err = ValidateResultColumns(m.Type, remaining); err != nil {
+			return err
+		}
+	}
+	target := rv.Elem()
+	switch target.Type() {
+	case m.Type, reflect.PointerTo(m.Type):
+		if !rows.Next() {
+			if err = rows.Err(); err != nil {
+				return fmt.Errorf("error occurred while fetching row: %w", err)
+			}
+			return sql.ErrNoRows
+		}
+		value, err := row.mapRow(rows)
+		if err != nil {
+			return err
+		}
+		if rows.Next() {
+			return ErrTooManyRows
+		}
+		if target.Kind() == reflect.Ptr {
+			target.Set(value)
+		} else {
+			target.Set(value.Elem())
+		}
+		return rows.Err()
+	case anyType, reflect.SliceOf(m.Type), reflect.SliceOf(reflect.PointerTo(m.Type)):
+		values, err := row.mapRows(rows)
+		if err != nil {
+			return err
+		}
+		sliceType := target.Type()
+		if sliceType == anyType {
+			sliceType = reflect.SliceOf(m.Type)
+		}
+		list := reflect.MakeSlice(sliceType, 0, len(values))
+		for _, value := range values {
+			if sliceType.Elem().Kind() != reflect.Ptr {
+				value = value.Elem()
+			}
+			list = reflect.Append(list, value)
+		}
+		target.Set(list)
+		return nil
+	default:
+		return m.mismatch(target)
+	}
+}
+
+// mismatch returns the error of a destination which doesn't match the result type.
+func (m ResultTypeMap) mismatch(target reflect.Value) error {
+	return fmt.Errorf("destination %s does not match result type %s", target.Type(), m.Type)
+}
+
+// ValidateResultColumns checks that every column maps to the type, following the mapping rules
+// of the default result maps. A single column maps to a type with a type handler, to a time.Time,
+// a sql.Scanner or a non-struct type as a whole. Otherwise, the type must be a struct and each
+// column must match a column tag of its fields, or ErrUnmappedColumn is returned.
+func ValidateResultColumns(tp reflect.Type, columns []string) error {
+	for tp.Kind() == reflect.Ptr {
+		tp = tp.Elem()
+	}
+	if len(columns) == 1 {
+		if lookupTypeHandler(tp) != nil || tp == timeType || reflect.PointerTo(tp).Implements(scannerType) || tp.Kind() != reflect.Struct {
+			return nil
+		}
+	}
+	if tp.Kind() != reflect.Struct {
+		return fmt.Errorf("expected struct, but got %s", tp.Kind())
+	}
+	structColumns := cachedStructColumns(tp, columns, nil)
+	for i, indexes := range structColumns.indexes {
+		if len(indexes) == 0 {
+			return fmt.Errorf("%w: %s of %s", ErrUnmappedColumn, columns[i], tp)
+		}
+	}
+	return nil
+}
+
+// statementResultMap returns the ResultMap of the result type declared by the statement.
+// It returns ErrResultMapNotSet if the statement doesn't declare one.
+func statementResultMap(statement Statement) (ResultMap, error) {
+	name := statement.Attribute(resultTypeAttribute)
+	if name == "" {
+		return nil, ErrResultMapNotSet
+	}
+	tp, exists := GetResultType(name)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s of %s", ErrResultTypeNotRegistered, name, statement.Name())
+	}
+	resultMap := ResultTypeMap{Type: tp}
+	if stmt, ok := statement.(*xmlSQLStatement); ok {
+		for _, result := range stmt.results {
+			resolved, err := result.resolve()
+			if err != nil {
+				return nil, fmt.Errorf("%w of %s", err, statement.Name())
+			}
+			resultMap.Results = append(resultMap.Results, resolved)
+		}
+	}
+	return resultMap, nil
+}
@@ -0,0 +1,97 @@
+package juice
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type resultTypeUser struct {
+	ID   int64  `column:"id"`
+	Name string `column:"name"`
+}
+
+func TestRegisterResultType(t *testing.T) {
+	RegisterResultType("test.resultTypeUser", &resultTypeUser{})
+	tp, ok := GetResultType("test.resultTypeUser")
+	if !ok {
+		t.Fatal("expected result type to be registered")
+	}
+	if tp != reflect.TypeOf(resultTypeUser{}) {
+		t.Errorf("unexpected result type: %s", tp)
+	}
+	if _, ok = GetResultType("test.unknown"); ok {
+		t.Error("expected unknown result type")
+	}
+}
+
+func TestValidateResultColumns(t *testing.T) {
+	userType := reflect.TypeOf(resultTypeUser{})
+	if err := ValidateResultColumns(userType, []string{"id", "name"}); err != nil {
+		t.Error(err)
+	}
+	if err := ValidateResultColumns(reflect.PointerTo(userType), []string{"name"}); err != nil {
+		t.Error(err)
+	}
+	if err := ValidateResultColumns(userType, []string{"id", "age"}); !errors.Is(err, ErrUnmappedColumn) {
+		t.Errorf("expected ErrUnmappedColumn, got %v", err)
+	}
+	if err := ValidateResultColumns(reflect.TypeOf(int64(0)), []string{"count"}); err != nil {
+		t.Error(err)
+	}
+	if err := ValidateResultColumns(reflect.TypeOf(int64(0)), []string{"id", "name"}); err == nil {
+		t.Error("expected error for multiple columns of a scalar type")
+	}
+}
+
+func TestStatement_ResultType(t *testing.T) {
+	RegisterResultType("test.resultTypeUser", resultTypeUser{})
+	resultSet := fakeResultSet{
+		columns: []string{"id", "name"},
+		rows:    [][]driver.Value{{int64(1), "a"}, {int64(2), "b"}},
+	}
+	statement := parseTestStatement(t, Select, `<select id="ListUsers" resultType="test.resultTypeUser">
+		SELECT id, name FROM user
+	</select>`)
+
+	db, _ := newFakeDB(t, resultSet)
+	executor := &GenericExecutor[any]{SQLRowsExecutor: newFakeExecutor(db, statement)}
+	result, err := executor.QueryContext(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users, ok := result.([]resultTypeUser)
+	if !ok {
+		t.Fatalf("unexpected result type: %T", result)
+	}
+	if len(users) != 2 || users[1] != (resultTypeUser{ID: 2, Name: "b"}) {
+		t.Errorf("unexpected users: %v", users)
+	}
+
+	one, err := (&GenericExecutor[*resultTypeUser]{SQLRowsExecutor: newFakeExecutor(db, statement)}).QueryContext(context.Background(), nil)
+	if !errors.Is(err, ErrTooManyRows) {
+		t.Errorf("expected ErrTooManyRows, got %v, %v", one, err)
+	}
+
+	_, err = (&GenericExecutor[[]string]{SQLRowsExecutor: newFakeExecutor(db, statement)}).QueryContext(context.Background(), nil)
+	if err == nil {
+		t.Error("expected error for mismatched destination")
+	}
+
+	unmapped, _ := newFakeDB(t, fakeResultSet{columns: []string{"id", "age"}})
+	_, err = (&GenericExecutor[any]{SQLRowsExecutor: newFakeExecutor(unmapped, statement)}).QueryContext(context.Background(), nil)
+	if !errors.Is(err, ErrUnmappedColumn) {
+		t.Errorf("expected ErrUnmappedColumn, got %v", err)
+	}
+
+	unknown := parseTestStatement(t, Select, `<select id="ListUsers" resultType="test.unknown">SELECT 1</select>`)
+	if _, err = unknown.ResultMap(); !errors.Is(err, ErrResultTypeNotRegistered) {
+		t.Errorf("expected ErrResultTypeNotRegistered, got %v", err)
+	}
+	plain := parseTestStatement(t, Select, `<select id="ListUsers">SELECT 1</select>`)
+	if _, err = plain.ResultMap(); !errors.Is(err, ErrResultMapNotSet) {
+		t.Errorf("expected ErrResultMapNotSet, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+// SQLRewriter rewrites the final SQL and its arguments right before execution.
+// It is invoked after the statement has been built from its nodes and before
+// any Middleware runs, so middlewares always see the rewritten SQL.
+//
+// Typical use cases are injecting a tenant predicate or rewriting table names
+// for sharding:
+//
+//	type tenantRewriter struct{}
+//
+//	func (tenantRewriter) Rewrite(stmt Statement, query string, args []any) (string, []any, error) {
+//	    if stmt.Action() != Select {
+//	        return query, args, nil
+//	    }
+//	    return strings.ReplaceAll(query, "users", "users_tenant_1"), args, nil
+//	}
+type SQLRewriter interface {
+	// Rewrite returns the rewritten query and args.
+	// Returning an error aborts the execution of the statement.
+	Rewrite(stmt Statement, query string, args []any) (string, []any, error)
+}
+
+// SQLRewriterFunc is an adapter to allow the use of ordinary functions as SQLRewriter.
+type SQLRewriterFunc func(stmt Statement, query string, args []any) (string, []any, error)
+
+// Rewrite implements SQLRewriter.
+func (f SQLRewriterFunc) Rewrite(stmt Statement, query string, args []any) (string, []any, error) {
+	return f(stmt, query, args)
+}
+
+// ensure SQLRewriterGroup implements SQLRewriter.
+var _ SQLRewriter = SQLRewriterGroup(nil) // compile time check
+
+// SQLRewriterGroup is a chain of SQLRewriter.
+type SQLRewriterGroup []SQLRewriter
+
+// Rewrite implements SQLRewriter.
+// Each rewriter receives the output of the previous one, in the order they were added.
+func (g SQLRewriterGroup) Rewrite(stmt Statement, query string, args []any) (string, []any, error) {
+	var err error
+	for _, rewriter := range g {
+		query, args, err = rewriter.Rewrite(stmt, query, args)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	return query, args, nil
+}
@@ -0,0 +1,48 @@
+package juice
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSQLRewriterGroup_Rewrite(t *testing.T) {
+	group := SQLRewriterGroup{
+		SQLRewriterFunc(func(_ Statement, query string, args []any) (string, []any, error) {
+			return strings.ReplaceAll(query, "users", "users_1"), args, nil
+		}),
+		SQLRewriterFunc(func(_ Statement, query string, args []any) (string, []any, error) {
+			return query + " AND tenant_id = ?", append(args, 1), nil
+		}),
+	}
+	query, args, err := group.Rewrite(nil, "SELECT * FROM users WHERE id = ?", []any{2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM users_1 WHERE id = ? AND tenant_id = ?" {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if len(args) != 2 || args[0] != 2 || args[1] != 1 {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
+
+func TestSQLRewriterGroup_RewriteError(t *testing.T) {
+	errRewrite := errors.New("rewrite failed")
+	var called bool
+	group := SQLRewriterGroup{
+		SQLRewriterFunc(func(_ Statement, query string, args []any) (string, []any, error) {
+			return "", nil, errRewrite
+		}),
+		SQLRewriterFunc(func(_ Statement, query string, args []any) (string, []any, error) {
+			called = true
+			return query, args, nil
+		}),
+	}
+	if _, _, err := group.Rewrite(nil, "SELECT 1", nil); !errors.Is(err, errRewrite) {
+		t.Errorf("expected rewrite error, got %v", err)
+	}
+	if called {
+		t.Error("rewriter should not be called after an error")
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// bindScalar binds the rows of a single column to T without reflection, when T is
+// a supported scalar or a slice of the supported scalars, which are the integers,
+// the floats, string, bool and time.Time. uint8 is not supported, since []uint8 is []byte.
+//
+// It returns false if T is not supported, the result set has more than one column, or
+// a TypeHandler is registered for the scalar, so that the caller can fall back to the
+// ResultMap. Nothing has been read from the rows in that case.
+//
+// The results are the same as the ones of SingleRowResultMap and MultiRowsResultMap,
+// it only saves the cost of the reflection on hot queries like SELECT COUNT(*).
+func bindScalar[T any](rows *sql.Rows) (result T, ok bool, err error) {
+	switch dest := any(&result).(type) {
+	case *int:
+		*dest, ok, err = scanScalar[int](rows)
+	case *int8:
+		*dest, ok, err = scanScalar[int8](rows)
+	case *int16:
+		*dest, ok, err = scanScalar[int16](rows)
+	case *int32:
+		*dest, ok, err = scanScalar[int32](rows)
+	case *int64:
+		*dest, ok, err = scanScalar[int64](rows)
+	case *uint:
+		*dest, ok, err = scanScalar[uint](rows)
+	case *uint16:
+		*dest, ok, err = scanScalar[uint16](rows)
+	case *uint32:
+		*dest, ok, err = scanScalar[uint32](rows)
+	case *uint64:
+		*dest, ok, err = scanScalar[uint64](rows)
+	case *float32:
+		*dest, ok, err = scanScalar[float32](rows)
+	case *float64:
+		*dest, ok, err = scanScalar[float64](rows)
+	case *string:
+		*dest, ok, err = scanScalar[string](rows)
+	case *bool:
+		*dest, ok, err = scanScalar[bool](rows)
+	case *time.Time:
+		*dest, ok, err = scanScalar[time.Time](rows)
+	case *[]int:
+		*dest, ok, err = scanScalars[int](rows)
+	case *[]int8:
+		*dest, ok, err = scanScalars[int8](rows)
+	case *[]int16:
+		*dest, ok, err = scanScalars[int16](rows)
+	case *[]int32:
+		*dest, ok, err = scanScalars[int32](rows)
+	case *[]int64:
+		*dest, ok, err = scanScalars[int64](rows)
+	case *[]uint:
+		*dest, ok, err = scanScalars[uint](rows)
+	case *[]uint16:
+		*dest, ok, err = scanScalars[uint16](rows)
+	case *[]uint32:
+		*dest, ok, err = scanScalars[uint32](rows)
+	case *[]uint64:
+		*dest, ok, err = scanScalars[uint64](rows)
+	case *[]float32:
+		*dest, ok, err = scanScalars[float32](rows)
+	case *[]float64:
+		*dest, ok, err = scanScalars[float64](rows)
+	case *[]string:
+		*dest, ok, err = scanScalars[string](rows)
+	case *[]bool:
+		*dest, ok, err = scanScalars[bool](rows)
+	case *[]time.Time:
+		*dest, ok, err = scanScalars[time.Time](rows)
+	}
+	return result, ok, err
+}
+
+// singleColumn reports whether the rows can be scanned into a V without a type handler.
+func singleColumn[V any](rows *sql.Rows) (bool, error) {
+	if lookupTypeHandler(reflect.TypeFor[V]()) != nil {
+		return false, nil
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return false, fmt.Errorf("failed to get columns: %w", err)
+	}
+	return len(columns) == 1, nil
+}
+
+// scanScalar scans the only row into a V as SingleRowResultMap does.
+func scanScalar[V any](rows *sql.Rows) (value V, ok bool, err error) {
+	if ok, err = singleColumn[V](rows); !ok || err != nil {
+		return value, ok, err
+	}
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return value, true, fmt.Errorf("error occurred while fetching row: %w", err)
+		}
+		return value, true, sql.ErrNoRows
+	}
+	if err = rows.Scan(&value); err != nil {
+		return value, true, fmt.Errorf("failed to scan row: %w", err)
+	}
+	if err = rows.Err(); err != nil {
+		return value, true, fmt.Errorf("error occurred during row scanning: %w", err)
+	}
+	if rows.Next() {
+		return value, true, ErrTooManyRows
+	}
+	return value, true, nil
+}
+
+// scanScalars scans all the rows into a []V as MultiRowsResultMap does.
+// It returns an empty slice if there are no rows.
+func scanScalars[V any](rows *sql.Rows) (values []V, ok bool, err error) {
+	if ok, err = singleColumn[V](rows); !ok || err != nil {
+		return nil, ok, err
+	}
+	values = make([]V, 0, 8)
+	for rows.Next() {
+		var value V
+		if err = rows.Scan(&value); err != nil {
+			return nil, true, fmt.Errorf("failed to scan row: %w", err)
+		}
+		values = append(values, value)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, true, fmt.Errorf("error occurred while iterating rows: %w", err)
+	}
+	return values, true, nil
+}
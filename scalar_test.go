@@ -0,0 +1,78 @@
+package juice
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBindScalar(t *testing.T) {
+	count, err := Bind[int64](queryFakeRows(t, fakeResultSet{
+		columns: []string{"COUNT(*)"},
+		rows:    [][]driver.Value{{int64(42)}},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 42 {
+		t.Errorf("unexpected count: %d", count)
+	}
+
+	names, err := Bind[[]string](queryFakeRows(t, fakeResultSet{
+		columns: []string{"name"},
+		rows:    [][]driver.Value{{"alice"}, {[]byte("bob")}},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"alice", "bob"}) {
+		t.Errorf("unexpected names: %v", names)
+	}
+
+	createdAt := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	times, err := Bind[[]time.Time](queryFakeRows(t, fakeResultSet{
+		columns: []string{"created_at"},
+		rows:    [][]driver.Value{{createdAt}},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(times) != 1 || !times[0].Equal(createdAt) {
+		t.Errorf("unexpected times: %v", times)
+	}
+}
+
+func TestBindScalar_SameErrorsAsResultMap(t *testing.T) {
+	_, err := Bind[int64](queryFakeRows(t, fakeResultSet{columns: []string{"id"}}))
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows, got %v", err)
+	}
+
+	_, err = Bind[int64](queryFakeRows(t, fakeResultSet{
+		columns: []string{"id"},
+		rows:    [][]driver.Value{{int64(1)}, {int64(2)}},
+	}))
+	if !errors.Is(err, ErrTooManyRows) {
+		t.Errorf("expected ErrTooManyRows, got %v", err)
+	}
+
+	ids, err := Bind[[]int](queryFakeRows(t, fakeResultSet{columns: []string{"id"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids == nil || len(ids) != 0 {
+		t.Errorf("expected an empty slice, got %#v", ids)
+	}
+
+	// more than one column falls back to the result map.
+	_, err = Bind[int64](queryFakeRows(t, fakeResultSet{
+		columns: []string{"id", "name"},
+		rows:    [][]driver.Value{{int64(1), "alice"}},
+	}))
+	if err == nil || err.Error() != "failed to create destination mapping: expected struct, but got int64" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
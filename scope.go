@@ -46,6 +46,19 @@ func WithReadOnly(readOnly bool) TransactionOptionFunc {
 	}
 }
 
+// newTxOptions returns the transaction options set by the given option functions.
+// It returns nil if there is no option function, which means the default options.
+func newTxOptions(opts []TransactionOptionFunc) *sql.TxOptions {
+	if len(opts) == 0 {
+		return nil
+	}
+	options := new(sql.TxOptions)
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
 // Transaction executes a transaction with the given handler.
 // If the manager is not an instance of Engine, it will return ErrInvalidManager.
 // If the handler returns an error, the transaction will be rolled back.
@@ -69,15 +82,8 @@ func Transaction(ctx context.Context, handler func(ctx context.Context) error, o
 		return ErrInvalidManager
 	}
 
-	var options *sql.TxOptions
-	if len(opts) > 0 {
-		options = new(sql.TxOptions)
-		for _, opt := range opts {
-			opt(options)
-		}
-	}
 	// create a new transaction
-	tx := engine.ContextTx(ctx, options)
+	tx := engine.ContextTx(ctx, newTxOptions(opts))
 
 	if err = tx.Begin(); err != nil {
 		return err
@@ -116,3 +122,50 @@ func NestedTransaction(ctx context.Context, handler func(ctx context.Context) er
 	}
 	return Transaction(ctx, handler, opts...)
 }
+
+// WithTx begins a transaction and calls the fn with the TxManager of the transaction.
+// If the fn returns nil, the transaction will be committed.
+// If the fn returns an error or panics, the transaction will be rolled back,
+// and the panic will be re-raised after the rollback.
+//
+// The code which gets the manager by ManagerFromContext, like the generated mapper implementations,
+// runs in the transaction when it is given the context returned by TxContext. For example:
+//
+//	err := engine.WithTx(ctx, func(tx juice.TxManager) error {
+//		ctx := juice.TxContext(tx)
+//		if err := userRepository.CreateUser(ctx, user); err != nil {
+//			return err
+//		}
+//		return orderRepository.CreateOrder(ctx, order)
+//	})
+func (e *Engine) WithTx(ctx context.Context, fn func(tx TxManager) error, opts ...TransactionOptionFunc) (err error) {
+	tx := e.ContextTx(ctx, newTxOptions(opts))
+	if err = tx.Begin(); err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			_ = tx.Rollback()
+			panic(r)
+		}
+	}()
+	if err = fn(tx); err != nil {
+		// the rollback error is reported along with the error of fn.
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			err = errors.Join(err, rollbackErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// TxContext returns a context which carries the TxManager, so that ManagerFromContext returns it.
+// The context is derived from the one the TxManager was created with by Engine.ContextTx or Engine.WithTx,
+// otherwise from context.Background().
+func TxContext(tx TxManager) context.Context {
+	ctx := context.Background()
+	if t, ok := tx.(*txManager); ok && t.ctx != nil {
+		ctx = t.ctx
+	}
+	return ContextWithManager(ctx, tx)
+}
@@ -0,0 +1,55 @@
+package juice
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-juicedev/juice/driver"
+)
+
+type scopeTestKey struct{}
+
+func TestEngine_WithTx(t *testing.T) {
+	db, state := newFakeDB(t, fakeResultSet{})
+	engine := &Engine{db: db, driver: driver.MySQLDriver{}, rw: &NoOpRWMutex{}}
+	ctx := context.WithValue(context.Background(), scopeTestKey{}, "value")
+
+	err := engine.WithTx(ctx, func(tx TxManager) error {
+		txCtx := TxContext(tx)
+		if ManagerFromContext(txCtx) != tx {
+			t.Error("expected the context to carry the tx manager")
+		}
+		if txCtx.Value(scopeTestKey{}) != "value" {
+			t.Error("expected the context to be derived from the given context")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.commits != 1 || state.rollbacks != 0 {
+		t.Errorf("expected 1 commit, got %d commits and %d rollbacks", state.commits, state.rollbacks)
+	}
+
+	errBusiness := errors.New("business error")
+	err = engine.WithTx(ctx, func(tx TxManager) error { return errBusiness })
+	if !errors.Is(err, errBusiness) {
+		t.Errorf("expected business error, got %v", err)
+	}
+	if state.commits != 1 || state.rollbacks != 1 {
+		t.Errorf("expected 1 rollback, got %d commits and %d rollbacks", state.commits, state.rollbacks)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("expected the panic to be re-raised, got %v", r)
+			}
+		}()
+		_ = engine.WithTx(ctx, func(tx TxManager) error { panic("boom") })
+	}()
+	if state.commits != 1 || state.rollbacks != 2 {
+		t.Errorf("expected 2 rollbacks, got %d commits and %d rollbacks", state.commits, state.rollbacks)
+	}
+}
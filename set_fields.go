@@ -0,0 +1,208 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/go-juicedev/juice/driver"
+	"github.com/go-juicedev/juice/internal/reflectlite"
+)
+
+// ErrDirtyTrackerRequired is an error that is returned when the dirty policy of the
+// SetFieldsNode is used with a struct which doesn't implement DirtyTracker.
+var ErrDirtyTrackerRequired = errors.New("struct does not implement DirtyTracker")
+
+// SetFieldsFilter reports whether the field mapped to the column is updated.
+type SetFieldsFilter func(column string, field reflect.Value) bool
+
+// SetFieldsPolicy decides which fields of the struct parameter are updated by the SetFieldsNode.
+type SetFieldsPolicy interface {
+	// Filter returns the filter of the fields of the struct.
+	// It is called once every time the node is accepted.
+	Filter(value reflect.Value) (SetFieldsFilter, error)
+}
+
+// NonZeroSetFieldsPolicy updates the fields which are not zero.
+type NonZeroSetFieldsPolicy struct{}
+
+// Filter implements SetFieldsPolicy.
+func (NonZeroSetFieldsPolicy) Filter(_ reflect.Value) (SetFieldsFilter, error) {
+	return func(_ string, field reflect.Value) bool { return !field.IsZero() }, nil
+}
+
+// DirtyTracker is implemented by the structs which track their changed fields.
+type DirtyTracker interface {
+	// DirtyColumns returns the columns of the changed fields.
+	DirtyColumns() []string
+}
+
+// DirtySetFieldsPolicy updates the fields whose columns are reported by the DirtyTracker
+// implemented by the struct, no matter whether they are zero.
+type DirtySetFieldsPolicy struct{}
+
+// Filter implements SetFieldsPolicy.
+func (DirtySetFieldsPolicy) Filter(value reflect.Value) (SetFieldsFilter, error) {
+	var tracker DirtyTracker
+	if value.CanAddr() {
+		tracker, _ = value.Addr().Interface().(DirtyTracker)
+	}
+	if tracker == nil {
+		tracker, _ = value.Interface().(DirtyTracker)
+	}
+	if tracker == nil {
+		return nil, fmt.Errorf("%w: %s", ErrDirtyTrackerRequired, value.Type())
+	}
+	dirty := tracker.DirtyColumns()
+	return func(column string, _ reflect.Value) bool { return slices.Contains(dirty, column) }, nil
+}
+
+// setFieldsPolicyLibraries is a map of the set fields policies by name.
+var setFieldsPolicyLibraries = map[string]SetFieldsPolicy{}
+
+// RegisterSetFieldsPolicy registers a set fields policy which can be referenced by the
+// policy attribute of the fields element.
+// It allows to override the built-in policies, nonZero and dirty.
+func RegisterSetFieldsPolicy(name string, policy SetFieldsPolicy) {
+	if len(name) == 0 {
+		panic("name is empty")
+	}
+	if policy == nil {
+		panic("juice: set fields policy is nil")
+	}
+	setFieldsPolicyLibraries[name] = policy
+}
+
+// GetSetFieldsPolicy returns the set fields policy registered with the name.
+func GetSetFieldsPolicy(name string) (SetFieldsPolicy, bool) {
+	policy, exists := setFieldsPolicyLibraries[name]
+	return policy, exists
+}
+
+func init() {
+	RegisterSetFieldsPolicy("nonZero", NonZeroSetFieldsPolicy{})
+	RegisterSetFieldsPolicy("dirty", DirtySetFieldsPolicy{})
+}
+
+// SetFieldsNode generates the assignments of a SET clause from the fields of a struct
+// parameter with column tags, so that an update doesn't need an <if> for each column.
+// It is used inside a SetNode, which removes its trailing comma.
+//
+// Example XML:
+//
+//	<update id="UpdateUser">
+//	    UPDATE users
+//	    <set>
+//	        <fields value="user" policy="nonZero" exclude="id"/>
+//	    </set>
+//	    WHERE id = #{user.ID}
+//	</update>
+//
+// Example result, with only the name and age of the user set:
+//
+//	UPDATE users SET name = ?, age = ? WHERE id = ?
+//
+// The fields are resolved the same way as the result mapping: the fields with a column tag
+// are mapped, the untagged anonymous struct fields are walked into, and the prefixed fields
+// are walked into with their prefix. The values are bound by #{} placeholders, so the JSON
+// columns and the type handlers apply. The policy decides which fields are updated,
+// see SetFieldsPolicy, and defaults to NonZeroSetFieldsPolicy.
+//
+// The struct must be named by the value, like juice.H{"user": user}, since the fields of
+// a struct passed as the whole parameter are not reachable by a name.
+type SetFieldsNode struct {
+	// Value is the name of the struct parameter.
+	Value string
+
+	// Policy is the policy of the updated fields.
+	Policy SetFieldsPolicy
+
+	// Exclude is the columns which are never updated, like the primary key.
+	Exclude []string
+}
+
+// Accept accepts parameters and returns query and arguments.
+func (n *SetFieldsNode) Accept(translator driver.Translator, p Parameter) (query string, args []any, err error) {
+	value, exists := p.Get(n.Value)
+	if !exists {
+		return "", nil, fmt.Errorf("parameter %s not found", n.Value)
+	}
+	value = reflectlite.Unwrap(value)
+	if value.Kind() != reflect.Struct {
+		return "", nil, fmt.Errorf("fields %s: expected a struct", n.Value)
+	}
+	policy := n.Policy
+	if policy == nil {
+		policy = NonZeroSetFieldsPolicy{}
+	}
+	filter, err := policy.Filter(value)
+	if err != nil {
+		return "", nil, fmt.Errorf("fields %s: %w", n.Value, err)
+	}
+	builder := getStringBuilder()
+	defer putStringBuilder(builder)
+	n.writeFields(builder, value, filter, n.Value, "")
+	if builder.Len() == 0 {
+		return "", nil, nil
+	}
+	return NewTextNode(builder.String()).Accept(translator, p)
+}
+
+// writeFields writes the assignments of the updated fields of the struct, each one followed by a comma.
+// path is the parameter name of the struct, and prefix is the column prefix of the enclosing prefixed fields.
+func (n *SetFieldsNode) writeFields(builder *strings.Builder, value reflect.Value, filter SetFieldsFilter, path, prefix string) {
+	tp := value.Type()
+	for i := 0; i < tp.NumField(); i++ {
+		field := tp.Field(i)
+		rawTag := field.Tag.Get("column")
+		tag, _ := parseColumnTag(rawTag)
+		// the fields of the anonymous struct are promoted, so they are named the same as its own fields.
+		if deepScan := field.Anonymous && field.Type.Kind() == reflect.Struct && len(tag) == 0; deepScan {
+			n.writeFields(builder, value.Field(i), filter, path, prefix)
+			continue
+		}
+		// the unexported fields can not be bound.
+		if !field.IsExported() {
+			continue
+		}
+		name := path + "." + field.Name
+		if columnTagOption(rawTag, prefixColumnOption) && field.Type.Kind() == reflect.Struct {
+			n.writeFields(builder, value.Field(i), filter, name, prefix+columnPrefix(field.Name, tag))
+			continue
+		}
+		if skip := tag == "" || tag == "-"; skip {
+			continue
+		}
+		column := prefix + tag
+		if slices.Contains(n.Exclude, column) || !filter(column, value.Field(i)) {
+			continue
+		}
+		if builder.Len() > 0 {
+			builder.WriteString(" ")
+		}
+		builder.WriteString(column)
+		builder.WriteString(" = #{")
+		builder.WriteString(name)
+		builder.WriteString("},")
+	}
+}
+
+var _ Node = (*SetFieldsNode)(nil)
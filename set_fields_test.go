@@ -0,0 +1,121 @@
+package juice
+
+import (
+	"encoding/xml"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-juicedev/juice/driver"
+)
+
+type setFieldsAudit struct {
+	UpdatedBy string `column:"updated_by"`
+}
+
+type setFieldsAddress struct {
+	City string `column:"city"`
+}
+
+type setFieldsUser struct {
+	setFieldsAudit
+	ID      int64            `column:"id"`
+	Name    string           `column:"name"`
+	Age     int              `column:"age"`
+	Meta    map[string]any   `column:"meta,json"`
+	Address setFieldsAddress `column:"addr,prefix"`
+	Ignored string
+	secret  string `column:"secret"`
+
+	dirty []string
+}
+
+func (u *setFieldsUser) DirtyColumns() []string { return u.dirty }
+
+func TestSetFieldsNode(t *testing.T) {
+	stmt := parseTestStatement(t, Update, `<update id="UpdateUser">
+		UPDATE users <set><fields value="user" exclude="id"/> version = version + 1,</set> WHERE id = #{user.ID}
+	</update>`)
+
+	user := &setFieldsUser{ID: 1, Name: "eatmoreapple", Meta: map[string]any{"a": 1}, Address: setFieldsAddress{City: "sh"}, secret: "x"}
+	user.UpdatedBy = "admin"
+	query, args, err := stmt.Build(driver.MySQLDriver{}.Translator(), H{"user": user})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "UPDATE users SET updated_by = ?, name = ?, meta = ?, addr_city = ?, version = version + 1 WHERE id = ?"
+	if query != expected {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if !reflect.DeepEqual(args, []any{"admin", "eatmoreapple", `{"a":1}`, "sh", int64(1)}) {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	names, err := stmt.ParameterNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"user", "user.ID"}) {
+		t.Errorf("unexpected names: %v", names)
+	}
+}
+
+func TestSetFieldsNode_DirtyPolicy(t *testing.T) {
+	stmt := parseTestStatement(t, Update, `<update id="UpdateUser">
+		UPDATE users <set><fields value="user" policy="dirty"/></set> WHERE id = #{user.ID}
+	</update>`)
+
+	// the dirty fields are updated even if they are zero.
+	user := &setFieldsUser{ID: 1, Name: "eatmoreapple", dirty: []string{"age", "addr_city"}}
+	query, args, err := stmt.Build(driver.PostgresDriver{}.Translator(), H{"user": user})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "UPDATE users SET age = $1, addr_city = $2 WHERE id = $3" {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if !reflect.DeepEqual(args, []any{0, "", int64(1)}) {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	type untracked struct {
+		Name string `column:"name"`
+	}
+	_, _, err = stmt.Build(driver.PostgresDriver{}.Translator(), H{"user": untracked{Name: "a"}})
+	if !errors.Is(err, ErrDirtyTrackerRequired) {
+		t.Errorf("expected ErrDirtyTrackerRequired, got %v", err)
+	}
+}
+
+func TestSetFieldsNode_Errors(t *testing.T) {
+	node := &SetFieldsNode{Value: "user"}
+	if _, _, err := node.Accept(driver.MySQLDriver{}.Translator(), H{}.AsParam()); err == nil {
+		t.Error("expected parameter not found error")
+	}
+	if _, _, err := node.Accept(driver.MySQLDriver{}.Translator(), H{"user": 1}.AsParam()); err == nil {
+		t.Error("expected struct error")
+	}
+	var user *setFieldsUser
+	if _, _, err := node.Accept(driver.MySQLDriver{}.Translator(), H{"user": user}.AsParam()); err == nil {
+		t.Error("expected struct error")
+	}
+	// nothing changed
+	query, args, err := node.Accept(driver.MySQLDriver{}.Translator(), H{"user": setFieldsUser{}}.AsParam())
+	if err != nil || query != "" || len(args) != 0 {
+		t.Errorf("unexpected result: %q %v %v", query, args, err)
+	}
+
+	parser := &XMLMappersElementParser{}
+	for _, content := range []string{
+		`<update id="a">UPDATE users <set><fields/></set></update>`,
+		`<update id="a">UPDATE users <set><fields value="user" policy="unknown"/></set></update>`,
+	} {
+		decoder := xml.NewDecoder(strings.NewReader(content))
+		token, _ := decoder.Token()
+		stmt := &xmlSQLStatement{mapper: &Mapper{namespace: "main.UserMapper", mappers: &Mappers{}}, action: Update}
+		if err = parser.parseStatement(stmt, decoder, token.(xml.StartElement)); err == nil {
+			t.Errorf("expected error for %s", content)
+		}
+	}
+}
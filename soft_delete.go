@@ -0,0 +1,166 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// softDeleteAttribute is the attribute of the mappers and the select statements which
+// enables the soft delete filtering of the SoftDeleteInterceptor:
+//
+//	<mapper namespace="main.UserMapper" softDelete="true">
+//	    <select id="GetUserByID">
+//	        select * from user where id = #{id}
+//	    </select>
+//	    <select id="GetUserWithOrders" softDelete="u.deleted_at">
+//	        select * from user u join orders o on o.user_id = u.id
+//	    </select>
+//	    <select id="GetDeletedUsers" softDelete="false">
+//	        select * from user where deleted_at is not null
+//	    </select>
+//	</mapper>
+//
+// The value true filters by the default column of the interceptor, false disables the filtering,
+// any other value is the column to filter by, which is useful to qualify the column of joins.
+// The attribute of the statement takes precedence over the one of the mapper.
+const softDeleteAttribute = "softDelete"
+
+// DefaultSoftDeleteColumn is the column of the SoftDeleteInterceptor if it is not specified.
+const DefaultSoftDeleteColumn = "deleted_at"
+
+// ErrCompoundQuery is returned when a predicate can not be appended to the WHERE clause of
+// a compound query, like UNION, since it is ambiguous which select it applies to.
+var ErrCompoundQuery = errors.New("juice: can not append predicate to a compound query")
+
+type softDeletedKey struct{}
+
+// ContextWithSoftDeleted returns a new context which includes the soft deleted rows,
+// the SoftDeleteInterceptor doesn't filter the queries executed with the context.
+func ContextWithSoftDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, softDeletedKey{}, true)
+}
+
+// softDeletedFromContext reports whether the context includes the soft deleted rows.
+func softDeletedFromContext(ctx context.Context) bool {
+	included, _ := ctx.Value(softDeletedKey{}).(bool)
+	return included
+}
+
+// ensure SoftDeleteInterceptor implements StatementInterceptor.
+var _ StatementInterceptor = SoftDeleteInterceptor{} // compile time check
+
+// SoftDeleteInterceptor is a StatementInterceptor which filters out the soft deleted rows
+// of the select statements with the softDelete attribute, by appending a "column IS NULL"
+// predicate to their WHERE clause:
+//
+//	engine.UseStatementInterceptor(juice.SoftDeleteInterceptor{})
+//
+//	SELECT * FROM user WHERE id = ? OR name = ? ORDER BY id
+//	SELECT * FROM user WHERE (id = ? OR name = ?) AND deleted_at IS NULL ORDER BY id
+//
+// The existing predicate is wrapped in parentheses, so that its precedence is kept.
+// Use ContextWithSoftDeleted to include the soft deleted rows.
+type SoftDeleteInterceptor struct {
+	// Column is the column of the deletion mark, DefaultSoftDeleteColumn if empty.
+	Column string
+}
+
+// Intercept implements StatementInterceptor.
+func (s SoftDeleteInterceptor) Intercept(ctx context.Context, stmt Statement, query string, args []any) (string, []any, error) {
+	if stmt.Action() != Select || softDeletedFromContext(ctx) {
+		return query, args, nil
+	}
+	column := stmt.Attribute(softDeleteAttribute)
+	switch column {
+	case "", "false":
+		return query, args, nil
+	case "true":
+		column = s.Column
+		if column == "" {
+			column = DefaultSoftDeleteColumn
+		}
+	}
+	query, err := AppendWherePredicate(query, column+" IS NULL")
+	if err != nil {
+		return "", nil, err
+	}
+	return query, args, nil
+}
+
+// AppendWherePredicate appends the predicate to the top level WHERE clause of the query with AND.
+// The existing predicate is wrapped in parentheses:
+//
+//	SELECT * FROM user WHERE a = ? OR b = ? LIMIT 10
+//	SELECT * FROM user WHERE (a = ? OR b = ?) AND deleted_at IS NULL LIMIT 10
+//
+// If the query has no WHERE clause, one is inserted before the trailing clauses, like GROUP BY,
+// ORDER BY, LIMIT or the row locking clause. The predicate must not contain placeholders,
+// so that the order of the arguments is kept.
+// It returns ErrCompoundQuery for the queries with UNION, INTERSECT or EXCEPT.
+func AppendWherePredicate(query, predicate string) (string, error) {
+	tokens := scanSQLKeywords(query)
+	// the trailing clauses are searched after the WHERE, or the FROM if there is no WHERE.
+	where, from := -1, -1
+	for _, token := range tokens {
+		switch token.word {
+		case "UNION", "INTERSECT", "EXCEPT":
+			return "", ErrCompoundQuery
+		case "WHERE":
+			if where < 0 {
+				where = token.pos
+			}
+		case "FROM":
+			if from < 0 {
+				from = token.pos
+			}
+		}
+	}
+	tail := len(query)
+	for _, token := range tokens {
+		if token.pos <= max(where, from) {
+			continue
+		}
+		switch token.word {
+		case "GROUP", "HAVING", "WINDOW", "ORDER", "LIMIT", "OFFSET", "FETCH", "FOR", "LOCK", "RETURNING":
+			tail = min(tail, token.pos)
+		}
+	}
+
+	var builder = getStringBuilder()
+	defer putStringBuilder(builder)
+	if where >= 0 {
+		builder.WriteString(query[:where])
+		builder.WriteString("WHERE ")
+		if existing := strings.TrimSpace(query[where+len("WHERE") : tail]); existing != "" {
+			builder.WriteString("(")
+			builder.WriteString(existing)
+			builder.WriteString(") AND ")
+		}
+	} else {
+		builder.WriteString(strings.TrimRight(query[:tail], " \t\r\n"))
+		builder.WriteString(" WHERE ")
+	}
+	builder.WriteString(predicate)
+	if tail < len(query) {
+		builder.WriteString(" ")
+		builder.WriteString(query[tail:])
+	}
+	return builder.String(), nil
+}
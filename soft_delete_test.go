@@ -0,0 +1,111 @@
+package juice
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-juicedev/juice/driver"
+)
+
+func TestAppendWherePredicate(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT * FROM user", "SELECT * FROM user WHERE deleted_at IS NULL"},
+		{"SELECT * FROM user WHERE id = ?", "SELECT * FROM user WHERE (id = ?) AND deleted_at IS NULL"},
+		{"SELECT * FROM user WHERE a = ? OR b = ? ORDER BY id LIMIT ?", "SELECT * FROM user WHERE (a = ? OR b = ?) AND deleted_at IS NULL ORDER BY id LIMIT ?"},
+		{"SELECT * FROM user ORDER BY id", "SELECT * FROM user WHERE deleted_at IS NULL ORDER BY id"},
+		{"SELECT status, COUNT(*) FROM user GROUP BY status", "SELECT status, COUNT(*) FROM user WHERE deleted_at IS NULL GROUP BY status"},
+		{"SELECT * FROM user WHERE id IN (SELECT user_id FROM orders WHERE total > ?) FOR UPDATE", "SELECT * FROM user WHERE (id IN (SELECT user_id FROM orders WHERE total > ?)) AND deleted_at IS NULL FOR UPDATE"},
+		{"SELECT * FROM user WHERE name = 'order by'", "SELECT * FROM user WHERE (name = 'order by') AND deleted_at IS NULL"},
+	}
+	for _, tt := range tests {
+		got, err := AppendWherePredicate(tt.query, "deleted_at IS NULL")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("AppendWherePredicate(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+	if _, err := AppendWherePredicate("SELECT id FROM a UNION SELECT id FROM b", "deleted_at IS NULL"); !errors.Is(err, ErrCompoundQuery) {
+		t.Errorf("expected ErrCompoundQuery, got %v", err)
+	}
+}
+
+func TestSoftDeleteInterceptor(t *testing.T) {
+	stmt := parseTestStatement(t, Select, `<select id="QueryUsers">
+		SELECT * FROM user
+		<where>
+			<if test='name != ""'>name = #{name}</if>
+			<if test="status > 0">OR status = #{status}</if>
+		</where>
+	</select>`)
+	stmt.mapper.setAttribute(softDeleteAttribute, "true")
+
+	builder := statementBuilder{interceptors: StatementInterceptorGroup{SoftDeleteInterceptor{}}}
+	translator := driver.MySQLDriver{}.Translator()
+	ctx := context.Background()
+
+	query, args, err := builder.build(ctx, translator, stmt, H{"name": "eatmoreapple", "status": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM user WHERE (name = ? OR status = ?) AND deleted_at IS NULL" || !reflect.DeepEqual(args, []any{"eatmoreapple", 1}) {
+		t.Errorf("unexpected result: %s %v", query, args)
+	}
+
+	// the where node renders nothing.
+	query, _, err = builder.build(ctx, translator, stmt, H{"name": "", "status": 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM user WHERE deleted_at IS NULL" {
+		t.Errorf("unexpected query: %s", query)
+	}
+
+	// the soft deleted rows are included by the context.
+	query, _, err = builder.build(ContextWithSoftDeleted(ctx), translator, stmt, H{"name": "", "status": 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM user" {
+		t.Errorf("unexpected query: %s", query)
+	}
+
+	// the statement attribute takes precedence over the mapper one.
+	stmt.setAttribute(softDeleteAttribute, "u.removed_at")
+	query, _, err = builder.build(ctx, translator, stmt, H{"name": "", "status": 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM user WHERE u.removed_at IS NULL" {
+		t.Errorf("unexpected query: %s", query)
+	}
+
+	stmt.setAttribute(softDeleteAttribute, "false")
+	query, _, err = builder.build(ctx, translator, stmt, H{"name": "", "status": 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM user" {
+		t.Errorf("unexpected query: %s", query)
+	}
+}
+
+func TestSoftDeleteInterceptor_NotSelect(t *testing.T) {
+	stmt := parseTestStatement(t, Update, `<update id="DeleteUser">
+		UPDATE user SET deleted_at = now() WHERE id = #{id}
+	</update>`)
+	stmt.setAttribute(softDeleteAttribute, "true")
+	query, _, err := SoftDeleteInterceptor{}.Intercept(context.Background(), stmt, "UPDATE user SET deleted_at = now() WHERE id = ?", []any{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "UPDATE user SET deleted_at = now() WHERE id = ?" {
+		t.Errorf("unexpected query: %s", query)
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// DefaultSQLCommentFormat is the format of the comments of the SQLCommentMiddleware if it is not specified.
+const DefaultSQLCommentFormat = "traceID=%s"
+
+// preparedStatementKey is the context key which marks the statements executed by the PreparedStatementHandler.
+type preparedStatementKey struct{}
+
+// withPreparedStatement returns a new context which marks the statement as a prepared one.
+// It is a ctxreducer.ContextReducerFunc of the PreparedStatementHandler.
+func withPreparedStatement(ctx context.Context) context.Context {
+	return context.WithValue(ctx, preparedStatementKey{}, true)
+}
+
+// preparedStatementFromContext reports whether the statement is executed as a prepared one.
+func preparedStatementFromContext(ctx context.Context) bool {
+	prepared, _ := ctx.Value(preparedStatementKey{}).(bool)
+	return prepared
+}
+
+// ensure SQLCommentMiddleware implements Middleware.
+var _ Middleware = (*SQLCommentMiddleware)(nil) // compile time check
+
+// SQLCommentMiddleware is a middleware that prepends a comment with a value of the context, like
+// the request or trace id, to the executed queries, so that they can be attributed on the database
+// side, like by pg_stat_activity or the slow query log:
+//
+//	type traceIDKey struct{}
+//
+//	engine.Use(&juice.SQLCommentMiddleware{Key: traceIDKey{}})
+//
+//	ctx = context.WithValue(ctx, traceIDKey{}, "abc")
+//	/* traceID=abc */ SELECT * FROM user WHERE id = ?
+//
+// The value is formatted by fmt.Sprint, and the queries without the value are left as they are.
+// The "*/" and "/*" sequences are removed from the comment, so that it can't end early.
+//
+// The comment is added after the query is built, so the cache keys of the results are not
+// changed by it. The statements of the PreparedStatementHandler are not commented unless
+// Prepared is true, since every distinct comment prepares the statement again.
+type SQLCommentMiddleware struct {
+	// Key is the context key of the value of the comment.
+	Key any
+
+	// Format is the format of the comment with a %s verb of the value,
+	// DefaultSQLCommentFormat if empty.
+	Format string
+
+	// Prepared makes the statements of the PreparedStatementHandler commented too.
+	Prepared bool
+}
+
+// QueryContext implements Middleware.
+// QueryContext will prepend the comment to the query.
+func (m *SQLCommentMiddleware) QueryContext(_ Statement, next QueryHandler) QueryHandler {
+	return func(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+		return next(ctx, m.comment(ctx, query), args...)
+	}
+}
+
+// ExecContext implements Middleware.
+// ExecContext will prepend the comment to the query.
+func (m *SQLCommentMiddleware) ExecContext(_ Statement, next ExecHandler) ExecHandler {
+	return func(ctx context.Context, query string, args ...any) (sql.Result, error) {
+		return next(ctx, m.comment(ctx, query), args...)
+	}
+}
+
+// comment returns the query with the comment of the value of the context.
+func (m *SQLCommentMiddleware) comment(ctx context.Context, query string) string {
+	if !m.Prepared && preparedStatementFromContext(ctx) {
+		return query
+	}
+	value := ctx.Value(m.Key)
+	if value == nil {
+		return query
+	}
+	text := fmt.Sprint(value)
+	if text == "" {
+		return query
+	}
+	format := m.Format
+	if format == "" {
+		format = DefaultSQLCommentFormat
+	}
+	return "/* " + sanitizeSQLComment(fmt.Sprintf(format, text)) + " */ " + query
+}
+
+// sanitizeSQLComment removes the sequences which open or close a comment,
+// until none is left, since a removal can join a new one, like "*/" in "**//".
+func sanitizeSQLComment(comment string) string {
+	for strings.Contains(comment, "*/") || strings.Contains(comment, "/*") {
+		comment = strings.ReplaceAll(comment, "*/", "")
+		comment = strings.ReplaceAll(comment, "/*", "")
+	}
+	return comment
+}
@@ -0,0 +1,52 @@
+package juice
+
+import (
+	"context"
+	"testing"
+
+	juicedriver "github.com/go-juicedev/juice/driver"
+)
+
+type traceIDKey struct{}
+
+func TestSQLCommentMiddleware(t *testing.T) {
+	db, state := newFakeDB(t, fakeResultSet{})
+	drv := juicedriver.MySQLDriver{}
+	middleware := &SQLCommentMiddleware{Key: traceIDKey{}}
+	handler := NewDefaultStatementHandler(drv, db, middleware)
+	statement := newFakeStatement("SELECT * FROM user")
+
+	ctx := context.WithValue(context.Background(), traceIDKey{}, "abc*/ DROP TABLE user; /**//")
+	rows, err := handler.QueryContext(ctx, statement, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = rows.Close()
+	if _, err = handler.ExecContext(context.Background(), statement, nil); err != nil {
+		t.Fatal(err)
+	}
+	if query := state.executions[0].query; query != "/* traceID=abc DROP TABLE user; / */ SELECT * FROM user" {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if query := state.executions[1].query; query != "SELECT * FROM user" {
+		t.Errorf("unexpected query without the value: %s", query)
+	}
+
+	// the prepared statements are not commented by default.
+	prepared := &PreparedStatementHandler{driver: drv, session: db, middlewares: MiddlewareGroup{middleware}}
+	defer func() { _ = prepared.Close() }()
+	if _, err = prepared.ExecContext(ctx, statement, nil); err != nil {
+		t.Fatal(err)
+	}
+	middleware.Prepared = true
+	middleware.Format = "request=%s"
+	if _, err = prepared.ExecContext(context.WithValue(ctx, traceIDKey{}, 42), statement, nil); err != nil {
+		t.Fatal(err)
+	}
+	if query := state.executions[2].query; query != "SELECT * FROM user" {
+		t.Errorf("unexpected prepared query: %s", query)
+	}
+	if query := state.executions[3].query; query != "/* request=42 */ SELECT * FROM user" {
+		t.Errorf("unexpected prepared query: %s", query)
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import "strings"
+
+// formatSQLSetting is the setting name and the statement attribute which enables the pretty
+// printing of the SQL logged by the DebugMiddleware, see FormatSQL:
+//
+//	<settings>
+//	    <setting name="formatSQL" value="true"/>
+//	</settings>
+//
+// It only changes the logged copy of the SQL, the executed SQL is never altered.
+const formatSQLSetting = "formatSQL"
+
+// sqlFormatKeywords are the keywords which are upper cased by FormatSQL.
+var sqlFormatKeywords = map[string]struct{}{
+	"ALL": {}, "AND": {}, "AS": {}, "ASC": {}, "BETWEEN": {}, "BY": {}, "CASE": {}, "CROSS": {},
+	"DELETE": {}, "DESC": {}, "DISTINCT": {}, "ELSE": {}, "END": {}, "EXCEPT": {}, "EXISTS": {},
+	"FETCH": {}, "FOR": {}, "FROM": {}, "FULL": {}, "GROUP": {}, "HAVING": {}, "IN": {}, "INNER": {},
+	"INSERT": {}, "INTERSECT": {}, "INTO": {}, "IS": {}, "JOIN": {}, "LEFT": {}, "LIKE": {}, "LIMIT": {},
+	"LOCKED": {}, "NATURAL": {}, "NOT": {}, "NOWAIT": {}, "NULL": {}, "OFFSET": {}, "ON": {}, "OR": {},
+	"ORDER": {}, "OUTER": {}, "RETURNING": {}, "RIGHT": {}, "SELECT": {}, "SET": {}, "SHARE": {},
+	"SKIP": {}, "THEN": {}, "UNION": {}, "UPDATE": {}, "VALUES": {}, "WHEN": {}, "WHERE": {}, "WITH": {},
+}
+
+// sqlFormatJoinModifiers are the keywords which start a join clause before the JOIN keyword.
+var sqlFormatJoinModifiers = map[string]struct{}{
+	"LEFT": {}, "RIGHT": {}, "INNER": {}, "OUTER": {}, "FULL": {}, "CROSS": {}, "NATURAL": {},
+}
+
+// FormatSQL pretty prints the SQL for reading, it is meant for the logs only:
+//
+//	select id, name from user where id = ? order by id
+//
+//	SELECT id, name
+//	FROM user
+//	WHERE id = ?
+//	ORDER BY id
+//
+// The keywords are upper cased, the whitespaces are collapsed, and the major clauses of
+// the top level query start on a new line. The quoted strings and identifiers are kept as is.
+func FormatSQL(query string) string {
+	var builder strings.Builder
+	builder.Grow(len(query))
+	var (
+		depth int
+		prev  string // the previous word in upper case, empty if it is not a word.
+		space bool   // whether there are whitespaces before the current token.
+	)
+	// separate writes the separator of the next token.
+	separate := func(newline bool) {
+		switch {
+		case builder.Len() == 0:
+		case newline:
+			builder.WriteByte('\n')
+		case space:
+			builder.WriteByte(' ')
+		}
+		space = false
+	}
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = true
+			i++
+		case c == '\'' || c == '"' || c == '`':
+			end := skipQuoted(query, i)
+			separate(false)
+			builder.WriteString(query[i:end])
+			prev = ""
+			i = end
+		case isWordByte(c):
+			start := i
+			for i < len(query) && isWordByte(query[i]) {
+				i++
+			}
+			word := query[start:i]
+			upper := strings.ToUpper(word)
+			separate(depth == 0 && sqlFormatBreaksBefore(upper, prev))
+			if _, ok := sqlFormatKeywords[upper]; ok {
+				word = upper
+			}
+			builder.WriteString(word)
+			prev = upper
+		default:
+			switch c {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+			separate(false)
+			builder.WriteByte(c)
+			prev = ""
+			i++
+		}
+	}
+	return builder.String()
+}
+
+// sqlFormatBreaksBefore reports whether the word starts a major clause.
+func sqlFormatBreaksBefore(word, prev string) bool {
+	switch word {
+	case "FROM", "WHERE", "GROUP", "HAVING", "ORDER", "LIMIT", "OFFSET", "FETCH", "UNION", "INTERSECT",
+		"EXCEPT", "VALUES", "SET", "RETURNING", "FOR":
+		return true
+	case "LEFT", "RIGHT", "INNER", "FULL", "CROSS", "NATURAL", "JOIN":
+		// LEFT OUTER JOIN starts the clause once.
+		_, modified := sqlFormatJoinModifiers[prev]
+		return !modified
+	}
+	return false
+}
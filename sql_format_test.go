@@ -0,0 +1,77 @@
+package juice
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func TestFormatSQL(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{
+			query: "select id, name from user  where id = ? and name = 'from  where' order by id limit ?",
+			want:  "SELECT id, name\nFROM user\nWHERE id = ? AND name = 'from  where'\nORDER BY id\nLIMIT ?",
+		},
+		{
+			query: "select u.id from user u left outer join orders o on o.user_id = u.id join item i on i.id = o.item_id",
+			want:  "SELECT u.id\nFROM user u\nLEFT OUTER JOIN orders o ON o.user_id = u.id\nJOIN item i ON i.id = o.item_id",
+		},
+		{
+			query: "select * from user where id in (select user_id from orders where total > ?) for update",
+			want:  "SELECT *\nFROM user\nWHERE id IN (SELECT user_id FROM orders WHERE total > ?)\nFOR UPDATE",
+		},
+		{
+			query: "update user set name = ? where id = ?",
+			want:  "UPDATE user\nSET name = ?\nWHERE id = ?",
+		},
+		{
+			query: "insert into user (`from`, name) values (?, ?)",
+			want:  "INSERT INTO user (`from`, name)\nVALUES (?, ?)",
+		},
+	}
+	for _, tt := range tests {
+		if got := FormatSQL(tt.query); got != tt.want {
+			t.Errorf("FormatSQL(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestDebugMiddleware_FormatSQL(t *testing.T) {
+	var output bytes.Buffer
+	writer := logger.Writer()
+	logger.SetOutput(&output)
+	defer logger.SetOutput(writer)
+
+	stmt := newFakeStatement("select id from user where id = ?")
+	stmt.mapper.mappers.cfg = &Configuration{settings: keyValueSettingProvider{formatSQLSetting: "true"}}
+	var executed string
+	next := func(_ context.Context, query string, _ ...any) (sql.Result, error) {
+		executed = query
+		return nil, nil
+	}
+	handler := (&DebugMiddleware{}).ExecContext(stmt, next)
+	if _, err := handler(context.Background(), "select id from user where id = ?", 1); err != nil {
+		t.Fatal(err)
+	}
+	if executed != "select id from user where id = ?" {
+		t.Errorf("the executed query should not be formatted: %s", executed)
+	}
+	if !strings.Contains(output.String(), "SELECT id\nFROM user\nWHERE id = ?") {
+		t.Errorf("unexpected log: %s", output.String())
+	}
+
+	// the statement attribute takes precedence over the setting.
+	output.Reset()
+	stmt.setAttribute(formatSQLSetting, "false")
+	if _, err := handler(context.Background(), "select id from user where id = ?", 1); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output.String(), "select id from user where id = ?") {
+		t.Errorf("unexpected log: %s", output.String())
+	}
+}
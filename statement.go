@@ -17,7 +17,10 @@ limitations under the License.
 package juice
 
 import (
+	"sync/atomic"
+
 	"github.com/go-juicedev/juice/driver"
+	"github.com/go-juicedev/juice/eval"
 )
 
 type Statement interface {
@@ -30,6 +33,17 @@ type Statement interface {
 	Build(translator driver.Translator, param Param) (query string, args []any, err error)
 }
 
+// lockAttribute is the attribute of the select statements which appends the row locking
+// clause of the driver to the built query, for the pessimistic locking in transactions:
+//
+//	<select id="ClaimJobs" lock="skipLocked">
+//	    select * from job where status = 'pending' order by id limit 10
+//	</select>
+//
+// The modes are update, share and skipLocked, see driver.LockMode. The statement fails to
+// build with driver.ErrLockModeUnsupported if the driver doesn't support the mode.
+const lockAttribute = "lock"
+
 // xmlSQLStatement defines a sql xmlSQLStatement.
 type xmlSQLStatement struct {
 	mapper *Mapper
@@ -38,6 +52,16 @@ type xmlSQLStatement struct {
 	attrs  map[string]string
 	name   string
 	id     string
+
+	// defaults is the default parameters declared by the <param> elements.
+	// They are used when the caller doesn't provide the parameters.
+	defaults eval.H
+
+	// results is the result elements which map several columns to one field of the result type.
+	results []columnsResult
+
+	// substitution is the substitution policy resolved by the last Build, see substitutionPolicy.
+	substitution atomic.Pointer[cachedSubstitutionPolicy]
 }
 
 // Attribute returns the value of the attribute with the given key.
@@ -94,16 +118,49 @@ func (s *xmlSQLStatement) Configuration() IConfiguration {
 }
 
 // ResultMap returns the ResultMap of the xmlSQLStatement.
+// It maps the rows to the type declared by the resultType attribute, see RegisterResultType.
 func (s *xmlSQLStatement) ResultMap() (ResultMap, error) {
-	// TODO: implement the ResultMap method.
+	// TODO: implement the resultMap element.
 	// why is this not implemented?
 	// result map implementation is too complex, and it's not a common feature.
-	return nil, ErrResultMapNotSet
+	return statementResultMap(s)
+}
+
+// ParameterNames returns the names of the parameters referenced by the nodes of the xmlSQLStatement.
+func (s *xmlSQLStatement) ParameterNames() ([]string, error) {
+	return ParameterNames(s.Nodes)
+}
+
+// substitutionPolicy returns the substitution policy of the xmlSQLStatement, see statementSubstitutionPolicy.
+// It is resolved once per configuration of the statement instead of on every Build, the configuration
+// is replaced when the statement is merged into another one, like by MergeConfigurations.
+func (s *xmlSQLStatement) substitutionPolicy() (substitutionPolicy, bool) {
+	cfg := s.Configuration()
+	if cached := s.substitution.Load(); cached != nil && sameConfiguration(cached.configuration, cfg) {
+		return cached.policy, cached.strict
+	}
+	policy, strict := statementSubstitutionPolicy(s)
+	s.substitution.Store(&cachedSubstitutionPolicy{configuration: cfg, policy: policy, strict: strict})
+	return policy, strict
 }
 
 // Build builds the xmlSQLStatement with the given parameter.
 func (s *xmlSQLStatement) Build(translator driver.Translator, param Param) (query string, args []any, err error) {
 	value := newGenericParam(param, s.Attribute("paramName"))
+	// the caller provided parameters take precedence over the defaults.
+	if len(s.defaults) > 0 {
+		value = eval.ParamGroup{value, s.defaults.AsParam()}
+	}
+	// carry the substitution policy to the nodes in strict mode.
+	if policy, strict := s.substitutionPolicy(); strict {
+		translator = substitutionTranslator{Translator: translator, policy: policy}
+	}
+	if statementLenientExpressions(s) {
+		translator = lenientTranslator{Translator: translator}
+	}
+	if statementQuoteSubstitution(s) {
+		translator = identifierTranslator{Translator: translator}
+	}
 	query, args, err = s.Nodes.Accept(translator, value)
 	if err != nil {
 		return "", nil, err
@@ -111,5 +168,15 @@ func (s *xmlSQLStatement) Build(translator driver.Translator, param Param) (quer
 	if len(query) == 0 {
 		return "", nil, ErrEmptyQuery
 	}
+	if lock := s.attrs[lockAttribute]; lock != "" {
+		clause, err := driver.LockClause(translator, driver.LockMode(lock))
+		if err != nil {
+			return "", nil, err
+		}
+		query += " " + clause
+	}
+	if column := statementVersionColumn(s); column != "" {
+		return appendVersionLock(translator, value, query, args, column)
+	}
 	return query, args, nil
 }
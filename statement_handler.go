@@ -47,12 +47,99 @@ type StatementHandler interface {
 	QueryContext(ctx context.Context, statement Statement, param Param) (*sql.Rows, error)
 }
 
+// statementBuilder builds the statements with the engine level parameter providers,
+// interceptors and rewriters.
+type statementBuilder struct {
+	paramProviders ParameterProviderGroup
+	interceptors   StatementInterceptorGroup
+	rewriters      SQLRewriterGroup
+	variants       queryVariants
+	times          *timePolicy
+}
+
+// route returns the statement to query instead of the given statement, see QueryVariant.
+func (b statementBuilder) route(ctx context.Context, statement Statement) (Statement, error) {
+	return b.variants.route(ctx, statement)
+}
+
+// build builds the statement with the given parameter merged with the provided parameters,
+// then intercepts the built query with the interceptors and rewrites it with the rewriters.
+// The interceptors of a derivedStatement intercept the query of its source statement before
+// it is derived, so that their predicates go inside the derived query.
+func (b statementBuilder) build(ctx context.Context, translator driver.Translator, statement Statement, param Param) (string, []any, error) {
+	param, err := b.paramProviders.merge(ctx, statement, param)
+	if err != nil {
+		return "", nil, err
+	}
+	source := statement
+	derived, isDerived := statement.(derivedStatement)
+	if isDerived {
+		source = derived.source()
+	}
+	query, args, err := source.Build(argNamesTranslatorFromContext(ctx, translator), param)
+	if err != nil {
+		return "", nil, err
+	}
+	query, args, err = b.interceptors.Intercept(ctx, source, query, args)
+	if err != nil {
+		return "", nil, err
+	}
+	if isDerived {
+		if query, args, err = derived.derive(translator, query, args); err != nil {
+			return "", nil, err
+		}
+	}
+	query, args, err = b.rewriters.Rewrite(statement, query, args)
+	if err != nil {
+		return "", nil, err
+	}
+	// the time args are bound by the time policy of the engine.
+	if args, err = b.times.bindArgs(args); err != nil {
+		return "", nil, err
+	}
+	// the args of the named placeholders are passed by name.
+	if binder, ok := driver.TranslatorAs[driver.NamedArgsBinder](translator); ok {
+		if args, err = binder.NamedArgs(query, args); err != nil {
+			return "", nil, err
+		}
+	}
+	return query, args, nil
+}
+
+// queryRows runs the query handler, the rows are closed if they are returned with an error,
+// like by a middleware which fails after the query, so that their connection is released.
+func queryRows(ctx context.Context, handler QueryHandler, query string, args []any) (*sql.Rows, error) {
+	rows, err := handler(ctx, query, args...)
+	if err != nil {
+		if rows != nil {
+			_ = rows.Close()
+		}
+		return nil, err
+	}
+	return rows, nil
+}
+
+// statementBuildHandler is a StatementHandler which can build the statements
+// the same way as it executes them.
+type statementBuildHandler interface {
+	StatementHandler
+	buildStatement(ctx context.Context, statement Statement, param Param) (string, []any, error)
+}
+
+// ensure the handlers implement statementBuildHandler.
+var (
+	_ statementBuildHandler = (*PreparedStatementHandler)(nil)
+	_ statementBuildHandler = (*SQLRowsStatementHandler)(nil)
+	_ statementBuildHandler = (*DefaultStatementHandler)(nil)
+)
+
 // PreparedStatementHandler implements the StatementHandler interface.
 // It maintains a single prepared statement that can be reused if the query is the same.
 // When a different query is encountered, it closes the existing statement and creates a new one.
 type PreparedStatementHandler struct {
 	stmts       *sql.Stmt
 	middlewares MiddlewareGroup
+	builder     statementBuilder
 	driver      driver.Driver
 	session     session.Session
 }
@@ -75,17 +162,27 @@ func (s *PreparedStatementHandler) getOrPrepare(ctx context.Context, query strin
 	return s.stmts, nil
 }
 
+// buildStatement builds the statement with the handler's driver and builder.
+func (s *PreparedStatementHandler) buildStatement(ctx context.Context, statement Statement, param Param) (string, []any, error) {
+	return s.builder.build(ctx, s.driver.Translator(), statement, param)
+}
+
 // QueryContext executes a query that returns rows. It builds the query using
 // the provided Statement and Param, applies middlewares, and executes the
 // prepared statement with the given context.
 func (s *PreparedStatementHandler) QueryContext(ctx context.Context, statement Statement, param Param) (*sql.Rows, error) {
-	query, args, err := statement.Build(s.driver.Translator(), param)
+	statement, err := s.builder.route(ctx, statement)
+	if err != nil {
+		return nil, err
+	}
+	query, args, err := s.buildStatement(ctx, statement, param)
 	if err != nil {
 		return nil, err
 	}
 	contextReducer := ctxreducer.G{
 		ctxreducer.NewSessionContextReducer(s.session),
 		ctxreducer.NewParamContextReducer(param),
+		ctxreducer.ContextReducerFunc(withPreparedStatement),
 	}
 	ctx = contextReducer.Reduce(ctx)
 	next := func(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
@@ -95,20 +192,21 @@ func (s *PreparedStatementHandler) QueryContext(ctx context.Context, statement S
 		}
 		return preparedStmt.QueryContext(ctx, args...)
 	}
-	return s.middlewares.QueryContext(statement, next)(ctx, query, args...)
+	return queryRows(ctx, s.middlewares.QueryContext(statement, next), query, args)
 }
 
 // ExecContext executes a query that doesn't return rows. It builds the query
 // using the provided Statement and Param, applies middlewares, and executes
 // the prepared statement with the given context.
 func (s *PreparedStatementHandler) ExecContext(ctx context.Context, statement Statement, param Param) (result sql.Result, err error) {
-	query, args, err := statement.Build(s.driver.Translator(), param)
+	query, args, err := s.buildStatement(ctx, statement, param)
 	if err != nil {
 		return nil, err
 	}
 	contextReducer := ctxreducer.G{
 		ctxreducer.NewSessionContextReducer(s.session),
 		ctxreducer.NewParamContextReducer(param),
+		ctxreducer.ContextReducerFunc(withPreparedStatement),
 	}
 	ctx = contextReducer.Reduce(ctx)
 	next := func(ctx context.Context, query string, args ...any) (sql.Result, error) {
@@ -116,7 +214,11 @@ func (s *PreparedStatementHandler) ExecContext(ctx context.Context, statement St
 		if err != nil {
 			return nil, err
 		}
-		return preparedStmt.ExecContext(ctx, args...)
+		// the insert statement with a RETURNING clause is run as a query, see useGeneratedKeysMiddleware.
+		if keys := returningKeysFromContext(ctx); keys != nil {
+			return keys.execReturning(func() (*sql.Rows, error) { return preparedStmt.QueryContext(ctx, args...) })
+		}
+		return cachedExecResult(preparedStmt.ExecContext(ctx, args...))
 	}
 	return s.middlewares.ExecContext(statement, next)(ctx, query, args...)
 }
@@ -136,15 +238,25 @@ func (s *PreparedStatementHandler) Close() error {
 type SQLRowsStatementHandler struct {
 	driver      driver.Driver
 	middlewares MiddlewareGroup
+	builder     statementBuilder
 	session     session.Session
 }
 
+// buildStatement builds the statement with the handler's driver and builder.
+func (s *SQLRowsStatementHandler) buildStatement(ctx context.Context, statement Statement, param Param) (string, []any, error) {
+	return s.builder.build(ctx, s.driver.Translator(), statement, param)
+}
+
 // QueryContext executes a query represented by the Statement object within a context,
 // and returns the resulting rows. It builds the query using the provided Param values,
 // processes the query through any configured middlewares, and then executes it using
 // the associated driver.
 func (s *SQLRowsStatementHandler) QueryContext(ctx context.Context, statement Statement, param Param) (*sql.Rows, error) {
-	query, args, err := statement.Build(s.driver.Translator(), param)
+	statement, err := s.builder.route(ctx, statement)
+	if err != nil {
+		return nil, err
+	}
+	query, args, err := s.buildStatement(ctx, statement, param)
 	if err != nil {
 		return nil, err
 	}
@@ -154,14 +266,14 @@ func (s *SQLRowsStatementHandler) QueryContext(ctx context.Context, statement St
 	}
 	ctx = contextReducer.Reduce(ctx)
 	queryHandler := s.middlewares.QueryContext(statement, SessionQueryHandler)
-	return queryHandler(ctx, query, args...)
+	return queryRows(ctx, queryHandler, query, args)
 }
 
 // ExecContext executes a non-query SQL statement (such as INSERT, UPDATE, DELETE)
 // within a context, and returns the result. Similar to QueryContext, it constructs
 // the SQL command, applies middlewares, and executes the command using the driver.
 func (s *SQLRowsStatementHandler) ExecContext(ctx context.Context, statement Statement, param Param) (sql.Result, error) {
-	query, args, err := statement.Build(s.driver.Translator(), param)
+	query, args, err := s.buildStatement(ctx, statement, param)
 	if err != nil {
 		return nil, err
 	}
@@ -190,9 +302,10 @@ func NewSQLRowsStatementHandler(driver driver.Driver, session session.Session, m
 // DefaultStatementHandler handles the execution of SQL statements in batches.
 // It integrates a driver, middlewares, and a session to manage the execution flow.
 type DefaultStatementHandler struct {
-	driver      driver.Driver   // The driver used to execute SQL statements.
-	middlewares MiddlewareGroup // The group of middlewares to apply to the SQL statements.
-	session     session.Session // The session used to manage the database connection.
+	driver      driver.Driver    // The driver used to execute SQL statements.
+	middlewares MiddlewareGroup  // The group of middlewares to apply to the SQL statements.
+	builder     statementBuilder // The builder used to build the SQL statements.
+	session     session.Session  // The session used to manage the database connection.
 }
 
 // QueryContext executes a query represented by the Statement object within a context,
@@ -200,7 +313,7 @@ type DefaultStatementHandler struct {
 // processes the query through any configured middlewares, and then executes it using
 // the associated driver.
 func (b *DefaultStatementHandler) QueryContext(ctx context.Context, statement Statement, param Param) (*sql.Rows, error) {
-	statementHandler := NewSQLRowsStatementHandler(b.driver, b.session, b.middlewares...)
+	statementHandler := b.sqlRowsStatementHandler()
 	return statementHandler.QueryContext(ctx, statement, param)
 }
 
@@ -253,6 +366,7 @@ func (b *DefaultStatementHandler) ExecContext(ctx context.Context, statement Sta
 	preparedStatementHandler := &PreparedStatementHandler{
 		driver:      b.driver,
 		middlewares: b.middlewares,
+		builder:     b.builder,
 		session:     b.session,
 	}
 
@@ -260,6 +374,8 @@ func (b *DefaultStatementHandler) ExecContext(ctx context.Context, statement Sta
 	defer func() { _ = preparedStatementHandler.Close() }()
 
 	// execute the statement in batches.
+	// the generated keys are set to the elements of each batch, since the batches share the elements.
+	results := make(batchResult, 0, times)
 	for i := 0; i < times; i++ {
 		start := i * int(batchSize)
 		end := (i + 1) * int(batchSize)
@@ -271,15 +387,32 @@ func (b *DefaultStatementHandler) ExecContext(ctx context.Context, statement Sta
 		if err != nil {
 			return nil, err
 		}
+		results = append(results, result)
 	}
-	return result, nil
+	return results, nil
+}
+
+// buildStatement builds the statement with the handler's driver and builder.
+func (b *DefaultStatementHandler) buildStatement(ctx context.Context, statement Statement, param Param) (string, []any, error) {
+	return b.builder.build(ctx, b.driver.Translator(), statement, param)
 }
 
 func (b *DefaultStatementHandler) execContext(ctx context.Context, statement Statement, param Param) (sql.Result, error) {
-	statementHandler := NewSQLRowsStatementHandler(b.driver, b.session, b.middlewares...)
+	statementHandler := b.sqlRowsStatementHandler()
 	return statementHandler.ExecContext(ctx, statement, param)
 }
 
+// sqlRowsStatementHandler returns a SQLRowsStatementHandler sharing the same
+// driver, middlewares, builder and session with the DefaultStatementHandler.
+func (b *DefaultStatementHandler) sqlRowsStatementHandler() StatementHandler {
+	return &SQLRowsStatementHandler{
+		driver:      b.driver,
+		middlewares: b.middlewares,
+		builder:     b.builder,
+		session:     b.session,
+	}
+}
+
 // NewDefaultStatementHandler returns a new instance of StatementHandler with the default behavior.
 func NewDefaultStatementHandler(driver driver.Driver, session session.Session, middlewares ...Middleware) StatementHandler {
 	return &DefaultStatementHandler{
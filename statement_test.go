@@ -0,0 +1,292 @@
+package juice
+
+import (
+	"encoding/xml"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-juicedev/juice/driver"
+)
+
+func parseTestStatement(t *testing.T, action Action, content string) *xmlSQLStatement {
+	t.Helper()
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	token, err := decoder.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt := &xmlSQLStatement{mapper: &Mapper{namespace: "main.UserMapper", mappers: &Mappers{}}, action: action}
+	parser := &XMLMappersElementParser{}
+	if err = parser.parseStatement(stmt, decoder, token.(xml.StartElement)); err != nil {
+		t.Fatal(err)
+	}
+	return stmt
+}
+
+func TestXMLSQLStatement_DefaultParams(t *testing.T) {
+	stmt := parseTestStatement(t, Select, `<select id="QueryUsers">
+		<param name="limit" default="20"/>
+		<param name="offset" default="0"/>
+		<param name="status" default="1" type="string"/>
+		<param name="active" default="true"/>
+		SELECT * FROM user WHERE status = #{status} AND active = #{active} LIMIT #{limit} OFFSET #{offset}
+	</select>`)
+
+	query, args, err := stmt.Build(driver.MySQLDriver{}.Translator(), H{"offset": 40})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM user WHERE status = ? AND active = ? LIMIT ? OFFSET ?" {
+		t.Errorf("unexpected query: %s", query)
+	}
+	// the caller provided parameters take precedence over the defaults.
+	if expected := []any{"1", true, int64(20), 40}; !reflect.DeepEqual(args, expected) {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
+
+func TestParseParamLiteral(t *testing.T) {
+	tests := []struct {
+		literal  string
+		typ      string
+		expected any
+	}{
+		{"20", "", int64(20)},
+		{"1.5", "", 1.5},
+		{"false", "", false},
+		{"asc", "", "asc"},
+		{"20", "string", "20"},
+		{"20", "float", float64(20)},
+	}
+	for _, tt := range tests {
+		value, err := parseParamLiteral(tt.literal, tt.typ)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != tt.expected {
+			t.Errorf("parseParamLiteral(%q, %q) = %#v, want %#v", tt.literal, tt.typ, value, tt.expected)
+		}
+	}
+	if _, err := parseParamLiteral("a", "int"); err == nil {
+		t.Error("expected error for invalid int")
+	}
+	if _, err := parseParamLiteral("a", "date"); err == nil {
+		t.Error("expected error for unsupported type")
+	}
+}
+
+func TestXMLSQLStatement_ParameterNames(t *testing.T) {
+	stmt := parseTestStatement(t, Select, `<select id="QueryUsers">
+		SELECT * FROM ${table}
+		<where>
+			<if test="name != nil">AND name = #{name}</if>
+			<choose>
+				<when test="age > 0">AND age = #{ age }</when>
+				<otherwise>AND status = #{user.status}</otherwise>
+			</choose>
+			<foreach collection="ids" item="id" index="i" open="AND id IN (" separator="," close=")">
+				#{id}<if test="__first">#{i}</if>#{name}
+			</foreach>
+		</where>
+		<limit value="size" offset="offset"/>
+	</select>`)
+
+	names, err := StatementParameterNames(stmt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"age", "ids", "name", "offset", "size", "table", "user.status"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("unexpected names: %v", names)
+	}
+}
+
+func TestParameterNames_Include(t *testing.T) {
+	node := NodeGroup{
+		NewTextNode("SELECT * FROM user WHERE"),
+		&IncludeNode{sqlNode: &SQLNode{id: "byID", nodes: NodeGroup{NewTextNode("id = #{id}")}}},
+		ValuesNode{{column: "name", value: "#{name}"}},
+	}
+	names, err := ParameterNames(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"id", "name"}) {
+		t.Errorf("unexpected names: %v", names)
+	}
+}
+
+func TestStatementParameterNames_Unsupported(t *testing.T) {
+	if _, err := StatementParameterNames(countStatement{}); !errors.Is(err, ErrParameterNamesUnsupported) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestXMLSQLStatement_ActionOverride(t *testing.T) {
+	stmt := parseTestStatement(t, Select, `<select id="ArchiveUsers" action="delete">
+		WITH archived AS (DELETE FROM user WHERE id = #{id} RETURNING *) SELECT * FROM archived
+	</select>`)
+	if stmt.Action() != Delete {
+		t.Errorf("expected action delete, got %s", stmt.Action())
+	}
+
+	stmt = parseTestStatement(t, Select, `<select id="QueryUsers">SELECT * FROM user</select>`)
+	if stmt.Action() != Select {
+		t.Errorf("expected action select, got %s", stmt.Action())
+	}
+
+	decoder := xml.NewDecoder(strings.NewReader(`<select id="QueryUsers" action="merge">SELECT * FROM user</select>`))
+	token, err := decoder.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt = &xmlSQLStatement{mapper: &Mapper{namespace: "main.UserMapper", mappers: &Mappers{}}, action: Select}
+	if err = (&XMLMappersElementParser{}).parseStatement(stmt, decoder, token.(xml.StartElement)); err == nil {
+		t.Error("expected error for invalid action")
+	}
+}
+
+func TestXMLSQLStatement_Partition(t *testing.T) {
+	stmt := parseTestStatement(t, Select, `<select id="QueryEvents" strictSubstitution="true">
+		SELECT * FROM <partition table="events" value="createdAt" pattern="events_\d{4}_\d{2}"/> WHERE id = #{id}
+	</select>`)
+
+	param := H{"createdAt": time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), "id": 1}
+	query, args, err := stmt.Build(driver.SQLServerDriver{}.Translator(), param)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM [events_2024_03] WHERE id = @p1" || !reflect.DeepEqual(args, []any{1}) {
+		t.Errorf("unexpected result: %s %v", query, args)
+	}
+
+	names, err := stmt.ParameterNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"createdAt", "id"}) {
+		t.Errorf("unexpected names: %v", names)
+	}
+}
+
+func TestXMLSQLStatement_Lock(t *testing.T) {
+	stmt := parseTestStatement(t, Select, `<select id="ClaimJobs" lock="skipLocked">
+		SELECT * FROM job WHERE status = #{status} ORDER BY id
+	</select>`)
+
+	query, _, err := stmt.Build(driver.PostgresDriver{}.Translator(), H{"status": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM job WHERE status = $1 ORDER BY id FOR UPDATE SKIP LOCKED" {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if _, _, err = stmt.Build(driver.SQLiteDriver{}.Translator(), H{"status": 1}); !errors.Is(err, driver.ErrLockModeUnsupported) {
+		t.Errorf("expected ErrLockModeUnsupported, got %v", err)
+	}
+
+	// the pagination clause goes before the locking clause.
+	paginated := paginatedStatement{Statement: stmt, driver: driver.MySQLDriver{}, limit: 10}
+	query, _, err = paginated.Build(driver.MySQLDriver{}.Translator(), H{"status": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM job WHERE status = ? ORDER BY id LIMIT ? OFFSET ? FOR UPDATE SKIP LOCKED" {
+		t.Errorf("unexpected paginated query: %s", query)
+	}
+}
+
+func TestXMLSQLStatement_InvalidLock(t *testing.T) {
+	for _, content := range []string{
+		`<select id="QueryUsers" lock="exclusive">SELECT * FROM user</select>`,
+		`<update id="UpdateUsers" lock="update">UPDATE user SET name = #{name}</update>`,
+	} {
+		decoder := xml.NewDecoder(strings.NewReader(content))
+		token, err := decoder.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		start := token.(xml.StartElement)
+		stmt := &xmlSQLStatement{mapper: &Mapper{namespace: "main.UserMapper", mappers: &Mappers{}}, action: Action(start.Name.Local)}
+		if err = (&XMLMappersElementParser{}).parseStatement(stmt, decoder, start); err == nil {
+			t.Errorf("expected error for %s", content)
+		}
+	}
+}
+
+func TestXMLSQLStatement_RawValue(t *testing.T) {
+	stmt := parseTestStatement(t, Insert, `<insert id="InsertUser">
+		INSERT INTO user
+		<values>
+			<value column="name"/>
+			<value column="created_at" raw="NOW()"/>
+			<value column="status" raw="DEFAULT"/>
+		</values>
+	</insert>`)
+
+	query, args, err := stmt.Build(driver.MySQLDriver{}.Translator(), H{"name": "eatmoreapple"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "INSERT INTO user (name, created_at, status) VALUES (?, NOW(), DEFAULT)" {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if !reflect.DeepEqual(args, []any{"eatmoreapple"}) {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	// every row emits the raw value.
+	type user struct {
+		Name string `param:"name"`
+	}
+	query, args, err = stmt.Build(driver.MySQLDriver{}.Translator(), []user{{Name: "a"}, {Name: "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "INSERT INTO user (name, created_at, status) VALUES (?, NOW(), DEFAULT), (?, NOW(), DEFAULT)" {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if !reflect.DeepEqual(args, []any{"a", "b"}) {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
+
+func TestXMLSQLStatement_InvalidRawValue(t *testing.T) {
+	for _, value := range []string{
+		`<value column="created_at" raw="NOW()" value="#{createdAt}"/>`,
+		`<value column="created_at" raw=" "/>`,
+		`<value column="created_at" raw="#{createdAt}"/>`,
+		`<value column="created_at" raw="${createdAt}"/>`,
+	} {
+		content := `<insert id="InsertUser">INSERT INTO user <values>` + value + `</values></insert>`
+		decoder := xml.NewDecoder(strings.NewReader(content))
+		token, err := decoder.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stmt := &xmlSQLStatement{mapper: &Mapper{namespace: "main.UserMapper", mappers: &Mappers{}}, action: Insert}
+		if err = (&XMLMappersElementParser{}).parseStatement(stmt, decoder, token.(xml.StartElement)); err == nil {
+			t.Errorf("expected error for %s", value)
+		}
+	}
+}
+
+func TestXMLSQLStatement_TrimOverlappingOverrides(t *testing.T) {
+	stmt := parseTestStatement(t, Select, `<select id="QueryUsers">
+		SELECT * FROM user
+		<trim prefix="WHERE " prefixOverrides="AND|AND NOT" caseInsensitive="true">
+			<if test="true">and not deleted</if>
+		</trim>
+	</select>`)
+
+	query, _, err := stmt.Build(driver.MySQLDriver{}.Translator(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM user WHERE  deleted" {
+		t.Errorf("unexpected query: %q", query)
+	}
+}
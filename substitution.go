@@ -0,0 +1,201 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-juicedev/juice/driver"
+)
+
+const (
+	// strictSubstitutionKey is the setting and statement attribute name which enables
+	// the strict mode of the ${} substitutions.
+	strictSubstitutionKey = "strictSubstitution"
+
+	// substitutionAllowlistKey is the setting and statement attribute name which
+	// declares the comma separated values permitted by the ${} substitutions.
+	substitutionAllowlistKey = "substitutionAllowlist"
+
+	// quoteSubstitutionKey is the setting and statement attribute name which quotes
+	// the values of the ${} substitutions as identifiers by the driver.
+	quoteSubstitutionKey = "quoteSubstitution"
+)
+
+// ErrSubstitutionNotAllowed is an error that is returned when a ${} substitution
+// produces a value which is not in the allowlist in strict mode.
+var ErrSubstitutionNotAllowed = errors.New("substitution not allowed")
+
+// substitutionPolicy controls the values which can be substituted by ${}.
+//
+// ${} inserts the values verbatim, which is an injection risk. The legitimate use,
+// like table or column names from a fixed set, can be protected by an allowlist:
+//
+//	<settings>
+//	    <setting name="strictSubstitution" value="true"/>
+//	    <setting name="substitutionAllowlist" value="id,name,created_at"/>
+//	</settings>
+//
+// Or per statement:
+//
+//	<select id="QueryUsers" strictSubstitution="true" substitutionAllowlist="id,name">
+//	    select * from user order by ${sortColumn}
+//	</select>
+//
+// The allowlist of the statement extends the global one. The attribute of the statement
+// takes precedence over the setting, strictSubstitution="false" turns the global strict
+// mode off for the statement. When the strict mode is off, which is the default, the values
+// are substituted as they are.
+type substitutionPolicy struct {
+	allowed map[string]struct{}
+}
+
+// check returns an error if the value of the named substitution is not allowed.
+func (s substitutionPolicy) check(name, value string) error {
+	if _, ok := s.allowed[value]; ok {
+		return nil
+	}
+	return fmt.Errorf("%w: ${%s} = %q", ErrSubstitutionNotAllowed, name, value)
+}
+
+// substitutionChecker checks the values of the ${} substitutions.
+type substitutionChecker interface {
+	checkSubstitution(name, value string) error
+}
+
+// substitutionTranslator is a driver.Translator which carries the substitution policy
+// of the statement to the nodes.
+type substitutionTranslator struct {
+	driver.Translator
+	policy substitutionPolicy
+}
+
+// checkSubstitution implements substitutionChecker.
+func (t substitutionTranslator) checkSubstitution(name, value string) error {
+	return t.policy.check(name, value)
+}
+
+// Unwrap returns the wrapped translator, so that driver.QuoteIdentifier can reach it.
+func (t substitutionTranslator) Unwrap() driver.Translator {
+	return t.Translator
+}
+
+// ensure substitutionTranslator implements substitutionChecker.
+var _ substitutionChecker = (*substitutionTranslator)(nil) // compile time check
+
+// cachedSubstitutionPolicy is the substitution policy of a statement resolved with the settings
+// of its configuration, see xmlSQLStatement.substitutionPolicy.
+type cachedSubstitutionPolicy struct {
+	configuration IConfiguration
+	policy        substitutionPolicy
+	strict        bool
+}
+
+// sameConfiguration reports whether the configurations are the same one. The configurations
+// which are not comparable are never the same, so that their policies are not cached.
+func sameConfiguration(a, b IConfiguration) bool {
+	tp := reflect.TypeOf(a)
+	return tp != nil && tp == reflect.TypeOf(b) && tp.Comparable() && a == b
+}
+
+// statementSubstitutionPolicy returns the substitution policy of the statement.
+// It returns false if the strict mode is not enabled, the attribute of the statement
+// takes precedence over the setting.
+func statementSubstitutionPolicy(statement Statement) (substitutionPolicy, bool) {
+	var settings SettingProvider
+	if cfg := statement.Configuration(); cfg != nil {
+		settings = cfg.Settings()
+	}
+	var strict bool
+	if attribute := statement.Attribute(strictSubstitutionKey); attribute != "" {
+		strict = StringValue(attribute).Bool()
+	} else if settings != nil {
+		strict = settings.Get(strictSubstitutionKey).Bool()
+	}
+	if !strict {
+		return substitutionPolicy{}, false
+	}
+	policy := substitutionPolicy{allowed: make(map[string]struct{})}
+	allowlists := []string{statement.Attribute(substitutionAllowlistKey)}
+	if settings != nil {
+		allowlists = append(allowlists, settings.Get(substitutionAllowlistKey).String())
+	}
+	for _, allowlist := range allowlists {
+		for _, value := range strings.Split(allowlist, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				policy.allowed[value] = struct{}{}
+			}
+		}
+	}
+	return policy, true
+}
+
+// identifierTranslator is a driver.Translator which quotes the values of the ${} substitutions
+// as identifiers, like table and column names:
+//
+//	<select id="QueryUsers" strictSubstitution="true" substitutionAllowlist="id,name" quoteSubstitution="true">
+//	    select * from user order by ${sortColumn}
+//	</select>
+//
+// The query is built as "select * from user order by `name`" with the MySQL driver, and with
+// double quotes or brackets by the other dialects, see driver.IdentifierQuoter. The qualified
+// names, like u.name, are quoted part by part. The allowlist of the strict mode is checked
+// against the values before they are quoted.
+type identifierTranslator struct {
+	driver.Translator
+}
+
+// quoteSubstitution implements substitutionQuoter.
+func (t identifierTranslator) quoteSubstitution(value string) (string, error) {
+	parts := strings.Split(value, ".")
+	for i, part := range parts {
+		quoted, ok := driver.QuoteIdentifier(t.Translator, part)
+		if !ok {
+			return "", ErrIdentifierQuotingUnsupported
+		}
+		parts[i] = quoted
+	}
+	return strings.Join(parts, "."), nil
+}
+
+// Unwrap returns the wrapped translator, so that the capabilities of the driver are reachable.
+func (t identifierTranslator) Unwrap() driver.Translator {
+	return t.Translator
+}
+
+// substitutionQuoter quotes the values of the ${} substitutions.
+type substitutionQuoter interface {
+	quoteSubstitution(value string) (string, error)
+}
+
+// ensure identifierTranslator implements substitutionQuoter.
+var _ substitutionQuoter = identifierTranslator{} // compile time check
+
+// statementQuoteSubstitution reports whether the ${} substitutions of the statement are quoted,
+// the attribute of the statement takes precedence over the setting.
+func statementQuoteSubstitution(statement Statement) bool {
+	if attribute := statement.Attribute(quoteSubstitutionKey); attribute != "" {
+		return StringValue(attribute).Bool()
+	}
+	if cfg := statement.Configuration(); cfg != nil {
+		return cfg.Settings().Get(quoteSubstitutionKey).Bool()
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package juice
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-juicedev/juice/driver"
+)
+
+func TestSubstitutionPolicy(t *testing.T) {
+	translator := driver.MySQLDriver{}.Translator()
+	newStatement := func(settings keyValueSettingProvider, attrs map[string]string) *xmlSQLStatement {
+		mapper := &Mapper{namespace: "main.UserMapper", mappers: &Mappers{cfg: &Configuration{settings: settings}}}
+		return &xmlSQLStatement{
+			mapper: mapper,
+			action: Select,
+			id:     "QueryUsers",
+			attrs:  attrs,
+			Nodes:  NodeGroup{NewTextNode("SELECT * FROM user ORDER BY ${sortColumn}")},
+		}
+	}
+
+	// default behavior
+	stmt := newStatement(nil, nil)
+	query, _, err := stmt.Build(translator, H{"sortColumn": "id; DROP TABLE user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM user ORDER BY id; DROP TABLE user" {
+		t.Errorf("unexpected query: %s", query)
+	}
+
+	// global strict mode
+	stmt = newStatement(keyValueSettingProvider{"strictSubstitution": "true", "substitutionAllowlist": "id, name"}, nil)
+	if query, _, err = stmt.Build(translator, H{"sortColumn": "name"}); err != nil || query != "SELECT * FROM user ORDER BY name" {
+		t.Errorf("unexpected result: %s %v", query, err)
+	}
+	if _, _, err = stmt.Build(translator, H{"sortColumn": "id; DROP TABLE user"}); !errors.Is(err, ErrSubstitutionNotAllowed) {
+		t.Errorf("expected ErrSubstitutionNotAllowed, got %v", err)
+	}
+
+	// the statement attribute turns the global strict mode off
+	stmt = newStatement(keyValueSettingProvider{"strictSubstitution": "true"}, map[string]string{"strictSubstitution": "false"})
+	if query, _, err = stmt.Build(translator, H{"sortColumn": "created_at"}); err != nil || query != "SELECT * FROM user ORDER BY created_at" {
+		t.Errorf("unexpected result: %s %v", query, err)
+	}
+
+	// per statement strict mode extends the global allowlist
+	stmt = newStatement(keyValueSettingProvider{"substitutionAllowlist": "id"}, map[string]string{
+		"strictSubstitution":    "true",
+		"substitutionAllowlist": "created_at",
+	})
+	for _, column := range []string{"id", "created_at"} {
+		if _, _, err = stmt.Build(translator, H{"sortColumn": column}); err != nil {
+			t.Errorf("expected %s to be allowed, got %v", column, err)
+		}
+	}
+	if _, _, err = stmt.Build(translator, H{"sortColumn": "name"}); !errors.Is(err, ErrSubstitutionNotAllowed) {
+		t.Errorf("expected ErrSubstitutionNotAllowed, got %v", err)
+	}
+
+	// the policy is resolved once, and again when the statement is moved to another configuration.
+	cached := stmt.substitution.Load()
+	if _, _, err = stmt.Build(translator, H{"sortColumn": "id"}); err != nil || stmt.substitution.Load() != cached {
+		t.Errorf("expected the cached policy to be reused, got %v", err)
+	}
+	stmt.mapper.mappers.cfg = &Configuration{settings: keyValueSettingProvider{"substitutionAllowlist": "name"}}
+	if _, _, err = stmt.Build(translator, H{"sortColumn": "name"}); err != nil {
+		t.Errorf("expected the policy of the new configuration, got %v", err)
+	}
+	if _, _, err = stmt.Build(translator, H{"sortColumn": "id"}); !errors.Is(err, ErrSubstitutionNotAllowed) {
+		t.Errorf("expected ErrSubstitutionNotAllowed, got %v", err)
+	}
+}
+
+func TestQuoteSubstitution(t *testing.T) {
+	newStatement := func(settings keyValueSettingProvider, attrs map[string]string) *xmlSQLStatement {
+		mapper := &Mapper{namespace: "main.UserMapper", mappers: &Mappers{cfg: &Configuration{settings: settings}}}
+		return &xmlSQLStatement{
+			mapper: mapper,
+			action: Select,
+			id:     "QueryUsers",
+			attrs:  attrs,
+			Nodes:  NodeGroup{NewTextNode("SELECT * FROM user u ORDER BY ${sortColumn}")},
+		}
+	}
+
+	tests := []struct {
+		driver   driver.Driver
+		column   string
+		expected string
+	}{
+		{driver.MySQLDriver{}, "name", "SELECT * FROM user u ORDER BY `name`"},
+		{driver.PostgresDriver{}, "u.name", `SELECT * FROM user u ORDER BY "u"."name"`},
+		{driver.SQLServerDriver{}, "a]b", "SELECT * FROM user u ORDER BY [a]]b]"},
+	}
+	stmt := newStatement(keyValueSettingProvider{"quoteSubstitution": "true"}, nil)
+	for _, tt := range tests {
+		query, _, err := stmt.Build(tt.driver.Translator(), H{"sortColumn": tt.column})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if query != tt.expected {
+			t.Errorf("%s: unexpected query: %s", tt.driver, query)
+		}
+	}
+
+	// the statement attribute takes precedence over the setting.
+	stmt = newStatement(keyValueSettingProvider{"quoteSubstitution": "true"}, map[string]string{"quoteSubstitution": "false"})
+	if query, _, _ := stmt.Build(driver.MySQLDriver{}.Translator(), H{"sortColumn": "name"}); query != "SELECT * FROM user u ORDER BY name" {
+		t.Errorf("unexpected query: %s", query)
+	}
+
+	// the allowlist is checked against the unquoted value.
+	stmt = newStatement(nil, map[string]string{"quoteSubstitution": "true", "strictSubstitution": "true", "substitutionAllowlist": "name"})
+	if query, _, err := stmt.Build(driver.MySQLDriver{}.Translator(), H{"sortColumn": "name"}); err != nil || query != "SELECT * FROM user u ORDER BY `name`" {
+		t.Errorf("unexpected result: %s %v", query, err)
+	}
+	if _, _, err := stmt.Build(driver.MySQLDriver{}.Translator(), H{"sortColumn": "id"}); !errors.Is(err, ErrSubstitutionNotAllowed) {
+		t.Errorf("expected ErrSubstitutionNotAllowed, got %v", err)
+	}
+
+	translator := driver.TranslateFunc(func(string) string { return "?" })
+	if _, _, err := stmt.Build(translator, H{"sortColumn": "name"}); !errors.Is(err, ErrIdentifierQuotingUnsupported) {
+		t.Errorf("expected ErrIdentifierQuotingUnsupported, got %v", err)
+	}
+}
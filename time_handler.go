@@ -0,0 +1,206 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// timeLayouts are the layouts of the time columns scanned as text, like by the mysql driver without parseTime.
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	time.RFC3339Nano,
+	time.DateOnly,
+}
+
+// ensure TimeHandler implements TypeHandler.
+var _ TypeHandler[time.Time] = TimeHandler{} // compile time check
+
+// TimeHandler is a TypeHandler of time.Time which enforces a consistent time policy:
+// the time parameters are bound in UTC, and the scanned times are converted to the Location.
+// It is not used by default, the times are passed through to the driver as they are,
+// see Engine.UseUTCTime.
+//
+// The times returned by the driver keep their instant, and only their location is changed.
+// The times scanned as text have no zone, and are parsed as UTC, since they are stored by
+// this handler in UTC. So the driver settings must agree with the UTC storage to avoid a
+// double conversion, for example, parseTime=true&loc=UTC of the mysql driver, or the
+// timestamptz columns of postgres. With loc=Local, the mysql driver reads the stored UTC
+// wall clock as the local time, which shifts the times by the offset of the local zone.
+//
+// The handler only applies to the time.Time values, the *time.Time and sql.NullTime values
+// are passed through.
+type TimeHandler struct {
+	// Location is the location which the scanned times are converted to.
+	// If nil, UTC is used.
+	Location *time.Location
+}
+
+// location returns the location of the scanned times.
+func (h TimeHandler) location() *time.Location {
+	if h.Location == nil {
+		return time.UTC
+	}
+	return h.Location
+}
+
+// Scan implements TypeHandler.
+// NULL is scanned as the zero time.
+func (h TimeHandler) Scan(src any) (time.Time, error) {
+	return scanTime(src, time.UTC, h.location())
+}
+
+// scanTime scans the time and converts it to the location.
+// The times scanned as text are parsed in the textLocation.
+func scanTime(src any, textLocation, location *time.Location) (time.Time, error) {
+	var text string
+	switch value := src.(type) {
+	case nil:
+		return time.Time{}, nil
+	case time.Time:
+		return value.In(location), nil
+	case []byte:
+		text = string(value)
+	case string:
+		text = value
+	default:
+		return time.Time{}, fmt.Errorf("juice: can not scan %T into time.Time", src)
+	}
+	for _, layout := range timeLayouts {
+		if value, err := time.ParseInLocation(layout, text, textLocation); err == nil {
+			return value.In(location), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("juice: can not parse %q as time.Time", text)
+}
+
+// Value implements TypeHandler.
+func (h TimeHandler) Value(value time.Time) (driver.Value, error) {
+	return value.UTC(), nil
+}
+
+// UseUTCTime sets the time policy of the engine, so that all the time.Time parameters are bound
+// in UTC, and all the scanned time.Time values are converted to the location, see TimeHandler.
+// It is off by default, the times are passed through to the driver as they are.
+//
+//	engine.UseUTCTime(time.Local)
+//
+// It only applies to the statements of the engine, and takes precedence over a TypeHandler of
+// time.Time registered by RegisterTypeHandler. The results of a ResultMap of a statement are
+// mapped by the ResultMap as they are.
+func (e *Engine) UseUTCTime(location *time.Location) {
+	policy := e.times.clone()
+	policy.utc = &TimeHandler{Location: location}
+	e.times = policy
+}
+
+// timePolicy is the time.Time policy of an engine, see Engine.UseUTCTime and Engine.SetDefaultTimeLocation.
+// It is replaced instead of modified, so that the executors keep the policy they are created with.
+type timePolicy struct {
+	// utc is the handler of Engine.UseUTCTime, nil if the times are passed through.
+	utc *TimeHandler
+	// location is the location of Engine.SetDefaultTimeLocation, nil if not set.
+	location *time.Location
+}
+
+// clone returns a copy of the policy, or an empty one if nil.
+func (p *timePolicy) clone() *timePolicy {
+	if p == nil {
+		return &timePolicy{}
+	}
+	clone := *p
+	return &clone
+}
+
+// scanHandler returns the handler of the scanned times, nil if they are scanned as they are.
+// The times stored in UTC by UseUTCTime are converted to the location if it is set.
+func (p *timePolicy) scanHandler() typeHandler {
+	switch {
+	case p == nil:
+		return nil
+	case p.utc != nil && p.location != nil:
+		return genericTypeHandler[time.Time]{handler: TimeHandler{Location: p.location}}
+	case p.utc != nil:
+		return genericTypeHandler[time.Time]{handler: *p.utc}
+	case p.location != nil:
+		return genericTypeHandler[time.Time]{handler: timeLocationHandler{location: p.location}}
+	default:
+		return nil
+	}
+}
+
+// bindArgs converts the time.Time args by the policy before they are bound.
+func (p *timePolicy) bindArgs(args []any) ([]any, error) {
+	if p == nil || p.utc == nil {
+		return args, nil
+	}
+	for i, arg := range args {
+		value, ok := arg.(time.Time)
+		if !ok {
+			continue
+		}
+		converted, err := p.utc.Value(value)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = converted
+	}
+	return args, nil
+}
+
+// timeResultMap returns the result map of T which scans the times by the policy.
+// The result maps other than SingleRowResultMap and MultiRowsResultMap are returned as they are,
+// and so is nil for the results which have no time.Time to scan, so that the scalars are still
+// bound without reflection.
+func timeResultMap[T any](resultMap ResultMap, policy *timePolicy) ResultMap {
+	if policy.scanHandler() == nil {
+		return resultMap
+	}
+	switch resultMap := resultMap.(type) {
+	case SingleRowResultMap:
+		resultMap.times = policy
+		return resultMap
+	case MultiRowsResultMap:
+		resultMap.times = policy
+		return resultMap
+	case nil:
+	default:
+		return resultMap
+	}
+	tp := reflect.TypeFor[T]()
+	if tp.Kind() == reflect.Ptr {
+		tp = tp.Elem()
+	}
+	multiple := tp.Kind() == reflect.Slice && !isStringAnyMap(tp.Elem())
+	if multiple {
+		tp = tp.Elem()
+		if tp.Kind() == reflect.Ptr {
+			tp = tp.Elem()
+		}
+	}
+	switch {
+	case tp != timeType && (tp.Kind() != reflect.Struct || reflect.PointerTo(tp).Implements(scannerType)):
+		return nil
+	case multiple:
+		return MultiRowsResultMap{times: policy}
+	default:
+		return SingleRowResultMap{times: policy}
+	}
+}
@@ -0,0 +1,93 @@
+package juice
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	juicedriver "github.com/go-juicedev/juice/driver"
+	"github.com/go-juicedev/juice/eval"
+)
+
+func TestTimeHandler_Scan(t *testing.T) {
+	shanghai := time.FixedZone("Asia/Shanghai", 8*60*60)
+	handler := TimeHandler{Location: shanghai}
+	instant := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		src  any
+		want time.Time
+	}{
+		{instant, instant},
+		{instant.In(time.FixedZone("EST", -5*60*60)), instant},
+		{[]byte("2024-05-01 12:30:00"), instant},
+		{"2024-05-01T12:30:00Z", instant},
+		{"2024-05-01", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := handler.Scan(tt.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(tt.want) || got.Location() != shanghai {
+			t.Errorf("%v: unexpected time %v", tt.src, got)
+		}
+	}
+	if got, err := handler.Scan(nil); err != nil || !got.IsZero() {
+		t.Errorf("expected zero time for NULL, got %v, %v", got, err)
+	}
+	if _, err := handler.Scan("yesterday"); err == nil {
+		t.Error("expected error for invalid time")
+	}
+	if got, _ := (TimeHandler{}).Scan(instant.Local()); got.Location() != time.UTC {
+		t.Errorf("expected UTC by default, got %v", got.Location())
+	}
+}
+
+func TestEngine_UseUTCTime(t *testing.T) {
+	type user struct {
+		UpdatedAt time.Time `column:"updated_at"`
+	}
+	shanghai := time.FixedZone("Asia/Shanghai", 8*60*60)
+	local := time.Date(2024, 5, 1, 20, 30, 0, 0, shanghai)
+	db, state := newFakeDB(t, fakeResultSet{
+		columns: []string{"updated_at"},
+		rows:    [][]driver.Value{{"2024-05-01 12:30:00"}},
+	})
+	statement := newFakeStatement("SELECT updated_at FROM user WHERE updated_at = #{updated_at}")
+	param := eval.H{"updated_at": local}
+	ctx := context.Background()
+
+	engine := &Engine{}
+	engine.UseUTCTime(shanghai)
+	drv := juicedriver.MySQLDriver{}
+	exe := &sqlRowsExecutor{statement: statement, statementHandler: engine.statementHandler(drv, db), driver: drv, times: engine.times}
+
+	users, err := (&GenericExecutor[[]user]{SQLRowsExecutor: exe}).QueryContext(ctx, param)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bound, ok := state.executions[0].args[0].(time.Time); !ok || bound.Location() != time.UTC || !bound.Equal(local) {
+		t.Errorf("expected the time bound in UTC, got %v", state.executions[0].args[0])
+	}
+	if len(users) != 1 || !users[0].UpdatedAt.Equal(local) || users[0].UpdatedAt.Location() != shanghai {
+		t.Errorf("unexpected users: %v", users)
+	}
+	updated, err := (&GenericExecutor[time.Time]{SQLRowsExecutor: exe}).QueryContext(ctx, param)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updated.Equal(local) || updated.Location() != shanghai {
+		t.Errorf("unexpected time: %v", updated)
+	}
+
+	// the other engines are not affected.
+	other := newFakeExecutor(db, statement)
+	if _, err = (&GenericExecutor[[]user]{SQLRowsExecutor: other}).QueryContext(ctx, param); err == nil {
+		t.Error("expected error for scanning the text time without the policy")
+	}
+	if bound, ok := state.executions[len(state.executions)-1].args[0].(time.Time); !ok || bound.Location() != shanghai {
+		t.Errorf("expected the time passed through, got %v", state.executions[len(state.executions)-1].args[0])
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"database/sql/driver"
+	"time"
+)
+
+// SetDefaultTimeLocation sets the location which the scanned time.Time values of the engine are
+// converted to, so that the times read by the drivers in UTC, the local zone or the session zone
+// are compared and formatted the same way. A nil location, which is the default, keeps the times
+// as they are returned by the driver.
+//
+//	loc, _ := time.LoadLocation("Asia/Shanghai")
+//	engine.SetDefaultTimeLocation(loc)
+//
+// It only converts the scanned times, the time parameters are passed through to the driver as
+// they are. The times scanned as text have no zone, and are parsed in the location, which is the
+// one they are written in. It is independent of Engine.UseUTCTime, which binds the parameters in
+// UTC, in which case the times scanned as text are parsed as UTC, and the scanned times are
+// converted to the location instead of the one of UseUTCTime.
+// The conversion keeps the instant of the time and only changes its location, the NULL
+// values are scanned as the zero time, see TimeHandler.
+func (e *Engine) SetDefaultTimeLocation(location *time.Location) {
+	policy := e.times.clone()
+	policy.location = location
+	e.times = policy
+}
+
+// DefaultTimeLocation returns the location which the scanned times of the engine are converted to,
+// by SetDefaultTimeLocation or UseUTCTime, nil if the times are kept as they are.
+func (e *Engine) DefaultTimeLocation() *time.Location {
+	switch {
+	case e.times == nil:
+		return nil
+	case e.times.location != nil:
+		return e.times.location
+	case e.times.utc != nil:
+		return e.times.utc.location()
+	default:
+		return nil
+	}
+}
+
+// ensure timeLocationHandler implements TypeHandler.
+var _ TypeHandler[time.Time] = timeLocationHandler{} // compile time check
+
+// timeLocationHandler is the handler of the scanned times of Engine.SetDefaultTimeLocation,
+// which parses the times scanned as text in the location, and passes the time parameters through.
+type timeLocationHandler struct {
+	location *time.Location
+}
+
+// Scan implements TypeHandler.
+func (h timeLocationHandler) Scan(src any) (time.Time, error) {
+	return scanTime(src, h.location, h.location)
+}
+
+// Value implements TypeHandler.
+func (h timeLocationHandler) Value(value time.Time) (driver.Value, error) {
+	return value, nil
+}
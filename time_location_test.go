@@ -0,0 +1,131 @@
+package juice
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	juicedriver "github.com/go-juicedev/juice/driver"
+)
+
+// timeLocationExecutor returns an executor of the engine which queries the result set.
+func timeLocationExecutor(t *testing.T, engine *Engine, query string, resultSet fakeResultSet) (*sqlRowsExecutor, *fakeDB) {
+	t.Helper()
+	db, state := newFakeDB(t, resultSet)
+	drv := juicedriver.MySQLDriver{}
+	return &sqlRowsExecutor{
+		statement:        newFakeStatement(query),
+		statementHandler: engine.statementHandler(drv, db),
+		driver:           drv,
+		times:            engine.times,
+	}, state
+}
+
+func TestEngine_SetDefaultTimeLocation(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*60*60)
+	engine := &Engine{}
+	engine.SetDefaultTimeLocation(shanghai)
+	ctx := context.Background()
+
+	type User struct {
+		ID        int64     `column:"id"`
+		CreatedAt time.Time `column:"created_at"`
+		DeletedAt time.Time `column:"deleted_at,nullzero"`
+	}
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	exe, state := timeLocationExecutor(t, engine, "SELECT * FROM user WHERE created_at = #{created_at}", fakeResultSet{
+		columns: []string{"id", "created_at", "deleted_at"},
+		rows:    [][]driver.Value{{int64(1), created, nil}},
+	})
+	param := H{"created_at": created.In(shanghai)}
+	users, err := (&GenericExecutor[[]User]{SQLRowsExecutor: exe}).QueryContext(ctx, param)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 {
+		t.Fatalf("expected 1 user, got %d", len(users))
+	}
+	if !users[0].CreatedAt.Equal(created) || users[0].CreatedAt.Location() != shanghai {
+		t.Errorf("unexpected created_at: %v", users[0].CreatedAt)
+	}
+	if users[0].CreatedAt.Hour() != 20 {
+		t.Errorf("expected the wall clock of the location, got %v", users[0].CreatedAt)
+	}
+	if !users[0].DeletedAt.IsZero() {
+		t.Errorf("expected zero deleted_at, got %v", users[0].DeletedAt)
+	}
+	// the time parameters are passed through.
+	if bound, ok := state.executions[0].args[0].(time.Time); !ok || bound.Location() != shanghai {
+		t.Errorf("expected the parameter to be passed through, got %v", state.executions[0].args[0])
+	}
+
+	// the single time result, the times scanned as text are parsed in the location.
+	exe, _ = timeLocationExecutor(t, engine, "SELECT created_at FROM user", fakeResultSet{
+		columns: []string{"created_at"},
+		rows:    [][]driver.Value{{"2024-01-01 20:00:00"}},
+	})
+	value, err := (&GenericExecutor[time.Time]{SQLRowsExecutor: exe}).QueryContext(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !value.Equal(created) || value.Location() != shanghai {
+		t.Errorf("unexpected time: %v", value)
+	}
+
+	// NULL is scanned as the zero time, as TimeHandler does.
+	exe, _ = timeLocationExecutor(t, engine, "SELECT id, created_at FROM user", fakeResultSet{
+		columns: []string{"id", "created_at"},
+		rows:    [][]driver.Value{{int64(1), nil}},
+	})
+	if users, err = (&GenericExecutor[[]User]{SQLRowsExecutor: exe}).QueryContext(ctx, nil); err != nil || !users[0].CreatedAt.IsZero() {
+		t.Errorf("unexpected result: %v %v", users, err)
+	}
+
+	// the other engines are not affected.
+	if users, err = List[User](queryFakeRows(t, fakeResultSet{
+		columns: []string{"id", "created_at"},
+		rows:    [][]driver.Value{{int64(1), created}},
+	})); err != nil || users[0].CreatedAt.Location() != time.UTC {
+		t.Errorf("unexpected result: %v %v", users, err)
+	}
+}
+
+func TestEngine_DefaultTimeLocation(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*60*60)
+	engine := &Engine{}
+	if engine.DefaultTimeLocation() != nil {
+		t.Errorf("expected no location, got %v", engine.DefaultTimeLocation())
+	}
+	engine.SetDefaultTimeLocation(shanghai)
+	if engine.DefaultTimeLocation() != shanghai {
+		t.Errorf("unexpected location: %v", engine.DefaultTimeLocation())
+	}
+
+	// UseUTCTime keeps the location, and the times scanned as text are parsed as UTC.
+	engine.UseUTCTime(nil)
+	if engine.DefaultTimeLocation() != shanghai {
+		t.Errorf("expected the location to be kept, got %v", engine.DefaultTimeLocation())
+	}
+	exe, state := timeLocationExecutor(t, engine, "SELECT created_at FROM user WHERE created_at > #{since}", fakeResultSet{
+		columns: []string{"created_at"},
+		rows:    [][]driver.Value{{"2024-01-01 12:00:00"}},
+	})
+	since := time.Date(2024, 1, 1, 8, 0, 0, 0, shanghai)
+	value, err := (&GenericExecutor[time.Time]{SQLRowsExecutor: exe}).QueryContext(context.Background(), H{"since": since})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !value.Equal(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)) || value.Location() != shanghai {
+		t.Errorf("unexpected time: %v", value)
+	}
+	if bound, ok := state.executions[0].args[0].(time.Time); !ok || bound.Location() != time.UTC || !bound.Equal(since) {
+		t.Errorf("expected the parameter bound in UTC, got %v", state.executions[0].args[0])
+	}
+
+	// removing the location keeps UseUTCTime.
+	engine.SetDefaultTimeLocation(nil)
+	if engine.DefaultTimeLocation() != time.UTC || engine.times.utc == nil {
+		t.Errorf("expected the location of UseUTCTime, got %v", engine.DefaultTimeLocation())
+	}
+}
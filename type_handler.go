@@ -0,0 +1,115 @@
+/*
+Copyright 2024 eatmoreapple
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package juice
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"sync"
+)
+
+// TypeHandler converts the values of the type T from the scanned column values,
+// and to the driver values bound by the #{} placeholders.
+// It is useful for the types which do not implement sql.Scanner and driver.Valuer,
+// like the exact decimal types of the monetary columns.
+type TypeHandler[T any] interface {
+	// Scan converts the column value to T. The src is nil for NULL.
+	Scan(src any) (T, error)
+
+	// Value converts T to the driver value which is bound to the placeholder.
+	Value(value T) (driver.Value, error)
+}
+
+// typeHandlerRegistry holds the registered type handlers, keyed by the reflect.Type.
+var typeHandlerRegistry sync.Map // map[reflect.Type]typeHandler
+
+// RegisterTypeHandler registers the TypeHandler of the type T.
+//
+// Once T is registered, the struct fields of type T, or the results of type T, are scanned
+// by the handler, and the parameters of type T are bound by the handler.
+// The json option of the column tag takes precedence over the handler.
+// Registering T again replaces its handler.
+func RegisterTypeHandler[T any](handler TypeHandler[T]) {
+	if handler == nil {
+		panic("juice: type handler is nil")
+	}
+	typeHandlerRegistry.Store(reflect.TypeFor[T](), genericTypeHandler[T]{handler: handler})
+}
+
+// UnregisterTypeHandler removes the TypeHandler of the type T.
+func UnregisterTypeHandler[T any]() {
+	typeHandlerRegistry.Delete(reflect.TypeFor[T]())
+}
+
+// typeHandler is the type-erased TypeHandler.
+type typeHandler interface {
+	scan(field reflect.Value, src any) error
+	value(value reflect.Value) (any, error)
+}
+
+// genericTypeHandler adapts a TypeHandler to a typeHandler.
+type genericTypeHandler[T any] struct {
+	handler TypeHandler[T]
+}
+
+func (h genericTypeHandler[T]) scan(field reflect.Value, src any) error {
+	value, err := h.handler.Scan(src)
+	if err != nil {
+		return err
+	}
+	field.Set(reflect.ValueOf(&value).Elem())
+	return nil
+}
+
+func (h genericTypeHandler[T]) value(value reflect.Value) (any, error) {
+	return h.handler.Value(value.Interface().(T))
+}
+
+// lookupTypeHandler returns the registered handler of the type, or nil.
+func lookupTypeHandler(tp reflect.Type) typeHandler {
+	handler, ok := typeHandlerRegistry.Load(tp)
+	if !ok {
+		return nil
+	}
+	return handler.(typeHandler)
+}
+
+// typeHandlerOf returns the handler of the dynamic type of the value, and the value of that type.
+func typeHandlerOf(value reflect.Value) (typeHandler, reflect.Value) {
+	for value.Kind() == reflect.Interface && !value.IsNil() {
+		value = value.Elem()
+	}
+	if !value.IsValid() || value.Kind() == reflect.Interface {
+		return nil, value
+	}
+	return lookupTypeHandler(value.Type()), value
+}
+
+// ensure typeHandlerColumn implements sql.Scanner.
+var _ sql.Scanner = (*typeHandlerColumn)(nil) // compile time check
+
+// typeHandlerColumn is a scan destination which scans the column into the field by the handler.
+type typeHandlerColumn struct {
+	field   reflect.Value
+	handler typeHandler
+}
+
+// Scan implements sql.Scanner.
+func (c typeHandlerColumn) Scan(src any) error {
+	return c.handler.scan(c.field, src)
+}
@@ -0,0 +1,85 @@
+package juice
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	juicedriver "github.com/go-juicedev/juice/driver"
+	"github.com/go-juicedev/juice/eval"
+)
+
+// testCents is an amount of money in cents, stored as a decimal string.
+type testCents int64
+
+type testCentsHandler struct{}
+
+func (testCentsHandler) Scan(src any) (testCents, error) {
+	var text string
+	switch v := src.(type) {
+	case nil:
+		return 0, nil
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	default:
+		return 0, fmt.Errorf("can not scan %T into cents", src)
+	}
+	units, fraction, _ := strings.Cut(text, ".")
+	value, err := strconv.ParseInt(units+(fraction + "00")[:2], 10, 64)
+	return testCents(value), err
+}
+
+func (testCentsHandler) Value(value testCents) (driver.Value, error) {
+	return fmt.Sprintf("%d.%02d", value/100, value%100), nil
+}
+
+func TestTypeHandler_Scan(t *testing.T) {
+	RegisterTypeHandler[testCents](testCentsHandler{})
+	t.Cleanup(UnregisterTypeHandler[testCents])
+
+	type order struct {
+		ID     int64     `column:"id"`
+		Amount testCents `column:"amount"`
+	}
+	rows := queryFakeRows(t, fakeResultSet{
+		columns: []string{"id", "amount"},
+		rows:    [][]driver.Value{{int64(1), []byte("12.34")}, {int64(2), nil}},
+	})
+	orders, err := BindWithResultMap[[]order](rows, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 2 || orders[0].Amount != 1234 || orders[1].Amount != 0 {
+		t.Errorf("unexpected orders: %+v", orders)
+	}
+
+	rows = queryFakeRows(t, fakeResultSet{
+		columns: []string{"amount"},
+		rows:    [][]driver.Value{{"0.5"}},
+	})
+	amount, err := BindWithResultMap[testCents](rows, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if amount != 50 {
+		t.Errorf("expected 50, got %d", amount)
+	}
+}
+
+func TestTypeHandler_Bind(t *testing.T) {
+	RegisterTypeHandler[testCents](testCentsHandler{})
+	t.Cleanup(UnregisterTypeHandler[testCents])
+
+	node := NewTextNode("UPDATE orders SET amount = #{amount}")
+	_, args, err := node.Accept(juicedriver.MySQLDriver{}.Translator(), eval.H{"amount": testCents(1205)}.AsParam())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(args) != 1 || args[0] != "12.05" {
+		t.Errorf("unexpected args: %v", args)
+	}
+}